@@ -3,13 +3,25 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"runtime"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/dlovans/tenet/pkg/conformance"
+	"github.com/dlovans/tenet/pkg/dmn"
+	"github.com/dlovans/tenet/pkg/graph"
+	"github.com/dlovans/tenet/pkg/impact"
 	"github.com/dlovans/tenet/pkg/lint"
+	"github.com/dlovans/tenet/pkg/mutate"
+	"github.com/dlovans/tenet/pkg/rego"
+	"github.com/dlovans/tenet/pkg/source"
 	"github.com/dlovans/tenet/pkg/tenet"
 )
 
@@ -17,14 +29,67 @@ func main() {
 	// Define flags
 	runCmd := flag.NewFlagSet("run", flag.ExitOnError)
 	runDate := runCmd.String("date", "", "Effective date (ISO 8601 format, defaults to now)")
-	runFile := runCmd.String("file", "", "Input JSON file (or use stdin)")
+	runFile := runCmd.String("file", "", "Input JSON file or URL (or use stdin)")
+	runPin := runCmd.String("sha256", "", "Expected SHA-256 of the input (integrity pinning)")
+	runSkeleton := runCmd.Bool("skeleton", false, "Output structure only (all values stripped)")
+	runMeta := runCmd.Bool("meta", false, "Add a meta block (input hash, logic version, engine version, evaluation time)")
+	runCompletion := runCmd.Bool("completion", false, "Add a completion block (filled/required fields, per page)")
+	runAttestationFields := runCmd.Bool("attestation-fields", false, "Mirror attestation signature state as readonly definitions")
+	runVerbose := runCmd.Bool("verbose", false, "Emit UI defaults such as \"visible\": true on every field")
+	runStrict := runCmd.Bool("strict", false, "Fail on keys no schema field accepts (typos such as logci_tree) and on repeated keys")
+	runPatch := runCmd.Bool("patch", false, "Output a JSON Patch (RFC 6902) from the input file to the result")
+	var runSets setFlags
+	runCmd.Var(&runSets, "set", "Override a field value before running (field=value, repeatable)")
+	runPacks := runCmd.String("packs", "", "Directory or base URL holding rule packs (<name>.json)")
+	runOverlay := runCmd.String("overlay", "", "Overlay file or URL to patch the schema with (experiment variant)")
+	var runParams setFlags
+	runCmd.Var(&runParams, "param", "Template parameter value (name=value, repeatable)")
 
 	verifyCmd := flag.NewFlagSet("verify", flag.ExitOnError)
-	verifyNew := verifyCmd.String("new", "", "Completed document to verify")
-	verifyBase := verifyCmd.String("base", "", "Original base schema")
+	verifyNew := verifyCmd.String("new", "", "Completed document to verify (file, URL, or - for stdin)")
+	verifyBase := verifyCmd.String("base", "", "Original base schema (file, URL, or - for stdin)")
+	verifyPin := verifyCmd.String("base-sha256", "", "Expected SHA-256 of the base schema")
 
 	lintCmd := flag.NewFlagSet("lint", flag.ExitOnError)
 	lintFile := lintCmd.String("file", "", "JSON schema file to lint")
+	lintBaseline := lintCmd.String("baseline", "", "Baseline of accepted issues; only new issues are reported")
+	lintUpdate := lintCmd.Bool("update-baseline", false, "Write the current issues to the -baseline file and exit")
+	lintFailOn := lintCmd.String("fail-on", "error", "Lowest severity that fails the run: error or warning")
+
+	testCmd := flag.NewFlagSet("test", flag.ExitOnError)
+	testFile := testCmd.String("file", "", "JSON schema file with a tests array")
+
+	mutateCmd := flag.NewFlagSet("mutate", flag.ExitOnError)
+	mutateFile := mutateCmd.String("file", "", "JSON schema file with a tests array")
+	mutateMin := mutateCmd.Float64("min-score", 0, "Fail if the mutation score is below this value (0-1)")
+
+	benchCmd := flag.NewFlagSet("bench", flag.ExitOnError)
+	benchFile := benchCmd.String("file", "", "JSON schema file to benchmark")
+	benchValues := benchCmd.String("values", "", "JSON file of field values to apply before running")
+	benchN := benchCmd.Int("n", 1000, "Number of runs")
+	benchDate := benchCmd.String("date", "", "Effective date (ISO 8601 format, defaults to now)")
+
+	graphCmd := flag.NewFlagSet("graph", flag.ExitOnError)
+	graphFile := graphCmd.String("file", "", "JSON schema file to graph (or pass it as the first argument)")
+	graphFormat := graphCmd.String("o", "dot", "Output format: dot or mermaid")
+
+	importCmd := flag.NewFlagSet("import-dmn", flag.ExitOnError)
+	importFile := importCmd.String("file", "", "DMN model to convert (file or URL, or use stdin)")
+
+	regoCmd := flag.NewFlagSet("export-rego", flag.ExitOnError)
+	regoFile := regoCmd.String("file", "", "JSON schema file to export (or pass it as the first argument)")
+	regoPackage := regoCmd.String("package", "", "Rego package path (defaults to tenet.<schema_id>)")
+
+	impactCmd := flag.NewFlagSet("impact", flag.ExitOnError)
+	impactOld := impactCmd.String("old", "", "Current schema (file or URL)")
+	impactNew := impactCmd.String("new", "", "Proposed schema (file or URL)")
+	impactDocs := impactCmd.String("docs", "", "Directory of stored documents (*.json)")
+	impactDate := impactCmd.String("date", "", "Effective date (ISO 8601 format, defaults to now)")
+	impactVerbose := impactCmd.Bool("v", false, "List every document that differs")
+
+	conformanceCmd := flag.NewFlagSet("conformance", flag.ExitOnError)
+	conformanceExec := conformanceCmd.String("exec", "", "External evaluator command (schema on stdin, date as last argument); defaults to this build")
+	conformanceVectors := conformanceCmd.String("vectors", "", "Directory of test vectors (defaults to the official set)")
 
 	if len(os.Args) < 2 {
 		printUsage()
@@ -34,15 +99,62 @@ func main() {
 	switch os.Args[1] {
 	case "run":
 		runCmd.Parse(os.Args[2:])
-		handleRun(*runDate, *runFile)
+		handleRun(*runDate, *runFile, *runPin, *runPacks, *runOverlay, *runSkeleton, *runVerbose, *runMeta, *runCompletion, *runAttestationFields, *runStrict, *runPatch, runSets, runParams)
 
 	case "verify":
 		verifyCmd.Parse(os.Args[2:])
-		handleVerify(*verifyNew, *verifyBase)
+		handleVerify(*verifyNew, *verifyBase, *verifyPin)
 
 	case "lint":
 		lintCmd.Parse(os.Args[2:])
-		handleLint(*lintFile)
+		handleLint(*lintFile, *lintBaseline, *lintUpdate, *lintFailOn)
+
+	case "test":
+		testCmd.Parse(os.Args[2:])
+		handleTest(*testFile)
+
+	case "mutate":
+		mutateCmd.Parse(os.Args[2:])
+		handleMutate(*mutateFile, *mutateMin)
+
+	case "bench":
+		benchCmd.Parse(os.Args[2:])
+		handleBench(*benchFile, *benchValues, *benchDate, *benchN)
+
+	case "graph":
+		graphCmd.Parse(os.Args[2:])
+		// Allow `tenet graph schema.json -o mermaid`
+		if graphCmd.NArg() > 0 && *graphFile == "" {
+			*graphFile = graphCmd.Arg(0)
+			graphCmd.Parse(graphCmd.Args()[1:])
+		}
+		handleGraph(*graphFile, *graphFormat)
+
+	case "import-dmn":
+		importCmd.Parse(os.Args[2:])
+		handleImportDMN(*importFile)
+
+	case "export-rego":
+		regoCmd.Parse(os.Args[2:])
+		if regoCmd.NArg() > 0 && *regoFile == "" {
+			*regoFile = regoCmd.Arg(0)
+			regoCmd.Parse(regoCmd.Args()[1:])
+		}
+		handleExportRego(*regoFile, *regoPackage)
+
+	case "impact":
+		impactCmd.Parse(os.Args[2:])
+		handleImpact(*impactOld, *impactNew, *impactDocs, *impactDate, *impactVerbose)
+
+	case "store":
+		handleStore(os.Args[2:])
+
+	case "conformance":
+		conformanceCmd.Parse(os.Args[2:])
+		handleConformance(*conformanceExec, *conformanceVectors)
+
+	case "version":
+		fmt.Println(tenet.EngineVersion)
 
 	default:
 		printUsage()
@@ -50,22 +162,84 @@ func main() {
 	}
 }
 
+// packLoader loads rule packs from a directory or, for http(s) locations, a base URL.
+func packLoader(location string) tenet.PackLoader {
+	if location == "" {
+		return nil
+	}
+	if !source.IsRemote(location) {
+		return tenet.DirPackLoader(location)
+	}
+	return func(name string) (string, error) {
+		data, err := source.Read(strings.TrimSuffix(location, "/")+"/"+url.PathEscape(name)+".json", "")
+		return string(data), err
+	}
+}
+
+// setFlags collects repeated -set field=value (or -param name=value) flags.
+// Values are parsed as JSON when possible (580, true, null, "x"), otherwise taken as plain strings.
+type setFlags []setFlag
+
+type setFlag struct {
+	field string
+	value any
+}
+
+func (s *setFlags) String() string {
+	return fmt.Sprint(len(*s), " overrides")
+}
+
+func (s *setFlags) Set(arg string) error {
+	field, raw, ok := strings.Cut(arg, "=")
+	if !ok || field == "" {
+		return fmt.Errorf("expected field=value, got '%s'", arg)
+	}
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+	*s = append(*s, setFlag{field: field, value: value})
+	return nil
+}
+
+// values returns the overrides as a map; later flags win.
+func (s setFlags) values() map[string]any {
+	m := make(map[string]any, len(s))
+	for _, f := range s {
+		m[f.field] = f.value
+	}
+	return m
+}
+
 func printUsage() {
 	fmt.Println("Tenet VM - Declarative Logic Engine for JSON Schemas")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  tenet run [-date YYYY-MM-DD] [-file input.json]")
-	fmt.Println("  tenet verify -new completed.json -base schema.json")
-	fmt.Println("  tenet lint -file schema.json")
+	fmt.Println("  tenet run [-date YYYY-MM-DD] [-file input.json|https://...] [-sha256 HASH] [-skeleton] [-verbose] [-meta] [-completion] [-attestation-fields] [-strict] [-patch] [-set field=value ...] [-param name=value ...] [-packs DIR|URL] [-overlay FILE|URL]")
+	fmt.Println("  tenet verify -new completed.json|- -base schema.json|https://... [-base-sha256 HASH]")
+	fmt.Println("  tenet lint -file schema.json [-baseline baseline.json [-update-baseline]] [-fail-on error|warning]")
+	fmt.Println("  tenet test -file schema.json")
+	fmt.Println("  tenet mutate -file schema.json [-min-score 0.8]")
+	fmt.Println("  tenet bench -file schema.json [-values values.json] [-n 1000]")
+	fmt.Println("  tenet graph schema.json [-o dot|mermaid]")
+	fmt.Println("  tenet import-dmn -file model.dmn")
+	fmt.Println("  tenet export-rego schema.json [-package tenet.loans]")
+	fmt.Println("  tenet impact -old current.json -new proposed.json -docs ./corpus [-date YYYY-MM-DD] [-v]")
+	fmt.Println("  tenet store [-db tenet.db] save [-file result.json|-] [-schema schema.json] [-name NAME] | list | load -id N | verify -base schema.json")
+	fmt.Println("  tenet conformance [-exec \"node run.mjs\"] [-vectors DIR]")
+	fmt.Println("  tenet version")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  tenet run -date 2025-06-15 -file schema.json")
 	fmt.Println("  cat schema.json | tenet run -date 2025-06-15")
+	fmt.Println("  tenet run -file schema.json -set credit_score=580 -set employment_status=unemployed")
 	fmt.Println("  tenet lint -file schema.json")
 	fmt.Println("  tenet verify -new updated.json -base original.json")
+	fmt.Println("  tenet graph schema.json -o dot | dot -Tsvg > schema.svg")
+	fmt.Println("  tenet run -file schema.json | tenet verify -new - -base https://example.com/schema.json")
 }
 
-func handleRun(dateStr, filePath string) {
+func handleRun(dateStr, filePath, pin, packs, overlay string, skeleton, verbose, meta, completion, attestationFields, strict, patch bool, sets, params setFlags) {
 	// Parse date
 	effectiveDate := time.Now()
 	if dateStr != "" {
@@ -80,44 +254,154 @@ func handleRun(dateStr, filePath string) {
 		}
 	}
 
+	if patch && skeleton {
+		fmt.Fprintln(os.Stderr, "Error: -patch and -skeleton can't be combined")
+		os.Exit(1)
+	}
+
 	// Read input
 	var input []byte
 	var err error
 
-	if filePath != "" {
-		input, err = os.ReadFile(filePath)
-	} else {
-		input, err = io.ReadAll(os.Stdin)
+	if filePath == "" {
+		filePath = "-"
 	}
+	input, err = source.Read(filePath, pin)
 
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
 		os.Exit(1)
 	}
+	original := string(input)
+
+	// Check the document as written; the steps below re-encode it and drop unknown and repeated keys
+	if strict {
+		unknown, err := tenet.UnknownFields(string(input))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		duplicates, _ := tenet.DuplicateKeys(string(input))
+		for _, path := range unknown {
+			fmt.Fprintf(os.Stderr, "Error: unknown field '%s'\n", path)
+		}
+		for _, path := range duplicates {
+			fmt.Fprintf(os.Stderr, "Error: duplicate key '%s'\n", path)
+		}
+		if len(unknown) > 0 || len(duplicates) > 0 {
+			os.Exit(1)
+		}
+	}
+
+	// Substitute template parameters (a no-op for schemas that declare none)
+	concrete, err := tenet.Instantiate(string(input), params.values())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	input = []byte(concrete)
+
+	// Merge use_packs (a no-op for schemas that use none)
+	resolved, err := tenet.ResolvePacks(concrete, packLoader(packs))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	input = []byte(resolved)
+
+	// Expand use_templates (a no-op for schemas that use none)
+	expanded, err := tenet.ExpandTemplates(resolved)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	input = []byte(expanded)
+
+	if overlay != "" {
+		patch, err := source.Read(overlay, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading overlay: %v\n", err)
+			os.Exit(1)
+		}
+		patched, err := tenet.Overlay(expanded, string(patch))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		input = []byte(patched)
+	}
+
+	if len(sets) > 0 {
+		input, err = applyValues(input, sets.values())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Run the VM
-	result, err := tenet.Run(string(input), effectiveDate)
+	var opts []tenet.RunOption
+	if verbose {
+		opts = append(opts, tenet.WithVerboseOutput())
+	}
+	if meta {
+		opts = append(opts, tenet.WithMeta(nil))
+	}
+	if completion {
+		opts = append(opts, tenet.WithCompletion())
+	}
+	if attestationFields {
+		opts = append(opts, tenet.WithAttestationFields())
+	}
+	result, err := tenet.Run(string(input), effectiveDate, opts...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	if skeleton {
+		result, err = tenet.ExportSkeleton(result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Diff against the file as read, so -set values and expanded templates are part of the patch
+	if patch {
+		ops, err := tenet.Diff(original, result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if ops == nil {
+			ops = []tenet.PatchOp{}
+		}
+		out, _ := json.MarshalIndent(ops, "", "  ")
+		result = string(out)
+	}
+
 	fmt.Println(result)
 }
 
-func handleVerify(newPath, basePath string) {
+func handleVerify(newPath, basePath, basePin string) {
 	if newPath == "" || basePath == "" {
 		fmt.Fprintln(os.Stderr, "Error: Both -new and -base flags are required")
 		os.Exit(1)
 	}
 
-	newJson, err := os.ReadFile(newPath)
+	if newPath == "-" && basePath == "-" {
+		fmt.Fprintln(os.Stderr, "Error: Only one of -new and -base can read from stdin")
+		os.Exit(1)
+	}
+
+	newJson, err := source.Read(newPath, "")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading new file: %v\n", err)
 		os.Exit(1)
 	}
 
-	baseJson, err := os.ReadFile(basePath)
+	baseJson, err := source.Read(basePath, basePin)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading base schema: %v\n", err)
 		os.Exit(1)
@@ -134,23 +418,41 @@ func handleVerify(newPath, basePath string) {
 		fmt.Println("✓ Document verified: transformation is legal")
 	} else {
 		fmt.Println("✗ Document verification failed")
-		for _, issue := range result.Issues {
-			location := ""
-			if issue.FieldID != "" {
-				location = fmt.Sprintf(" [%s]", issue.FieldID)
-			}
-			fmt.Printf("  %s%s: %s\n", issue.Code, location, issue.Message)
+	}
+	for _, issue := range result.Issues {
+		icon := "✗"
+		if issue.Severity == tenet.VerifySeverityWarning {
+			icon = "⚠"
 		}
+		location := ""
+		if issue.FieldID != "" {
+			location = fmt.Sprintf(" [%s]", issue.FieldID)
+		}
+		fmt.Printf("  %s %s%s: %s\n", icon, issue.Code, location, issue.Message)
+		if issue.Remediation != nil {
+			fmt.Printf("    → %s\n", issue.Remediation.Description)
+		}
+	}
+	if !result.Valid {
 		os.Exit(1)
 	}
 }
 
-func handleLint(filePath string) {
+func handleLint(filePath, baselinePath string, updateBaseline bool, failOn string) {
+	if failOn != "error" && failOn != "warning" {
+		fmt.Fprintf(os.Stderr, "Unknown -fail-on %q (use error or warning)\n", failOn)
+		os.Exit(1)
+	}
+	if updateBaseline && baselinePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -update-baseline requires -baseline")
+		os.Exit(1)
+	}
+
 	var input []byte
 	var err error
 
 	if filePath != "" {
-		input, err = os.ReadFile(filePath)
+		input, err = source.Read(filePath, "")
 	} else {
 		input, err = io.ReadAll(os.Stdin)
 	}
@@ -166,8 +468,42 @@ func handleLint(filePath string) {
 		os.Exit(1)
 	}
 
+	if updateBaseline {
+		data, _ := json.MarshalIndent(lint.NewBaseline(result), "", "  ")
+		if err := os.WriteFile(baselinePath, append(data, '\n'), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing baseline: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Baseline written to %s (%d issues)\n", baselinePath, len(result.Issues))
+		return
+	}
+
+	suppressed := 0
+	if baselinePath != "" {
+		data, err := os.ReadFile(baselinePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading baseline: %v\n", err)
+			os.Exit(1)
+		}
+		var baseline lint.Baseline
+		if err := json.Unmarshal(data, &baseline); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing baseline: %v\n", err)
+			os.Exit(1)
+		}
+		filtered := baseline.Filter(result)
+		suppressed = len(result.Issues) - len(filtered.Issues)
+		if stale := baseline.Stale(result); len(stale) > 0 {
+			fmt.Printf("ℹ %d baseline issues no longer occur (run with -update-baseline to prune)\n", len(stale))
+		}
+		result = filtered
+	}
+
 	if len(result.Issues) == 0 {
-		fmt.Println("✓ No issues found")
+		if suppressed > 0 {
+			fmt.Printf("✓ No new issues found (%d suppressed by baseline)\n", suppressed)
+		} else {
+			fmt.Println("✓ No issues found")
+		}
 		return
 	}
 
@@ -186,8 +522,449 @@ func handleLint(filePath string) {
 		}
 		fmt.Printf("%s %s%s: %s\n", icon, issue.Severity, location, issue.Message)
 	}
+	if suppressed > 0 {
+		fmt.Printf("\n%d issues suppressed by baseline\n", suppressed)
+	}
 
-	if !result.Valid {
+	if !result.Valid || failOn == "warning" {
+		os.Exit(1)
+	}
+}
+
+func handleGraph(filePath, format string) {
+	var input []byte
+	var err error
+
+	if filePath != "" {
+		input, err = source.Read(filePath, "")
+	} else {
+		input, err = io.ReadAll(os.Stdin)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		os.Exit(1)
+	}
+
+	g, err := graph.Build(string(input))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Graph error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch format {
+	case "dot":
+		fmt.Print(g.DOT())
+	case "mermaid":
+		fmt.Print(g.Mermaid())
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown output format %q (use dot or mermaid)\n", format)
+		os.Exit(1)
+	}
+}
+
+func handleImportDMN(filePath string) {
+	if filePath == "" {
+		filePath = "-"
+	}
+	input, err := source.Read(filePath, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		os.Exit(1)
+	}
+
+	schema, err := dmn.Import(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(schema)
+}
+
+func handleExportRego(filePath, pkg string) {
+	var input []byte
+	var err error
+
+	if filePath != "" {
+		input, err = source.Read(filePath, "")
+	} else {
+		input, err = io.ReadAll(os.Stdin)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		os.Exit(1)
+	}
+
+	policy, err := rego.Export(string(input), pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Export error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(policy.Module)
+
+	// The module goes to stdout; untranslated constructs are reported on stderr
+	for _, gap := range policy.Gaps {
+		fmt.Fprintf(os.Stderr, "%s: %s: %s\n", gap.Location, gap.Construct, gap.Reason)
+	}
+}
+
+func handleImpact(oldPath, newPath, docsDir, dateStr string, verbose bool) {
+	if oldPath == "" || newPath == "" || docsDir == "" {
+		fmt.Fprintln(os.Stderr, "Error: -old, -new and -docs are required")
+		os.Exit(1)
+	}
+
+	effectiveDate := time.Now()
+	if dateStr != "" {
+		var err error
+		effectiveDate, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			effectiveDate, err = time.Parse(time.RFC3339, dateStr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Invalid date format '%s'\n", dateStr)
+				os.Exit(1)
+			}
+		}
+	}
+
+	oldSchema, err := source.Read(oldPath, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading old schema: %v\n", err)
+		os.Exit(1)
+	}
+	newSchema, err := source.Read(newPath, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading new schema: %v\n", err)
+		os.Exit(1)
+	}
+	docs, err := impact.LoadDir(docsDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading documents: %v\n", err)
+		os.Exit(1)
+	}
+
+	report, err := impact.Analyze(string(oldSchema), string(newSchema), docs, effectiveDate)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Impact error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d documents: %d flip status, %d change decisions, %d failed\n",
+		report.Total, report.StatusFlips, report.Changed, report.Failed)
+
+	transitions := make([]string, 0, len(report.Transitions))
+	for t := range report.Transitions {
+		transitions = append(transitions, t)
+	}
+	sort.Strings(transitions)
+	for _, t := range transitions {
+		fmt.Printf("  %-28s %d\n", t, report.Transitions[t])
+	}
+
+	if len(report.Rules) > 0 || report.Unattributed > 0 {
+		fmt.Println("\nBy rule:")
+		for _, r := range report.Rules {
+			fmt.Printf("  %-28s %d documents (%d status flips)\n", r.RuleID, r.Documents, r.StatusFlips)
+		}
+		if report.Unattributed > 0 {
+			fmt.Printf("  %-28s %d documents\n", "(no rule)", report.Unattributed)
+		}
+	}
+
+	for _, d := range report.Documents {
+		if d.Error != "" {
+			fmt.Fprintf(os.Stderr, "✗ %s: %s\n", d.Name, d.Error)
+			continue
+		}
+		if !verbose {
+			continue
+		}
+		cmp := d.Comparison
+		fmt.Printf("\n%s: %s -> %s\n", d.Name, cmp.StatusA, cmp.StatusB)
+		for _, f := range cmp.Fields {
+			fmt.Printf("    %s: %v -> %v\n", f.FieldID, f.A, f.B)
+		}
+		for _, e := range cmp.ErrorsAdded {
+			fmt.Printf("    + %s\n", e.Message)
+		}
+		for _, e := range cmp.ErrorsRemoved {
+			fmt.Printf("    - %s\n", e.Message)
+		}
+	}
+}
+
+func handleConformance(command, vectorsDir string) {
+	ev := conformance.Native()
+	if command != "" {
+		parts := strings.Fields(command)
+		ev = conformance.Command(parts[0], parts[1:]...)
+	}
+
+	var cases []conformance.Case
+	var err error
+	if vectorsDir != "" {
+		cases, err = conformance.LoadDir(vectorsDir)
+	} else {
+		cases, err = conformance.Vectors()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading vectors: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := conformance.Check(ev, cases)
+	for _, f := range report.Failures {
+		fmt.Printf("✗ %s\n", f.Case)
+		if f.Error != "" {
+			fmt.Printf("  error:    %s\n", f.Error)
+			continue
+		}
+		fmt.Printf("  expected: %s\n  got:      %s\n", f.Expected, f.Got)
+	}
+
+	fmt.Printf("\n%d/%d cases passed\n", report.Passed, report.Total)
+	if report.Passed != report.Total {
+		os.Exit(1)
+	}
+}
+
+func handleTest(filePath string) {
+	var input []byte
+	var err error
+
+	if filePath != "" {
+		input, err = source.Read(filePath, "")
+	} else {
+		input, err = io.ReadAll(os.Stdin)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		os.Exit(1)
+	}
+
+	report, err := tenet.RunSchemaTests(string(input))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Test error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, r := range report.Results {
+		if r.Passed {
+			fmt.Printf("✓ %s\n", r.Name)
+			continue
+		}
+		fmt.Printf("✗ %s\n", r.Name)
+		for _, f := range r.Failures {
+			fmt.Printf("    %s\n", f)
+		}
+	}
+	fmt.Printf("\n%d passed, %d failed\n", report.Passed, report.Failed)
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func handleMutate(filePath string, minScore float64) {
+	var input []byte
+	var err error
+
+	if filePath != "" {
+		input, err = source.Read(filePath, "")
+	} else {
+		input, err = io.ReadAll(os.Stdin)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := mutate.Run(string(input))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Mutate error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if result.Baseline != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", result.Baseline)
 		os.Exit(1)
 	}
+
+	for _, m := range result.Mutants {
+		if m.Killed {
+			continue
+		}
+		location := ""
+		if m.Rule != "" {
+			location = fmt.Sprintf(" [rule: %s]", m.Rule)
+		}
+		if m.Derived != "" {
+			location = fmt.Sprintf(" [derived: %s]", m.Derived)
+		}
+		fmt.Printf("⚠ survived%s: %s\n", location, m.Description)
+	}
+	fmt.Printf("\n%d mutants, %d killed, %d survived (score %.2f)\n",
+		result.Total, result.Killed, result.Survived, result.Score)
+
+	if result.Score < minScore {
+		os.Exit(1)
+	}
+}
+
+func handleBench(filePath, valuesPath, dateStr string, n int) {
+	if n <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -n must be positive")
+		os.Exit(1)
+	}
+
+	effectiveDate := time.Now()
+	if dateStr != "" {
+		var err error
+		effectiveDate, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			effectiveDate, err = time.Parse(time.RFC3339, dateStr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Invalid date format '%s'\n", dateStr)
+				os.Exit(1)
+			}
+		}
+	}
+
+	var input []byte
+	var err error
+
+	if filePath != "" {
+		input, err = source.Read(filePath, "")
+	} else {
+		input, err = io.ReadAll(os.Stdin)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		os.Exit(1)
+	}
+
+	if valuesPath != "" {
+		input, err = applyValuesFile(input, valuesPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	jsonText := string(input)
+
+	// Warm up and fail fast on invalid input
+	if _, err := tenet.Run(jsonText, effectiveDate); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	durations := make([]time.Duration, n)
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < n; i++ {
+		start := time.Now()
+		tenet.Run(jsonText, effectiveDate)
+		durations[i] = time.Since(start)
+	}
+	runtime.ReadMemStats(&after)
+
+	// Per-rule timings come from a separate pass so the clock reads don't skew latency figures
+	ruleTotals := make(map[string]time.Duration)
+	timer := tenet.WithRuleTimings(func(ruleID string, d time.Duration) {
+		ruleTotals[ruleID] += d
+	})
+	for i := 0; i < n; i++ {
+		tenet.Run(jsonText, effectiveDate, timer)
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+
+	fmt.Printf("%d runs\n", n)
+	fmt.Printf("  mean  %v\n", total/time.Duration(n))
+	fmt.Printf("  p50   %v\n", percentile(durations, 0.50))
+	fmt.Printf("  p95   %v\n", percentile(durations, 0.95))
+	fmt.Printf("  p99   %v\n", percentile(durations, 0.99))
+	fmt.Printf("  max   %v\n", durations[n-1])
+	fmt.Printf("  allocs/run  %d\n", (after.Mallocs-before.Mallocs)/uint64(n))
+	fmt.Printf("  bytes/run   %d\n", (after.TotalAlloc-before.TotalAlloc)/uint64(n))
+
+	if len(ruleTotals) == 0 {
+		return
+	}
+	ids := make([]string, 0, len(ruleTotals))
+	for id := range ruleTotals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ruleTotals[ids[i]] != ruleTotals[ids[j]] {
+			return ruleTotals[ids[i]] > ruleTotals[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > 10 {
+		ids = ids[:10]
+	}
+	fmt.Println("\nRule hotspots (mean per run):")
+	for _, id := range ids {
+		name := id
+		if name == "" {
+			name = "(unnamed)"
+		}
+		fmt.Printf("  %-30s %v\n", name, ruleTotals[id]/time.Duration(n))
+	}
+}
+
+// applyValuesFile patches definition values from a JSON object of field IDs to values.
+func applyValuesFile(input []byte, valuesPath string) ([]byte, error) {
+	raw, err := os.ReadFile(valuesPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading values file: %w", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("parsing values file: %w", err)
+	}
+	return applyValues(input, values)
+}
+
+// applyValues sets the value of existing definitions in a schema document.
+func applyValues(input []byte, values map[string]any) ([]byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(input, &doc); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	defs, _ := doc["definitions"].(map[string]any)
+	if defs == nil {
+		defs = make(map[string]any)
+		doc["definitions"] = defs
+	}
+	for id, value := range values {
+		def, ok := defs[id].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field '%s' does not exist", id)
+		}
+		def["value"] = value
+	}
+	return json.Marshal(doc)
+}
+
+// percentile returns the q-th quantile of sorted durations (nearest rank).
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	idx := int(q*float64(len(sorted))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
 }
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/dlovans/tenet/pkg/source"
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// storeSchema creates the local document store. schema_hash is the SHA-256 of the base
+// schema the document was created from, as recorded by Run's meta block or given to save.
+const storeSchema = `CREATE TABLE IF NOT EXISTS documents (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	schema_id TEXT NOT NULL,
+	schema_hash TEXT NOT NULL,
+	effective_date TEXT NOT NULL,
+	status TEXT NOT NULL,
+	saved_at TEXT NOT NULL,
+	document TEXT NOT NULL
+)`
+
+// handleStore runs `tenet store [-db FILE] save|list|load|verify ...`.
+func handleStore(args []string) {
+	storeCmd := flag.NewFlagSet("store", flag.ExitOnError)
+	dbPath := storeCmd.String("db", "tenet.db", "SQLite file holding the stored documents")
+	storeCmd.Parse(args)
+	if storeCmd.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Error: store needs an action: save, list, load or verify")
+		os.Exit(1)
+	}
+
+	db, err := sql.Open("sqlite", *dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening store: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	if _, err := db.Exec(storeSchema); err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening store: %v\n", err)
+		os.Exit(1)
+	}
+
+	action, rest := storeCmd.Arg(0), storeCmd.Args()[1:]
+	switch action {
+	case "save":
+		cmd := flag.NewFlagSet("store save", flag.ExitOnError)
+		file := cmd.String("file", "-", "Run result to save (file, URL, or - for stdin)")
+		schemaPath := cmd.String("schema", "", "Base schema the document was created from, recorded by hash")
+		name := cmd.String("name", "", "Name to list the document under (defaults to the file name)")
+		cmd.Parse(rest)
+		storeSave(db, *file, *schemaPath, *name)
+	case "list":
+		storeList(db)
+	case "load":
+		cmd := flag.NewFlagSet("store load", flag.ExitOnError)
+		id := cmd.Int64("id", 0, "ID of the document to print")
+		cmd.Parse(rest)
+		storeLoad(db, *id)
+	case "verify":
+		cmd := flag.NewFlagSet("store verify", flag.ExitOnError)
+		basePath := cmd.String("base", "", "Base schema (file or URL); documents saved with its hash are verified")
+		cmd.Parse(rest)
+		storeVerify(db, *basePath)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown store action '%s' (use save, list, load or verify)\n", action)
+		os.Exit(1)
+	}
+}
+
+func storeSave(db *sql.DB, file, schemaPath, name string) {
+	data, err := source.Read(file, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading document: %v\n", err)
+		os.Exit(1)
+	}
+	var doc tenet.Schema
+	if err := json.Unmarshal(data, &doc); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing document: %v\n", err)
+		os.Exit(1)
+	}
+
+	schemaHash, effectiveDate := "", doc.ValidFrom
+	if doc.Meta != nil {
+		schemaHash = doc.Meta.SchemaHash
+		effectiveDate = doc.Meta.EffectiveDate
+	}
+	if schemaPath != "" {
+		base, err := source.Read(schemaPath, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading schema: %v\n", err)
+			os.Exit(1)
+		}
+		schemaHash = sha256Hex(base)
+	}
+	if name == "" && file != "-" {
+		name = filepath.Base(file)
+	}
+
+	res, err := db.Exec(`INSERT INTO documents (name, schema_id, schema_hash, effective_date, status, saved_at, document)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		name, doc.SchemaID, schemaHash, effectiveDate, string(doc.Status), time.Now().UTC().Format(time.RFC3339), string(data))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
+		os.Exit(1)
+	}
+	id, _ := res.LastInsertId()
+	fmt.Println(id)
+}
+
+func storeList(db *sql.DB) {
+	rows, err := db.Query(`SELECT id, name, schema_id, schema_hash, effective_date, status, saved_at FROM documents ORDER BY id`)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing documents: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	fmt.Printf("%-6s %-24s %-20s %-12s %-12s %-10s %s\n", "ID", "NAME", "SCHEMA", "HASH", "DATE", "STATUS", "SAVED")
+	for rows.Next() {
+		var id int64
+		var name, schemaID, hash, date, status, savedAt string
+		if err := rows.Scan(&id, &name, &schemaID, &hash, &date, &status, &savedAt); err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing documents: %v\n", err)
+			os.Exit(1)
+		}
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		fmt.Printf("%-6d %-24s %-20s %-12s %-12s %-10s %s\n", id, name, schemaID, hash, date, status, savedAt)
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing documents: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func storeLoad(db *sql.DB, id int64) {
+	var doc string
+	err := db.QueryRow(`SELECT document FROM documents WHERE id = ?`, id).Scan(&doc)
+	if err == sql.ErrNoRows {
+		fmt.Fprintf(os.Stderr, "Error: no stored document with ID %d\n", id)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading document: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(doc)
+}
+
+// storeVerify verifies every document saved against the base schema's hash.
+func storeVerify(db *sql.DB, basePath string) {
+	if basePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -base is required")
+		os.Exit(1)
+	}
+	base, err := source.Read(basePath, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading base schema: %v\n", err)
+		os.Exit(1)
+	}
+	compiled, err := tenet.Compile(string(base))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error compiling base schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	rows, err := db.Query(`SELECT id, name, document FROM documents WHERE schema_hash = ? ORDER BY id`, compiled.Hash())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading documents: %v\n", err)
+		os.Exit(1)
+	}
+	defer rows.Close()
+
+	total, failed := 0, 0
+	for rows.Next() {
+		var id int64
+		var name, doc string
+		if err := rows.Scan(&id, &name, &doc); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading documents: %v\n", err)
+			os.Exit(1)
+		}
+		total++
+		vr := tenet.VerifyWithCompiled(doc, compiled)
+		if vr.Valid {
+			continue
+		}
+		failed++
+		fmt.Printf("✗ %d %s\n", id, name)
+		for _, issue := range vr.Issues {
+			fmt.Printf("    %s: %s\n", issue.Code, issue.Message)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading documents: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d documents verified, %d failed\n", total, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
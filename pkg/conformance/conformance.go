@@ -0,0 +1,120 @@
+// Package conformance checks that an evaluator produces exactly the results this
+// package's engine does. Clients verify server results and servers verify client
+// results, so every build — native Go, the TypeScript package, or a WASM build — must
+// agree byte for byte on the canonical form of each case's output.
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// Case is one canonical input/output pair.
+type Case struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"` // What semantics the case pins down
+	Date        string `json:"date"`                  // Effective date (YYYY-MM-DD)
+	Input       string `json:"input"`                 // Schema passed to Run
+	Expected    string `json:"expected"`              // Canonical Run output
+}
+
+// Evaluator runs a schema for an effective date (YYYY-MM-DD) and returns the completed document.
+type Evaluator interface {
+	Evaluate(schema, date string) (string, error)
+}
+
+// EvaluatorFunc adapts a function to Evaluator.
+type EvaluatorFunc func(schema, date string) (string, error)
+
+// Evaluate calls f.
+func (f EvaluatorFunc) Evaluate(schema, date string) (string, error) {
+	return f(schema, date)
+}
+
+// Native evaluates with this package's engine. Vectors pin the verbose wire format
+// (every field carries "visible"), which all implementations share.
+func Native() Evaluator {
+	return EvaluatorFunc(func(schema, date string) (string, error) {
+		d, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return "", fmt.Errorf("invalid date %q: %w", date, err)
+		}
+		return tenet.Run(schema, d, tenet.WithVerboseOutput())
+	})
+}
+
+// Command evaluates by running an external program — for example a Node script
+// wrapping the TypeScript or WASM build. The program receives the effective date as
+// its last argument and the schema on stdin, and must print the completed document.
+func Command(name string, args ...string) Evaluator {
+	return EvaluatorFunc(func(schema, date string) (string, error) {
+		cmd := exec.Command(name, append(append([]string{}, args...), date)...)
+		cmd.Stdin = strings.NewReader(schema)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+		}
+		return string(out), nil
+	})
+}
+
+// Canonicalize rewrites a JSON document in canonical form: object keys sorted,
+// no insignificant whitespace, numbers in shortest round-trip form.
+func Canonicalize(jsonText string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(jsonText))
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", fmt.Errorf("parse: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// Failure is a case whose canonical output differed from the expected output.
+type Failure struct {
+	Case     string `json:"case"`
+	Expected string `json:"expected"`
+	Got      string `json:"got,omitempty"`
+	Error    string `json:"error,omitempty"` // Evaluation or parse error, if any
+}
+
+// Report summarizes a conformance run.
+type Report struct {
+	Total    int       `json:"total"`
+	Passed   int       `json:"passed"`
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// Check runs every case through ev and compares canonical outputs byte for byte.
+func Check(ev Evaluator, cases []Case) Report {
+	report := Report{Total: len(cases)}
+	for _, c := range cases {
+		out, err := ev.Evaluate(c.Input, c.Date)
+		if err == nil {
+			out, err = Canonicalize(out)
+		}
+		if err != nil {
+			report.Failures = append(report.Failures, Failure{Case: c.Name, Expected: c.Expected, Error: err.Error()})
+			continue
+		}
+		if out != c.Expected {
+			report.Failures = append(report.Failures, Failure{Case: c.Name, Expected: c.Expected, Got: out})
+			continue
+		}
+		report.Passed++
+	}
+	return report
+}
@@ -0,0 +1,71 @@
+package conformance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNative(t *testing.T) {
+	cases, err := Vectors()
+	if err != nil {
+		t.Fatalf("Vectors failed: %v", err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("expected embedded vectors")
+	}
+	report := Check(Native(), cases)
+	if report.Passed != report.Total {
+		for _, f := range report.Failures {
+			t.Errorf("case %s:\n expected %s\n      got %s %s", f.Case, f.Expected, f.Got, f.Error)
+		}
+	}
+}
+
+func TestCheckReportsDifferences(t *testing.T) {
+	ev := EvaluatorFunc(func(schema, date string) (string, error) {
+		out, err := Native().Evaluate(schema, date)
+		return strings.Replace(out, `"READY"`, `"INVALID"`, 1), err
+	})
+
+	cases, _ := Vectors()
+	report := Check(ev, cases)
+	if len(report.Failures) == 0 {
+		t.Fatal("expected failures for an evaluator that changes READY to INVALID")
+	}
+	for _, f := range report.Failures {
+		if !strings.Contains(f.Expected, `"READY"`) {
+			t.Errorf("case %s failed but expected no READY status", f.Case)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	got, err := Canonicalize("{\n  \"b\": 1.50, \"a\": [true, null, \"<x>\"]\n}")
+	if err != nil {
+		t.Fatalf("Canonicalize failed: %v", err)
+	}
+	if want := `{"a":[true,null,"<x>"],"b":1.5}`; got != want {
+		t.Errorf("Canonicalize = %s, want %s", got, want)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	vector := `{"date": "2025-06-01", "input": {"definitions": {}}, "expected": {"status": "READY", "definitions": {}}}`
+	if err := os.WriteFile(filepath.Join(dir, "empty_schema.json"), []byte(vector), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if len(cases) != 1 || cases[0].Name != "empty_schema" || cases[0].Expected != `{"definitions":{},"status":"READY"}` {
+		t.Fatalf("unexpected cases: %+v", cases)
+	}
+	if report := Check(Native(), cases); report.Passed != 1 {
+		t.Fatalf("expected the vector to pass, got %+v", report.Failures)
+	}
+}
@@ -0,0 +1,82 @@
+package conformance
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+)
+
+// vectors holds the official test vectors, one JSON file per case:
+//
+//	{"name": "...", "description": "...", "date": "YYYY-MM-DD", "input": {schema}, "expected": {completed document}}
+//
+//go:embed vectors/*.json
+var vectors embed.FS
+
+// vectorFile is the on-disk form of a Case. Input and expected are JSON objects rather
+// than strings so the files read naturally in any language.
+type vectorFile struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Date        string          `json:"date"`
+	Input       json.RawMessage `json:"input"`
+	Expected    json.RawMessage `json:"expected"`
+}
+
+// Vectors returns the official conformance cases shipped with this package.
+func Vectors() ([]Case, error) {
+	sub, err := fs.Sub(vectors, "vectors")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+// LoadDir reads test vectors from a directory of .json files.
+func LoadDir(dir string) ([]Case, error) {
+	return Load(os.DirFS(dir))
+}
+
+// Load reads every .json file at the root of fsys as a test vector, in file name order.
+// Expected outputs are canonicalized on load.
+func Load(fsys fs.FS) ([]Case, error) {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	cases := make([]Case, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var v vectorFile
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if v.Name == "" {
+			v.Name = name[:len(name)-len(path.Ext(name))]
+		}
+		if len(v.Input) == 0 || len(v.Expected) == 0 {
+			return nil, fmt.Errorf("%s: input and expected are required", name)
+		}
+		expected, err := Canonicalize(string(v.Expected))
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected: %w", name, err)
+		}
+		cases = append(cases, Case{
+			Name:        v.Name,
+			Description: v.Description,
+			Date:        v.Date,
+			Input:       string(v.Input),
+			Expected:    expected,
+		})
+	}
+	return cases, nil
+}
@@ -0,0 +1,414 @@
+// Package dmn imports DMN (Decision Model and Notation) models into Tenet schemas.
+// Decision tables become Tenet decision tables and literal expressions become derived
+// values. Only the FEEL subset that has a direct Tenet equivalent is accepted; anything
+// else is reported as an error naming the decision and rule, rather than approximated.
+package dmn
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// XML model (namespaces are ignored, so DMN 1.1 through 1.5 files decode alike).
+
+type definitions struct {
+	ID        string     `xml:"id,attr"`
+	Name      string     `xml:"name,attr"`
+	Decisions []decision `xml:"decision"`
+}
+
+type decision struct {
+	ID                string             `xml:"id,attr"`
+	Name              string             `xml:"name,attr"`
+	Variable          *variable          `xml:"variable"`
+	DecisionTable     *decisionTable     `xml:"decisionTable"`
+	LiteralExpression *literalExpression `xml:"literalExpression"`
+}
+
+type variable struct {
+	Name    string `xml:"name,attr"`
+	TypeRef string `xml:"typeRef,attr"`
+}
+
+type decisionTable struct {
+	HitPolicy string   `xml:"hitPolicy,attr"`
+	Inputs    []input  `xml:"input"`
+	Outputs   []output `xml:"output"`
+	Rules     []rule   `xml:"rule"`
+}
+
+type input struct {
+	Label           string          `xml:"label,attr"`
+	InputExpression inputExpression `xml:"inputExpression"`
+}
+
+type inputExpression struct {
+	TypeRef string `xml:"typeRef,attr"`
+	Text    string `xml:"text"`
+}
+
+type output struct {
+	Name    string `xml:"name,attr"`
+	Label   string `xml:"label,attr"`
+	TypeRef string `xml:"typeRef,attr"`
+}
+
+type rule struct {
+	ID           string  `xml:"id,attr"`
+	InputEntries []entry `xml:"inputEntry"`
+	OutputEntry  []entry `xml:"outputEntry"`
+}
+
+type entry struct {
+	Text string `xml:"text"`
+}
+
+type literalExpression struct {
+	TypeRef string `xml:"typeRef,attr"`
+	Text    string `xml:"text"`
+}
+
+// identifier matches names usable as Tenet field IDs (dotted paths for inputs).
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// Import converts a DMN model to a Tenet schema (JSON). Each decision table becomes a
+// decision table whose input columns are fields (the inputExpression text must be a
+// field name) and whose outputs are readonly fields; each literal expression becomes a
+// derived value named after the decision's variable. Definitions are created for every
+// input and output, typed from typeRef.
+//
+// Hit policies UNIQUE, FIRST and ANY import as "first"; RULE ORDER and COLLECT without
+// an aggregator import as "all". Decisions are emitted in document order.
+func Import(data []byte) (string, error) {
+	var defs definitions
+	if err := xml.Unmarshal(data, &defs); err != nil {
+		return "", fmt.Errorf("parse DMN: %w", err)
+	}
+	if len(defs.Decisions) == 0 {
+		return "", fmt.Errorf("DMN model has no decisions")
+	}
+
+	schema := &tenet.Schema{
+		SchemaID:    defs.ID,
+		Definitions: make(map[string]*tenet.Definition),
+	}
+	for _, d := range defs.Decisions {
+		name := d.ID
+		if name == "" {
+			name = d.Name
+		}
+		var err error
+		switch {
+		case d.DecisionTable != nil:
+			err = importTable(schema, name, d.DecisionTable)
+		case d.LiteralExpression != nil:
+			err = importLiteral(schema, d)
+		default:
+			err = fmt.Errorf("only decision tables and literal expressions are supported")
+		}
+		if err != nil {
+			return "", fmt.Errorf("decision '%s': %w", name, err)
+		}
+	}
+
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// importTable converts a decisionTable and declares its input and output fields.
+func importTable(schema *tenet.Schema, id string, dt *decisionTable) error {
+	table := &tenet.DecisionTable{ID: id}
+	switch strings.ToUpper(strings.TrimSpace(dt.HitPolicy)) {
+	case "", "UNIQUE", "FIRST", "ANY":
+		table.HitPolicy = tenet.HitFirst
+	case "RULE ORDER", "COLLECT":
+		table.HitPolicy = tenet.HitAll
+	default:
+		return fmt.Errorf("unsupported hit policy '%s'", dt.HitPolicy)
+	}
+
+	for _, in := range dt.Inputs {
+		field := strings.TrimSpace(in.InputExpression.Text)
+		if !identifier.MatchString(field) {
+			return fmt.Errorf("input expression '%s' is not a field name", field)
+		}
+		table.Inputs = append(table.Inputs, field)
+		root, _, _ := strings.Cut(field, ".")
+		declare(schema, root, in.InputExpression.TypeRef, in.Label, false)
+	}
+	for _, out := range dt.Outputs {
+		if !identifier.MatchString(out.Name) || strings.Contains(out.Name, ".") {
+			return fmt.Errorf("output name '%s' is not a field name", out.Name)
+		}
+		table.Outputs = append(table.Outputs, out.Name)
+		declare(schema, out.Name, out.TypeRef, out.Label, true)
+	}
+
+	for i, r := range dt.Rules {
+		where := r.ID
+		if where == "" {
+			where = strconv.Itoa(i + 1)
+		}
+		if len(r.InputEntries) != len(table.Inputs) || len(r.OutputEntry) != len(table.Outputs) {
+			return fmt.Errorf("rule %s: entries don't match the table's columns", where)
+		}
+		row := &tenet.DecisionRow{}
+		for col, e := range r.InputEntries {
+			cell, err := unaryTest(table.Inputs[col], e.Text)
+			if err != nil {
+				return fmt.Errorf("rule %s, input '%s': %w", where, table.Inputs[col], err)
+			}
+			row.When = append(row.When, cell)
+		}
+		for col, e := range r.OutputEntry {
+			val, err := outputValue(e.Text)
+			if err != nil {
+				return fmt.Errorf("rule %s, output '%s': %w", where, table.Outputs[col], err)
+			}
+			row.Then = append(row.Then, val)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	schema.DecisionTables = append(schema.DecisionTables, table)
+	return nil
+}
+
+// importLiteral converts a literal expression decision to a derived value.
+func importLiteral(schema *tenet.Schema, d decision) error {
+	name := d.Name
+	if d.Variable != nil && d.Variable.Name != "" {
+		name = d.Variable.Name
+	}
+	if !identifier.MatchString(name) || strings.Contains(name, ".") {
+		return fmt.Errorf("variable '%s' is not a field name", name)
+	}
+	expr, err := feelExpr(d.LiteralExpression.Text)
+	if err != nil {
+		return err
+	}
+	if schema.StateModel == nil {
+		schema.StateModel = &tenet.StateModel{Derived: make(map[string]*tenet.DerivedDef)}
+	}
+	schema.StateModel.Derived[name] = &tenet.DerivedDef{Eval: expr}
+	return nil
+}
+
+// declare adds a definition for a field unless one exists. Outputs are readonly.
+func declare(schema *tenet.Schema, id, typeRef, label string, readonly bool) {
+	if _, ok := schema.Definitions[id]; ok {
+		return
+	}
+	def := &tenet.Definition{Type: tenetType(typeRef), Label: label}
+	if readonly {
+		def.SetReadonly(true)
+	}
+	schema.Definitions[id] = def
+}
+
+// tenetType maps a FEEL typeRef to a Tenet definition type.
+func tenetType(typeRef string) string {
+	switch strings.ToLower(strings.TrimPrefix(typeRef, "feel:")) {
+	case "number", "integer", "long", "double":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "date", "date and time":
+		return "date"
+	default:
+		return "string"
+	}
+}
+
+// unaryTest converts a FEEL unary test to a decision table cell for field.
+func unaryTest(field, text string) (any, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || text == "-" {
+		return "-", nil
+	}
+
+	if inner, ok := strings.CutPrefix(text, "not("); ok && strings.HasSuffix(inner, ")") {
+		cond, err := unaryCondition(field, strings.TrimSuffix(inner, ")"))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"!": cond}, nil
+	}
+
+	parts := splitList(text)
+	if len(parts) > 1 {
+		list := make([]any, 0, len(parts))
+		for _, p := range parts {
+			v, err := literal(p)
+			if err != nil {
+				return nil, fmt.Errorf("unsupported list entry '%s'", p)
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	}
+
+	// Closed ranges map to the table's own range syntax
+	if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+		if lo, hi, ok := strings.Cut(text[1:len(text)-1], ".."); ok {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(lo), 64); err == nil {
+				if _, err := strconv.ParseFloat(strings.TrimSpace(hi), 64); err == nil {
+					return strings.TrimSpace(lo) + ".." + strings.TrimSpace(hi), nil
+				}
+			}
+		}
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if rest, ok := strings.CutPrefix(text, op); ok {
+			v, err := literal(strings.TrimSpace(rest))
+			if err != nil {
+				return nil, fmt.Errorf("unsupported comparison '%s'", text)
+			}
+			return map[string]any{op: []any{map[string]any{"var": field}, v}}, nil
+		}
+	}
+
+	v, err := literal(text)
+	if err != nil {
+		// Anything else needs the general form (open ranges, not(list), ...)
+		return unaryCondition(field, text)
+	}
+	if s, ok := v.(string); ok {
+		// Keep strings that the table would read as syntax unambiguous
+		if s == "" || s == "-" || strings.ContainsAny(s[:1], "<>=!") || strings.Contains(s, "..") {
+			return map[string]any{"==": []any{map[string]any{"var": field}, s}}, nil
+		}
+	}
+	return v, nil
+}
+
+// unaryCondition converts a FEEL unary test to a JSON-logic condition on field.
+func unaryCondition(field, text string) (any, error) {
+	text = strings.TrimSpace(text)
+	v := map[string]any{"var": field}
+
+	if parts := splitList(text); len(parts) > 1 {
+		conds := make([]any, 0, len(parts))
+		for _, p := range parts {
+			c, err := unaryCondition(field, p)
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, c)
+		}
+		return map[string]any{"or": conds}, nil
+	}
+
+	if len(text) >= 2 && strings.ContainsAny(text[:1], "[(]") && strings.ContainsAny(text[len(text)-1:], "])[") {
+		if lo, hi, ok := strings.Cut(text[1:len(text)-1], ".."); ok {
+			low, errLo := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+			high, errHi := strconv.ParseFloat(strings.TrimSpace(hi), 64)
+			if errLo != nil || errHi != nil {
+				return nil, fmt.Errorf("unsupported range '%s'", text)
+			}
+			lowOp, highOp := ">=", "<="
+			if text[0] != '[' {
+				lowOp = ">"
+			}
+			if text[len(text)-1] != ']' {
+				highOp = "<"
+			}
+			return map[string]any{"and": []any{
+				map[string]any{lowOp: []any{v, low}},
+				map[string]any{highOp: []any{v, high}},
+			}}, nil
+		}
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if rest, ok := strings.CutPrefix(text, op); ok {
+			val, err := literal(strings.TrimSpace(rest))
+			if err != nil {
+				return nil, fmt.Errorf("unsupported comparison '%s'", text)
+			}
+			return map[string]any{op: []any{v, val}}, nil
+		}
+	}
+
+	val, err := literal(text)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported FEEL unary test '%s'", text)
+	}
+	return map[string]any{"==": []any{v, val}}, nil
+}
+
+// outputValue converts a FEEL output entry: a literal, or an expression over fields.
+func outputValue(text string) (any, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || text == "-" {
+		return "-", nil
+	}
+	if text == "null" {
+		return nil, nil
+	}
+	if v, err := literal(text); err == nil {
+		return v, nil
+	}
+	return feelExpr(text)
+}
+
+// feelExpr converts a FEEL expression to JSON-logic. FEEL's arithmetic, comparisons,
+// and/or, not() and if-then-else read the same as Tenet's infix syntax.
+func feelExpr(text string) (any, error) {
+	expr, err := tenet.ParseExpr(strings.TrimSpace(text))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported FEEL expression '%s': %w", strings.TrimSpace(text), err)
+	}
+	return expr, nil
+}
+
+// literal parses a FEEL string, number or boolean literal.
+func literal(text string) (any, error) {
+	switch text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseFloat(text, 64); err == nil {
+		return n, nil
+	}
+	if len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"' {
+		if s, err := strconv.Unquote(text); err == nil {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("not a literal: %s", text)
+}
+
+// splitList splits a comma-separated FEEL list, ignoring commas inside strings,
+// brackets and parentheses.
+func splitList(text string) []string {
+	var parts []string
+	depth, start, quoted := 0, 0, false
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"' && (i == 0 || text[i-1] != '\\'):
+			quoted = !quoted
+		case quoted:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(text[start:]))
+}
@@ -0,0 +1,110 @@
+package dmn
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+const model = `<?xml version="1.0" encoding="UTF-8"?>
+<definitions xmlns="https://www.omg.org/spec/DMN/20191111/MODEL/" id="loans" name="Loans" namespace="https://example.com/dmn">
+  <decision id="dti" name="dti">
+    <variable name="dti" typeRef="number"/>
+    <literalExpression><text>loan_amount / income</text></literalExpression>
+  </decision>
+  <decision id="eligibility" name="Eligibility">
+    <decisionTable hitPolicy="FIRST">
+      <input label="Credit score"><inputExpression typeRef="number"><text>credit_score</text></inputExpression></input>
+      <input label="Employment"><inputExpression typeRef="string"><text>employment</text></inputExpression></input>
+      <output name="decision" typeRef="string"/>
+      <output name="rate" typeRef="number"/>
+      <rule id="r1">
+        <inputEntry><text>&gt;= 720</text></inputEntry>
+        <inputEntry><text>"employed","self_employed"</text></inputEntry>
+        <outputEntry><text>"approve"</text></outputEntry>
+        <outputEntry><text>0.05</text></outputEntry>
+      </rule>
+      <rule id="r2">
+        <inputEntry><text>[640..720)</text></inputEntry>
+        <inputEntry><text>not("unemployed")</text></inputEntry>
+        <outputEntry><text>"approve"</text></outputEntry>
+        <outputEntry><text>base_rate + 0.03</text></outputEntry>
+      </rule>
+      <rule id="r3">
+        <inputEntry><text>-</text></inputEntry>
+        <inputEntry><text>-</text></inputEntry>
+        <outputEntry><text>"review"</text></outputEntry>
+        <outputEntry><text>-</text></outputEntry>
+      </rule>
+    </decisionTable>
+  </decision>
+</definitions>`
+
+func TestImport(t *testing.T) {
+	out, err := Import([]byte(model))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	var schema tenet.Schema
+	if err := json.Unmarshal([]byte(out), &schema); err != nil {
+		t.Fatalf("bad output: %v", err)
+	}
+	if len(schema.DecisionTables) != 1 || schema.DecisionTables[0].HitPolicy != tenet.HitFirst {
+		t.Fatalf("expected one first-hit table, got %+v", schema.DecisionTables)
+	}
+	if def := schema.Definitions["decision"]; def == nil || !def.IsReadonly() || def.Type != "string" {
+		t.Errorf("expected readonly string output 'decision', got %+v", def)
+	}
+	if def := schema.Definitions["credit_score"]; def == nil || def.Type != "number" {
+		t.Errorf("expected number input 'credit_score', got %+v", def)
+	}
+	if schema.StateModel == nil || schema.StateModel.Derived["dti"] == nil {
+		t.Fatal("expected derived value 'dti'")
+	}
+
+	// Fill in the inputs the model references and evaluate the imported schema
+	values := map[string]any{"credit_score": 700, "employment": "self_employed", "base_rate": 0.04, "loan_amount": 200000, "income": 50000}
+	for id, v := range values {
+		if schema.Definitions[id] == nil {
+			schema.Definitions[id] = &tenet.Definition{Type: "number"}
+		}
+		schema.Definitions[id].Value = v
+	}
+	data, _ := json.Marshal(&schema)
+	result, err := tenet.Run(string(data), time.Now())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var evaluated tenet.Schema
+	if err := json.Unmarshal([]byte(result), &evaluated); err != nil {
+		t.Fatalf("bad result: %v", err)
+	}
+	if got := evaluated.Definitions["decision"].Value; got != "approve" {
+		t.Errorf("decision = %v, want approve", got)
+	}
+	if got, _ := evaluated.Definitions["rate"].Value.(float64); got < 0.0699 || got > 0.0701 {
+		t.Errorf("rate = %v, want 0.07", evaluated.Definitions["rate"].Value)
+	}
+	if got := evaluated.Definitions["dti"].Value; got != float64(4) {
+		t.Errorf("dti = %v, want 4", got)
+	}
+}
+
+func TestImportUnsupported(t *testing.T) {
+	tests := []struct {
+		name, from, to, want string
+	}{
+		{"hit policy", `hitPolicy="FIRST"`, `hitPolicy="PRIORITY"`, "unsupported hit policy"},
+		{"input expression", `<text>credit_score</text>`, `<text>credit score * 2</text>`, "is not a field name"},
+		{"unary test", `<text>&gt;= 720</text>`, `<text>matches(x, "a")</text>`, "rule r1, input 'credit_score'"},
+	}
+	for _, tt := range tests {
+		_, err := Import([]byte(strings.Replace(model, tt.from, tt.to, 1)))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Package graph extracts the dependency structure of a schema — which fields feed which
+// derived values and rules, and which fields each rule sets — and renders it as
+// Graphviz DOT or Mermaid for review.
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// Node kinds.
+const (
+	KindField   = "field"
+	KindDerived = "derived"
+	KindRule    = "rule"
+)
+
+// Edge kinds.
+const (
+	EdgeReads = "reads" // Field or derived value → derived value or rule that uses it
+	EdgeSets  = "sets"  // Rule → field it assigns
+)
+
+// Node is a field, derived value or rule.
+type Node struct {
+	ID   string `json:"id"`   // Kind-prefixed identifier, e.g. "field:income"
+	Kind string `json:"kind"` // KindField, KindDerived or KindRule
+	Name string `json:"name"` // Field name or rule ID
+}
+
+// Edge is a dependency between two nodes.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"` // EdgeReads or EdgeSets
+}
+
+// Graph is the dependency graph of a schema.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Build parses a schema and returns its dependency graph:
+// fields → derived values → rules → fields the rules set.
+// Variables that aren't defined anywhere still appear as field nodes.
+func Build(jsonText string) (*Graph, error) {
+	var s tenet.Schema
+	if err := json.Unmarshal([]byte(jsonText), &s); err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+
+	b := &builder{index: make(map[string]int), seen: make(map[Edge]bool)}
+
+	derived := make(map[string]*tenet.DerivedDef)
+	if s.StateModel != nil {
+		derived = s.StateModel.Derived
+	}
+	nodeFor := func(name string) string {
+		if _, ok := derived[name]; ok {
+			return b.node(KindDerived, name)
+		}
+		return b.node(KindField, name)
+	}
+
+	for _, name := range sortedKeys(s.Definitions) {
+		nodeFor(name)
+	}
+	for _, name := range sortedKeys(derived) {
+		to := b.node(KindDerived, name)
+		if derived[name] == nil {
+			continue
+		}
+		exprs := []any{derived[name].Eval}
+		for _, scope := range sortedKeys(derived[name].ByJurisdiction) {
+			exprs = append(exprs, derived[name].ByJurisdiction[scope])
+		}
+		for _, expr := range exprs {
+			if text, ok := expr.(string); ok {
+				if expr, _ = tenet.ParseEval(text); expr == text {
+					continue // String constant
+				}
+			}
+			for _, v := range extractVars(compile(expr)) {
+				b.edge(nodeFor(v), to, EdgeReads)
+			}
+		}
+	}
+
+	for _, rule := range s.LogicTree {
+		if rule == nil {
+			continue
+		}
+		id := b.node(KindRule, rule.ID)
+
+		conditions := []any{compile(rule.When), compile(rule.Unless)}
+		for _, cond := range rule.WhenAny {
+			conditions = append(conditions, compile(cond))
+		}
+		if rule.Then != nil {
+			for _, field := range sortedKeys(rule.Then.Set) {
+				conditions = append(conditions, rule.Then.Set[field])
+			}
+		}
+		for _, v := range extractVars(conditions) {
+			b.edge(nodeFor(v), id, EdgeReads)
+		}
+
+		if rule.Then != nil {
+			for _, field := range sortedKeys(rule.Then.Set) {
+				b.edge(id, nodeFor(field), EdgeSets)
+			}
+		}
+	}
+
+	return &b.graph, nil
+}
+
+// DOT renders the graph in Graphviz DOT format.
+// Fields are ellipses, derived values parallelograms and rules boxes; set edges are bold.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph tenet {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		shape := map[string]string{KindField: "ellipse", KindDerived: "parallelogram", KindRule: "box"}[n.Kind]
+		fmt.Fprintf(&sb, "  %s [label=%s, shape=%s];\n", dotQuote(n.ID), dotQuote(n.Name), shape)
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if e.Kind == EdgeSets {
+			style = " [style=bold]"
+		}
+		fmt.Fprintf(&sb, "  %s -> %s%s;\n", dotQuote(e.From), dotQuote(e.To), style)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart.
+// Fields are stadiums, derived values parallelograms and rules rectangles; set edges are thick.
+func (g *Graph) Mermaid() string {
+	ids := make(map[string]string, len(g.Nodes))
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		label := `"` + strings.ReplaceAll(n.Name, `"`, "#quot;") + `"`
+		switch n.Kind {
+		case KindField:
+			fmt.Fprintf(&sb, "  n%d([%s])\n", i, label)
+		case KindDerived:
+			fmt.Fprintf(&sb, "  n%d[/%s/]\n", i, label)
+		default:
+			fmt.Fprintf(&sb, "  n%d[%s]\n", i, label)
+		}
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == EdgeSets {
+			arrow = "==>"
+		}
+		fmt.Fprintf(&sb, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+	}
+	return sb.String()
+}
+
+// builder accumulates nodes and edges without duplicates, in first-seen order.
+type builder struct {
+	graph Graph
+	index map[string]int
+	seen  map[Edge]bool
+}
+
+func (b *builder) node(kind, name string) string {
+	id := kind + ":" + name
+	if _, ok := b.index[id]; !ok {
+		b.index[id] = len(b.graph.Nodes)
+		b.graph.Nodes = append(b.graph.Nodes, Node{ID: id, Kind: kind, Name: name})
+	}
+	return id
+}
+
+func (b *builder) edge(from, to, kind string) {
+	e := Edge{From: from, To: to, Kind: kind}
+	if !b.seen[e] {
+		b.seen[e] = true
+		b.graph.Edges = append(b.graph.Edges, e)
+	}
+}
+
+// compile turns infix string expressions into JSON-logic. Strings that don't parse
+// are dropped here; lint reports them.
+func compile(expr any) any {
+	switch e := expr.(type) {
+	case string:
+		parsed, err := tenet.ParseExpr(e)
+		if err != nil {
+			return nil
+		}
+		return parsed
+	case []any:
+		out := make([]any, len(e))
+		for i, elem := range e {
+			out[i] = compile(elem)
+		}
+		return out
+	}
+	return expr
+}
+
+// extractVars returns the root names of all {"var": "name"} references and missing/missing_some
+// keys, in order, without duplicates. Quantifier element references ({"var": ""}) are skipped.
+func extractVars(node any) []string {
+	var vars []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		root, _, _ := strings.Cut(name, ".")
+		if root != "" && !seen[root] {
+			seen[root] = true
+			vars = append(vars, root)
+		}
+	}
+	// missing and missing_some name fields as string literals
+	var addKeys func(any)
+	addKeys = func(node any) {
+		switch v := node.(type) {
+		case string:
+			add(v)
+		case []any:
+			for _, elem := range v {
+				addKeys(elem)
+			}
+		}
+	}
+	var walk func(any)
+	walk = func(node any) {
+		switch v := node.(type) {
+		case map[string]any:
+			if name, ok := v["var"].(string); ok {
+				add(name)
+			}
+			if len(v) == 1 {
+				addKeys(v["missing"])
+				addKeys(v["missing_some"])
+			}
+			for _, key := range sortedKeys(v) {
+				walk(v[key])
+			}
+		case []any:
+			for _, elem := range v {
+				walk(elem)
+			}
+		}
+	}
+	walk(node)
+	return vars
+}
+
+// dotQuote quotes s as a DOT identifier.
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+const schema = `{
+	"definitions": {
+		"income": {"type": "number"},
+		"loan_amount": {"type": "number"},
+		"review": {"type": "boolean"}
+	},
+	"state_model": {
+		"derived": {"dti": {"eval": "= loan_amount / income"}}
+	},
+	"logic_tree": [
+		{"id": "high_dti", "when": {">": [{"var": "dti"}, 0.43]}, "then": {"set": {"review": true}}},
+		{"id": "copy", "when": "true", "then": {"set": {"requested": {"var": "loan_amount"}}}}
+	]
+}`
+
+func TestBuild(t *testing.T) {
+	g, err := Build(schema)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	edges := make(map[string]bool)
+	for _, e := range g.Edges {
+		edges[e.From+" "+e.Kind+" "+e.To] = true
+	}
+	for _, want := range []string{
+		"field:income reads derived:dti",
+		"field:loan_amount reads derived:dti",
+		"derived:dti reads rule:high_dti",
+		"rule:high_dti sets field:review",
+		"field:loan_amount reads rule:copy",
+		"rule:copy sets field:requested",
+	} {
+		if !edges[want] {
+			t.Errorf("missing edge %q in %+v", want, g.Edges)
+		}
+	}
+	if len(g.Edges) != 6 {
+		t.Errorf("expected 6 edges, got %d", len(g.Edges))
+	}
+	if len(g.Nodes) != 7 {
+		t.Errorf("expected 7 nodes (4 fields, 1 derived, 2 rules), got %d", len(g.Nodes))
+	}
+}
+
+func TestRender(t *testing.T) {
+	g, err := Build(schema)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	dot := g.DOT()
+	for _, want := range []string{
+		`"derived:dti" [label="dti", shape=parallelogram];`,
+		`"rule:high_dti" -> "field:review" [style=bold];`,
+		`"field:income" -> "derived:dti";`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, dot)
+		}
+	}
+
+	mermaid := g.Mermaid()
+	if !strings.HasPrefix(mermaid, "flowchart LR\n") || !strings.Contains(mermaid, `[/"dti"/]`) || !strings.Contains(mermaid, "==>") {
+		t.Errorf("unexpected Mermaid output:\n%s", mermaid)
+	}
+}
@@ -0,0 +1,207 @@
+// Package impact estimates the effect of a schema change on documents already in circulation.
+// It re-evaluates a corpus of stored documents under the old and the new schema version with
+// tenet.CompareRuns and summarizes how many change status or decisions, and which rules cause it.
+package impact
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// Document is one stored document of the corpus.
+type Document struct {
+	Name string // File name or other identifier, for reporting
+	JSON string // The document as stored, typically a Run output
+}
+
+// Report summarizes a corpus run.
+type Report struct {
+	Total        int              `json:"total"`
+	Failed       int              `json:"failed"`                // Documents that couldn't be evaluated
+	StatusFlips  int              `json:"status_flips"`          // Documents whose status differs
+	Changed      int              `json:"changed"`               // Documents with a field value that differs
+	Transitions  map[string]int   `json:"transitions,omitempty"` // "READY -> INVALID" -> documents
+	Rules        []RuleImpact     `json:"rules,omitempty"`       // Most documents first
+	Unattributed int              `json:"unattributed"`          // Differing documents no rule accounts for
+	Documents    []DocumentImpact `json:"documents,omitempty"`   // Documents that differ or failed, in corpus order
+}
+
+// RuleImpact counts the documents a rule accounts for: it set a differing field or raised an
+// error only one version reports.
+type RuleImpact struct {
+	RuleID      string `json:"rule_id"`
+	Documents   int    `json:"documents"`
+	StatusFlips int    `json:"status_flips"` // Of those, documents whose status differs
+}
+
+// DocumentImpact is the comparison for one document.
+type DocumentImpact struct {
+	Name       string               `json:"name"`
+	Comparison *tenet.RunComparison `json:"comparison,omitempty"`
+	Rules      []string             `json:"rules,omitempty"` // Rules the difference is attributed to
+	Error      string               `json:"error,omitempty"`
+}
+
+// Analyze re-evaluates each document under both schema versions at the given date.
+//
+// A stored document carries the values its schema computed as well as the user's input. Only
+// the input is replayed: definitions that are readonly or that a rule or decision table of
+// the old schema sets are left for each version to compute. Options apply to every run.
+func Analyze(oldSchema, newSchema string, docs []Document, date time.Time, opts ...tenet.RunOption) (*Report, error) {
+	var old tenet.Schema
+	if err := json.Unmarshal([]byte(oldSchema), &old); err != nil {
+		return nil, fmt.Errorf("old schema: %w", err)
+	}
+	if err := json.Unmarshal([]byte(newSchema), &tenet.Schema{}); err != nil {
+		return nil, fmt.Errorf("new schema: %w", err)
+	}
+	computed := ruleTargets(&old)
+
+	report := &Report{Total: len(docs), Transitions: make(map[string]int)}
+	rules := make(map[string]*RuleImpact)
+	for _, doc := range docs {
+		values, err := inputs(doc.JSON, computed)
+		if err != nil {
+			report.Failed++
+			report.Documents = append(report.Documents, DocumentImpact{Name: doc.Name, Error: err.Error()})
+			continue
+		}
+		cmp, err := tenet.CompareRuns(oldSchema, newSchema, values, date, opts...)
+		if err != nil {
+			report.Failed++
+			report.Documents = append(report.Documents, DocumentImpact{Name: doc.Name, Error: err.Error()})
+			continue
+		}
+		if !cmp.Differs() {
+			continue
+		}
+
+		flipped := cmp.StatusA != cmp.StatusB
+		if flipped {
+			report.StatusFlips++
+			report.Transitions[fmt.Sprintf("%s -> %s", cmp.StatusA, cmp.StatusB)]++
+		}
+		if len(cmp.Fields) > 0 {
+			report.Changed++
+		}
+
+		attributed := attribute(cmp)
+		if len(attributed) == 0 {
+			report.Unattributed++
+		}
+		for _, id := range attributed {
+			r := rules[id]
+			if r == nil {
+				r = &RuleImpact{RuleID: id}
+				rules[id] = r
+			}
+			r.Documents++
+			if flipped {
+				r.StatusFlips++
+			}
+		}
+		report.Documents = append(report.Documents, DocumentImpact{Name: doc.Name, Comparison: cmp, Rules: attributed})
+	}
+
+	for _, r := range rules {
+		report.Rules = append(report.Rules, *r)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool {
+		a, b := report.Rules[i], report.Rules[j]
+		if a.Documents != b.Documents {
+			return a.Documents > b.Documents
+		}
+		return a.RuleID < b.RuleID
+	})
+	return report, nil
+}
+
+// LoadDir reads every .json file at the root of dir as a document, in file name order.
+func LoadDir(dir string) ([]Document, error) {
+	fsys := os.DirFS(dir)
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	docs := make([]Document, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, Document{Name: name, JSON: string(data)})
+	}
+	return docs, nil
+}
+
+// ruleTargets returns the root fields that the schema's rules and decision tables set.
+func ruleTargets(schema *tenet.Schema) map[string]bool {
+	targets := make(map[string]bool)
+	for _, rule := range schema.LogicTree {
+		if rule == nil || rule.Then == nil {
+			continue
+		}
+		for field := range rule.Then.Set {
+			root, _, _ := strings.Cut(field, ".")
+			targets[root] = true
+		}
+	}
+	for _, table := range schema.DecisionTables {
+		if table == nil {
+			continue
+		}
+		for _, field := range table.Outputs {
+			root, _, _ := strings.Cut(field, ".")
+			targets[root] = true
+		}
+	}
+	return targets
+}
+
+// inputs returns the values of a stored document's editable fields that no rule computes.
+func inputs(docJSON string, computed map[string]bool) (map[string]any, error) {
+	var doc tenet.Schema
+	if err := json.Unmarshal([]byte(docJSON), &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	values := make(map[string]any, len(doc.Definitions))
+	for id, def := range doc.Definitions {
+		if def == nil || def.Value == nil || def.IsReadonly() || computed[id] {
+			continue
+		}
+		values[id] = def.Value
+	}
+	return values, nil
+}
+
+// attribute returns the rules a comparison's differences come from, sorted.
+func attribute(cmp *tenet.RunComparison) []string {
+	seen := make(map[string]bool)
+	for _, f := range cmp.Fields {
+		seen[f.RuleA] = true
+		seen[f.RuleB] = true
+	}
+	for _, e := range cmp.ErrorsAdded {
+		seen[e.RuleID] = true
+	}
+	for _, e := range cmp.ErrorsRemoved {
+		seen[e.RuleID] = true
+	}
+	delete(seen, "")
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
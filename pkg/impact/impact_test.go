@@ -0,0 +1,94 @@
+package impact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+const oldSchema = `{
+	"definitions": {
+		"income": {"type": "number", "required": true},
+		"decision": {"type": "string"}
+	},
+	"logic_tree": [
+		{"id": "approve", "when": "income >= 5000", "then": {"set": {"decision": "approved"}}},
+		{"id": "too_low", "when": "income < 1000", "then": {"error_msg": "Income too low"}}
+	]
+}`
+
+const newSchema = `{
+	"definitions": {
+		"income": {"type": "number", "required": true},
+		"decision": {"type": "string"}
+	},
+	"logic_tree": [
+		{"id": "approve", "when": "income >= 3000", "then": {"set": {"decision": "approved"}}},
+		{"id": "too_low", "when": "income < 2000", "then": {"error_msg": "Income too low"}}
+	]
+}`
+
+func TestAnalyze(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	var docs []Document
+	for _, income := range []string{"500", "1500", "4000", "6000"} {
+		out, err := tenet.Run(`{"definitions": {"income": {"type": "number", "value": `+income+`}, "decision": {"type": "string"}},
+			"logic_tree": [{"id": "approve", "when": "income >= 5000", "then": {"set": {"decision": "approved"}}}]}`, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		docs = append(docs, Document{Name: income, JSON: out})
+	}
+	docs = append(docs, Document{Name: "broken", JSON: "{"})
+
+	report, err := Analyze(oldSchema, newSchema, docs, date)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	// 500: invalid under both; 1500: becomes invalid; 4000: becomes approved; 6000: unchanged
+	if report.Total != 5 || report.Failed != 1 || report.StatusFlips != 1 || report.Changed != 1 {
+		t.Fatalf("unexpected totals: %+v", report)
+	}
+	if report.Transitions["READY -> INVALID"] != 1 {
+		t.Errorf("expected one READY -> INVALID, got %v", report.Transitions)
+	}
+	if len(report.Rules) != 2 || report.Rules[0].RuleID != "approve" || report.Rules[1].RuleID != "too_low" {
+		t.Fatalf("unexpected rule attribution: %+v", report.Rules)
+	}
+	if report.Rules[0].StatusFlips != 0 || report.Rules[1].StatusFlips != 1 {
+		t.Errorf("unexpected status flips per rule: %+v", report.Rules)
+	}
+
+	// Documents that differ or failed, in corpus order; the stored decision isn't replayed
+	if len(report.Documents) != 3 {
+		t.Fatalf("expected 3 reported documents, got %+v", report.Documents)
+	}
+	names := []string{report.Documents[0].Name, report.Documents[1].Name, report.Documents[2].Name}
+	if names[0] != "1500" || names[1] != "4000" || names[2] != "broken" || report.Documents[2].Error == "" {
+		t.Errorf("unexpected documents: %+v", report.Documents)
+	}
+
+	if _, err := Analyze(oldSchema, "{", docs, date); err == nil {
+		t.Error("expected an error for a malformed new schema")
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"b.json": "{}", "a.json": "[]", "notes.txt": "x"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if len(docs) != 2 || docs[0].Name != "a.json" || docs[1].JSON != "{}" {
+		t.Errorf("unexpected documents: %+v", docs)
+	}
+}
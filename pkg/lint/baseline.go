@@ -0,0 +1,54 @@
+package lint
+
+// Baseline is a recorded set of accepted issues. Linting against a baseline reports
+// only issues that aren't in it, so legacy schemas can adopt stricter checks gradually.
+// Its JSON form matches Result, so a saved lint result is a valid baseline.
+type Baseline struct {
+	Issues []Issue `json:"issues"`
+}
+
+// NewBaseline records the issues of a lint result as accepted.
+func NewBaseline(r *Result) *Baseline {
+	return &Baseline{Issues: append([]Issue(nil), r.Issues...)}
+}
+
+// Filter returns a copy of r without the issues recorded in the baseline, with Valid
+// recomputed from what remains. Issues match on severity, field, rule and message;
+// each baseline entry suppresses one occurrence, so a second copy of a known issue is new.
+func (b *Baseline) Filter(r *Result) *Result {
+	known := make(map[Issue]int, len(b.Issues))
+	for _, issue := range b.Issues {
+		known[issue]++
+	}
+
+	filtered := &Result{Valid: true, Issues: make([]Issue, 0)}
+	for _, issue := range r.Issues {
+		if known[issue] > 0 {
+			known[issue]--
+			continue
+		}
+		filtered.Issues = append(filtered.Issues, issue)
+		if issue.Severity == "error" {
+			filtered.Valid = false
+		}
+	}
+	return filtered
+}
+
+// Stale returns baseline entries that no longer occur in r, so they can be pruned.
+func (b *Baseline) Stale(r *Result) []Issue {
+	current := make(map[Issue]int, len(r.Issues))
+	for _, issue := range r.Issues {
+		current[issue]++
+	}
+
+	var stale []Issue
+	for _, issue := range b.Issues {
+		if current[issue] > 0 {
+			current[issue]--
+			continue
+		}
+		stale = append(stale, issue)
+	}
+	return stale
+}
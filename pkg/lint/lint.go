@@ -5,7 +5,12 @@ package lint
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
+	"strings"
+	"time"
+
+	"github.com/dlovans/tenet/pkg/tenet"
 )
 
 // Issue represents a problem found during static analysis.
@@ -30,24 +35,85 @@ type schema struct {
 	TemporalMap  []*temporalBranch       `json:"temporal_map,omitempty"`
 	StateModel   *stateModel             `json:"state_model,omitempty"`
 	Attestations map[string]*attestation `json:"attestations,omitempty"`
+	LawRefs      map[string]*lawRef      `json:"law_refs,omitempty"`
+	Aliases      map[string]string       `json:"aliases,omitempty"`
+	Summary      *summary                `json:"summary,omitempty"`
+
+	DecisionTables []*decisionTable `json:"decision_tables,omitempty"`
+
+	RequireTogether []*fieldGroup `json:"require_together,omitempty"`
+	RequireOneOf    []*fieldGroup `json:"require_one_of,omitempty"`
 }
 
 type definition struct {
-	Type string `json:"type,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Required      bool   `json:"required,omitempty"`
+	Visible       *bool  `json:"visible,omitempty"`
+	LabelExpr     any    `json:"label_expr,omitempty"`
+	UIMessageExpr any    `json:"ui_message_expr,omitempty"`
+
+	RenamedFrom []string `json:"renamed_from,omitempty"`
+}
+
+type decisionTable struct {
+	ID      string   `json:"id,omitempty"`
+	Inputs  []string `json:"inputs,omitempty"`
+	Outputs []string `json:"outputs,omitempty"`
+	Rows    []*struct {
+		When []any `json:"when,omitempty"`
+		Then []any `json:"then,omitempty"`
+	} `json:"rows,omitempty"`
+}
+
+type summary struct {
+	TitleExpr    any `json:"title_expr,omitempty"`
+	SubtitleExpr any `json:"subtitle_expr,omitempty"`
+	Figures      []*struct {
+		ValueExpr any `json:"value_expr,omitempty"`
+	} `json:"figures,omitempty"`
+}
+
+// exprs lists the summary's expressions.
+func (s *summary) exprs() []any {
+	if s == nil {
+		return nil
+	}
+	exprs := []any{s.TitleExpr, s.SubtitleExpr}
+	for _, fig := range s.Figures {
+		if fig != nil {
+			exprs = append(exprs, fig.ValueExpr)
+		}
+	}
+	return exprs
 }
 
 type rule struct {
-	ID   string  `json:"id,omitempty"`
-	When any     `json:"when,omitempty"`
-	Then *action `json:"then,omitempty"`
+	ID           string  `json:"id,omitempty"`
+	LawRef       string  `json:"law_ref,omitempty"`
+	LogicVersion string  `json:"logic_version,omitempty"`
+	ValidFrom    string  `json:"valid_from,omitempty"`
+	ValidUntil   string  `json:"valid_until,omitempty"`
+	When         any     `json:"when,omitempty"`
+	WhenAny      any     `json:"when_any,omitempty"`
+	Unless       any     `json:"unless,omitempty"`
+	Then         *action `json:"then,omitempty"`
 }
 
 type action struct {
-	Set map[string]any `json:"set,omitempty"`
+	Set      map[string]any `json:"set,omitempty"`
+	UIModify map[string]any `json:"ui_modify,omitempty"`
+}
+
+type fieldGroup struct {
+	ID     string   `json:"id,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+	LawRef string   `json:"law_ref,omitempty"`
 }
 
 type temporalBranch struct {
-	LogicVersion string `json:"logic_version,omitempty"`
+	ValidRange   [2]*string `json:"valid_range"`
+	LogicVersion string     `json:"logic_version,omitempty"`
+	Status       string     `json:"status,omitempty"`
 }
 
 type stateModel struct {
@@ -55,26 +121,56 @@ type stateModel struct {
 }
 
 type derivedDef struct {
-	Eval any `json:"eval,omitempty"`
+	Eval           any            `json:"eval,omitempty"`
+	ByJurisdiction map[string]any `json:"by_jurisdiction,omitempty"`
 }
 
 type attestation struct {
 	Statement string `json:"statement,omitempty"`
+	LawRef    string `json:"law_ref,omitempty"`
+}
+
+type lawRef struct {
+	Title string `json:"title,omitempty"`
 }
 
 // Run performs static analysis on a schema without executing it.
 // Detects potential issues like undefined variables, type mismatches, and cycles.
+// Templates are expanded first, so their instances are checked like any other field.
 func Run(jsonText string) (*Result, error) {
 	var s schema
 	if err := json.Unmarshal([]byte(jsonText), &s); err != nil {
 		return nil, fmt.Errorf("parse error: %w", err)
 	}
+	expanded, err := tenet.ExpandTemplates(jsonText)
+	if err != nil {
+		result := &Result{Issues: make([]Issue, 0)}
+		result.addError("", "", fmt.Sprintf("Template expansion failed: %v", err))
+		return result, nil
+	}
+	if expanded != jsonText {
+		s = schema{}
+		if err := json.Unmarshal([]byte(expanded), &s); err != nil {
+			return nil, fmt.Errorf("parse error: %w", err)
+		}
+	}
 
 	result := &Result{
 		Valid:  true,
 		Issues: make([]Issue, 0),
 	}
 
+	// Check 0: Repeated keys, of which the decoder silently kept only the last
+	if duplicates, err := tenet.DuplicateKeys(jsonText); err == nil {
+		for _, path := range duplicates {
+			field := ""
+			if id, ok := strings.CutPrefix(path, "definitions."); ok && !strings.Contains(id, ".") {
+				field = id
+			}
+			result.addError(field, "", fmt.Sprintf("Duplicate key '%s': only the last value is kept", path))
+		}
+	}
+
 	// Collect all defined field names
 	definedFields := make(map[string]bool)
 	for name := range s.Definitions {
@@ -88,17 +184,61 @@ func Run(jsonText string) (*Result, error) {
 		}
 	}
 
+	// Aliases must name an existing field and not be shadowed by one; valid
+	// aliases count as defined for the checks below
+	for _, alias := range sortedKeys(s.Aliases) {
+		target := s.Aliases[alias]
+		switch {
+		case definedFields[alias]:
+			result.addWarning(alias, "", fmt.Sprintf("alias '%s' is never used: a field with that ID exists", alias))
+		case s.Aliases[target] != "" && !definedFields[target]:
+			result.addError(alias, "", fmt.Sprintf("alias '%s' points to alias '%s'; aliases aren't followed further", alias, target))
+		case !definedFields[target]:
+			result.addError(alias, "", fmt.Sprintf("alias '%s' points to undefined field '%s'", alias, target))
+		}
+	}
+	for alias, target := range s.Aliases {
+		if definedFields[target] {
+			definedFields[alias] = true
+		}
+	}
+
+	// Legacy IDs in renamed_from must belong to one field and not be fields themselves
+	claimedBy := make(map[string]string)
+	for _, name := range sortedKeys(s.Definitions) {
+		def := s.Definitions[name]
+		if def == nil {
+			continue
+		}
+		for _, old := range def.RenamedFrom {
+			switch {
+			case s.Definitions[old] != nil:
+				result.addWarning(name, "", fmt.Sprintf("renamed_from '%s' is ignored: a field with that ID exists", old))
+			case claimedBy[old] != "":
+				result.addError(name, "", fmt.Sprintf("renamed_from '%s' is already claimed by field '%s'", old, claimedBy[old]))
+			default:
+				claimedBy[old] = name
+			}
+		}
+	}
+
 	// Check 1: Undefined variables in logic tree
 	for _, rule := range s.LogicTree {
 		if rule == nil {
 			continue
 		}
 
-		// Check variables in "when" condition
-		varsInWhen := extractVars(rule.When)
+		// Infix string conditions are checked in their compiled form
+		rule.When = compileInfix(rule.When, rule.ID, "when", result)
+		rule.WhenAny = compileInfix(rule.WhenAny, rule.ID, "when_any", result)
+		rule.Unless = compileInfix(rule.Unless, rule.ID, "unless", result)
+
+		// Check variables in "when", "when_any" and "unless" conditions
+		varsInWhen := append(extractVars(rule.When), extractVars(rule.WhenAny)...)
+		varsInWhen = append(varsInWhen, extractVars(rule.Unless)...)
 		for _, v := range varsInWhen {
 			if !definedFields[v] {
-				result.addError(v, rule.ID, fmt.Sprintf("undefined variable '%s' in rule condition", v))
+				result.addError(v, rule.ID, fmt.Sprintf("undefined variable '%s' in rule condition: %s", v, conditionText(rule)))
 			}
 		}
 	}
@@ -118,6 +258,25 @@ func Run(jsonText string) (*Result, error) {
 		}
 	}
 
+	// Also check variables in computed labels and messages
+	for name, def := range s.Definitions {
+		if def == nil {
+			continue
+		}
+		for _, v := range append(extractVars(def.LabelExpr), extractVars(def.UIMessageExpr)...) {
+			if !definedFields[v] {
+				result.addError(name, "", fmt.Sprintf("undefined variable '%s' in display expression of '%s'", v, name))
+			}
+		}
+	}
+	for _, expr := range s.Summary.exprs() {
+		for _, v := range extractVars(expr) {
+			if !definedFields[v] {
+				result.addError("", "", fmt.Sprintf("undefined variable '%s' in summary", v))
+			}
+		}
+	}
+
 	// Check 2: Potential cycles (fields set by multiple rules)
 	fieldSetBy := make(map[string][]string)
 	for _, rule := range s.LogicTree {
@@ -138,16 +297,8 @@ func Run(jsonText string) (*Result, error) {
 		}
 	}
 
-	// Check 3: Temporal map status validation
-	for i, branch := range s.TemporalMap {
-		if branch == nil {
-			continue
-		}
-		if branch.LogicVersion == "" {
-			result.addWarning("", "", fmt.Sprintf(
-				"temporal branch %d has no logic_version", i))
-		}
-	}
+	// Check 3: Temporal map and rule effective dates
+	checkTemporal(&s, result, time.Now())
 
 	// Check 4: Empty required fields in definitions
 	for name, def := range s.Definitions {
@@ -167,11 +318,384 @@ func Run(jsonText string) (*Result, error) {
 		if att.Statement == "" {
 			result.addWarning(name, "", fmt.Sprintf("attestation '%s' has no statement", name))
 		}
+		for _, v := range statementVars(att.Statement) {
+			if !definedFields[v] {
+				result.addError(name, "", fmt.Sprintf("undefined variable '%s' in statement of attestation '%s'", v, name))
+			}
+		}
+	}
+
+	// Check 6: Field groups referencing undefined or too few fields
+	groups := map[string][]*fieldGroup{
+		"require_together": s.RequireTogether,
+		"require_one_of":   s.RequireOneOf,
+	}
+	for _, kind := range []string{"require_together", "require_one_of"} {
+		for i, group := range groups[kind] {
+			if group == nil {
+				continue
+			}
+			if len(group.Fields) < 2 {
+				result.addWarning("", group.ID, fmt.Sprintf("%s group %d has fewer than two fields", kind, i))
+			}
+			for _, f := range group.Fields {
+				if !definedFields[f] {
+					result.addError(f, group.ID, fmt.Sprintf("undefined field '%s' in %s group %d", f, kind, i))
+				}
+			}
+		}
+	}
+
+	// Check 7: Dead fields (never read, never required, never visible)
+	checkDeadFields(&s, result)
+
+	// Check 8: law_ref citations against the law_refs registry
+	checkLawRefs(&s, result)
+
+	// Check 9: Decision tables with undefined columns or misshapen rows
+	for _, table := range s.DecisionTables {
+		if table == nil {
+			continue
+		}
+		for _, f := range append(append([]string{}, table.Inputs...), table.Outputs...) {
+			root, _, _ := strings.Cut(f, ".")
+			if !definedFields[root] {
+				result.addError(f, table.ID, fmt.Sprintf("undefined field '%s' in decision table '%s'", f, table.ID))
+			}
+		}
+		for i, row := range table.Rows {
+			if row != nil && (len(row.When) != len(table.Inputs) || len(row.Then) > len(table.Outputs)) {
+				result.addError("", table.ID, fmt.Sprintf("decision table '%s' row %d doesn't match its columns", table.ID, i+1))
+			}
+		}
 	}
 
 	return result, nil
 }
 
+// checkDeadFields warns about definitions that nothing reads, that are never required
+// and never visible — usually cruft left behind by schema edits. A rule's ui_modify
+// can make a field required or visible, which counts.
+func checkDeadFields(s *schema, result *Result) {
+	read := make(map[string]bool)
+	markRead := func(expr any) {
+		for _, v := range extractVars(expr) {
+			read[v] = true
+		}
+	}
+
+	for _, r := range s.LogicTree {
+		if r == nil {
+			continue
+		}
+		markRead(r.When)
+		markRead(r.WhenAny)
+		markRead(r.Unless)
+		if r.Then != nil {
+			for _, val := range r.Then.Set {
+				markRead(val)
+			}
+		}
+	}
+	if s.StateModel != nil {
+		for _, d := range s.StateModel.Derived {
+			if d == nil {
+				continue
+			}
+			exprs := []any{d.Eval}
+			for _, expr := range d.ByJurisdiction {
+				exprs = append(exprs, expr)
+			}
+			for _, expr := range exprs {
+				if text, ok := expr.(string); ok {
+					expr, _ = tenet.ParseEval(text)
+				}
+				markRead(expr)
+			}
+		}
+	}
+	for _, def := range s.Definitions {
+		if def != nil {
+			markRead(def.LabelExpr)
+			markRead(def.UIMessageExpr)
+		}
+	}
+	for _, expr := range s.Summary.exprs() {
+		markRead(expr)
+	}
+	for _, table := range s.DecisionTables {
+		if table != nil {
+			for _, f := range table.Inputs {
+				root, _, _ := strings.Cut(f, ".")
+				read[root] = true
+			}
+		}
+	}
+	for _, att := range s.Attestations {
+		if att != nil {
+			for _, v := range statementVars(att.Statement) {
+				read[v] = true
+			}
+		}
+	}
+	for _, group := range append(append([]*fieldGroup{}, s.RequireTogether...), s.RequireOneOf...) {
+		if group != nil {
+			for _, f := range group.Fields {
+				read[f] = true
+			}
+		}
+	}
+
+	// Reading a former ID reads the field it was renamed to
+	for alias, target := range s.Aliases {
+		if read[alias] {
+			read[target] = true
+		}
+	}
+
+	// Fields a rule can make required or visible
+	surfaced := make(map[string]bool)
+	for _, r := range s.LogicTree {
+		if r == nil || r.Then == nil {
+			continue
+		}
+		for field, mod := range r.Then.UIModify {
+			if m, ok := mod.(map[string]any); ok && (m["visible"] == true || m["required"] == true) {
+				surfaced[field] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(s.Definitions))
+	for name := range s.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		def := s.Definitions[name]
+		if def == nil || read[name] || def.Required || surfaced[name] {
+			continue
+		}
+		if def.Visible == nil || *def.Visible {
+			continue
+		}
+		result.addWarning(name, "", fmt.Sprintf(
+			"field '%s' is never read, never required and never visible (dead field?)", name))
+	}
+}
+
+// checkLawRefs validates citations once a schema declares a law_refs registry: every
+// law_ref must name an entry, entries need a title, and unused entries are reported.
+// Schemas without a registry keep free-text law_refs and are not checked.
+func checkLawRefs(s *schema, result *Result) {
+	if len(s.LawRefs) == 0 {
+		return
+	}
+	used := make(map[string]bool)
+	check := func(ref, field, ruleID, owner string) {
+		if ref == "" {
+			return
+		}
+		used[ref] = true
+		if _, ok := s.LawRefs[ref]; !ok {
+			result.addError(field, ruleID, fmt.Sprintf("%s cites unknown law_ref '%s'", owner, ref))
+		}
+	}
+
+	for _, r := range s.LogicTree {
+		if r != nil {
+			check(r.LawRef, "", r.ID, fmt.Sprintf("rule '%s'", r.ID))
+		}
+	}
+	for _, name := range sortedKeys(s.Attestations) {
+		if att := s.Attestations[name]; att != nil {
+			check(att.LawRef, name, "", fmt.Sprintf("attestation '%s'", name))
+		}
+	}
+	for _, group := range append(append([]*fieldGroup{}, s.RequireTogether...), s.RequireOneOf...) {
+		if group != nil {
+			check(group.LawRef, "", group.ID, fmt.Sprintf("field group '%s'", group.ID))
+		}
+	}
+
+	for _, id := range sortedKeys(s.LawRefs) {
+		ref := s.LawRefs[id]
+		if ref == nil || ref.Title == "" {
+			result.addWarning("", "", fmt.Sprintf("law_ref '%s' has no title", id))
+		}
+		if !used[id] {
+			result.addWarning("", "", fmt.Sprintf("law_ref '%s' is never cited", id))
+		}
+	}
+}
+
+// sortedKeys returns a map's keys in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dateRange is a parsed temporal branch range. A nil end is open-ended.
+type dateRange struct {
+	index int
+	start time.Time
+	end   *time.Time
+}
+
+// checkTemporal validates temporal_map ranges and rule valid_from/valid_until:
+// parseable dates, start before end, branch ordering, overlaps, gaps, archived branches
+// that still cover today, and rules pointing at versions no branch declares.
+func checkTemporal(s *schema, result *Result, now time.Time) {
+	versions := make(map[string]bool)
+	var ranges []dateRange
+
+	for i, branch := range s.TemporalMap {
+		if branch == nil {
+			continue
+		}
+		if branch.LogicVersion == "" {
+			result.addWarning("", "", fmt.Sprintf(
+				"temporal branch %d has no logic_version", i))
+		}
+		versions[branch.LogicVersion] = true
+
+		switch branch.Status {
+		case "", "ACTIVE", "ARCHIVED":
+		default:
+			result.addWarning("", "", fmt.Sprintf(
+				"temporal branch %d has unknown status '%s' (expected ACTIVE or ARCHIVED)", i, branch.Status))
+		}
+
+		if branch.ValidRange[0] == nil {
+			result.addError("", "", fmt.Sprintf(
+				"temporal branch %d has no start date and can never be selected", i))
+			continue
+		}
+		start, ok := parseDate(*branch.ValidRange[0])
+		if !ok {
+			result.addError("", "", fmt.Sprintf(
+				"temporal branch %d has unparseable start date '%s'", i, *branch.ValidRange[0]))
+			continue
+		}
+		r := dateRange{index: i, start: start}
+		if branch.ValidRange[1] != nil {
+			end, ok := parseDate(*branch.ValidRange[1])
+			if !ok {
+				result.addError("", "", fmt.Sprintf(
+					"temporal branch %d has unparseable end date '%s'", i, *branch.ValidRange[1]))
+				continue
+			}
+			if end.Before(start) {
+				result.addError("", "", fmt.Sprintf(
+					"temporal branch %d ends (%s) before it starts (%s)", i, *branch.ValidRange[1], *branch.ValidRange[0]))
+				continue
+			}
+			r.end = &end
+		}
+		ranges = append(ranges, r)
+
+		if branch.Status == "ARCHIVED" && !now.Before(start) && (r.end == nil || !now.After(*r.end)) {
+			result.addWarning("", "", fmt.Sprintf(
+				"temporal branch %d (%s) is ARCHIVED but still covers today's date", i, branch.LogicVersion))
+		}
+	}
+
+	// Branches are matched first-to-last, so they should be listed chronologically
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].start.Before(ranges[i-1].start) {
+			result.addWarning("", "", fmt.Sprintf(
+				"temporal branch %d starts before branch %d (list branches in chronological order)",
+				ranges[i].index, ranges[i-1].index))
+			break
+		}
+	}
+
+	sorted := append([]dateRange(nil), ranges...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].start.Before(sorted[j].start) })
+	for i := 1; i < len(sorted); i++ {
+		prev, curr := sorted[i-1], sorted[i]
+		if prev.end == nil || !curr.start.After(*prev.end) {
+			result.addError("", "", fmt.Sprintf(
+				"temporal branch %d overlaps with branch %d", curr.index, prev.index))
+			continue
+		}
+		if curr.start.Sub(*prev.end) > 24*time.Hour {
+			result.addWarning("", "", fmt.Sprintf(
+				"gap between temporal branch %d (ends %s) and branch %d (starts %s): dates in between use unversioned rules only",
+				prev.index, prev.end.Format("2006-01-02"), curr.index, curr.start.Format("2006-01-02")))
+		}
+	}
+
+	for _, rule := range s.LogicTree {
+		if rule == nil {
+			continue
+		}
+		if rule.LogicVersion != "" && len(s.TemporalMap) > 0 && !versions[rule.LogicVersion] {
+			result.addWarning("", rule.ID, fmt.Sprintf(
+				"rule '%s' has logic_version '%s' but no temporal branch declares it", rule.ID, rule.LogicVersion))
+		}
+
+		var from, until time.Time
+		var hasFrom, hasUntil bool
+		if rule.ValidFrom != "" {
+			if from, hasFrom = parseDate(rule.ValidFrom); !hasFrom {
+				result.addError("", rule.ID, fmt.Sprintf("rule '%s' has unparseable valid_from '%s'", rule.ID, rule.ValidFrom))
+			}
+		}
+		if rule.ValidUntil != "" {
+			if until, hasUntil = parseDate(rule.ValidUntil); !hasUntil {
+				result.addError("", rule.ID, fmt.Sprintf("rule '%s' has unparseable valid_until '%s'", rule.ID, rule.ValidUntil))
+			}
+		}
+		if hasFrom && hasUntil && until.Before(from) {
+			result.addError("", rule.ID, fmt.Sprintf("rule '%s' has valid_until before valid_from and never applies", rule.ID))
+		}
+	}
+}
+
+// conditionText renders a rule's guards as readable text for messages.
+func conditionText(r *rule) string {
+	var parts []string
+	if r.When != nil {
+		parts = append(parts, "when "+tenet.FormatExpr(r.When))
+	}
+	if r.WhenAny != nil {
+		parts = append(parts, "when any of "+tenet.FormatExpr(r.WhenAny))
+	}
+	if r.Unless != nil {
+		parts = append(parts, "unless "+tenet.FormatExpr(r.Unless))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseDate accepts the same date formats as the engine.
+func parseDate(s string) (time.Time, bool) {
+	for _, format := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// statementPlaceholder matches {{field}} placeholders in attestation statements.
+var statementPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// statementVars returns the root field of every placeholder in a statement.
+func statementVars(statement string) []string {
+	var vars []string
+	for _, m := range statementPlaceholder.FindAllStringSubmatch(statement, -1) {
+		root, _, _ := strings.Cut(m[1], ".")
+		vars = append(vars, root)
+	}
+	return vars
+}
+
 func (r *Result) addError(field, rule, message string) {
 	r.Valid = false
 	r.Issues = append(r.Issues, Issue{
@@ -191,7 +715,28 @@ func (r *Result) addWarning(field, rule, message string) {
 	})
 }
 
+// compileInfix parses infix string conditions (at the top level or inside an array)
+// into JSON-logic, reporting strings that don't parse.
+func compileInfix(cond any, ruleID, field string, result *Result) any {
+	switch c := cond.(type) {
+	case string:
+		expr, err := tenet.ParseExpr(c)
+		if err != nil {
+			result.addError("", ruleID, fmt.Sprintf("unparseable %s expression '%s': %v", field, c, err))
+			return nil
+		}
+		return expr
+	case []any:
+		for i, elem := range c {
+			c[i] = compileInfix(elem, ruleID, field, result)
+		}
+	}
+	return cond
+}
+
 // extractVars recursively finds all {"var": "name"} references in a JSON-logic tree.
+// Element references inside some/all/none and aggregations ({"var": ""}, {"var": ".amount"})
+// are skipped.
 func extractVars(node any) []string {
 	if node == nil {
 		return nil
@@ -206,7 +751,15 @@ func extractVars(node any) []string {
 			if name, isString := varName.(string); isString {
 				// Get the root variable name (before any dot notation)
 				parts := splitFirst(name, ".")
-				vars = append(vars, parts[0])
+				if parts[0] != "" {
+					vars = append(vars, parts[0])
+				}
+			}
+		}
+		// missing and missing_some name fields as string literals
+		for _, op := range []string{"missing", "missing_some"} {
+			if args, ok := v[op]; ok && len(v) == 1 {
+				vars = append(vars, literalKeys(args)...)
 			}
 		}
 		// Recurse into all values
@@ -223,6 +776,23 @@ func extractVars(node any) []string {
 	return vars
 }
 
+// literalKeys returns the root names of the string literals in missing/missing_some arguments.
+func literalKeys(node any) []string {
+	switch v := node.(type) {
+	case string:
+		if root := splitFirst(v, ".")[0]; root != "" {
+			return []string{root}
+		}
+	case []any:
+		var keys []string
+		for _, elem := range v {
+			keys = append(keys, literalKeys(elem)...)
+		}
+		return keys
+	}
+	return nil
+}
+
 // splitFirst splits a string by the first occurrence of sep.
 func splitFirst(s, sep string) []string {
 	for i := 0; i < len(s); i++ {
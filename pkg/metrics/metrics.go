@@ -0,0 +1,201 @@
+// Package metrics aggregates tenet.Service events and exposes them in the Prometheus text
+// exposition format, without a Prometheus client dependency. Wire a Collector into a Service
+// with ServiceConfig.OnEvent and mount it as the server's /metrics handler:
+//
+//	collector := metrics.NewCollector()
+//	svc := tenet.NewService(tenet.ServiceConfig{OnEvent: collector.Observe})
+//	collector.Track(svc)
+//	http.Handle("/metrics", collector)
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// Unregistered is the schema label of events whose schema ID isn't registered with the
+// tracked Service.
+const Unregistered = "unregistered"
+
+// DefaultBuckets are the upper bounds, in seconds, of the duration histogram.
+var DefaultBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// Collector counts Service events. It is safe for concurrent use.
+//
+// Exposed metrics, all labeled by op ("evaluate" or "verify") and schema:
+//
+//	tenet_evaluations_total{op,schema,status}   calls that completed, by resulting status
+//	tenet_evaluation_errors_total{op,schema}    calls that failed (parse errors, limits, unknown schema)
+//	tenet_evaluation_duration_seconds{op,schema} histogram of wall time
+//	tenet_verify_issues_total{schema,code}      verify issues by code
+//
+// The schema label is the schema ID only when it is registered with the Service passed to
+// Track, and Unregistered otherwise. Evaluate events carry the document's schema_id and verify
+// errors the caller's ID, so labeling them as sent would let clients add series without bound.
+type Collector struct {
+	buckets []float64
+	svc     *tenet.Service // Registry for the schema label (nil = every schema is Unregistered)
+
+	mu        sync.Mutex
+	results   map[labels]uint64     // op, schema, status
+	errors    map[labels]uint64     // op, schema
+	durations map[labels]*histogram // op, schema
+	issues    map[labels]uint64     // schema, code
+}
+
+// labels are the label values of one series, unused trailing values left empty.
+type labels [3]string
+
+type histogram struct {
+	counts []uint64 // Per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewCollector creates a Collector with DefaultBuckets.
+func NewCollector() *Collector {
+	return NewCollectorWithBuckets(DefaultBuckets)
+}
+
+// NewCollectorWithBuckets creates a Collector whose duration histogram uses the given
+// upper bounds in seconds. They are sorted; +Inf is implied.
+func NewCollectorWithBuckets(buckets []float64) *Collector {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &Collector{
+		buckets:   b,
+		results:   make(map[labels]uint64),
+		errors:    make(map[labels]uint64),
+		durations: make(map[labels]*histogram),
+		issues:    make(map[labels]uint64),
+	}
+}
+
+// Track labels events by the schema IDs registered with svc. Schemas registered or
+// unregistered later are picked up as events arrive.
+func (c *Collector) Track(svc *tenet.Service) {
+	c.mu.Lock()
+	c.svc = svc
+	c.mu.Unlock()
+}
+
+// Observe records a Service event. Pass it as ServiceConfig.OnEvent.
+func (c *Collector) Observe(e tenet.ServiceEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	schema := c.schemaLabel(e.SchemaID)
+	key := labels{e.Op, schema}
+	if e.Err != nil {
+		c.errors[key]++
+	} else {
+		c.results[labels{e.Op, schema, string(e.Status)}]++
+	}
+	for _, code := range e.IssueCodes {
+		c.issues[labels{schema, string(code)}]++
+	}
+
+	h := c.durations[key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.durations[key] = h
+	}
+	seconds := e.Duration.Seconds()
+	if i := sort.SearchFloat64s(c.buckets, seconds); i < len(c.buckets) {
+		h.counts[i]++
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// schemaLabel returns id if it is registered with the tracked Service, else Unregistered.
+func (c *Collector) schemaLabel(id string) string {
+	if c.svc == nil {
+		return Unregistered
+	}
+	if _, ok := c.svc.Schema(id); !ok {
+		return Unregistered
+	}
+	return id
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Write(w)
+}
+
+// Write writes the metrics in the Prometheus text format, series in label order.
+func (c *Collector) Write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP tenet_evaluations_total Completed Evaluate and Verify calls by resulting status.\n")
+	b.WriteString("# TYPE tenet_evaluations_total counter\n")
+	for _, k := range sortedKeys(c.results) {
+		fmt.Fprintf(&b, "tenet_evaluations_total{op=%s,schema=%s,status=%s} %d\n", quote(k[0]), quote(k[1]), quote(k[2]), c.results[k])
+	}
+
+	b.WriteString("# HELP tenet_evaluation_errors_total Evaluate and Verify calls that failed.\n")
+	b.WriteString("# TYPE tenet_evaluation_errors_total counter\n")
+	for _, k := range sortedKeys(c.errors) {
+		fmt.Fprintf(&b, "tenet_evaluation_errors_total{op=%s,schema=%s} %d\n", quote(k[0]), quote(k[1]), c.errors[k])
+	}
+
+	b.WriteString("# HELP tenet_evaluation_duration_seconds Wall time of Evaluate and Verify calls.\n")
+	b.WriteString("# TYPE tenet_evaluation_duration_seconds histogram\n")
+	for _, k := range sortedKeys(c.durations) {
+		h := c.durations[k]
+		series := fmt.Sprintf("op=%s,schema=%s", quote(k[0]), quote(k[1]))
+		var cumulative uint64
+		for i, le := range c.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "tenet_evaluation_duration_seconds_bucket{%s,le=\"%g\"} %d\n", series, le, cumulative)
+		}
+		fmt.Fprintf(&b, "tenet_evaluation_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", series, h.count)
+		fmt.Fprintf(&b, "tenet_evaluation_duration_seconds_sum{%s} %g\n", series, h.sum)
+		fmt.Fprintf(&b, "tenet_evaluation_duration_seconds_count{%s} %d\n", series, h.count)
+	}
+
+	b.WriteString("# HELP tenet_verify_issues_total Verify issues by code.\n")
+	b.WriteString("# TYPE tenet_verify_issues_total counter\n")
+	for _, k := range sortedKeys(c.issues) {
+		fmt.Fprintf(&b, "tenet_verify_issues_total{schema=%s,code=%s} %d\n", quote(k[0]), quote(k[1]), c.issues[k])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quote renders a label value, escaping backslashes, quotes and newlines.
+func quote(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	return `"` + v + `"`
+}
+
+// sortedKeys returns a map's label tuples in lexical order.
+func sortedKeys[V any](m map[labels]V) []labels {
+	keys := make([]labels, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		for n := range a {
+			if a[n] != b[n] {
+				return a[n] < b[n]
+			}
+		}
+		return false
+	})
+	return keys
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollectorWithBuckets([]float64{0.1, 0.01})
+	svc := tenet.NewService(tenet.ServiceConfig{})
+	if err := svc.Register("loan", `{"definitions": {"amount": {"type": "number"}}}`); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	c.Track(svc)
+	c.Observe(tenet.ServiceEvent{Op: "evaluate", SchemaID: "loan", Status: tenet.StatusReady, Duration: 5 * time.Millisecond})
+	c.Observe(tenet.ServiceEvent{Op: "evaluate", SchemaID: "loan", Status: tenet.StatusInvalid, Duration: 50 * time.Millisecond})
+	c.Observe(tenet.ServiceEvent{Op: "evaluate", SchemaID: "loan", Status: tenet.StatusInvalid, Duration: 2 * time.Second})
+	c.Observe(tenet.ServiceEvent{Op: "verify", SchemaID: "loan", Status: tenet.StatusReady,
+		IssueCodes: []tenet.VerifyIssueCode{tenet.VerifyComputedMismatch, tenet.VerifyStatusMismatch, tenet.VerifyComputedMismatch}})
+	c.Observe(tenet.ServiceEvent{Op: "verify", SchemaID: `we"ird`, Err: errors.New("schema not registered")})
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	out := rec.Body.String()
+
+	for _, want := range []string{
+		`tenet_evaluations_total{op="evaluate",schema="loan",status="INVALID"} 2`,
+		`tenet_evaluations_total{op="evaluate",schema="loan",status="READY"} 1`,
+		`tenet_evaluation_errors_total{op="verify",schema="unregistered"} 1`,
+		`tenet_evaluation_duration_seconds_bucket{op="evaluate",schema="loan",le="0.01"} 1`,
+		`tenet_evaluation_duration_seconds_bucket{op="evaluate",schema="loan",le="0.1"} 2`,
+		`tenet_evaluation_duration_seconds_bucket{op="evaluate",schema="loan",le="+Inf"} 3`,
+		`tenet_evaluation_duration_seconds_count{op="evaluate",schema="loan"} 3`,
+		`tenet_verify_issues_total{schema="loan",code="computed_mismatch"} 2`,
+		`tenet_verify_issues_total{schema="loan",code="status_mismatch"} 1`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, `status="INVALID"`) > strings.Index(out, `status="READY"`) {
+		t.Error("expected series in label order")
+	}
+}
+
+func TestCollectorService(t *testing.T) {
+	c := NewCollector()
+	svc := tenet.NewService(tenet.ServiceConfig{OnEvent: c.Observe})
+	c.Track(svc)
+	base := `{"schema_id": "form", "definitions": {"total": {"type": "number", "value": 5, "readonly": true}}}`
+	if err := svc.Register("form", base); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if _, err := svc.Evaluate(base, time.Now()); err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	svc.Verify("form", `{"definitions": {"total": {"type": "number", "value": 6, "readonly": true}}, "status": "READY"}`)
+
+	// Client-chosen IDs must not add series
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("client_%d", i)
+		if _, err := svc.Evaluate(strings.Replace(base, `"form"`, `"`+id+`"`, 1), time.Now()); err != nil {
+			t.Fatalf("Evaluate failed: %v", err)
+		}
+		svc.Verify(id, base)
+	}
+
+	var b strings.Builder
+	if err := c.Write(&b); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`tenet_evaluations_total{op="evaluate",schema="form",status="READY"} 1`,
+		`tenet_verify_issues_total{schema="form",code="computed_mismatch"} 1`,
+		`tenet_evaluations_total{op="evaluate",schema="unregistered",status="READY"} 3`,
+		`tenet_evaluation_errors_total{op="verify",schema="unregistered"} 3`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "client_") {
+		t.Errorf("unregistered schema IDs leaked into labels:\n%s", out)
+	}
+}
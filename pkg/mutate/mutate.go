@@ -0,0 +1,288 @@
+// Package mutate measures how well a schema's embedded test fixtures cover its logic.
+// It systematically perturbs rule conditions and thresholds and checks whether
+// tenet.RunSchemaTests notices each change (a "killed" mutant) or not (a "survivor").
+package mutate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// Mutant is a single perturbation of the schema.
+type Mutant struct {
+	Rule        string `json:"rule,omitempty"`    // Rule ID (empty for derived expressions)
+	Derived     string `json:"derived,omitempty"` // Derived field name (empty for rules)
+	Description string `json:"description"`       // What was changed
+	Killed      bool   `json:"killed"`            // True if at least one fixture failed
+}
+
+// Result summarizes a mutation run.
+type Result struct {
+	Total    int      `json:"total"`
+	Killed   int      `json:"killed"`
+	Survived int      `json:"survived"`
+	Score    float64  `json:"score"` // Killed / Total (1.0 when there are no mutants)
+	Mutants  []Mutant `json:"mutants"`
+	Baseline string   `json:"baseline,omitempty"` // Set when fixtures fail before mutation
+	NumTests int      `json:"num_tests"`
+}
+
+// operatorSwaps lists the replacement operators tried for each operator.
+var operatorSwaps = map[string][]string{
+	">":      {">=", "<"},
+	">=":     {">", "<"},
+	"<":      {"<=", ">"},
+	"<=":     {"<", ">"},
+	"==":     {"!="},
+	"!=":     {"=="},
+	"and":    {"or"},
+	"or":     {"and"},
+	"before": {"after"},
+	"after":  {"before"},
+	"some":   {"all"},
+	"all":    {"some"},
+}
+
+// site is a location in the schema document that can be mutated.
+type site struct {
+	path        []any // Keys (string) and indices (int) from the document root
+	rule        string
+	derived     string
+	description string
+	apply       func(doc map[string]any, path []any)
+}
+
+// Run generates mutants for a schema and runs its fixtures against each one.
+// The fixtures must pass on the unmodified schema; otherwise Baseline explains why and no mutants run.
+func Run(jsonText string) (*Result, error) {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(jsonText), &doc); err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+
+	baseline, err := tenet.RunSchemaTests(jsonText)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Mutants: make([]Mutant, 0), NumTests: baseline.Passed + baseline.Failed}
+	if result.NumTests == 0 {
+		result.Baseline = "schema has no tests"
+		return result, nil
+	}
+	if baseline.Failed > 0 {
+		result.Baseline = fmt.Sprintf("%d of %d tests fail before mutation", baseline.Failed, result.NumTests)
+		return result, nil
+	}
+
+	for _, s := range collectSites(doc) {
+		mutated, err := applySite(jsonText, s)
+		if err != nil {
+			return nil, err
+		}
+		report, err := tenet.RunSchemaTests(mutated)
+		if err != nil {
+			return nil, err
+		}
+
+		m := Mutant{Rule: s.rule, Derived: s.derived, Description: s.description, Killed: report.Failed > 0}
+		result.Mutants = append(result.Mutants, m)
+		result.Total++
+		if m.Killed {
+			result.Killed++
+		} else {
+			result.Survived++
+		}
+	}
+
+	result.Score = 1
+	if result.Total > 0 {
+		result.Score = float64(result.Killed) / float64(result.Total)
+	}
+	return result, nil
+}
+
+// applySite re-parses the schema and applies one mutation to the fresh copy.
+func applySite(jsonText string, s site) (string, error) {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(jsonText), &doc); err != nil {
+		return "", fmt.Errorf("parse error: %w", err)
+	}
+	s.apply(doc, s.path)
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("marshal mutant: %w", err)
+	}
+	return string(out), nil
+}
+
+// collectSites enumerates every mutation site in a deterministic order.
+func collectSites(doc map[string]any) []site {
+	var sites []site
+
+	rules, _ := doc["logic_tree"].([]any)
+	for i, r := range rules {
+		rule, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, _ := rule["id"].(string)
+		if id == "" {
+			id = fmt.Sprintf("#%d", i)
+		}
+
+		sites = append(sites, site{
+			path:        []any{"logic_tree", i},
+			rule:        id,
+			description: "rule removed",
+			apply: func(doc map[string]any, path []any) {
+				if rule, ok := lookup(doc, path).(map[string]any); ok {
+					rule["disabled"] = true
+				}
+			},
+		})
+
+		for _, key := range []string{"when", "when_any", "unless"} {
+			if cond, ok := rule[key]; ok {
+				sites = append(sites, exprSites(cond, []any{"logic_tree", i, key}, id, "")...)
+			}
+		}
+	}
+
+	if sm, ok := doc["state_model"].(map[string]any); ok {
+		derived, _ := sm["derived"].(map[string]any)
+		for _, name := range sortedKeys(derived) {
+			def, ok := derived[name].(map[string]any)
+			if !ok {
+				continue
+			}
+			if eval, ok := def["eval"]; ok {
+				sites = append(sites, exprSites(eval, []any{"state_model", "derived", name, "eval"}, "", name)...)
+			}
+		}
+	}
+
+	return sites
+}
+
+// exprSites finds operator swaps and numeric threshold shifts within a JSON-logic expression.
+func exprSites(node any, path []any, rule, derived string) []site {
+	var sites []site
+
+	switch v := node.(type) {
+	case map[string]any:
+		if len(v) == 1 {
+			for op, args := range v {
+				for _, swap := range operatorSwaps[op] {
+					sites = append(sites, site{
+						path:        clonePath(path),
+						rule:        rule,
+						derived:     derived,
+						description: fmt.Sprintf("operator '%s' replaced with '%s'", op, swap),
+						apply: func(doc map[string]any, path []any) {
+							if m, ok := lookup(doc, path).(map[string]any); ok {
+								m[swap] = m[op]
+								delete(m, op)
+							}
+						},
+					})
+				}
+				if op == "var" {
+					continue
+				}
+				sites = append(sites, exprSites(args, append(clonePath(path), op), rule, derived)...)
+			}
+		}
+
+	case []any:
+		for i, elem := range v {
+			sites = append(sites, exprSites(elem, append(clonePath(path), i), rule, derived)...)
+		}
+
+	case float64:
+		for _, shifted := range shiftNumber(v) {
+			sites = append(sites, site{
+				path:        clonePath(path),
+				rule:        rule,
+				derived:     derived,
+				description: fmt.Sprintf("threshold %s changed to %s", formatNumber(v), formatNumber(shifted)),
+				apply: func(doc map[string]any, path []any) {
+					setAt(doc, path, shifted)
+				},
+			})
+		}
+	}
+
+	return sites
+}
+
+// shiftNumber returns the perturbed values for a numeric literal.
+// Whole numbers move by one (catching off-by-one boundaries); fractions move by 10%.
+func shiftNumber(v float64) []float64 {
+	if v == math.Trunc(v) {
+		return []float64{v - 1, v + 1}
+	}
+	return []float64{v * 0.9, v * 1.1}
+}
+
+// lookup returns the node at path, or nil if the path doesn't exist.
+func lookup(doc map[string]any, path []any) any {
+	var node any = doc
+	for _, p := range path {
+		switch key := p.(type) {
+		case string:
+			m, ok := node.(map[string]any)
+			if !ok {
+				return nil
+			}
+			node = m[key]
+		case int:
+			arr, ok := node.([]any)
+			if !ok || key >= len(arr) {
+				return nil
+			}
+			node = arr[key]
+		}
+	}
+	return node
+}
+
+// setAt replaces the node at path with value.
+func setAt(doc map[string]any, path []any, value any) {
+	if len(path) == 0 {
+		return
+	}
+	parent := lookup(doc, path[:len(path)-1])
+	switch key := path[len(path)-1].(type) {
+	case string:
+		if m, ok := parent.(map[string]any); ok {
+			m[key] = value
+		}
+	case int:
+		if arr, ok := parent.([]any); ok && key < len(arr) {
+			arr[key] = value
+		}
+	}
+}
+
+func clonePath(path []any) []any {
+	return append([]any(nil), path...)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,81 @@
+package mutate
+
+import (
+	"strings"
+	"testing"
+)
+
+const schema = `{
+	"valid_from": "2025-01-01",
+	"definitions": {
+		"credit_score": {"type": "number", "value": null},
+		"decision": {"type": "string", "value": "pending"}
+	},
+	"logic_tree": [
+		{"id": "approve", "when": {">=": [{"var": "credit_score"}, 700]}, "then": {"set": {"decision": "approved"}}}
+	],
+	"tests": [
+		{"name": "approved", "set": {"credit_score": 750}, "expect": {"values": {"decision": "approved"}}},
+		{"name": "pending", "set": {"credit_score": 500}, "expect": {"values": {"decision": "pending"}}}
+	]
+}`
+
+func TestRun(t *testing.T) {
+	result, err := Run(schema)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Baseline != "" {
+		t.Fatalf("unexpected baseline failure: %s", result.Baseline)
+	}
+
+	// rule removed, >= -> >, >= -> <, 700 -> 699, 700 -> 701
+	if result.Total != 5 {
+		t.Fatalf("expected 5 mutants, got %d: %+v", result.Total, result.Mutants)
+	}
+
+	// Fixtures at 750/500 can't tell >= from > or notice a one-point threshold shift
+	survivors := map[string]bool{}
+	for _, m := range result.Mutants {
+		if !m.Killed {
+			survivors[m.Description] = true
+		}
+	}
+	for _, want := range []string{
+		"operator '>=' replaced with '>'",
+		"threshold 700 changed to 699",
+		"threshold 700 changed to 701",
+	} {
+		if !survivors[want] {
+			t.Errorf("expected survivor %q, got %v", want, survivors)
+		}
+	}
+	if result.Killed != 2 {
+		t.Errorf("expected 2 killed, got %d", result.Killed)
+	}
+
+	// A boundary fixture kills the threshold and operator mutants
+	boundary := strings.Replace(schema, `"tests": [`, `"tests": [
+		{"name": "boundary", "set": {"credit_score": 700}, "expect": {"values": {"decision": "approved"}}},
+		{"name": "below", "set": {"credit_score": 699}, "expect": {"values": {"decision": "pending"}}},`, 1)
+	result, err = Run(boundary)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Survived != 0 {
+		t.Errorf("expected no survivors, got %+v", result.Mutants)
+	}
+	if result.Score != 1 {
+		t.Errorf("expected score 1, got %v", result.Score)
+	}
+}
+
+func TestRunWithoutTests(t *testing.T) {
+	result, err := Run(`{"definitions": {}}`)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Baseline == "" {
+		t.Error("expected baseline message for schema without tests")
+	}
+}
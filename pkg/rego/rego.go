@@ -0,0 +1,812 @@
+// Package rego exports a schema's decision logic as a Rego policy for Open Policy Agent,
+// so teams that audit policies in OPA tooling can review the same rules. The translation
+// is best-effort: constructs without a faithful Rego equivalent are left out and listed
+// in the policy's gaps rather than approximated.
+package rego
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// Gap is a construct that wasn't translated, or whose translation behaves differently.
+type Gap struct {
+	Location  string `json:"location"`  // "rule:<id>", "derived:<name>" or "decision_table:<id>"
+	Construct string `json:"construct"` // Operator or schema key, e.g. "pow", "feature"
+	Reason    string `json:"reason"`
+}
+
+// Policy is an exported Rego module and the gaps found while writing it.
+type Policy struct {
+	Module string `json:"module"`
+	Gaps   []Gap  `json:"gaps,omitempty"`
+}
+
+// Export translates a schema into a Rego module (rego.v1 syntax) in package pkg, or
+// "tenet.<schema_id>" when pkg is empty. Field values are read from input, so the policy is
+// evaluated against {"field": value, ...}. The module defines:
+//
+//	derived_<name>  one rule per derived value
+//	rule_<id>       true when the rule's conditions hold
+//	fired           set of IDs of the rules that hold
+//	errors          set of {"rule", "message", "law_ref"} for rules with error_msg
+//	sets            set of {"rule", "field", "value"} for each field a rule sets
+//	ui_modify       set of {"rule", "field", "changes"} for each ui_modify entry
+//
+// Rules run in order in Tenet and may read fields set by earlier rules; in Rego every rule
+// sees the input, which is reported as a gap. So are rule scopes (feature, jurisdictions,
+// validity windows, logic versions) the policy doesn't model. Rules and derived values
+// that use an unsupported operator are left out entirely.
+func Export(jsonText, pkg string) (*Policy, error) {
+	expanded, err := tenet.ExpandTemplates(jsonText)
+	if err != nil {
+		return nil, err
+	}
+	var s tenet.Schema
+	if err := json.Unmarshal([]byte(expanded), &s); err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+	if pkg == "" {
+		id := s.SchemaID
+		if id == "" {
+			id = "policy"
+		}
+		pkg = "tenet." + ident(id)
+	}
+	if !packagePath.MatchString(pkg) {
+		return nil, fmt.Errorf("invalid package path '%s'", pkg)
+	}
+
+	w := &writer{policy: &Policy{}}
+	if s.StateModel != nil {
+		w.derived = s.StateModel.Derived
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Exported from Tenet schema %q. Best-effort translation: see the export's gaps.\n", s.SchemaID)
+	fmt.Fprintf(&sb, "package %s\n\nimport rego.v1\n", pkg)
+
+	for _, table := range s.DecisionTables {
+		if table != nil {
+			w.gap("decision_table:"+table.ID, "decision_tables", "decision tables are compiled at run time; export the output of `tenet run` to include their rules")
+		}
+	}
+
+	setBy := make(map[string]string)
+	for _, rule := range s.LogicTree {
+		if rule != nil && rule.Then != nil {
+			for _, field := range sortedKeys(rule.Then.Set) {
+				if _, ok := setBy[field]; !ok {
+					setBy[field] = rule.ID
+				}
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(w.derived) {
+		def := w.derived[name]
+		if def == nil {
+			continue
+		}
+		loc := "derived:" + name
+		if len(def.ByJurisdiction) > 0 {
+			w.gap(loc, "by_jurisdiction", "jurisdiction formulas are not exported; the policy uses eval")
+		}
+		expr, err := def.Eval, error(nil)
+		if text, ok := def.Eval.(string); ok {
+			if expr, err = tenet.ParseEval(text); err != nil {
+				err = &unsupported{"expression", fmt.Sprintf("unparseable expression '%s': %v", text, err)}
+			}
+		}
+		if err == nil {
+			for _, v := range readVars(expr) {
+				if rule, ok := setBy[v]; ok {
+					w.gap(loc, "set_then_read", fmt.Sprintf("reads '%s', which rule '%s' sets; the policy sees the input value", v, rule))
+				}
+			}
+			var text string
+			if text, err = w.assign("derived_"+ident(name), expr, ""); err == nil {
+				fmt.Fprintf(&sb, "\n# Derived value %s\n%s", name, text)
+				sb.WriteString(w.flush())
+				continue
+			}
+		}
+		w.discard()
+		w.gap(loc, construct(err), err.Error())
+		fmt.Fprintf(&sb, "\n# Derived value %s not exported: %v\n", name, err)
+	}
+
+	setSoFar := make(map[string]string)
+	for _, rule := range s.LogicTree {
+		if rule == nil {
+			continue
+		}
+		text, err := w.rule(rule, setSoFar)
+		if err != nil {
+			w.discard()
+			w.gap("rule:"+rule.ID, construct(err), err.Error())
+			fmt.Fprintf(&sb, "\n# Rule %s not exported: %v\n", rule.ID, err)
+		} else {
+			sb.WriteString(text)
+			sb.WriteString(w.flush())
+		}
+		if rule.Then != nil {
+			for field := range rule.Then.Set {
+				setSoFar[field] = rule.ID
+			}
+		}
+	}
+
+	sb.WriteString(w.library())
+	w.policy.Module = sb.String()
+	return w.policy, nil
+}
+
+var packagePath = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// unsupported is a translation failure naming the construct responsible.
+type unsupported struct {
+	construct string
+	reason    string
+}
+
+func (u *unsupported) Error() string { return u.reason }
+
+func construct(err error) string {
+	if u, ok := err.(*unsupported); ok {
+		return u.construct
+	}
+	return "expression"
+}
+
+// writer accumulates helper rules while translating one rule or derived value.
+type writer struct {
+	policy  *Policy
+	derived map[string]*tenet.DerivedDef
+	helpers []string // Helper rules of the item being translated
+	next    int      // Helper and element variable counter
+	truthy  bool     // _truthy is used
+	date    bool     // _date is used
+}
+
+func (w *writer) gap(location, construct, reason string) {
+	w.policy.Gaps = append(w.policy.Gaps, Gap{Location: location, Construct: construct, Reason: reason})
+}
+
+// flush returns the pending helper rules and clears them.
+func (w *writer) flush() string {
+	var sb strings.Builder
+	for _, h := range w.helpers {
+		sb.WriteString("\n" + h)
+	}
+	w.helpers = nil
+	return sb.String()
+}
+
+// discard drops the pending helper rules of an item that failed to translate.
+func (w *writer) discard() { w.helpers = nil }
+
+// library returns the shared helper functions the module uses.
+func (w *writer) library() string {
+	var sb strings.Builder
+	if w.truthy {
+		sb.WriteString("\n# JSON-logic truthiness: false, null, 0, \"\", [] and {} are falsy\n")
+		sb.WriteString("_truthy(x) if {\n\tx != false\n\tx != null\n\tx != 0\n\tx != \"\"\n\tx != []\n\tx != {}\n}\n")
+	}
+	if w.date {
+		sb.WriteString("\n# Dates are RFC 3339 timestamps or YYYY-MM-DD\n")
+		sb.WriteString("_date(s) := time.parse_rfc3339_ns(s) if {\n\tcontains(s, \"T\")\n} else := time.parse_ns(\"2006-01-02\", s)\n")
+	}
+	return sb.String()
+}
+
+// rule writes rule_<id> and its contributions to fired, errors, sets and ui_modify.
+func (w *writer) rule(rule *tenet.Rule, setSoFar map[string]string) (string, error) {
+	loc := "rule:" + rule.ID
+	name := "rule_" + ident(rule.ID)
+
+	var body []string
+	for _, cond := range conditions(rule.When) {
+		expr, err := w.compile(cond)
+		if err != nil {
+			return "", err
+		}
+		lines, err := w.cond(expr, "")
+		if err != nil {
+			return "", err
+		}
+		body = append(body, lines...)
+	}
+	if len(rule.WhenAny) > 0 {
+		var exprs []any
+		for _, cond := range rule.WhenAny {
+			expr, err := w.compile(cond)
+			if err != nil {
+				return "", err
+			}
+			exprs = append(exprs, expr)
+		}
+		line, err := w.or(exprs, "")
+		if err != nil {
+			return "", err
+		}
+		body = append(body, line)
+	}
+	if rule.Unless != nil {
+		var exprs []any
+		for _, cond := range conditions(rule.Unless) {
+			expr, err := w.compile(cond)
+			if err != nil {
+				return "", err
+			}
+			exprs = append(exprs, expr)
+		}
+		line, err := w.or(exprs, "")
+		if err != nil {
+			return "", err
+		}
+		body = append(body, "not "+line)
+	}
+
+	reads, _ := w.compile([]any{rule.When, rule.Unless, rule.WhenAny})
+	if rule.Then != nil {
+		reads = []any{reads, rule.Then.Set}
+	}
+	for _, v := range readVars(reads) {
+		if by, ok := setSoFar[v]; ok {
+			w.gap(loc, "set_then_read", fmt.Sprintf("reads '%s', which rule '%s' sets; the policy sees the input value", v, by))
+		}
+	}
+	if rule.Feature != "" {
+		w.gap(loc, "feature", fmt.Sprintf("applies only with feature '%s'; the policy applies it unconditionally", rule.Feature))
+	}
+	if len(rule.Jurisdictions) > 0 {
+		w.gap(loc, "jurisdictions", fmt.Sprintf("applies only in %s; the policy applies it everywhere", strings.Join(rule.Jurisdictions, ", ")))
+	}
+	if rule.ValidFrom != "" || rule.ValidUntil != "" {
+		w.gap(loc, "valid_from", "has a validity window; the policy applies it on every date")
+	}
+	if rule.LogicVersion != "" {
+		w.gap(loc, "logic_version", fmt.Sprintf("belongs to logic version '%s'; the policy applies every version", rule.LogicVersion))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n# Rule " + rule.ID)
+	if rule.Title != "" {
+		sb.WriteString(": " + rule.Title)
+	}
+	if rule.LawRef != "" {
+		sb.WriteString(" (" + rule.LawRef + ")")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(block(name, body))
+	fmt.Fprintf(&sb, "\nfired contains %s if %s\n", quote(rule.ID), name)
+
+	if rule.Then == nil {
+		return sb.String(), nil
+	}
+	if rule.Then.ErrorMsg != "" {
+		fmt.Fprintf(&sb, "\nerrors contains {\"rule\": %s, \"message\": %s, \"law_ref\": %s} if %s\n",
+			quote(rule.ID), quote(rule.Then.ErrorMsg), quote(rule.LawRef), name)
+	}
+	for _, field := range sortedKeys(rule.Then.Set) {
+		text, err := w.value(rule.Then.Set[field], "")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "\nsets contains {\"rule\": %s, \"field\": %s, \"value\": v} if {\n\t%s\n\tv := %s\n}\n",
+			quote(rule.ID), quote(field), name, text)
+	}
+	for _, field := range sortedKeys(rule.Then.UIModify) {
+		changes, err := json.Marshal(rule.Then.UIModify[field])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "\nui_modify contains {\"rule\": %s, \"field\": %s, \"changes\": %s} if %s\n",
+			quote(rule.ID), quote(field), changes, name)
+	}
+	return sb.String(), nil
+}
+
+// conditions splits a when or unless into its conditions (an array is one per element).
+func conditions(cond any) []any {
+	if cond == nil {
+		return nil
+	}
+	if arr, ok := cond.([]any); ok {
+		return arr
+	}
+	return []any{cond}
+}
+
+// compile turns infix strings into JSON-logic, as Run does for conditions (elements of
+// condition arrays included). Derived formulas go through tenet.ParseEval instead, and
+// set values are never infix.
+func (w *writer) compile(expr any) (any, error) {
+	switch e := expr.(type) {
+	case string:
+		parsed, err := tenet.ParseExpr(e)
+		if err != nil {
+			return nil, &unsupported{"expression", fmt.Sprintf("unparseable expression '%s': %v", e, err)}
+		}
+		return parsed, nil
+	case []any:
+		out := make([]any, len(e))
+		for i, elem := range e {
+			var err error
+			if out[i], err = w.compile(elem); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+	return expr, nil
+}
+
+// operator splits a single-key JSON-logic node into its operator and arguments.
+func operator(expr any) (string, []any, bool) {
+	m, ok := expr.(map[string]any)
+	if !ok || len(m) != 1 {
+		return "", nil, false
+	}
+	for op, args := range m {
+		if arr, ok := args.([]any); ok {
+			return op, arr, true
+		}
+		return op, []any{args}, true
+	}
+	return "", nil, false
+}
+
+var comparisons = map[string]string{"==": "==", "!=": "!=", ">": ">", "<": "<", ">=": ">=", "<=": "<="}
+
+// cond translates a condition into rule body lines, all of which must hold.
+// elem is the Rego variable bound to {"var": ""} inside some/all/none, if any.
+func (w *writer) cond(expr any, elem string) ([]string, error) {
+	op, args, ok := operator(expr)
+	if !ok || op == "var" {
+		if b, ok := expr.(bool); ok {
+			if b {
+				return nil, nil
+			}
+			return []string{"false"}, nil
+		}
+		val, err := w.value(expr, elem)
+		if err != nil {
+			return nil, err
+		}
+		w.truthy = true
+		return []string{"_truthy(" + val + ")"}, nil
+	}
+
+	switch op {
+	case "and":
+		var lines []string
+		for _, arg := range args {
+			sub, err := w.cond(arg, elem)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, sub...)
+		}
+		return lines, nil
+
+	case "or":
+		line, err := w.or(args, elem)
+		if err != nil {
+			return nil, err
+		}
+		return []string{line}, nil
+
+	case "not", "!":
+		if len(args) != 1 {
+			return nil, &unsupported{op, fmt.Sprintf("'%s' takes one argument", op)}
+		}
+		sub, err := w.cond(args[0], elem)
+		if err != nil {
+			return nil, err
+		}
+		if len(sub) == 1 && !strings.HasPrefix(sub[0], "not ") && !strings.HasPrefix(sub[0], "some ") && !strings.HasPrefix(sub[0], "every ") {
+			return []string{"not " + sub[0]}, nil
+		}
+		return []string{"not " + w.helper(elem, [][]string{sub})}, nil
+
+	case "if":
+		bodies, err := w.ifBodies(args, elem)
+		if err != nil {
+			return nil, err
+		}
+		return []string{w.helper(elem, bodies)}, nil
+
+	case "==", "!=", ">", "<", ">=", "<=":
+		if len(args) != 2 {
+			return nil, &unsupported{op, fmt.Sprintf("'%s' takes two arguments", op)}
+		}
+		a, err := w.value(args[0], elem)
+		if err != nil {
+			return nil, err
+		}
+		b, err := w.value(args[1], elem)
+		if err != nil {
+			return nil, err
+		}
+		return []string{a + " " + comparisons[op] + " " + b}, nil
+
+	case "before", "after":
+		if len(args) != 2 {
+			return nil, &unsupported{op, fmt.Sprintf("'%s' takes two arguments", op)}
+		}
+		a, err := w.value(args[0], elem)
+		if err != nil {
+			return nil, err
+		}
+		b, err := w.value(args[1], elem)
+		if err != nil {
+			return nil, err
+		}
+		w.date = true
+		cmp := "<"
+		if op == "after" {
+			cmp = ">"
+		}
+		return []string{fmt.Sprintf("_date(%s) %s _date(%s)", a, cmp, b)}, nil
+
+	case "in":
+		if len(args) != 2 {
+			return nil, &unsupported{op, "'in' takes two arguments"}
+		}
+		needle, err := w.value(args[0], elem)
+		if err != nil {
+			return nil, err
+		}
+		haystack, err := w.value(args[1], elem)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := args[1].(string); ok {
+			return []string{fmt.Sprintf("contains(%s, %s)", haystack, needle)}, nil
+		}
+		return []string{needle + " in " + haystack}, nil
+
+	case "some", "all", "none":
+		if len(args) != 2 {
+			return nil, &unsupported{op, fmt.Sprintf("'%s' takes an array and a condition", op)}
+		}
+		list, err := w.value(args[0], elem)
+		if err != nil {
+			return nil, err
+		}
+		w.next++
+		x := fmt.Sprintf("x%d", w.next)
+		sub, err := w.cond(args[1], x)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "some":
+			return append([]string{"some " + x + " in " + list}, sub...), nil
+		case "all":
+			if len(sub) == 0 {
+				sub = []string{"true"}
+			}
+			return []string{"every " + x + " in " + list + " {\n\t\t" + strings.Join(sub, "\n\t\t") + "\n\t}"}, nil
+		default:
+			lines := append([]string{"some " + x + " in " + list}, sub...)
+			return []string{"is_array(" + list + ")", "not " + w.helper(elem, [][]string{lines})}, nil
+		}
+	}
+
+	// Anything else is a value tested for truthiness
+	val, err := w.value(expr, elem)
+	if err != nil {
+		return nil, err
+	}
+	w.truthy = true
+	return []string{"_truthy(" + val + ")"}, nil
+}
+
+// or returns a body line that holds when any of the conditions does.
+func (w *writer) or(exprs []any, elem string) (string, error) {
+	bodies := make([][]string, 0, len(exprs))
+	for _, expr := range exprs {
+		lines, err := w.cond(expr, elem)
+		if err != nil {
+			return "", err
+		}
+		bodies = append(bodies, lines)
+	}
+	if len(bodies) == 0 {
+		return "false", nil
+	}
+	return w.helper(elem, bodies), nil
+}
+
+// ifBodies returns the alternative bodies of an if/else-if chain used as a condition.
+func (w *writer) ifBodies(args []any, elem string) ([][]string, error) {
+	var bodies [][]string
+	var negated []string
+	for len(args) >= 2 {
+		c, err := w.cond(args[0], elem)
+		if err != nil {
+			return nil, err
+		}
+		then, err := w.cond(args[1], elem)
+		if err != nil {
+			return nil, err
+		}
+		body := append(append(append([]string{}, negated...), c...), then...)
+		bodies = append(bodies, body)
+		negated = append(negated, "not "+w.helper(elem, [][]string{c}))
+		args = args[2:]
+	}
+	if len(args) == 1 {
+		last, err := w.cond(args[0], elem)
+		if err != nil {
+			return nil, err
+		}
+		bodies = append(bodies, append(append([]string{}, negated...), last...))
+	}
+	return bodies, nil
+}
+
+// helper defines a boolean helper rule (a function of elem inside quantifiers) with one
+// definition per body, and returns the expression that calls it.
+func (w *writer) helper(elem string, bodies [][]string) string {
+	w.next++
+	name := fmt.Sprintf("_h%d", w.next)
+	call := name
+	if elem != "" {
+		name += "(" + elem + ")"
+		call = name
+	}
+	for _, body := range bodies {
+		w.helpers = append(w.helpers, block(name, body))
+	}
+	return call
+}
+
+// value translates an expression into a Rego term.
+func (w *writer) value(expr any, elem string) (string, error) {
+	switch e := expr.(type) {
+	case nil:
+		return "null", nil
+	case bool, float64, string:
+		b, err := json.Marshal(e)
+		return string(b), err
+	case []any:
+		items := make([]string, len(e))
+		for i, item := range e {
+			var err error
+			if items[i], err = w.value(item, elem); err != nil {
+				return "", err
+			}
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	}
+
+	op, args, ok := operator(expr)
+	if !ok {
+		return "", &unsupported{"object", "object literals are not supported"}
+	}
+	switch op {
+	case "var":
+		path, _ := args[0].(string)
+		return w.ref(path, elem)
+
+	case "+", "-", "*", "/", "%":
+		if len(args) != 2 {
+			return "", &unsupported{op, fmt.Sprintf("'%s' takes two arguments", op)}
+		}
+		a, err := w.value(args[0], elem)
+		if err != nil {
+			return "", err
+		}
+		b, err := w.value(args[1], elem)
+		if err != nil {
+			return "", err
+		}
+		return "(" + a + " " + op + " " + b + ")", nil
+
+	case "abs":
+		a, err := w.value(args[0], elem)
+		if err != nil {
+			return "", err
+		}
+		return "abs(" + a + ")", nil
+
+	case "lower", "upper", "trim", "length":
+		a, err := w.value(args[0], elem)
+		if err != nil {
+			return "", err
+		}
+		fn := map[string]string{"lower": "lower", "upper": "upper", "trim": "trim_space", "length": "count"}[op]
+		return fn + "(" + a + ")", nil
+
+	case "if":
+		return w.conditional(args, elem)
+
+	case "and", "or", "not", "!", "==", "!=", ">", "<", ">=", "<=", "before", "after", "in", "some", "all", "none":
+		lines, err := w.cond(expr, elem)
+		if err != nil {
+			return "", err
+		}
+		w.next++
+		name := fmt.Sprintf("_h%d", w.next)
+		if elem == "" {
+			w.helpers = append(w.helpers, "default "+name+" := false\n\n"+block(name, lines))
+			return name, nil
+		}
+		w.helpers = append(w.helpers, "default "+name+"(_) := false\n\n"+block(name+"("+elem+")", lines))
+		return name + "(" + elem + ")", nil
+	}
+	return "", &unsupported{op, fmt.Sprintf("operator '%s' has no Rego translation", op)}
+}
+
+// conditional translates an if/else-if chain used as a value into a helper with else
+// branches; a chain without a final else yields null.
+func (w *writer) conditional(args []any, elem string) (string, error) {
+	w.next++
+	name := fmt.Sprintf("_h%d", w.next)
+	head := name
+	if elem != "" {
+		head += "(" + elem + ")"
+	}
+	text, err := w.chain(head, args, elem)
+	if err != nil {
+		return "", err
+	}
+	w.helpers = append(w.helpers, text)
+	return head, nil
+}
+
+// assign writes `name := expr`, using an else chain when expr is an if.
+func (w *writer) assign(name string, expr any, elem string) (string, error) {
+	if op, args, ok := operator(expr); ok && op == "if" {
+		return w.chain(name, args, elem)
+	}
+	val, err := w.value(expr, elem)
+	if err != nil {
+		return "", err
+	}
+	return name + " := " + val + "\n", nil
+}
+
+func (w *writer) chain(head string, args []any, elem string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(head)
+	for len(args) >= 2 {
+		c, err := w.cond(args[0], elem)
+		if err != nil {
+			return "", err
+		}
+		then, err := w.value(args[1], elem)
+		if err != nil {
+			return "", err
+		}
+		if len(c) == 0 {
+			c = []string{"true"}
+		}
+		fmt.Fprintf(&sb, " := %s if {\n\t%s\n} else", then, strings.Join(c, "\n\t"))
+		args = args[2:]
+	}
+	last := "null"
+	if len(args) == 1 {
+		var err error
+		if last, err = w.value(args[0], elem); err != nil {
+			return "", err
+		}
+	}
+	fmt.Fprintf(&sb, " := %s\n", last)
+	return sb.String(), nil
+}
+
+// ref translates a var path: derived values are rules, fields come from input, and ""
+// (or a leading dot) is the current quantifier element.
+func (w *writer) ref(path, elem string) (string, error) {
+	if path == "" {
+		if elem == "" {
+			return "", &unsupported{"var", "{\"var\": \"\"} outside some/all/none"}
+		}
+		return elem, nil
+	}
+	if rest, ok := strings.CutPrefix(path, "."); ok {
+		if elem == "" {
+			return "", &unsupported{"var", fmt.Sprintf("{\"var\": \"%s\"} outside some/all/none", path)}
+		}
+		var sb strings.Builder
+		sb.WriteString(elem)
+		for _, part := range strings.Split(rest, ".") {
+			sb.WriteString(segment(part))
+		}
+		return sb.String(), nil
+	}
+	parts := strings.Split(path, ".")
+	var sb strings.Builder
+	if _, ok := w.derived[parts[0]]; ok {
+		sb.WriteString("derived_" + ident(parts[0]))
+	} else {
+		sb.WriteString("input")
+		sb.WriteString(segment(parts[0]))
+	}
+	for _, part := range parts[1:] {
+		sb.WriteString(segment(part))
+	}
+	return sb.String(), nil
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func segment(part string) string {
+	if identifier.MatchString(part) {
+		return "." + part
+	}
+	return "[" + quote(part) + "]"
+}
+
+// ident turns an ID into a Rego identifier fragment.
+func ident(id string) string {
+	var sb strings.Builder
+	for _, r := range id {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// block writes `head if { body }`.
+func block(head string, body []string) string {
+	if len(body) == 0 {
+		body = []string{"true"}
+	}
+	return head + " if {\n\t" + strings.Join(body, "\n\t") + "\n}\n"
+}
+
+// readVars returns the root names of the fields an expression reads, without duplicates.
+func readVars(node any) []string {
+	var vars []string
+	seen := make(map[string]bool)
+	var walk func(any)
+	walk = func(node any) {
+		switch v := node.(type) {
+		case map[string]any:
+			if name, ok := v["var"].(string); ok {
+				root, _, _ := strings.Cut(name, ".")
+				if root != "" && !seen[root] {
+					seen[root] = true
+					vars = append(vars, root)
+				}
+			}
+			for _, key := range sortedKeys(v) {
+				walk(v[key])
+			}
+		case []any:
+			for _, elem := range v {
+				walk(elem)
+			}
+		}
+	}
+	walk(node)
+	return vars
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
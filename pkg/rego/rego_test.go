@@ -0,0 +1,99 @@
+package rego
+
+import (
+	"strings"
+	"testing"
+)
+
+const schema = `{
+	"schema_id": "loan-app",
+	"definitions": {
+		"income": {"type": "number"},
+		"country": {"type": "string"},
+		"debts": {"type": "array"},
+		"decision": {"type": "string"}
+	},
+	"state_model": {
+		"derived": {
+			"monthly": {"eval": "= income / 12"},
+			"band": {"eval": {"if": [{">": [{"var": "income"}, 100000]}, "high", "standard"]}},
+			"squared": {"eval": {"pow": [{"var": "income"}, 2]}}
+		}
+	},
+	"logic_tree": [
+		{
+			"id": "low_income",
+			"law_ref": "Credit Act 4",
+			"when": ["monthly < 1000", {"or": [{"==": [{"var": "country"}, "SE"]}, {"!": {"in": [{"var": "country"}, ["NO", "DK"]]}}]}],
+			"unless": {"var": "debts"},
+			"then": {"set": {"decision": "decline"}, "error_msg": "Income too low"}
+		},
+		{
+			"id": "large_debt",
+			"feature": "debt_check",
+			"when": {"some": [{"var": "debts"}, {">": [{"var": ""}, 5000]}]},
+			"then": {"ui_modify": {"decision": {"visible": false}}}
+		},
+		{"id": "review", "when": {"==": [{"var": "decision"}, "decline"]}, "then": {"set": {"note": {"pow": [2, 2]}}}}
+	]
+}`
+
+func TestExport(t *testing.T) {
+	p, err := Export(schema, "")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"package tenet.loan_app\n\nimport rego.v1\n",
+		"derived_monthly := (input.income / 12)\n",
+		"derived_band := \"high\" if {\n\tinput.income > 100000\n} else := \"standard\"\n",
+		"rule_low_income if {\n\tderived_monthly < 1000\n\t_h1\n\tnot _h2\n}\n",
+		"_h1 if {\n\tnot input.country in [\"NO\", \"DK\"]\n}\n",
+		"_h2 if {\n\t_truthy(input.debts)\n}\n",
+		`errors contains {"rule": "low_income", "message": "Income too low", "law_ref": "Credit Act 4"} if rule_low_income`,
+		"sets contains {\"rule\": \"low_income\", \"field\": \"decision\", \"value\": v} if {\n\trule_low_income\n\tv := \"decline\"\n}\n",
+		"rule_large_debt if {\n\tsome x3 in input.debts\n\tx3 > 5000\n}\n",
+		`ui_modify contains {"rule": "large_debt", "field": "decision", "changes": {"visible":false}} if rule_large_debt`,
+		"# Rule review not exported: operator 'pow' has no Rego translation\n",
+		"_truthy(x) if {",
+	} {
+		if !strings.Contains(p.Module, want) {
+			t.Errorf("module missing %q:\n%s", want, p.Module)
+		}
+	}
+	if strings.Contains(p.Module, "rule_review") || strings.Contains(p.Module, "derived_squared") {
+		t.Errorf("untranslatable items should be left out:\n%s", p.Module)
+	}
+
+	gaps := make(map[string]bool)
+	for _, g := range p.Gaps {
+		gaps[g.Location+" "+g.Construct] = true
+	}
+	for _, want := range []string{
+		"derived:squared pow",
+		"rule:large_debt feature",
+		"rule:review set_then_read",
+		"rule:review pow",
+	} {
+		if !gaps[want] {
+			t.Errorf("missing gap %q in %+v", want, p.Gaps)
+		}
+	}
+	if len(p.Gaps) != 4 {
+		t.Errorf("expected 4 gaps, got %+v", p.Gaps)
+	}
+}
+
+func TestExportPackage(t *testing.T) {
+	p, err := Export(schema, "policies.credit")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(p.Module, "package policies.credit\n") {
+		t.Errorf("expected custom package:\n%s", p.Module)
+	}
+	if _, err := Export(schema, "policies.1credit"); err == nil {
+		t.Error("expected an invalid package path to be rejected")
+	}
+}
@@ -0,0 +1,84 @@
+package source
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	doc := []byte(`{"protocol": "Test_v1", "definitions": {}}`)
+	pin := Hash(doc)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schema.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(doc)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, doc, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := &Loader{Client: srv.Client(), Stdin: strings.NewReader(string(doc))}
+
+	for _, src := range []string{path, srv.URL + "/schema.json", "-"} {
+		got, err := loader.Read(src, "sha256:"+strings.ToUpper(pin))
+		if err != nil {
+			t.Fatalf("Read(%s) failed: %v", src, err)
+		}
+		if string(got) != string(doc) {
+			t.Errorf("Read(%s) = %s", src, got)
+		}
+	}
+
+	if _, err := loader.Read(path, Hash([]byte("other"))); !errors.Is(err, ErrIntegrity) {
+		t.Errorf("expected ErrIntegrity, got %v", err)
+	}
+	if _, err := loader.Read(srv.URL+"/missing.json", pin); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected 404 error, got %v", err)
+	}
+}
+
+func TestReadRemoteSafety(t *testing.T) {
+	doc := []byte(`{"protocol": "Test_v1", "definitions": {}}`)
+	var fetched int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		w.Write(doc)
+	})
+
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+	loader := &Loader{Client: plain.Client()}
+	if _, err := loader.Read(plain.URL+"/schema.json", ""); !errors.Is(err, ErrUnpinned) {
+		t.Errorf("expected ErrUnpinned, got %v", err)
+	}
+	if fetched != 0 {
+		t.Errorf("unpinned http source should not be fetched, got %d requests", fetched)
+	}
+
+	tls := httptest.NewTLSServer(handler)
+	defer tls.Close()
+	loader = &Loader{Client: tls.Client()}
+	if got, err := loader.Read(tls.URL+"/schema.json", ""); err != nil || string(got) != string(doc) {
+		t.Errorf("https without a pin should load, got %s (%v)", got, err)
+	}
+
+	loader.MaxSize = int64(len(doc)) - 1
+	if _, err := loader.Read(tls.URL+"/schema.json", ""); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+	loader.MaxSize = int64(len(doc))
+	if _, err := loader.Read(tls.URL+"/schema.json", ""); err != nil {
+		t.Errorf("document at the limit should load: %v", err)
+	}
+}
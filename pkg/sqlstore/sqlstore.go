@@ -0,0 +1,123 @@
+// Package sqlstore implements tenet's storage interfaces on database/sql, so sessions survive
+// restarts in whatever database a host already runs. It imports no driver: open the *sql.DB
+// with the driver of your choice (SQLite, PostgreSQL, MySQL, ...).
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// DefaultSessionTable is the table SessionStore uses when Config.Table is empty.
+const DefaultSessionTable = "tenet_sessions"
+
+// Config selects the table and the driver's SQL dialect.
+type Config struct {
+	Table          string // Table name (empty = DefaultSessionTable)
+	NumberedParams bool   // Use $1, $2 placeholders (PostgreSQL) instead of ?
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SessionStore is a tenet.SessionStore backed by a SQL table of
+// (session_id, document, updated_at). Create the table with CreateTable or an equivalent migration.
+type SessionStore struct {
+	db  *sql.DB
+	cfg Config
+}
+
+var _ tenet.SessionStore = (*SessionStore)(nil)
+
+// NewSessionStore returns a SessionStore on db. The table name must be a plain SQL identifier.
+func NewSessionStore(db *sql.DB, cfg Config) (*SessionStore, error) {
+	if cfg.Table == "" {
+		cfg.Table = DefaultSessionTable
+	}
+	if !identifier.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("invalid table name '%s'", cfg.Table)
+	}
+	return &SessionStore{db: db, cfg: cfg}, nil
+}
+
+// CreateTable creates the session table if it doesn't exist.
+func (s *SessionStore) CreateTable() error {
+	_, err := s.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	session_id VARCHAR(255) PRIMARY KEY,
+	document TEXT NOT NULL,
+	updated_at VARCHAR(32) NOT NULL
+)`, s.cfg.Table))
+	return err
+}
+
+// Get returns the document stored for sessionID, or an error wrapping tenet.ErrSessionNotFound.
+func (s *SessionStore) Get(sessionID string) (string, error) {
+	var doc string
+	err := s.db.QueryRow(s.query("SELECT document FROM %s WHERE session_id = ?"), sessionID).Scan(&doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: '%s'", tenet.ErrSessionNotFound, sessionID)
+	}
+	return doc, err
+}
+
+// Put stores document under sessionID, replacing any stored document. It updates, and
+// inserts when no row was updated, so it needs no dialect-specific upsert; of two
+// concurrent first writes to one session, the second fails on the primary key.
+func (s *SessionStore) Put(sessionID, document string) error {
+	if sessionID == "" {
+		return errors.New("session ID is empty")
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(s.query("UPDATE %s SET document = ?, updated_at = ? WHERE session_id = ?"), document, now, sessionID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		if _, err := tx.Exec(s.query("INSERT INTO %s (session_id, document, updated_at) VALUES (?, ?, ?)"), sessionID, document, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete removes the document stored for sessionID.
+func (s *SessionStore) Delete(sessionID string) error {
+	_, err := s.db.Exec(s.query("DELETE FROM %s WHERE session_id = ?"), sessionID)
+	return err
+}
+
+// query fills in the table name and, for numbered dialects, rewrites ? placeholders.
+func (s *SessionStore) query(format string) string {
+	q := fmt.Sprintf(format, s.cfg.Table)
+	if !s.cfg.NumberedParams {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
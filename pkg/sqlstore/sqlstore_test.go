@@ -0,0 +1,167 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dlovans/tenet/pkg/tenet"
+)
+
+// fakeDriver is a one-table database that understands the statements SessionStore issues.
+// It records every query so tests can check the SQL dialect.
+type fakeDriver struct {
+	mu      sync.Mutex
+	rows    map[string][2]string // session_id -> document, updated_at
+	queries []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.d, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, s.query)
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE"):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "UPDATE"):
+		id := args[2].(string)
+		if _, ok := d.rows[id]; !ok {
+			return driver.RowsAffected(0), nil
+		}
+		d.rows[id] = [2]string{args[0].(string), args[1].(string)}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "INSERT"):
+		id := args[0].(string)
+		if _, ok := d.rows[id]; ok {
+			return nil, errors.New("duplicate primary key")
+		}
+		d.rows[id] = [2]string{args[1].(string), args[2].(string)}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "DELETE"):
+		delete(d.rows, args[0].(string))
+		return driver.RowsAffected(1), nil
+	}
+	return nil, errors.New("unsupported statement: " + s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, s.query)
+	rows := &fakeRows{}
+	if row, ok := d.rows[args[0].(string)]; ok {
+		rows.values = []string{row[0]}
+	}
+	return rows, nil
+}
+
+type fakeRows struct{ values []string }
+
+func (r *fakeRows) Columns() []string { return []string{"document"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+// openFake opens a database on a fresh fakeDriver registered under the test's name.
+func openFake(t *testing.T) (*sql.DB, *fakeDriver) {
+	t.Helper()
+	d := &fakeDriver{rows: make(map[string][2]string)}
+	name := "fake-" + t.Name()
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func TestSessionStore(t *testing.T) {
+	db, d := openFake(t)
+	store, err := NewSessionStore(db, Config{})
+	if err != nil {
+		t.Fatalf("NewSessionStore failed: %v", err)
+	}
+	if err := store.CreateTable(); err != nil {
+		t.Fatalf("CreateTable failed: %v", err)
+	}
+
+	if _, err := store.Get("s1"); !errors.Is(err, tenet.ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+	if err := store.Put("s1", `{"definitions": {}}`); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := store.Put("s1", `{"definitions": {"a": {"type": "number", "value": 1}}}`); err != nil {
+		t.Fatalf("second Put failed: %v", err)
+	}
+	doc, err := store.Get("s1")
+	if err != nil || doc != `{"definitions": {"a": {"type": "number", "value": 1}}}` {
+		t.Fatalf("expected the latest document, got %q (%v)", doc, err)
+	}
+	if err := store.Delete("s1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Get("s1"); !errors.Is(err, tenet.ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound after Delete, got %v", err)
+	}
+	if err := store.Put("", "{}"); err == nil {
+		t.Error("expected an error for an empty session ID")
+	}
+	for _, q := range d.queries {
+		if !strings.Contains(q, DefaultSessionTable) || strings.Contains(q, "$") {
+			t.Errorf("unexpected query %q", q)
+		}
+	}
+}
+
+func TestSessionStoreDialect(t *testing.T) {
+	db, d := openFake(t)
+	store, err := NewSessionStore(db, Config{Table: "sessions", NumberedParams: true})
+	if err != nil {
+		t.Fatalf("NewSessionStore failed: %v", err)
+	}
+	if err := store.Put("s1", "{}"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	want := "INSERT INTO sessions (session_id, document, updated_at) VALUES ($1, $2, $3)"
+	if last := d.queries[len(d.queries)-1]; last != want {
+		t.Errorf("got %q, want %q", last, want)
+	}
+
+	if _, err := NewSessionStore(db, Config{Table: "sessions; DROP TABLE users"}); err == nil {
+		t.Error("expected an invalid table name to be rejected")
+	}
+}
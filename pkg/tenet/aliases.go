@@ -0,0 +1,30 @@
+package tenet
+
+import "strings"
+
+// aliasPath rewrites a path whose root is one of the schema's aliases to the aliased field:
+// with "aliases": {"income": "applicant_income"}, "income.monthly" becomes
+// "applicant_income.monthly". Fields and derived values with the root's ID take precedence,
+// and aliases aren't followed further, so an alias of an alias stays unresolved.
+func (e *Engine) aliasPath(path string) (string, bool) {
+	if len(e.schema.Aliases) == 0 {
+		return path, false
+	}
+	root, rest, nested := strings.Cut(path, ".")
+	target, ok := e.schema.Aliases[root]
+	if !ok || target == "" || e.isField(root) {
+		return path, false
+	}
+	if nested {
+		return target + "." + rest, true
+	}
+	return target, true
+}
+
+// isField reports whether id is a definition or a derived value.
+func (e *Engine) isField(id string) bool {
+	if _, ok := e.schema.Definitions[id]; ok {
+		return true
+	}
+	return e.isDerived(id)
+}
@@ -0,0 +1,53 @@
+package tenet
+
+// WithAttestationFields mirrors each rich attestation as readonly definitions, for form
+// renderers that only understand definitions: <id>_signed (boolean), <id>_signer and
+// <id>_signed_at (from the evidence, null until signed). They carry the attestation's ID in
+// `attestation`, are recomputed on every run and ignored as input, so a document can go
+// back through Run or Verify as is. An existing definition with the same ID is left alone.
+func WithAttestationFields() RunOption {
+	return func(c *runConfig) {
+		c.attestationFields = true
+	}
+}
+
+// mirrorAttestations adds the definitions described by WithAttestationFields.
+func (e *Engine) mirrorAttestations() {
+	for _, id := range sortedIDs(e.schema.Attestations) {
+		att := e.schema.Attestations[id]
+		if att == nil {
+			continue
+		}
+		var signer, signedAt any
+		if att.Evidence != nil {
+			if att.Evidence.SignerID != "" {
+				signer = att.Evidence.SignerID
+			}
+			if att.Evidence.Timestamp != "" {
+				signedAt = att.Evidence.Timestamp
+			}
+		}
+		e.mirrorField(id+"_signed", id, "boolean", att.Signed)
+		e.mirrorField(id+"_signer", id, "string", signer)
+		e.mirrorField(id+"_signed_at", id, "string", signedAt)
+	}
+}
+
+func (e *Engine) mirrorField(fieldID, attID, typ string, value any) {
+	if _, exists := e.schema.Definitions[fieldID]; exists {
+		return
+	}
+	def := &Definition{Type: typ, Value: value, Attestation: attID}
+	def.SetReadonly(true)
+	def.SetVisible(true)
+	e.schema.Definitions[fieldID] = def
+}
+
+// dropAttestationFields removes definitions mirrored by an earlier run.
+func dropAttestationFields(schema *Schema) {
+	for id, def := range schema.Definitions {
+		if def != nil && def.Attestation != "" {
+			delete(schema.Definitions, id)
+		}
+	}
+}
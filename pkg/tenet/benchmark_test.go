@@ -1,6 +1,7 @@
 package tenet
 
 import (
+	"bytes"
 	"encoding/json"
 	"testing"
 	"time"
@@ -37,6 +38,38 @@ func BenchmarkRunParallel(b *testing.B) {
 	})
 }
 
+// BenchmarkRunCompactBuffer measures Run with compact output into a reused buffer.
+func BenchmarkRunCompactBuffer(b *testing.B) {
+	effectiveDate := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	schema := createBenchmarkSchema()
+	var buf bytes.Buffer
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := Run(schema, effectiveDate, WithCompactOutput(), WithBuffer(&buf))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRunSchemaLarge measures RunSchema on the large schema, without the JSON round-trip of BenchmarkLargeSchema.
+func BenchmarkRunSchemaLarge(b *testing.B) {
+	effectiveDate := time.Now()
+	var schema Schema
+	if err := json.Unmarshal([]byte(createLargeSchema(100, 50)), &schema); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := RunSchema(&schema, Options{EffectiveDate: effectiveDate})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 // BenchmarkLargeSchema tests performance with many definitions and rules.
 func BenchmarkLargeSchema(b *testing.B) {
 	effectiveDate := time.Now()
@@ -74,6 +107,31 @@ func BenchmarkVerify(b *testing.B) {
 	}
 }
 
+// BenchmarkVerifyCompiled measures verification against a base schema compiled once.
+func BenchmarkVerifyCompiled(b *testing.B) {
+	effectiveDate := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	baseSchema := createBenchmarkSchema()
+	completedDoc, err := Run(baseSchema, effectiveDate)
+	if err != nil {
+		b.Fatal(err)
+	}
+	compiled, err := Compile(baseSchema)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result := VerifyWithCompiled(completedDoc, compiled)
+		if result.Error != "" {
+			b.Fatal(result.Error)
+		}
+		if !result.Valid {
+			b.Fatal("expected valid")
+		}
+	}
+}
+
 // BenchmarkVerifyParallel measures Verify throughput with concurrency.
 func BenchmarkVerifyParallel(b *testing.B) {
 	effectiveDate := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
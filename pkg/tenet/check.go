@@ -0,0 +1,56 @@
+package tenet
+
+import (
+	"fmt"
+	"time"
+)
+
+// CheckResult is the outcome of Check: the document's status and errors as submitted.
+type CheckResult struct {
+	Status DocStatus         `json:"status"`
+	Errors []ValidationError `json:"errors,omitempty"`
+}
+
+// Check reports whether a document is acceptable as-is, without producing a new one. Rules
+// are evaluated for their errors and ui_modify (visibility and required flags decide what
+// validation applies), but set actions are skipped, derived values are only read where
+// conditions use them, and on_hide clearing doesn't run, so no value is written and no
+// definition is created. Nothing is marshaled, which makes it a cheap gate for submissions.
+//
+// A field that a rule would fill counts as missing if the submission doesn't carry it;
+// documents produced by Run do. Use Verify to check that submitted values are the ones
+// the schema computes.
+func Check(jsonText string, date time.Time, opts ...RunOption) (result *CheckResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			err = recovered(r)
+		}
+	}()
+
+	cfg := newRunConfig(opts)
+	var schema Schema
+	if err := cfg.unmarshal([]byte(jsonText), &schema); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	if err := checkEngine(&schema); err != nil {
+		return nil, err
+	}
+	if cfg.limits != nil {
+		if err := cfg.limits.Check(&schema); err != nil {
+			return nil, err
+		}
+	}
+	if err := decryptValues(&schema, cfg.encrypter); err != nil {
+		return nil, err
+	}
+
+	engine := prepareSchema(&schema, date, cfg)
+	engine.readOnly = true
+	engine.evaluateLogicTree()
+	engine.validateDefinitions()
+	engine.validateFieldGroups()
+	engine.checkAttestations()
+
+	return &CheckResult{Status: engine.determineStatus(), Errors: engine.errors}, nil
+}
@@ -0,0 +1,73 @@
+package tenet
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	schema := `{
+		"definitions": {
+			"income": {"type": "number", "value": 2000, "required": true},
+			"employer": {"type": "string", "required": true, "visible": false},
+			"flagged": {"type": "boolean"}
+		},
+		"state_model": {"derived": {"monthly": {"eval": "= income / 12"}}},
+		"logic_tree": [
+			{"id": "low", "when": "monthly < 500", "then": {"set": {"flagged": true}, "ui_modify": {"employer": {"visible": true}}}},
+			{"id": "flag_error", "when": "flagged == true", "then": {"error_msg": "Flagged for review"}}
+		]
+	}`
+
+	// Derived values are read by conditions; ui_modify applies, so employer becomes required
+	res, err := Check(schema, date)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	assertEqual(t, res.Status, StatusIncomplete)
+	if len(res.Errors) != 1 || res.Errors[0].FieldID != "employer" || res.Errors[0].Kind != ErrMissingRequired {
+		t.Errorf("expected only employer missing, got %+v", res.Errors)
+	}
+
+	// Run sets flagged, so the second rule fires; Check doesn't write it
+	out, err := Run(schema, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var ran Schema
+	if err := json.Unmarshal([]byte(out), &ran); err != nil {
+		t.Fatalf("bad output: %v", err)
+	}
+	if len(ran.Errors) != 2 || ran.Errors[0].RuleID != "flag_error" {
+		t.Errorf("expected Run to fire flag_error, got %+v", ran.Errors)
+	}
+
+	// A document produced by Run checks the same as it ran
+	res, err = Check(out, date)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	assertEqual(t, res.Status, ran.Status)
+	assertEqual(t, len(res.Errors), len(ran.Errors))
+}
+
+func TestCheckDoesNotWrite(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	schema := `{
+		"definitions": {"a": {"type": "number", "value": 1}},
+		"state_model": {"derived": {"b": {"eval": "= a + 1"}}},
+		"logic_tree": [{"id": "r", "when": true, "then": {"set": {"a": 5, "created": 1}}}]
+	}`
+
+	var changes []FieldChange
+	res, err := Check(schema, date, WithFieldListener(func(c FieldChange) { changes = append(changes, c) }))
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	assertEqual(t, res.Status, StatusReady)
+	if len(changes) != 0 {
+		t.Errorf("Check should not write values, got %+v", changes)
+	}
+}
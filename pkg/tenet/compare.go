@@ -0,0 +1,139 @@
+package tenet
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RunComparison is the outcome of evaluating one document under two schema versions.
+type RunComparison struct {
+	StatusA       DocStatus         `json:"status_a"`
+	StatusB       DocStatus         `json:"status_b"`
+	Fields        []FieldDiff       `json:"fields,omitempty"`         // Fields whose final value differs, by ID
+	ErrorsAdded   []ValidationError `json:"errors_added,omitempty"`   // Errors under B that A doesn't report
+	ErrorsRemoved []ValidationError `json:"errors_removed,omitempty"` // Errors under A that B doesn't report
+}
+
+// FieldDiff is a field whose final value differs between the two runs. A field that only
+// one schema defines has a nil value on the other side.
+type FieldDiff struct {
+	FieldID string `json:"field_id"`
+	A       any    `json:"a"`
+	B       any    `json:"b"`
+	RuleA   string `json:"rule_a,omitempty"` // Rule that last set the field under A (empty for inputs and derived values)
+	RuleB   string `json:"rule_b,omitempty"` // Rule that last set the field under B
+}
+
+// Differs reports whether the two runs had any different outcome.
+func (c *RunComparison) Differs() bool {
+	return c.StatusA != c.StatusB || len(c.Fields) > 0 || len(c.ErrorsAdded) > 0 || len(c.ErrorsRemoved) > 0
+}
+
+// CompareRuns evaluates one document under two schema versions (typically the deployed
+// rules and a proposed change) and reports how the outcome differs: status, final field
+// values (derived and rule-set values included) and errors. values are the document's
+// field values; each is applied to the schemas that define the field and ignored by the
+// others, so fields added or removed between versions don't make the comparison fail.
+// Options apply to both runs.
+func CompareRuns(schemaA, schemaB string, values map[string]any, date time.Time, opts ...RunOption) (cmp *RunComparison, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			cmp = nil
+			err = recovered(r)
+		}
+	}()
+
+	cfg := newRunConfig(opts)
+	a, setByA, err := runWithValues(schemaA, values, date, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("schema A: %w", err)
+	}
+	b, setByB, err := runWithValues(schemaB, values, date, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("schema B: %w", err)
+	}
+
+	cmp = &RunComparison{StatusA: a.Status, StatusB: b.Status}
+	ids := make(map[string]bool, len(a.Definitions)+len(b.Definitions))
+	for id := range a.Definitions {
+		ids[id] = true
+	}
+	for id := range b.Definitions {
+		ids[id] = true
+	}
+	for _, id := range sortedIDs(ids) {
+		va, vb := definitionValue(a, id), definitionValue(b, id)
+		if !reflect.DeepEqual(va, vb) {
+			cmp.Fields = append(cmp.Fields, FieldDiff{FieldID: id, A: va, B: vb, RuleA: setByA[id], RuleB: setByB[id]})
+		}
+	}
+	cmp.ErrorsAdded = errorsMissingFrom(b.Errors, a.Errors)
+	cmp.ErrorsRemoved = errorsMissingFrom(a.Errors, b.Errors)
+	return cmp, nil
+}
+
+// runWithValues parses a schema, applies values to the fields it defines and evaluates it.
+// It also returns the rule that last changed each field.
+func runWithValues(jsonText string, values map[string]any, date time.Time, cfg runConfig) (*Schema, map[string]string, error) {
+	var schema Schema
+	if err := cfg.unmarshal([]byte(jsonText), &schema); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	if err := checkEngine(&schema); err != nil {
+		return nil, nil, err
+	}
+	if cfg.limits != nil {
+		if err := cfg.limits.Check(&schema); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := decryptValues(&schema, cfg.encrypter); err != nil {
+		return nil, nil, err
+	}
+	for id, value := range values {
+		if def := schema.Definitions[id]; def != nil {
+			def.Value = cloneValue(value)
+		}
+	}
+
+	setBy := make(map[string]string)
+	// Copy the listeners so the two runs don't append into the caller's slice
+	cfg.fieldListeners = append(cfg.fieldListeners[:len(cfg.fieldListeners):len(cfg.fieldListeners)], func(c FieldChange) {
+		root, _, _ := strings.Cut(c.FieldID, ".")
+		setBy[root] = c.RuleID
+	})
+	runSchema(&schema, date, cfg)
+	return &schema, setBy, nil
+}
+
+func definitionValue(s *Schema, id string) any {
+	if def := s.Definitions[id]; def != nil {
+		return def.Value
+	}
+	return nil
+}
+
+// errorsMissingFrom returns the errors in errs that other doesn't report. Errors are
+// matched by kind, field, rule and message.
+func errorsMissingFrom(errs, other []ValidationError) []ValidationError {
+	type key struct {
+		kind                 ErrorKind
+		field, rule, message string
+	}
+	seen := make(map[key]int, len(other))
+	for _, e := range other {
+		seen[key{e.Kind, e.FieldID, e.RuleID, e.Message}]++
+	}
+	var missing []ValidationError
+	for _, e := range errs {
+		k := key{e.Kind, e.FieldID, e.RuleID, e.Message}
+		if seen[k] > 0 {
+			seen[k]--
+			continue
+		}
+		missing = append(missing, e)
+	}
+	return missing
+}
@@ -0,0 +1,68 @@
+package tenet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareRuns(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	current := `{
+		"definitions": {
+			"income": {"type": "number", "required": true},
+			"tier": {"type": "string"},
+			"legacy_code": {"type": "string"}
+		},
+		"logic_tree": [
+			{"id": "gold", "when": "income >= 5000", "then": {"set": {"tier": "gold"}}},
+			{"id": "low", "when": "income < 1000", "then": {"error_msg": "Income too low"}}
+		]
+	}`
+	proposed := `{
+		"definitions": {
+			"income": {"type": "number", "required": true},
+			"tier": {"type": "string"},
+			"region": {"type": "string", "required": true}
+		},
+		"logic_tree": [
+			{"id": "gold", "when": "income >= 3000", "then": {"set": {"tier": "gold"}}},
+			{"id": "low", "when": "income < 1000", "then": {"error_msg": "Income too low"}}
+		]
+	}`
+
+	// legacy_code only exists in the current schema, region only in the proposed one
+	cmp, err := CompareRuns(current, proposed, map[string]any{"income": 4000.0, "legacy_code": "X1"}, date)
+	if err != nil {
+		t.Fatalf("CompareRuns failed: %v", err)
+	}
+	assertEqual(t, cmp.StatusA, StatusReady)
+	assertEqual(t, cmp.StatusB, StatusIncomplete)
+	if !cmp.Differs() {
+		t.Error("expected the runs to differ")
+	}
+	if len(cmp.Fields) != 2 {
+		t.Fatalf("expected 2 field diffs, got %+v", cmp.Fields)
+	}
+	assertEqual(t, cmp.Fields[0], FieldDiff{FieldID: "legacy_code", A: "X1", B: nil})
+	assertEqual(t, cmp.Fields[1], FieldDiff{FieldID: "tier", A: nil, B: "gold", RuleB: "gold"})
+	if len(cmp.ErrorsAdded) != 1 || cmp.ErrorsAdded[0].FieldID != "region" {
+		t.Errorf("expected region to be missing under B, got %+v", cmp.ErrorsAdded)
+	}
+	if len(cmp.ErrorsRemoved) != 0 {
+		t.Errorf("expected no removed errors, got %+v", cmp.ErrorsRemoved)
+	}
+
+	// Errors both versions report aren't differences
+	cmp, err = CompareRuns(current, current, map[string]any{"income": 500.0}, date)
+	if err != nil {
+		t.Fatalf("CompareRuns failed: %v", err)
+	}
+	if cmp.Differs() {
+		t.Errorf("expected identical runs, got %+v", cmp)
+	}
+	assertEqual(t, cmp.StatusA, StatusInvalid)
+
+	if _, err := CompareRuns(current, "{", nil, date); err == nil {
+		t.Error("expected an error for malformed schema B")
+	}
+}
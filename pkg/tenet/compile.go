@@ -0,0 +1,277 @@
+package tenet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// CompiledSchema is a parsed base schema that can be reused across many evaluations.
+// It is immutable after Compile; every use works on a private deep copy.
+type CompiledSchema struct {
+	base  *Schema
+	hash  string
+	index *schemaIndex
+
+	decimal        bool // WithDecimalCurrency, replayed by VerifyWithCompiled
+	currencyPlaces int
+	fixedPoint     int      // WithFixedPoint, replayed by VerifyWithCompiled
+	features       []string // WithFeatures, replayed instead of the submission's flags
+
+	tracer PhaseTracer // WithTracer, for VerifyWithCompiled
+}
+
+// Compile parses a base schema once for repeated use (e.g., VerifyWithCompiled).
+// Infix expressions are compiled to JSON-logic up front, and option sets, patterns and
+// dotted var paths are indexed so each evaluation skips that work.
+//
+// Of the run options, only WithDecimalCurrency, WithFixedPoint, WithFeatures and WithTracer
+// apply: VerifyWithCompiled replays with the first three, because they change computed values,
+// and traces with the last. Compiled feature flags replace the ones a submission records.
+func Compile(jsonText string, opts ...RunOption) (*CompiledSchema, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(jsonText), &schema); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	sum := sha256.Sum256([]byte(jsonText))
+	return compileSchema(&schema, hex.EncodeToString(sum[:]), newRunConfig(opts))
+}
+
+// compileSchema is Compile for a parsed schema, which it takes ownership of.
+func compileSchema(schema *Schema, hash string, cfg runConfig) (*CompiledSchema, error) {
+	if err := checkEngine(schema); err != nil {
+		return nil, err
+	}
+	if schema.Definitions == nil {
+		schema.Definitions = make(map[string]*Definition)
+	}
+
+	// Precompile infix expressions. If any fail to parse, keep the source text so every
+	// run reports the runtime warning.
+	precompiled := cloneSchema(schema)
+	engine := NewEngine(precompiled)
+	engine.compileDecisionTables()
+	engine.compileInfix()
+	if len(engine.errors) == 0 {
+		schema = precompiled
+	}
+
+	return &CompiledSchema{
+		base:           schema,
+		hash:           hash,
+		index:          buildIndex(schema),
+		decimal:        cfg.decimal,
+		currencyPlaces: cfg.currencyPlaces,
+		fixedPoint:     cfg.fixedPoint,
+		features:       cfg.features,
+		tracer:         cfg.tracer,
+	}, nil
+}
+
+// schemaIndex holds lookups precomputed by Compile for the hot paths of evaluation.
+// Min, max and step need no index; they are parsed into float64 on unmarshal.
+type schemaIndex struct {
+	options  map[string]map[string]struct{} // Definition ID → allowed options as a set
+	patterns map[string]*regexp.Regexp      // Pattern text → compiled regexp (invalid patterns omitted)
+	paths    map[string][]string            // Dotted var path → its segments
+}
+
+// buildIndex indexes a parsed schema's definitions and expressions.
+func buildIndex(s *Schema) *schemaIndex {
+	x := &schemaIndex{
+		options:  make(map[string]map[string]struct{}),
+		patterns: make(map[string]*regexp.Regexp),
+		paths:    make(map[string][]string),
+	}
+
+	for id, def := range s.Definitions {
+		if def == nil {
+			continue
+		}
+		if def.Options != nil {
+			set := make(map[string]struct{}, len(def.Options))
+			for _, opt := range def.Options {
+				set[opt] = struct{}{}
+			}
+			x.options[id] = set
+		}
+		if def.Pattern != "" {
+			if re, err := regexp.Compile(def.Pattern); err == nil {
+				x.patterns[def.Pattern] = re
+			}
+		}
+		x.addPaths(def.LabelExpr)
+		x.addPaths(def.UIMessageExpr)
+	}
+	for _, expr := range summaryExprs(s.Summary) {
+		x.addPaths(expr)
+	}
+
+	for _, rule := range s.LogicTree {
+		if rule == nil {
+			continue
+		}
+		x.addPaths(rule.When)
+		x.addPaths(rule.WhenAny)
+		x.addPaths(rule.Unless)
+		if rule.Then != nil {
+			for _, val := range rule.Then.Set {
+				x.addPaths(val)
+			}
+		}
+	}
+	if s.StateModel != nil {
+		for _, derived := range s.StateModel.Derived {
+			if derived != nil {
+				x.addPaths(derived.Eval)
+				for _, expr := range derived.ByJurisdiction {
+					x.addPaths(expr)
+				}
+			}
+		}
+	}
+	return x
+}
+
+// addPaths records the segments of every dotted {"var": "a.b"} path in an expression.
+func (x *schemaIndex) addPaths(node any) {
+	switch v := node.(type) {
+	case map[string]any:
+		if path, ok := v["var"].(string); ok && strings.IndexByte(path, '.') >= 0 {
+			x.paths[path] = strings.Split(path, ".")
+		}
+		for _, val := range v {
+			x.addPaths(val)
+		}
+	case []any:
+		for _, elem := range v {
+			x.addPaths(elem)
+		}
+	}
+}
+
+// optionSet returns the indexed options of a definition. Nil-safe.
+func (x *schemaIndex) optionSet(id string) (map[string]struct{}, bool) {
+	if x == nil {
+		return nil, false
+	}
+	set, ok := x.options[id]
+	return set, ok
+}
+
+// pattern returns the precompiled regexp for pattern text, or nil. Nil-safe.
+func (x *schemaIndex) pattern(text string) *regexp.Regexp {
+	if x == nil {
+		return nil
+	}
+	return x.patterns[text]
+}
+
+// path returns the precomputed segments of a dotted var path, or nil. Nil-safe.
+func (x *schemaIndex) path(p string) []string {
+	if x == nil {
+		return nil
+	}
+	return x.paths[p]
+}
+
+// Hash returns the hex SHA-256 of the source JSON, identifying the exact schema version.
+func (c *CompiledSchema) Hash() string {
+	return c.hash
+}
+
+// clone returns a private copy of the compiled base schema.
+func (c *CompiledSchema) clone() *Schema {
+	return cloneSchema(c.base)
+}
+
+// cloneSchema deep-copies everything Run mutates: definitions, rules (Disabled),
+// attestations (Signed/Evidence) and nested values. Expressions are shared — the VM never writes to them.
+func cloneSchema(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	out.Features = slices.Clone(s.Features)
+
+	out.Definitions = make(map[string]*Definition, len(s.Definitions))
+	for id, def := range s.Definitions {
+		if def == nil {
+			out.Definitions[id] = nil
+			continue
+		}
+		d := *def
+		d.Value = cloneValue(def.Value)
+		d.Visible = cloneBool(def.Visible)
+		d.Required = cloneBool(def.Required)
+		d.Readonly = cloneBool(def.Readonly)
+		if def.Options != nil {
+			d.Options = append([]string(nil), def.Options...)
+		}
+		out.Definitions[id] = &d
+	}
+
+	if s.LogicTree != nil {
+		out.LogicTree = make([]*Rule, len(s.LogicTree))
+		for i, rule := range s.LogicTree {
+			if rule == nil {
+				continue
+			}
+			r := *rule
+			out.LogicTree[i] = &r
+		}
+	}
+
+	if s.Attestations != nil {
+		out.Attestations = make(map[string]*Attestation, len(s.Attestations))
+		for id, att := range s.Attestations {
+			if att == nil {
+				out.Attestations[id] = nil
+				continue
+			}
+			a := *att
+			if att.Evidence != nil {
+				ev := *att.Evidence
+				a.Evidence = &ev
+			}
+			out.Attestations[id] = &a
+		}
+	}
+
+	out.Errors = append([]ValidationError(nil), s.Errors...)
+	out.Annotations = append([]Annotation(nil), s.Annotations...)
+	return &out
+}
+
+// cloneValue deep-copies a JSON value (maps and slices); scalars are returned as-is.
+func cloneValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(val))
+		for k, elem := range val {
+			m[k] = cloneValue(elem)
+		}
+		return m
+	case []any:
+		arr := make([]any, len(val))
+		for i, elem := range val {
+			arr[i] = cloneValue(elem)
+		}
+		return arr
+	default:
+		return v
+	}
+}
+
+// cloneBool copies a tri-state flag so the clone never aliases the original.
+func cloneBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	v := *b
+	return &v
+}
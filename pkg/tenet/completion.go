@@ -0,0 +1,61 @@
+package tenet
+
+// Completion is the share of currently-required fields that are filled, for progress
+// indicators. It reflects the final state of the run: fields made required by rules count,
+// and hidden fields count only when they are validated (see hidden_validation).
+type Completion struct {
+	Required int                    `json:"required"`        // Fields required right now
+	Filled   int                    `json:"filled"`          // Of those, how many have a value
+	Percent  int                    `json:"percent"`         // Filled/Required rounded down (100 when nothing is required)
+	Pages    map[string]*Completion `json:"pages,omitempty"` // Per page, for definitions with a page
+}
+
+// WithCompletion adds a completion block to the output.
+func WithCompletion() RunOption {
+	return func(c *runConfig) {
+		c.completion = true
+	}
+}
+
+// computeCompletion counts required and filled fields, overall and per page.
+func (e *Engine) computeCompletion() *Completion {
+	total := &Completion{}
+	for _, id := range sortedIDs(e.schema.Definitions) {
+		def := e.schema.Definitions[id]
+		if def == nil || !def.IsRequired() || e.skipValidation(def) {
+			continue
+		}
+		filled := isFilled(def.Value)
+		total.add(filled)
+		if def.Page != "" {
+			if total.Pages == nil {
+				total.Pages = make(map[string]*Completion)
+			}
+			page := total.Pages[def.Page]
+			if page == nil {
+				page = &Completion{}
+				total.Pages[def.Page] = page
+			}
+			page.add(filled)
+		}
+	}
+	total.finish()
+	for _, page := range total.Pages {
+		page.finish()
+	}
+	return total
+}
+
+func (c *Completion) add(filled bool) {
+	c.Required++
+	if filled {
+		c.Filled++
+	}
+}
+
+func (c *Completion) finish() {
+	c.Percent = 100
+	if c.Required > 0 {
+		c.Percent = c.Filled * 100 / c.Required
+	}
+}
@@ -69,3 +69,63 @@ func TestCycleDetection(t *testing.T) {
 		}
 	})
 }
+
+func TestDerivedOrder(t *testing.T) {
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("evaluates each derived field once, dependencies first", func(t *testing.T) {
+		// Name order is the reverse of dependency order, and c reads an undefined field
+		schema := `{
+			"definitions": {"base": {"type": "number", "value": 1}},
+			"state_model": {"derived": {
+				"a": {"eval": {"+": [{"var": "b"}, 1]}},
+				"b": {"eval": {"+": [{"var": "c"}, 1]}},
+				"c": {"eval": {"+": [{"var": "base"}, {"if": [{"var": "unknown"}, 100, 0]}]}}
+			}}
+		}`
+		result, err := Run(schema, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		parsed := parseResult(t, result)
+		assertDefinitionValue(t, parsed, "c", 1.0)
+		assertDefinitionValue(t, parsed, "b", 2.0)
+		assertDefinitionValue(t, parsed, "a", 3.0)
+
+		// One warning per derived pass (Run makes two), not one per read of c
+		warnings := 0
+		for _, e := range parsed.Errors {
+			if strings.Contains(e.Message, "Undefined variable 'unknown'") {
+				warnings++
+			}
+		}
+		assertEqual(t, warnings, 2)
+	})
+
+	t.Run("reports each cycle once", func(t *testing.T) {
+		schema := `{
+			"definitions": {"base": {"type": "number", "value": 1}},
+			"state_model": {"derived": {
+				"x": {"eval": {"+": [{"var": "y"}, 1]}},
+				"y": {"eval": {"+": [{"var": "x"}, {"var": "base"}]}},
+				"self": {"eval": {"var": "self"}},
+				"after": {"eval": {"+": [{"var": "base"}, 1]}}
+			}},
+			"logic_tree": [{"id": "reads_x", "when": {">": [{"var": "x"}, 0]}, "then": {"set": {"base": 2}}}]
+		}`
+		result, err := Run(schema, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		parsed := parseResult(t, result)
+		var cycles []string
+		for _, e := range parsed.Errors {
+			if e.Kind == ErrCycleDetected {
+				cycles = append(cycles, strings.Join(e.Cycle, ","))
+			}
+		}
+		assertEqual(t, strings.Join(cycles, "|"), "self|x,y")
+		assertDefinitionValue(t, parsed, "x", nil)
+		assertDefinitionValue(t, parsed, "after", 2.0)
+	})
+}
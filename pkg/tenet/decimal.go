@@ -0,0 +1,126 @@
+package tenet
+
+import (
+	"math/big"
+	"strings"
+)
+
+// WithDecimalCurrency evaluates arithmetic on "currency" fields in exact decimal instead of
+// float64, so 0.1 + 0.2 is 0.3 and a chain of fees doesn't drift. An operation is decimal
+// when one of its operands reads a currency field, directly or through other arithmetic,
+// an if, or a derived value computed that way. Values a rule or derived formula writes into
+// a currency field are rounded half away from zero to places decimals (2 for most currencies).
+//
+// Verify replays without run options; compile the base schema with Compile(base,
+// WithDecimalCurrency(places)) and use VerifyWithCompiled to verify such documents.
+func WithDecimalCurrency(places int) RunOption {
+	return func(c *runConfig) {
+		c.decimal = true
+		c.currencyPlaces = places
+	}
+}
+
+// decimalOps are the operators evaluated in decimal for currency operands.
+var decimalOps = map[string]bool{"+": true, "-": true, "*": true, "/": true, "%": true}
+
+// maxCurrencyDepth bounds how far currencyOperands follows derived values and nesting.
+const maxCurrencyDepth = 32
+
+// currencyOperands reports whether any argument of an arithmetic operator is a currency expression.
+func (e *Engine) currencyOperands(args any) bool {
+	list, ok := args.([]any)
+	if !ok {
+		list = []any{args}
+	}
+	for _, arg := range list {
+		if e.isCurrencyExpr(arg, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCurrencyExpr reports whether an expression yields a currency amount: a currency field,
+// round_currency, or arithmetic, abs, min, max, rounding or an if over one.
+func (e *Engine) isCurrencyExpr(node any, depth int) bool {
+	m, ok := node.(map[string]any)
+	if !ok || len(m) != 1 || depth > maxCurrencyDepth {
+		return false
+	}
+	for op, args := range m {
+		switch op {
+		case "var":
+			path, _ := args.(string)
+			root, _, _ := strings.Cut(path, ".")
+			if def := e.schema.Definitions[root]; def != nil && def.Type == "currency" {
+				return true
+			}
+			if e.schema.StateModel != nil {
+				if derived := e.schema.StateModel.Derived[root]; derived != nil {
+					return e.isCurrencyExpr(derived.Eval, depth+1)
+				}
+			}
+		case "round_currency":
+			return true
+		case "+", "-", "*", "/", "%", "abs", "min", "max", "round", "floor", "ceil", "if":
+			list, ok := args.([]any)
+			if !ok {
+				list = []any{args}
+			}
+			for _, arg := range list {
+				if e.isCurrencyExpr(arg, depth+1) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// decimalArith is +, -, *, / and % in exact decimal. Like the float operators it returns nil
+// for nil or non-numeric operands and for division by zero. % keeps the sign of a.
+func decimalArith(op string, a, b any) any {
+	aNum, aOk := toFloat(a)
+	bNum, bOk := toFloat(b)
+	if !aOk || !bOk {
+		return nil
+	}
+	x, xOk := exactRat(aNum)
+	y, yOk := exactRat(bNum)
+	if !xOk || !yOk {
+		return nil
+	}
+
+	r := new(big.Rat)
+	switch op {
+	case "+":
+		r.Add(x, y)
+	case "-":
+		r.Sub(x, y)
+	case "*":
+		r.Mul(x, y)
+	case "/", "%":
+		if y.Sign() == 0 {
+			return nil
+		}
+		r.Quo(x, y)
+		if op == "%" {
+			// a - b * trunc(a / b)
+			trunc := new(big.Int).Quo(r.Num(), r.Denom())
+			r.Sub(x, new(big.Rat).Mul(y, new(big.Rat).SetInt(trunc)))
+		}
+	}
+	f, _ := r.Float64()
+	return f
+}
+
+// currencyValue rounds a value written into a currency field in decimal mode.
+func (e *Engine) currencyValue(def *Definition, value any) any {
+	if !e.decimal || def == nil || def.Type != "currency" {
+		return value
+	}
+	if _, ok := value.(float64); !ok {
+		return value
+	}
+	return opRound("round", value, float64(e.currencyPlaces))
+}
@@ -0,0 +1,86 @@
+package tenet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecimalCurrency(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	schema := `{
+		"definitions": {
+			"price": {"type": "currency", "value": 0.1},
+			"fee": {"type": "currency", "value": 0.2},
+			"qty": {"type": "number", "value": 3},
+			"ratio": {"type": "number", "value": 0.1},
+			"total": {"type": "currency", "readonly": true},
+			"per_item": {"type": "currency"},
+			"plain": {"type": "number"}
+		},
+		"state_model": {"derived": {
+			"subtotal": {"eval": "= price + fee"},
+			"total": {"eval": "= subtotal * qty"},
+			"float_sum": {"eval": "= ratio + 0.2"}
+		}},
+		"logic_tree": [
+			{"id": "split", "when": true, "then": {"set": {"per_item": {"/": [{"var": "total"}, 7]}, "plain": {"/": [{"var": "total"}, 7]}}}}
+		]
+	}`
+
+	result, err := Run(schema, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	floats := parseResult(t, result)
+	assertDefinitionValue(t, floats, "subtotal", 0.30000000000000004)
+	assertDefinitionValue(t, floats, "total", 0.9000000000000001)
+
+	result, err = Run(schema, date, WithDecimalCurrency(2))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	decimal := parseResult(t, result)
+	// Arithmetic over currency fields, including through derived values, is exact
+	assertDefinitionValue(t, decimal, "subtotal", 0.3)
+	assertDefinitionValue(t, decimal, "total", 0.9)
+	// Values written into currency fields are rounded; other fields aren't
+	assertDefinitionValue(t, decimal, "per_item", 0.13)
+	assertDefinitionValue(t, decimal, "plain", 0.9/7)
+	// Arithmetic without a currency operand stays float
+	assertDefinitionValue(t, decimal, "float_sum", 0.30000000000000004)
+
+	// Verify replays in decimal mode when the base is compiled with it
+	if vr := Verify(result, schema); vr.Valid {
+		t.Error("expected a float-mode replay to disagree with decimal values")
+	}
+	compiled, err := Compile(schema, WithDecimalCurrency(2))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if vr := VerifyWithCompiled(result, compiled); !vr.Valid {
+		t.Errorf("expected the document to verify, got %+v", vr.Issues)
+	}
+}
+
+func TestDecimalArith(t *testing.T) {
+	tests := []struct {
+		op   string
+		a, b any
+		want any
+	}{
+		{"+", 0.1, 0.2, 0.3},
+		{"-", 0.3, 0.1, 0.2},
+		{"*", 1.1, 1.1, 1.21},
+		{"/", 1.0, 3.0, 1.0 / 3},
+		{"%", -7.5, 2.0, -1.5},
+		{"%", 0.3, 0.1, 0.0},
+		{"/", 1.0, 0.0, nil},
+		{"+", nil, 1.0, nil},
+		{"+", "x", 1.0, nil},
+	}
+	for _, tt := range tests {
+		if got := decimalArith(tt.op, tt.a, tt.b); got != tt.want {
+			t.Errorf("decimalArith(%s, %v, %v) = %v, want %v", tt.op, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package tenet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Hit policies for decision tables.
+const (
+	HitFirst = "first" // Only the first matching row applies (default)
+	HitAll   = "all"   // Every matching row applies, in order
+)
+
+// DecisionTable is a block of rules authored as a table: each input column is a field,
+// each row a case, and each output column a field the matching row sets. Tables are
+// compiled into ordinary rules (<id>_<row>, 1-based) ahead of the logic tree.
+//
+// Input cells are tests against the column's field:
+//
+//	"-", "" or null    any value
+//	700, true, "SE"    equal to the value
+//	">= 700", "!= SE"  compared with =, ==, !=, >, >=, <, <=
+//	"600..699"         within the inclusive numeric range
+//	["SE", "NO"]       one of the values
+//	{...}              a JSON-logic condition, used as-is
+//
+// Output cells are the values to set; "-" or null leaves the field alone.
+type DecisionTable struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title,omitempty"`
+	LawRef        string   `json:"law_ref,omitempty"`       // Citation for every generated rule (rows may override)
+	LogicVersion  string   `json:"logic_version,omitempty"` // Temporal branch of every generated rule
+	Tags          []string `json:"tags,omitempty"`
+	Jurisdictions []string `json:"jurisdictions,omitempty"`
+	HitPolicy     string   `json:"hit_policy,omitempty"` // "first" (default) or "all"
+
+	Inputs  []string       `json:"inputs"`            // Field (or dotted path) tested by each input column
+	Outputs []string       `json:"outputs,omitempty"` // Field set by each output column
+	Rows    []*DecisionRow `json:"rows"`
+}
+
+// DecisionRow is one case of a decision table.
+type DecisionRow struct {
+	When     []any  `json:"when"`                // One cell per input column
+	Then     []any  `json:"then,omitempty"`      // One cell per output column
+	ErrorMsg string `json:"error_msg,omitempty"` // Validation error emitted when the row applies
+	LawRef   string `json:"law_ref,omitempty"`   // Overrides the table's law_ref
+}
+
+// compileDecisionTables turns decision_tables into rules placed before the logic tree's
+// own rules, and removes the tables, so the output (and any re-run of it) carries the
+// compiled rules. Malformed rows are skipped with a runtime warning.
+func (e *Engine) compileDecisionTables() {
+	if len(e.schema.DecisionTables) == 0 {
+		return
+	}
+	var rules []*Rule
+	for _, table := range e.schema.DecisionTables {
+		if table != nil {
+			rules = append(rules, e.compileTable(table)...)
+		}
+	}
+	e.schema.LogicTree = append(rules, e.schema.LogicTree...)
+	e.schema.DecisionTables = nil
+}
+
+// compileTable compiles one table's rows in order.
+func (e *Engine) compileTable(table *DecisionTable) []*Rule {
+	var rules []*Rule
+	var earlier []any // Conditions of the preceding rows, for the first hit policy
+	for i, row := range table.Rows {
+		id := fmt.Sprintf("%s_%d", table.ID, i+1)
+		if row == nil {
+			continue
+		}
+		if len(row.When) != len(table.Inputs) || len(row.Then) > len(table.Outputs) {
+			e.addError("", id, ErrRuntimeWarning, fmt.Sprintf(
+				"Decision table '%s' row %d has %d input and %d output cells, want %d and at most %d",
+				table.ID, i+1, len(row.When), len(row.Then), len(table.Inputs), len(table.Outputs)), "")
+			continue
+		}
+
+		var conds []any
+		valid := true
+		for col, cell := range row.When {
+			cond, err := cellCondition(table.Inputs[col], cell)
+			if err != nil {
+				e.addError("", id, ErrRuntimeWarning, fmt.Sprintf(
+					"Decision table '%s' row %d, column '%s': %v", table.ID, i+1, table.Inputs[col], err), "")
+				valid = false
+				break
+			}
+			if cond != nil {
+				conds = append(conds, cond)
+			}
+		}
+		if !valid {
+			continue
+		}
+		var when any = true
+		switch len(conds) {
+		case 0:
+		case 1:
+			when = conds[0]
+		default:
+			when = map[string]any{"and": conds}
+		}
+
+		action := &Action{ErrorMsg: row.ErrorMsg}
+		for col, cell := range row.Then {
+			if cell == nil || cell == "-" {
+				continue
+			}
+			if action.Set == nil {
+				action.Set = make(map[string]any)
+			}
+			action.Set[table.Outputs[col]] = cell
+		}
+
+		rule := &Rule{
+			ID:            id,
+			LawRef:        table.LawRef,
+			Title:         table.Title,
+			LogicVersion:  table.LogicVersion,
+			Tags:          table.Tags,
+			Jurisdictions: table.Jurisdictions,
+			When:          when,
+			Then:          action,
+		}
+		if row.LawRef != "" {
+			rule.LawRef = row.LawRef
+		}
+		if table.HitPolicy != HitAll && len(earlier) > 0 {
+			rule.Unless = append([]any(nil), earlier...)
+		}
+		earlier = append(earlier, when)
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// cellCondition compiles an input cell to a JSON-logic condition on field.
+// Returns nil for cells that match any value.
+func cellCondition(field string, cell any) (any, error) {
+	v := map[string]any{"var": field}
+	switch c := cell.(type) {
+	case nil:
+		return nil, nil
+	case bool, float64:
+		return map[string]any{"==": []any{v, c}}, nil
+	case []any:
+		return map[string]any{"in": []any{v, c}}, nil
+	case map[string]any:
+		return c, nil
+	case string:
+		text := strings.TrimSpace(c)
+		if text == "" || text == "-" {
+			return nil, nil
+		}
+		for _, op := range []string{">=", "<=", "!=", "==", ">", "<", "="} {
+			if rest, ok := strings.CutPrefix(text, op); ok {
+				if op == "=" {
+					op = "=="
+				}
+				return map[string]any{op: []any{v, cellLiteral(strings.TrimSpace(rest))}}, nil
+			}
+		}
+		if lo, hi, ok := strings.Cut(text, ".."); ok {
+			low, errLo := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+			high, errHi := strconv.ParseFloat(strings.TrimSpace(hi), 64)
+			if errLo != nil || errHi != nil {
+				return nil, fmt.Errorf("invalid range '%s'", text)
+			}
+			return map[string]any{"and": []any{
+				map[string]any{">=": []any{v, low}},
+				map[string]any{"<=": []any{v, high}},
+			}}, nil
+		}
+		return map[string]any{"==": []any{v, text}}, nil
+	}
+	return nil, fmt.Errorf("unsupported cell %v", cell)
+}
+
+// cellLiteral reads the operand of a comparison cell: a number, true/false,
+// a quoted string or bare text.
+func cellLiteral(text string) any {
+	if n, err := strconv.ParseFloat(text, 64); err == nil {
+		return n
+	}
+	switch text {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
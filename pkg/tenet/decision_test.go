@@ -0,0 +1,94 @@
+package tenet
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecisionTables(t *testing.T) {
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	schema := func(score, status, policy string) string {
+		return `{
+			"definitions": {
+				"credit_score": {"type": "number", "value": ` + score + `},
+				"employment": {"type": "string", "value": "` + status + `"},
+				"decision": {"type": "string"},
+				"rate": {"type": "number"},
+				"checked": {"type": "boolean"}
+			},
+			"decision_tables": [{
+				"id": "eligibility",
+				"law_ref": "Credit Policy 3.2",
+				"hit_policy": "` + policy + `",
+				"inputs": ["credit_score", "employment"],
+				"outputs": ["decision", "rate"],
+				"rows": [
+					{"when": [">= 720", ["employed", "self_employed"]], "then": ["approve", 0.05]},
+					{"when": ["640..719", "employed"], "then": ["approve", 0.08]},
+					{"when": ["< 500", "-"], "then": ["decline", "-"], "error_msg": "Score below minimum", "law_ref": "Credit Policy 3.4"},
+					{"when": ["-", "-"], "then": ["review"]}
+				]
+			}],
+			"logic_tree": [
+				{"id": "own", "when": {"!=": [{"var": "decision"}, null]}, "then": {"set": {"checked": true}}}
+			]
+		}`
+	}
+
+	run := func(t *testing.T, input string) *Schema {
+		t.Helper()
+		result, err := Run(input, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return parseResult(t, result)
+	}
+
+	t.Run("first hit", func(t *testing.T) {
+		s := run(t, schema("760", "self_employed", ""))
+		assertDefinitionValue(t, s, "decision", "approve")
+		assertDefinitionValue(t, s, "rate", 0.05)
+		// Table rules run before the logic tree's own rules
+		assertDefinitionValue(t, s, "checked", true)
+		if s.DecisionTables != nil || len(s.LogicTree) != 5 || s.LogicTree[0].ID != "eligibility_1" || s.LogicTree[4].ID != "own" {
+			t.Errorf("expected tables compiled ahead of the logic tree, got %d rules", len(s.LogicTree))
+		}
+
+		s = run(t, schema("680", "employed", ""))
+		assertDefinitionValue(t, s, "rate", 0.08)
+
+		// The catch-all row only applies when nothing earlier matched
+		s = run(t, schema("680", "unemployed", ""))
+		assertDefinitionValue(t, s, "decision", "review")
+		assertDefinitionValue(t, s, "rate", nil)
+	})
+
+	t.Run("error row", func(t *testing.T) {
+		s := run(t, schema("450", "employed", ""))
+		assertDefinitionValue(t, s, "decision", "decline")
+		assertHasErrorWithLawRef(t, s, "Credit Policy 3.4")
+		assertEqual(t, s.Status, StatusInvalid)
+	})
+
+	t.Run("all hits", func(t *testing.T) {
+		// Every matching row applies in order, so the catch-all wins
+		s := run(t, schema("760", "employed", "all"))
+		assertDefinitionValue(t, s, "decision", "review")
+		assertDefinitionValue(t, s, "rate", 0.05)
+	})
+
+	t.Run("malformed row", func(t *testing.T) {
+		input := strings.Replace(schema("760", "employed", ""), `{"when": ["-", "-"], "then": ["review"]}`, `{"when": ["-"], "then": ["review"]}`, 1)
+		s := run(t, input)
+		found := false
+		for _, e := range s.Errors {
+			if e.Kind == ErrRuntimeWarning && e.RuleID == "eligibility_4" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a runtime warning for row 4, got %+v", s.Errors)
+		}
+	})
+}
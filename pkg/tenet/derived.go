@@ -0,0 +1,160 @@
+package tenet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// derivedPlan is the evaluation order of the state model's derived fields, computed once
+// per engine: every field comes after the derived fields it reads, and fields on a
+// dependency cycle are left out and listed in cycles.
+type derivedPlan struct {
+	order  []string
+	cycles [][]string      // Each cycle's fields, sorted
+	cyclic map[string]bool // Fields on any cycle
+}
+
+// planDerived builds the dependency graph of the derived fields and sorts it topologically
+// (Tarjan's algorithm, which emits dependencies first). Names and edges are visited in
+// sorted order, so the plan is the same on every run.
+func (e *Engine) planDerived() *derivedPlan {
+	derived := e.schema.StateModel.Derived
+	deps := make(map[string][]string, len(derived))
+	for name, def := range derived {
+		if def == nil {
+			continue
+		}
+		for _, ref := range exprRoots(def.Eval) {
+			if target, ok := e.aliasPath(ref); ok {
+				ref = target
+			}
+			if _, ok := derived[ref]; ok {
+				deps[name] = append(deps[name], ref)
+			}
+		}
+	}
+
+	plan := &derivedPlan{cyclic: make(map[string]bool)}
+	index := make(map[string]int, len(derived))
+	low := make(map[string]int, len(derived))
+	onStack := make(map[string]bool)
+	var stack []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		index[name] = len(index)
+		low[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		selfLoop := false
+		for _, dep := range sortedUnique(deps[name]) {
+			if dep == name {
+				selfLoop = true
+			}
+			if _, seen := index[dep]; !seen {
+				visit(dep)
+				low[name] = min(low[name], low[dep])
+			} else if onStack[dep] {
+				low[name] = min(low[name], index[dep])
+			}
+		}
+		if low[name] != index[name] {
+			return
+		}
+
+		// name is the root of a strongly connected component
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == name {
+				break
+			}
+		}
+		if len(component) == 1 && !selfLoop {
+			plan.order = append(plan.order, name)
+			return
+		}
+		component = sortedUnique(component)
+		for _, member := range component {
+			plan.cyclic[member] = true
+		}
+		plan.cycles = append(plan.cycles, component)
+	}
+	for _, name := range sortedIDs(derived) {
+		if _, seen := index[name]; !seen {
+			visit(name)
+		}
+	}
+	return plan
+}
+
+// reportCycles adds one cycle_detected error per dependency cycle, naming its fields.
+func (e *Engine) reportCycles(plan *derivedPlan) {
+	for _, cycle := range plan.cycles {
+		path := strings.Join(append(append([]string(nil), cycle...), cycle[0]), "' → '")
+		e.emitError(ValidationError{
+			FieldID: cycle[0],
+			Kind:    ErrCycleDetected,
+			Message: fmt.Sprintf("Circular dependency among derived fields '%s'; they are left unset", path),
+			Cycle:   cycle,
+		})
+	}
+}
+
+// exprRoots returns the root field names an expression reads: {"var": "a.b"} reads a, and
+// missing and missing_some read the fields they list. Element references ("", ".amount")
+// are skipped.
+func exprRoots(node any) []string {
+	var roots []string
+	add := func(path string) {
+		if root, _, _ := strings.Cut(path, "."); root != "" {
+			roots = append(roots, root)
+		}
+	}
+	var addKeys func(any)
+	addKeys = func(node any) {
+		switch v := node.(type) {
+		case string:
+			add(v)
+		case []any:
+			for _, elem := range v {
+				addKeys(elem)
+			}
+		}
+	}
+	var walk func(any)
+	walk = func(node any) {
+		switch v := node.(type) {
+		case map[string]any:
+			if len(v) == 1 {
+				if path, ok := v["var"].(string); ok {
+					add(path)
+				}
+				addKeys(v["missing"])
+				addKeys(v["missing_some"])
+			}
+			for _, val := range v {
+				walk(val)
+			}
+		case []any:
+			for _, elem := range v {
+				walk(elem)
+			}
+		}
+	}
+	walk(node)
+	return roots
+}
+
+// sortedUnique returns names sorted, without duplicates.
+func sortedUnique(names []string) []string {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return sortedIDs(set)
+}
@@ -0,0 +1,95 @@
+package tenet
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RuleEvaluation describes what a single rule would do, without applying it.
+type RuleEvaluation struct {
+	RuleID   string         `json:"rule_id"`
+	Title    string         `json:"title,omitempty"`     // Rule documentation, for review tooling
+	Active   bool           `json:"active"`              // False if pruned (temporal branch, rule dates, tags, features or disabled)
+	Fires    bool           `json:"fires"`               // True if the rule is active and its guards pass
+	Changes  []FieldChange  `json:"changes,omitempty"`   // Values the rule would set, sorted by field
+	UIModify map[string]any `json:"ui_modify,omitempty"` // UI changes the rule would make
+	ErrorMsg string         `json:"error_msg,omitempty"` // Error the rule would emit
+}
+
+// EvaluateRule reports whether one rule would fire and what it would change, without
+// applying anything. The rule sees the document as the logic tree does at its start:
+// after temporal routing, pruning, normalization and derived values, before any other rule runs.
+// Changes lists only values that would actually differ from the current state.
+func EvaluateRule(jsonText, ruleID string, date time.Time, opts ...RunOption) (eval *RuleEvaluation, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			eval = nil
+			err = recovered(r)
+		}
+	}()
+
+	cfg := newRunConfig(opts)
+	var schema Schema
+	if err := cfg.unmarshal([]byte(jsonText), &schema); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	if err := checkEngine(&schema); err != nil {
+		return nil, err
+	}
+	if cfg.limits != nil {
+		if err := cfg.limits.Check(&schema); err != nil {
+			return nil, err
+		}
+	}
+	if err := decryptValues(&schema, cfg.encrypter); err != nil {
+		return nil, err
+	}
+
+	var rule *Rule
+	for _, r := range schema.LogicTree {
+		if r != nil && r.ID == ruleID {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("rule '%s' not found", ruleID)
+	}
+
+	engine := prepareSchema(&schema, date, runConfig{
+		includeTags:     cfg.includeTags,
+		excludeTags:     cfg.excludeTags,
+		features:        cfg.features,
+		normalizers:     cfg.normalizers,
+		typeNormalizers: cfg.typeNormalizers,
+		limits:          cfg.limits,
+	})
+	engine.computeDerived()
+
+	eval = &RuleEvaluation{RuleID: ruleID, Title: rule.Title, Active: !rule.Disabled}
+	if !eval.Active || !engine.ruleMatches(rule) {
+		return eval, nil
+	}
+	eval.Fires = true
+
+	if rule.Then == nil {
+		return eval, nil
+	}
+	keys := make([]string, 0, len(rule.Then.Set))
+	for key := range rule.Then.Set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := engine.resolve(rule.Then.Set[key])
+		old := engine.getVar(key)
+		if engine.compareEqual(old, value) {
+			continue
+		}
+		eval.Changes = append(eval.Changes, FieldChange{FieldID: key, Old: old, New: value, RuleID: ruleID})
+	}
+	eval.UIModify = rule.Then.UIModify
+	eval.ErrorMsg = rule.Then.ErrorMsg
+	return eval, nil
+}
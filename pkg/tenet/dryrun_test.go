@@ -0,0 +1,79 @@
+package tenet
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluateRule(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"income": {"type": "number", "value": 80000},
+			"tier": {"type": "string", "value": "basic"},
+			"flag": {"type": "boolean", "value": true}
+		},
+		"logic_tree": [
+			{
+				"id": "premium",
+				"when": {">": [{"var": "tax"}, 20000]},
+				"then": {
+					"set": {"tier": "premium", "flag": true},
+					"ui_modify": {"tier": {"ui_class": "gold"}},
+					"error_msg": "Premium review"
+				}
+			},
+			{"id": "low", "when": {"<": [{"var": "income"}, 1000]}, "then": {"set": {"tier": "low"}}},
+			{"id": "flagged", "feature": "beta", "when": true, "then": {"set": {"tier": "beta"}}}
+		],
+		"state_model": {"derived": {"tax": {"eval": {"*": [{"var": "income"}, 0.3]}}}}
+	}`
+
+	eval, err := EvaluateRule(input, "premium", date)
+	if err != nil {
+		t.Fatalf("EvaluateRule failed: %v", err)
+	}
+	// Derived values are visible to the rule; unchanged values are not reported
+	assertEqual(t, eval.Active, true)
+	assertEqual(t, eval.Fires, true)
+	if len(eval.Changes) != 1 {
+		t.Fatalf("expected 1 change, got %+v", eval.Changes)
+	}
+	assertEqual(t, eval.Changes[0], FieldChange{FieldID: "tier", Old: "basic", New: "premium", RuleID: "premium"})
+	assertEqual(t, eval.ErrorMsg, "Premium review")
+	if eval.UIModify["tier"] == nil {
+		t.Error("expected ui_modify to be reported")
+	}
+
+	eval, err = EvaluateRule(input, "low", date)
+	if err != nil {
+		t.Fatalf("EvaluateRule failed: %v", err)
+	}
+	assertEqual(t, eval.Fires, false)
+	if len(eval.Changes) != 0 {
+		t.Errorf("a rule that doesn't fire changes nothing, got %+v", eval.Changes)
+	}
+
+	eval, err = EvaluateRule(input, "flagged", date)
+	if err != nil {
+		t.Fatalf("EvaluateRule failed: %v", err)
+	}
+	assertEqual(t, eval.Active, false)
+	eval, err = EvaluateRule(input, "flagged", date, WithFeatures("beta"))
+	if err != nil {
+		t.Fatalf("EvaluateRule failed: %v", err)
+	}
+	assertEqual(t, eval.Fires, true)
+
+	if _, err := EvaluateRule(input, "nope", date); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+
+	var le *LimitError
+	if _, err := EvaluateRule(input, "premium", date, WithLimits(Limits{MaxRules: 2})); !errors.As(err, &le) {
+		t.Fatalf("expected *LimitError, got %v", err)
+	}
+	assertEqual(t, le.Limit, "max_rules")
+}
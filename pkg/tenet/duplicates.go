@@ -0,0 +1,95 @@
+package tenet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDuplicateKeys is wrapped by every *DuplicateKeysError, for errors.Is checks.
+var ErrDuplicateKeys = errors.New("duplicate keys")
+
+// DuplicateKeysError lists the keys that appear more than once in one JSON object.
+type DuplicateKeysError struct {
+	Paths []string // e.g. "definitions.income", "logic_tree[3].then"
+}
+
+func (e *DuplicateKeysError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrDuplicateKeys, strings.Join(e.Paths, ", "))
+}
+
+// Unwrap makes errors.Is(err, ErrDuplicateKeys) hold.
+func (e *DuplicateKeysError) Unwrap() error {
+	return ErrDuplicateKeys
+}
+
+// WithRejectDuplicateKeys makes Run fail with a *DuplicateKeysError when an object in the
+// document repeats a key. encoding/json keeps the last value, so a second "income" definition
+// or a repeated "then" silently replaces the first.
+func WithRejectDuplicateKeys() RunOption {
+	return func(c *runConfig) {
+		c.rejectDuplicates = true
+	}
+}
+
+// DuplicateKeys returns the path of every key that appears more than once in the same
+// object, in document order and once per key, or nil when there are none. Expressions are
+// scanned too: {"and": [...], "and": [...]} loses its first operand list.
+func DuplicateKeys(jsonText string) ([]string, error) {
+	dec := json.NewDecoder(strings.NewReader(jsonText))
+	dec.UseNumber()
+	var paths []string
+	if err := scanDuplicates(dec, "", &paths); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	return paths, nil
+}
+
+// scanDuplicates reads one value from dec, recording repeated keys of the objects in it.
+func scanDuplicates(dec *json.Decoder, path string, paths *[]string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]int)
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			keyPath := joinPath(path, key)
+			if seen[key]++; seen[key] == 2 {
+				*paths = append(*paths, keyPath)
+			}
+			if err := scanDuplicates(dec, keyPath, paths); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := scanDuplicates(dec, fmt.Sprintf("%s[%d]", path, i), paths); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	_, err = dec.Token() // Closing delimiter
+	return err
+}
+
+// checkDuplicateKeys returns a *DuplicateKeysError for a document with repeated keys.
+func checkDuplicateKeys(jsonText string) error {
+	paths, err := DuplicateKeys(jsonText)
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		return &DuplicateKeysError{Paths: paths}
+	}
+	return nil
+}
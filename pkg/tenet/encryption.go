@@ -0,0 +1,123 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// encryptedKey is the single key of the envelope object that replaces an encrypted value.
+// An encrypted field serializes as: "value": {"$enc": "<ciphertext>"}
+const encryptedKey = "$enc"
+
+// Encrypter protects the values of definitions marked `sensitive`.
+// The VM never chooses algorithms or manages keys — that's the host's job.
+// fieldID is passed so implementations can use per-field keys or associated data.
+type Encrypter interface {
+	Encrypt(fieldID string, plaintext []byte) (string, error)
+	Decrypt(fieldID string, ciphertext string) ([]byte, error)
+}
+
+// EncryptDocument encrypts all sensitive values in a document (e.g., a Run result) for storage at rest.
+// Values that are nil or already encrypted are left untouched.
+func EncryptDocument(jsonText string, enc Encrypter) (string, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(jsonText), &schema); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+	if err := encryptValues(&schema, enc); err != nil {
+		return "", err
+	}
+	result, err := json.MarshalIndent(&schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return string(result), nil
+}
+
+// DecryptDocument reverses EncryptDocument, restoring plaintext sensitive values.
+func DecryptDocument(jsonText string, enc Encrypter) (string, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(jsonText), &schema); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+	if err := decryptValues(&schema, enc); err != nil {
+		return "", err
+	}
+	result, err := json.MarshalIndent(&schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return string(result), nil
+}
+
+// encryptValues replaces each sensitive plaintext value with an encrypted envelope.
+// Fields are processed in sorted order so failures are reported deterministically.
+func encryptValues(schema *Schema, enc Encrypter) error {
+	if enc == nil {
+		return nil
+	}
+	for _, id := range sensitiveFields(schema) {
+		def := schema.Definitions[id]
+		if def.Value == nil || isEncrypted(def.Value) {
+			continue
+		}
+		plaintext, err := json.Marshal(def.Value)
+		if err != nil {
+			return fmt.Errorf("encrypt field '%s': %w", id, err)
+		}
+		ciphertext, err := enc.Encrypt(id, plaintext)
+		if err != nil {
+			return fmt.Errorf("encrypt field '%s': %w", id, err)
+		}
+		def.Value = map[string]any{encryptedKey: ciphertext}
+	}
+	return nil
+}
+
+// decryptValues replaces each encrypted envelope with its plaintext value.
+// Plaintext values are left as-is, so documents may be encrypted lazily.
+func decryptValues(schema *Schema, enc Encrypter) error {
+	if enc == nil {
+		return nil
+	}
+	for _, id := range sensitiveFields(schema) {
+		def := schema.Definitions[id]
+		if !isEncrypted(def.Value) {
+			continue
+		}
+		ciphertext := def.Value.(map[string]any)[encryptedKey].(string)
+		plaintext, err := enc.Decrypt(id, ciphertext)
+		if err != nil {
+			return fmt.Errorf("decrypt field '%s': %w", id, err)
+		}
+		var value any
+		if err := json.Unmarshal(plaintext, &value); err != nil {
+			return fmt.Errorf("decrypt field '%s': %w", id, err)
+		}
+		def.Value = value
+	}
+	return nil
+}
+
+// sensitiveFields returns the sorted IDs of definitions marked sensitive.
+func sensitiveFields(schema *Schema) []string {
+	var ids []string
+	for id, def := range schema.Definitions {
+		if def != nil && def.Sensitive {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// isEncrypted reports whether a value is an encrypted envelope.
+func isEncrypted(value any) bool {
+	m, ok := value.(map[string]any)
+	if !ok || len(m) != 1 {
+		return false
+	}
+	_, ok = m[encryptedKey].(string)
+	return ok
+}
@@ -0,0 +1,107 @@
+package tenet
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reverseEncrypter is a toy Encrypter: base64 of the reversed plaintext, bound to the field ID.
+type reverseEncrypter struct{}
+
+func (reverseEncrypter) Encrypt(fieldID string, plaintext []byte) (string, error) {
+	out := make([]byte, len(plaintext))
+	for i, b := range plaintext {
+		out[len(plaintext)-1-i] = b
+	}
+	return fieldID + ":" + base64.StdEncoding.EncodeToString(out), nil
+}
+
+func (reverseEncrypter) Decrypt(fieldID string, ciphertext string) ([]byte, error) {
+	prefix := fieldID + ":"
+	if !strings.HasPrefix(ciphertext, prefix) {
+		return nil, errors.New("ciphertext bound to another field")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, prefix))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(raw))
+	for i, b := range raw {
+		out[len(raw)-1-i] = b
+	}
+	return out, nil
+}
+
+func TestEncryptionHooks(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"national_id": {"type": "string", "value": "19900101-1234", "sensitive": true, "required": true},
+			"age": {"type": "number", "value": 35}
+		},
+		"logic_tree": [
+			{"id": "flag", "when": {"==": [{"var": "national_id"}, "19900101-1234"]}, "then": {"set": {"matched": true}}}
+		]
+	}`
+
+	t.Run("Run encrypts sensitive values in output", func(t *testing.T) {
+		result, err := Run(input, date, WithEncrypter(reverseEncrypter{}))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		if !isEncrypted(schema.Definitions["national_id"].Value) {
+			t.Errorf("expected encrypted envelope, got %v", schema.Definitions["national_id"].Value)
+		}
+		// Logic saw the plaintext value
+		assertDefinitionValue(t, schema, "matched", true)
+		assertDefinitionValue(t, schema, "age", float64(35))
+		assertEqual(t, schema.Status, StatusReady)
+
+		// Re-running the encrypted result decrypts transparently
+		again, err := Run(result, date, WithEncrypter(reverseEncrypter{}))
+		if err != nil {
+			t.Fatalf("second Run failed: %v", err)
+		}
+		assertDefinitionValue(t, parseResult(t, again), "matched", true)
+
+		plain, err := DecryptDocument(again, reverseEncrypter{})
+		if err != nil {
+			t.Fatalf("DecryptDocument failed: %v", err)
+		}
+		assertDefinitionValue(t, parseResult(t, plain), "national_id", "19900101-1234")
+	})
+
+	t.Run("EncryptDocument round trip", func(t *testing.T) {
+		enc, err := EncryptDocument(input, reverseEncrypter{})
+		if err != nil {
+			t.Fatalf("EncryptDocument failed: %v", err)
+		}
+		if !isEncrypted(parseResult(t, enc).Definitions["national_id"].Value) {
+			t.Fatal("expected national ID to be encrypted")
+		}
+		dec, err := DecryptDocument(enc, reverseEncrypter{})
+		if err != nil {
+			t.Fatalf("DecryptDocument failed: %v", err)
+		}
+		assertDefinitionValue(t, parseResult(t, dec), "national_id", "19900101-1234")
+	})
+
+	t.Run("decrypt failure is returned as error", func(t *testing.T) {
+		tampered := `{"definitions": {"national_id": {"type": "string", "sensitive": true, "value": {"$enc": "other:AAAA"}}}}`
+		if _, err := Run(tampered, date, WithEncrypter(reverseEncrypter{})); err == nil {
+			t.Fatal("expected decrypt error")
+		}
+	})
+
+	t.Run("without encrypter values pass through", func(t *testing.T) {
+		result, err := Run(input, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		assertDefinitionValue(t, parseResult(t, result), "national_id", "19900101-1234")
+	})
+}
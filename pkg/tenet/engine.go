@@ -1,9 +1,12 @@
 package tenet
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,33 +17,264 @@ import (
 //
 // This is the "Transformer" - it takes raw input and returns a fully evaluated document.
 // Panic-safe: recovers from any unexpected panic and returns it as an error.
-func Run(jsonText string, date time.Time) (result string, err error) {
+// Optional RunOptions (e.g., WithEncrypter) adjust behavior; Run(json, date) keeps the defaults.
+func Run(jsonText string, date time.Time, opts ...RunOption) (result string, err error) {
+	result, _, err = runJSON(jsonText, date, newRunConfig(opts))
+	return result, err
+}
+
+// RunSchema is Run for a schema that is already parsed, for Go services that hold schemas in
+// memory: it skips the unmarshal and marshal that dominate Run time for large documents. s
+// is not modified; the evaluated document is returned as a new Schema. Output-only options
+// (Compact, Verbose, Buffer) have no effect. With Meta, the input hash is taken over s
+// marshaled as JSON.
+func RunSchema(s *Schema, o Options) (result *Schema, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			err = recovered(r)
+		}
+	}()
+
+	if s == nil {
+		return nil, fmt.Errorf("schema is nil")
+	}
+	cfg := newRunConfig(o.RunOptions())
+	date := o.EffectiveDate
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	schema := cloneSchema(s)
+	if err := checkEngine(schema); err != nil {
+		return nil, err
+	}
+	if cfg.limits != nil {
+		if err := cfg.limits.Check(schema); err != nil {
+			return nil, err
+		}
+	}
+	if err := decryptValues(schema, cfg.encrypter); err != nil {
+		return nil, err
+	}
+
+	runSchema(schema, date, cfg)
+	schema.Meta = nil
+	if cfg.meta {
+		input, err := json.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("marshal input: %w", err)
+		}
+		schema.Meta = newRunMeta(string(input), schema, date, cfg.metaBase)
+	}
+	if err := encryptValues(schema, cfg.encrypter); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// runJSON is Run with resolved options. It also returns the evaluated schema
+// (nil on error) so in-package callers can inspect status without re-parsing.
+func runJSON(jsonText string, date time.Time, cfg runConfig) (result string, evaluated *Schema, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			result = ""
-			err = fmt.Errorf("internal error: %v", r)
+			evaluated = nil
+			err = recovered(r)
 		}
 	}()
 
 	// 1. Unmarshal
 	var schema Schema
-	if err := json.Unmarshal([]byte(jsonText), &schema); err != nil {
-		return "", fmt.Errorf("unmarshal: %w", err)
+	end := cfg.tracer.start("parse")
+	err = cfg.unmarshal([]byte(jsonText), &schema)
+	if err != nil && cfg.partial {
+		var partial Schema
+		if skipped, perr := decodePartial([]byte(jsonText), cfg, &partial); perr == nil {
+			schema, cfg.skipped, err = partial, skipped, nil
+		}
+	}
+	end()
+	if err != nil {
+		return "", nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	if cfg.strict {
+		if err := checkUnknownFields(jsonText); err != nil {
+			return "", nil, err
+		}
+	}
+	if cfg.rejectDuplicates {
+		if err := checkDuplicateKeys(jsonText); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := checkEngine(&schema); err != nil {
+		return "", nil, err
+	}
+	if cfg.limits != nil {
+		if err := cfg.limits.Check(&schema); err != nil {
+			return "", nil, err
+		}
+	}
+
+	// The patch starts from the input as it would be marshaled, so only evaluation shows in it
+	var before string
+	if cfg.patch {
+		if before, err = marshalInput(&schema, cfg); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := decryptValues(&schema, cfg.encrypter); err != nil {
+		return "", nil, err
 	}
 
+	// 2-7. Evaluate
+	engine := runSchema(&schema, date, cfg)
+	schema.Meta = nil
+	if cfg.meta {
+		schema.Meta = newRunMeta(jsonText, &schema, date, cfg.metaBase)
+	}
+
+	// 8. Marshal result
+	if err := encryptValues(&schema, cfg.encrypter); err != nil {
+		return "", nil, err
+	}
+	if !cfg.verbose {
+		omitDefaults(&schema)
+	}
+	end = cfg.tracer.start("marshal")
+	result, err = engine.marshal(cfg)
+	if err == nil && cfg.patch {
+		result, err = patchResult(before, result, cfg)
+	}
+	end()
+	if err != nil {
+		return "", nil, err
+	}
+	return result, &schema, nil
+}
+
+// runSchema evaluates a parsed schema in place: temporal routing, derived state,
+// logic tree, validation and status. The schema is mutated and the engine returned
+// so callers can marshal it or inspect it directly (Verify stays in memory).
+func runSchema(schema *Schema, date time.Time, cfg runConfig) *Engine {
+	end := cfg.tracer.start("temporal")
+	engine := prepareSchema(schema, date, cfg)
+	for _, skipped := range cfg.skipped {
+		engine.addError(skipped.FieldID, skipped.RuleID, skipped.Kind, skipped.Message, "")
+	}
+	end()
+
+	// 3. Compute derived state (so logic tree can use derived values)
+	end = cfg.tracer.start("derived")
+	engine.computeDerived()
+	end()
+
+	// 4-5. Evaluate logic tree, then re-compute derived state (in case logic modified inputs).
+	// With WithFixedPoint this repeats until a pass changes nothing; each pass starts from
+	// the errors before the first, so only the last pass's errors remain.
+	mark := len(engine.errors)
+	for pass := 1; ; pass++ {
+		var before valueSnapshot
+		if cfg.fixedPoint > 0 {
+			before = engine.snapshotValues()
+			engine.errors = engine.errors[:mark]
+		}
+
+		end = cfg.tracer.start("logic")
+		engine.evaluateLogicTree()
+
+		// Clear values of fields hidden with on_hide: "clear"
+		engine.applyHideCascade()
+		end()
+
+		end = cfg.tracer.start("derived")
+		engine.computeDerived()
+		if cfg.fixedPoint == 0 || engine.converged(before) {
+			break
+		}
+		if pass == cfg.fixedPoint {
+			engine.nonConvergenceWarning(pass)
+			break
+		}
+		end()
+	}
+
+	// Evaluate computed labels and messages against the final state
+	engine.evaluateDisplayExprs()
+	engine.computeSummary()
+	end()
+
+	// 6. Validate
+	end = cfg.tracer.start("validate")
+	defer end()
+	engine.validateDefinitions()
+	engine.validateFieldGroups()
+	engine.checkAttestations()
+
+	// 7. Determine status and attach errors
+	schema.Errors = engine.errors
+	schema.Status = engine.determineStatus()
+	schema.Annotations = engine.collectAnnotations()
+	if cfg.attestationFields {
+		engine.mirrorAttestations()
+	}
+	if cfg.provenance {
+		engine.applyProvenance()
+	}
+	schema.FieldOrder = fieldOrder(schema)
+	schema.FocusOrder = focusOrder(schema)
+	schema.Completion = nil
+	if cfg.completion {
+		schema.Completion = engine.computeCompletion()
+	}
+	engine.stampEvidenceVersion()
+
+	return engine
+}
+
+// prepareSchema runs the steps before evaluation: default visibility, temporal routing,
+// rule pruning and input normalization. Nothing is computed or set yet.
+func prepareSchema(schema *Schema, date time.Time, cfg runConfig) *Engine {
 	if schema.Definitions == nil {
 		schema.Definitions = make(map[string]*Definition)
 	}
 
-	// Initialize default visibility for definitions
+	// Initialize default visibility for definitions; set_by is recomputed, never read
 	for _, def := range schema.Definitions {
-		if def != nil && def.Visible == nil {
-			t := true
-			def.Visible = &t
+		if def == nil {
+			continue
+		}
+		if def.Visible == nil {
+			def.SetVisible(true)
 		}
+		def.SetBy = nil
 	}
 
-	engine := NewEngine(&schema)
+	// Mirrors from an earlier run are recomputed (or dropped) rather than evaluated as input
+	dropAttestationFields(schema)
+
+	engine := NewEngine(schema)
+	for _, fn := range cfg.fieldListeners {
+		engine.OnFieldChanged(fn)
+	}
+	for _, fn := range cfg.errorListeners {
+		engine.OnErrorAdded(fn)
+	}
+	engine.ruleTimer = cfg.ruleTimer
+	engine.provenance = cfg.provenance
+	engine.index = cfg.index
+	engine.session = cfg.session
+	engine.date = date
+	engine.decimal, engine.currencyPlaces = cfg.decimal, cfg.currencyPlaces
+	if cfg.limits != nil {
+		engine.allocMax = cfg.limits.MaxAllocation
+	}
+
+	// Compile decision tables into rules, so temporal and other pruning applies to them
+	engine.compileDecisionTables()
+	engine.warnUnresolvedPacks()
 
 	// 2. Validate and select temporal branch, prune inactive rules
 	if len(schema.TemporalMap) > 0 {
@@ -48,28 +282,34 @@ func Run(jsonText string, date time.Time) (result string, err error) {
 		branch := engine.selectBranch(date)
 		if branch != nil {
 			engine.prune(branch)
+			engine.checkArchived(branch, date)
+			schema.ActiveVersion = branch.LogicVersion
 		}
 	}
 
-	// 3. Compute derived state (so logic tree can use derived values)
-	engine.computeDerived()
+	// Select jurisdiction-scoped rules and derived formulas
+	if cfg.jurisdiction != "" {
+		schema.Jurisdiction = cfg.jurisdiction
+	}
+	engine.applyJurisdiction(schema.Jurisdiction)
 
-	// 4. Evaluate logic tree
-	engine.evaluateLogicTree()
+	// Compile infix string conditions and derived expressions to JSON-logic
+	engine.compileInfix()
 
-	// 5. Re-compute derived state (in case logic modified inputs)
-	engine.computeDerived()
+	// Disable rules outside their own effective dates
+	engine.pruneDates(date)
 
-	// 6. Validate
-	engine.validateDefinitions()
-	engine.checkAttestations()
+	// Disable rules filtered out by tag options
+	engine.pruneTags(cfg.includeTags, cfg.excludeTags)
+	if len(cfg.features) > 0 {
+		schema.Features = sortedUnique(append(slices.Clone(schema.Features), cfg.features...))
+	}
+	engine.pruneFeatures(schema.Features)
 
-	// 7. Determine status and attach errors
-	schema.Errors = engine.errors
-	schema.Status = engine.determineStatus()
+	// Clean up input values before anything reads them
+	engine.normalizeValues(cfg.normalizers, cfg.typeNormalizers)
 
-	// 8. Marshal result
-	return engine.marshal()
+	return engine
 }
 
 // Verify checks that a completed document (newJson) was correctly derived from a base schema.
@@ -80,38 +320,109 @@ func Run(jsonText string, date time.Time) (result string, err error) {
 // This is the "Auditor" - it proves the transformation was legal by replaying the journey.
 // Returns a structured VerifyResult with all issues found (not just the first).
 // Panic-safe: recovers from any unexpected panic and returns it as an internal_error issue.
-func Verify(newJson, baseSchemaJson string, maxIter ...int) (vr VerifyResult) {
+func Verify(newJson, baseSchemaJson string, maxIter ...int) VerifyResult {
+	compiled, err := Compile(baseSchemaJson)
+	if err != nil {
+		return VerifyResult{
+			Valid: false,
+			Issues: []VerifyIssue{{
+				Code:     VerifyInternalError,
+				Severity: VerifySeverityError,
+				Message:  "failed to parse base schema",
+			}},
+			Error: fmt.Sprintf("unmarshal base schema: %v", err),
+		}
+	}
+	return VerifyWithCompiled(newJson, compiled, maxIter...)
+}
+
+// VerifyWithCompiled is Verify against a base schema compiled once with Compile.
+// Servers verifying many submissions against the same base skip re-parsing it on every call;
+// each call works on its own copy, so a CompiledSchema can be shared across goroutines.
+func VerifyWithCompiled(newJson string, compiled *CompiledSchema, maxIter ...int) VerifyResult {
+	return verifyCompiled(newJson, compiled, nil, maxIter...)
+}
+
+// verifyCompiled implements VerifyWithCompiled and, with a store, VerifyWithEvidence.
+func verifyCompiled(newJson string, compiled *CompiledSchema, store EvidenceStore, maxIter ...int) (vr VerifyResult) {
 	defer func() {
 		if r := recover(); r != nil {
-			vr = VerifyResult{
-				Valid: false,
-				Issues: []VerifyIssue{{
-					Code:    VerifyInternalError,
-					Message: fmt.Sprintf("internal panic: %v", r),
-				}},
-				Error: fmt.Sprintf("internal panic: %v", r),
-			}
+			vr = verifyPanic(r)
 		}
 	}()
 
-	maxIterations := 100
-	if len(maxIter) > 0 && maxIter[0] > 0 {
-		maxIterations = maxIter[0]
-	}
-
-	// Parse both documents
+	// Parse the submitted document
 	var newSchema Schema
-	if err := json.Unmarshal([]byte(newJson), &newSchema); err != nil {
+	end := compiled.tracer.start("parse")
+	err := json.Unmarshal([]byte(newJson), &newSchema)
+	end()
+	if err != nil {
 		return VerifyResult{
 			Valid: false,
 			Issues: []VerifyIssue{{
-				Code:    VerifyInternalError,
-				Message: fmt.Sprintf("failed to parse submitted document: %v", err),
+				Code:     VerifyInternalError,
+				Severity: VerifySeverityError,
+				Message:  fmt.Sprintf("failed to parse submitted document: %v", err),
 			}},
 			Error: fmt.Sprintf("unmarshal newJson: %v", err),
 		}
 	}
 
+	// Swap embedded evidence for the stored records before anything reads it
+	var evidenceIssues []VerifyIssue
+	if store != nil {
+		evidenceIssues = resolveEvidence(&newSchema, store)
+	}
+	return withIssues(replayVerify(&newSchema, compiled, maxIter...), evidenceIssues)
+}
+
+// VerifySchema is Verify for documents that are already parsed, for Go services that hold
+// schemas in memory and would otherwise marshal them only to have Verify parse them again.
+// Neither schema is modified.
+func VerifySchema(newSchema, baseSchema *Schema, maxIter ...int) (vr VerifyResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			vr = verifyPanic(r)
+		}
+	}()
+
+	if newSchema == nil || baseSchema == nil {
+		return VerifyResult{
+			Issues: []VerifyIssue{{Code: VerifyInternalError, Severity: VerifySeverityError, Message: "schema is nil"}},
+			Error:  "schema is nil",
+		}
+	}
+	compiled, err := compileSchema(cloneSchema(baseSchema), "", runConfig{})
+	if err != nil {
+		return VerifyResult{
+			Issues: []VerifyIssue{{Code: VerifyInternalError, Severity: VerifySeverityError, Message: err.Error()}},
+			Error:  err.Error(),
+		}
+	}
+	return replayVerify(newSchema, compiled, maxIter...)
+}
+
+// verifyPanic is the result of a verification that panicked.
+func verifyPanic(r any) VerifyResult {
+	return VerifyResult{
+		Valid: false,
+		Issues: []VerifyIssue{{
+			Code:     VerifyInternalError,
+			Severity: VerifySeverityError,
+			Message:  fmt.Sprintf("internal panic: %v", r),
+		}},
+		Error: fmt.Sprintf("internal panic: %v", r),
+	}
+}
+
+// replayVerify replays a parsed submission on the compiled base until the visible fields
+// converge, then validates the final state.
+func replayVerify(newSchema *Schema, compiled *CompiledSchema, maxIter ...int) VerifyResult {
+	maxIterations := 100
+	if len(maxIter) > 0 && maxIter[0] > 0 {
+		maxIterations = maxIter[0]
+	}
+
 	// Extract effective date from newJson
 	effectiveDate := time.Now()
 	if newSchema.ValidFrom != "" {
@@ -120,32 +431,39 @@ func Verify(newJson, baseSchemaJson string, maxIter ...int) (vr VerifyResult) {
 		}
 	}
 
-	// Start with base schema
-	currentJson := baseSchemaJson
+	// Start with a private copy of the base schema; each iteration runs on it in place
+	currentSchema := compiled.clone()
+	fixed := fixedPrefills(compiled.base)
+
+	// Fields submitted under an ID the base has since renamed are read under the current ID
+	newSchema, renamed := migrateRenamed(newSchema, renamedFields(compiled.base))
+
+	// Replay in the submission's jurisdiction unless the base schema fixes one
+	if currentSchema.Jurisdiction == "" {
+		currentSchema.Jurisdiction = newSchema.Jurisdiction
+	}
+
+	// Replay with the submission's feature flags unless Compile fixed them
+	if len(compiled.features) > 0 {
+		currentSchema.Features = sortedUnique(append(slices.Clone(currentSchema.Features), compiled.features...))
+	} else {
+		currentSchema.Features = sortedUnique(append(slices.Clone(currentSchema.Features), newSchema.Features...))
+	}
 	previousVisibleSet := ""
 
 	for iteration := 0; iteration < maxIterations; iteration++ {
-		// Parse current state
-		var currentSchema Schema
-		if err := json.Unmarshal([]byte(currentJson), &currentSchema); err != nil {
-			return VerifyResult{
-				Valid: false,
-				Issues: []VerifyIssue{{
-					Code:    VerifyInternalError,
-					Message: fmt.Sprintf("failed to parse schema at iteration %d", iteration),
-				}},
-				Error: fmt.Sprintf("unmarshal current (iteration %d): %v", iteration, err),
-			}
-		}
-
 		// Count visible editable fields before copying
-		visibleEditable := getVisibleEditableFields(&currentSchema)
+		visibleEditable := getVisibleEditableFields(currentSchema)
 
 		// Copy values from newJson for visible, editable fields
 		for fieldId := range visibleEditable {
+			if _, ok := fixed[fieldId]; ok {
+				continue // Replay with the author's value; a changed submission is reported below
+			}
 			if newDef, ok := newSchema.Definitions[fieldId]; ok && newDef != nil {
 				if currentDef, ok := currentSchema.Definitions[fieldId]; ok && currentDef != nil {
-					currentDef.Value = newDef.Value
+					currentDef.Value = cloneValue(newDef.Value)
+					currentDef.ChangedAt = newDef.ChangedAt
 				}
 			}
 		}
@@ -162,70 +480,52 @@ func Verify(newJson, baseSchemaJson string, maxIter ...int) (vr VerifyResult) {
 		}
 
 		// Run the schema
-		modifiedJson, err := json.Marshal(currentSchema)
-		if err != nil {
-			return VerifyResult{
-				Valid: false,
-				Issues: []VerifyIssue{{
-					Code:    VerifyInternalError,
-					Message: fmt.Sprintf("failed to serialize schema at iteration %d", iteration),
-				}},
-				Error: fmt.Sprintf("marshal (iteration %d): %v", iteration, err),
-			}
-		}
-
-		resultJson, err := Run(string(modifiedJson), effectiveDate)
-		if err != nil {
-			return VerifyResult{
-				Valid: false,
-				Issues: []VerifyIssue{{
-					Code:    VerifyInternalError,
-					Message: fmt.Sprintf("VM run failed at iteration %d", iteration),
-				}},
-				Error: fmt.Sprintf("run failed (iteration %d): %v", iteration, err),
-			}
-		}
-
-		// Parse result
-		var resultSchema Schema
-		if err := json.Unmarshal([]byte(resultJson), &resultSchema); err != nil {
-			return VerifyResult{
-				Valid: false,
-				Issues: []VerifyIssue{{
-					Code:    VerifyInternalError,
-					Message: fmt.Sprintf("failed to parse VM result at iteration %d", iteration),
-				}},
-				Error: fmt.Sprintf("unmarshal result (iteration %d): %v", iteration, err),
-			}
-		}
+		end := compiled.tracer.start("replay")
+		runSchema(currentSchema, effectiveDate, runConfig{index: compiled.index, decimal: compiled.decimal, currencyPlaces: compiled.currencyPlaces, fixedPoint: compiled.fixedPoint, tracer: compiled.tracer})
+		end()
 
 		// Build sorted set of visible field IDs for convergence check
-		currentVisibleSet := visibleFieldSet(&resultSchema)
+		currentVisibleSet := visibleFieldSet(currentSchema)
 
 		// Check for convergence
 		if currentVisibleSet == previousVisibleSet {
 			// Converged - now validate the final state and return full result
-			return validateFinalState(&newSchema, &resultSchema)
+			defer compiled.tracer.start("final_state")()
+			return withIssues(validateFinalState(newSchema, currentSchema, fixed), renamed)
 		}
 
 		previousVisibleSet = currentVisibleSet
-		currentJson = resultJson
 	}
 
 	return VerifyResult{
 		Valid: false,
 		Issues: []VerifyIssue{{
-			Code:    VerifyConvergenceFailed,
-			Message: fmt.Sprintf("document did not converge after %d iterations", maxIterations),
+			Code:     VerifyConvergenceFailed,
+			Severity: VerifySeverityError,
+			Message:  fmt.Sprintf("document did not converge after %d iterations", maxIterations),
 		}},
 	}
 }
 
+// withIssues puts issues found before the replay ahead of the result's own.
+func withIssues(vr VerifyResult, issues []VerifyIssue) VerifyResult {
+	if len(issues) == 0 {
+		return vr
+	}
+	vr.Issues = append(issues, vr.Issues...)
+	for _, issue := range issues {
+		if issue.Severity == VerifySeverityError {
+			vr.Valid = false
+		}
+	}
+	return vr
+}
+
 // getVisibleEditableFields returns field IDs that are visible and not readonly
 func getVisibleEditableFields(schema *Schema) map[string]bool {
 	result := make(map[string]bool)
 	for id, def := range schema.Definitions {
-		if def != nil && def.Visible != nil && *def.Visible && !def.Readonly {
+		if def != nil && def.IsVisible() && !def.IsReadonly() {
 			result[id] = true
 		}
 	}
@@ -236,7 +536,7 @@ func getVisibleEditableFields(schema *Schema) map[string]bool {
 func visibleFieldSet(schema *Schema) string {
 	var ids []string
 	for id, def := range schema.Definitions {
-		if def != nil && def.Visible != nil && *def.Visible {
+		if def != nil && def.IsVisible() {
 			ids = append(ids, id)
 		}
 	}
@@ -244,26 +544,38 @@ func visibleFieldSet(schema *Schema) string {
 	return strings.Join(ids, ",")
 }
 
-// validateFinalState compares computed values and attestation fulfillment.
+// validateFinalState compares computed values, fixed prefills and attestation fulfillment.
 // Collects ALL issues instead of bailing on the first — the UI needs the complete picture.
-func validateFinalState(newSchema, resultSchema *Schema) VerifyResult {
+func validateFinalState(newSchema, resultSchema *Schema, fixed map[string]any) VerifyResult {
 	engine := &Engine{}
 	var issues []VerifyIssue
 
 	// Check for unknown/injected fields in newSchema that don't exist in result
-	for id := range newSchema.Definitions {
+	for id, newDef := range newSchema.Definitions {
+		if newDef != nil && newDef.Attestation != "" {
+			continue // Mirrored attestation, not input
+		}
 		if _, existsInResult := resultSchema.Definitions[id]; !existsInResult {
 			issues = append(issues, VerifyIssue{
-				Code:    VerifyUnknownField,
-				FieldID: id,
-				Message: fmt.Sprintf("field '%s' does not exist in the schema", id),
+				Code:     VerifyUnknownField,
+				Severity: VerifySeverityError,
+				FieldID:  id,
+				Message:  fmt.Sprintf("field '%s' does not exist in the schema", id),
+				Remediation: &Remediation{
+					Action:      RemediationRemoveField,
+					Target:      id,
+					Description: fmt.Sprintf("remove field '%s'", id),
+				},
 			})
 		}
 	}
 
+	// Author-provided values that submissions must keep
+	issues = append(issues, checkPrefills(newSchema, fixed)...)
+
 	// Compare computed (readonly) values
 	for id, resultDef := range resultSchema.Definitions {
-		if resultDef == nil || !resultDef.Readonly {
+		if resultDef == nil || !resultDef.IsReadonly() {
 			continue
 		}
 
@@ -271,9 +583,16 @@ func validateFinalState(newSchema, resultSchema *Schema) VerifyResult {
 		if !ok {
 			issues = append(issues, VerifyIssue{
 				Code:     VerifyComputedMismatch,
+				Severity: VerifySeverityError,
 				FieldID:  id,
 				Message:  fmt.Sprintf("computed field '%s' is missing from the submitted document", id),
 				Expected: resultDef.Value,
+				Remediation: &Remediation{
+					Action:      RemediationSetValue,
+					Target:      id,
+					Value:       resultDef.Value,
+					Description: fmt.Sprintf("add computed field '%s' with the value the VM computed", id),
+				},
 			})
 			continue
 		}
@@ -281,10 +600,17 @@ func validateFinalState(newSchema, resultSchema *Schema) VerifyResult {
 		if !engine.compareEqual(newDef.Value, resultDef.Value) {
 			issues = append(issues, VerifyIssue{
 				Code:     VerifyComputedMismatch,
+				Severity: VerifySeverityError,
 				FieldID:  id,
 				Message:  fmt.Sprintf("computed field '%s' was modified", id),
 				Expected: resultDef.Value,
 				Claimed:  newDef.Value,
+				Remediation: &Remediation{
+					Action:      RemediationSetValue,
+					Target:      id,
+					Value:       resultDef.Value,
+					Description: fmt.Sprintf("restore computed field '%s' to the value the VM computed", id),
+				},
 			})
 		}
 	}
@@ -302,61 +628,150 @@ func validateFinalState(newSchema, resultSchema *Schema) VerifyResult {
 
 		if !newAtt.Signed {
 			issues = append(issues, VerifyIssue{
-				Code:    VerifyAttestationUnsigned,
-				FieldID: id,
-				Message: fmt.Sprintf("required attestation '%s' has not been signed", id),
+				Code:     VerifyAttestationUnsigned,
+				Severity: VerifySeverityError,
+				FieldID:  id,
+				Message:  fmt.Sprintf("required attestation '%s' has not been signed", id),
+				Remediation: &Remediation{
+					Action:      RemediationSignAttestation,
+					Target:      id,
+					Description: fmt.Sprintf("sign attestation '%s'", id),
+				},
 			})
 			continue // No point checking evidence if unsigned
 		}
 
 		if newAtt.Evidence == nil || newAtt.Evidence.ProviderAuditID == "" {
 			issues = append(issues, VerifyIssue{
-				Code:    VerifyAttestationNoEvidence,
-				FieldID: id,
-				Message: fmt.Sprintf("attestation '%s' is signed but missing proof of signing", id),
+				Code:        VerifyAttestationNoEvidence,
+				Severity:    VerifySeverityError,
+				FieldID:     id,
+				Message:     fmt.Sprintf("attestation '%s' is signed but missing proof of signing", id),
+				Remediation: resignAttestation(id),
 			})
 		}
 
 		if newAtt.Evidence == nil || newAtt.Evidence.Timestamp == "" {
 			issues = append(issues, VerifyIssue{
-				Code:    VerifyAttestationNoTimestamp,
-				FieldID: id,
-				Message: fmt.Sprintf("attestation '%s' is signed but missing a timestamp", id),
+				Code:        VerifyAttestationNoTimestamp,
+				Severity:    VerifySeverityError,
+				FieldID:     id,
+				Message:     fmt.Sprintf("attestation '%s' is signed but missing a timestamp", id),
+				Remediation: resignAttestation(id),
 			})
 		}
 	}
 
+	// Verify signatures were made within the effective version and after the content they certify
+	issues = append(issues, checkEvidenceWindows(newSchema, resultSchema)...)
+
 	// Verify status matches
 	if newSchema.Status != resultSchema.Status {
 		issues = append(issues, VerifyIssue{
 			Code:     VerifyStatusMismatch,
+			Severity: VerifySeverityError,
 			Message:  "the document status does not match what was computed",
 			Expected: resultSchema.Status,
 			Claimed:  newSchema.Status,
+			Remediation: &Remediation{
+				Action:      RemediationSetStatus,
+				Value:       resultSchema.Status,
+				Description: fmt.Sprintf("set status to %s", resultSchema.Status),
+			},
 		})
 	}
 
+	valid := true
+	for _, issue := range issues {
+		if issue.Severity == VerifySeverityError {
+			valid = false
+		}
+	}
+
 	return VerifyResult{
-		Valid:  len(issues) == 0,
+		Valid:  valid,
 		Status: resultSchema.Status,
 		Issues: issues,
 		Schema: resultSchema,
 	}
 }
 
+// resignAttestation is the remediation for a signature whose evidence is incomplete.
+func resignAttestation(id string) *Remediation {
+	return &Remediation{
+		Action:      RemediationResignAttestation,
+		Target:      id,
+		Description: fmt.Sprintf("re-sign attestation '%s'", id),
+	}
+}
+
 // evaluateLogicTree processes all active rules in order.
 func (e *Engine) evaluateLogicTree() {
-	for _, rule := range e.schema.LogicTree {
+	for i, rule := range e.schema.LogicTree {
 		if rule == nil || rule.Disabled {
 			continue
 		}
 
-		// Evaluate the condition
-		condition := e.resolve(rule.When)
-		if e.isTruthy(condition) {
+		e.currentRule = rule
+		if e.ruleTimer != nil {
+			start := time.Now()
+			if e.matches(i, rule) {
+				e.applyAction(rule.Then, rule.ID, rule.LawRef)
+			}
+			e.ruleTimer(rule.ID, time.Since(start))
+			continue
+		}
+
+		if e.matches(i, rule) {
 			e.applyAction(rule.Then, rule.ID, rule.LawRef)
 		}
 	}
+	e.currentRule = nil
+}
+
+// matches evaluates the guards of the i-th rule in the logic tree, through the session
+// cache when there is one.
+func (e *Engine) matches(i int, rule *Rule) bool {
+	if e.session != nil {
+		return e.session.ruleMatches(e, i, rule)
+	}
+	return e.ruleMatches(rule)
+}
+
+// ruleMatches evaluates a rule's guards.
+// `when` may be a single condition or an array (all must hold); `when_any` needs at least one.
+// `unless` is a negated guard: if it holds (or, for an array, if any exception holds) the rule is skipped.
+// All present guards must pass. A rule with no guards never fires.
+func (e *Engine) ruleMatches(rule *Rule) bool {
+	if rule.When == nil && rule.WhenAny == nil && rule.Unless == nil {
+		return false
+	}
+
+	if rule.When != nil {
+		if conditions, ok := rule.When.([]any); ok {
+			if !e.opAnd(conditions) {
+				return false
+			}
+		} else if !e.isTruthy(e.resolve(rule.When)) {
+			return false
+		}
+	}
+
+	if rule.WhenAny != nil && !e.opOr(rule.WhenAny) {
+		return false
+	}
+
+	if rule.Unless != nil {
+		if exceptions, ok := rule.Unless.([]any); ok {
+			if e.opOr(exceptions) {
+				return false
+			}
+		} else if e.isTruthy(e.resolve(rule.Unless)) {
+			return false
+		}
+	}
+
+	return true
 }
 
 // applyAction executes a rule's action: setting values, modifying UI, or emitting errors.
@@ -365,9 +780,16 @@ func (e *Engine) applyAction(action *Action, ruleID, lawRef string) {
 		return
 	}
 
-	// Apply value mutations
-	if action.Set != nil {
-		for key, value := range action.Set {
+	// Apply value mutations, in key order so errors and overlapping paths ("a", "a.b") are deterministic
+	if action.Set != nil && !e.readOnly {
+		for _, key := range sortedIDs(action.Set) {
+			value := action.Set[key]
+			if target, ok := e.aliasPath(key); ok {
+				key = target
+			}
+			if e.writeBlocked(key, ruleID, lawRef) {
+				continue
+			}
 			// Resolve the value in case it's an expression
 			resolvedValue := e.resolve(value)
 			e.setDefinitionValue(key, resolvedValue, ruleID)
@@ -377,6 +799,9 @@ func (e *Engine) applyAction(action *Action, ruleID, lawRef string) {
 	// Apply UI modifications
 	if action.UIModify != nil {
 		for key, mods := range action.UIModify {
+			if target, ok := e.aliasPath(key); ok {
+				key = target
+			}
 			e.applyUIModify(key, mods)
 		}
 	}
@@ -391,6 +816,39 @@ func (e *Engine) applyAction(action *Action, ruleID, lawRef string) {
 	}
 }
 
+// writeBlocked enforces readonly_protection: a readonly field that isn't derived may only be set
+// by rules listed in its writable_by (by rule ID, tag or logic_version). Blocked writes are
+// reported as constraint violations and skipped.
+func (e *Engine) writeBlocked(key, ruleID, lawRef string) bool {
+	if !e.schema.ReadonlyProtection {
+		return false
+	}
+	def, ok := e.schema.Definitions[key]
+	if !ok || def == nil || !def.IsReadonly() || e.isDerived(key) {
+		return false
+	}
+	for _, allowed := range def.WritableBy {
+		if allowed == ruleID {
+			return false
+		}
+		if rule := e.currentRule; rule != nil && (allowed == rule.LogicVersion || slices.Contains(rule.Tags, allowed)) {
+			return false
+		}
+	}
+	e.addError(key, ruleID, ErrConstraintViolation, fmt.Sprintf(
+		"rule '%s' may not overwrite readonly field '%s'", ruleID, key), lawRef)
+	return true
+}
+
+// isDerived reports whether id is computed by the state model.
+func (e *Engine) isDerived(id string) bool {
+	if e.schema.StateModel == nil {
+		return false
+	}
+	_, ok := e.schema.StateModel.Derived[id]
+	return ok
+}
+
 // setDefinitionValue updates or creates a definition value.
 // Tracks which rule set each field to detect potential cycles.
 func (e *Engine) setDefinitionValue(key string, value any, ruleID string) {
@@ -400,21 +858,96 @@ func (e *Engine) setDefinitionValue(key string, value any, ruleID string) {
 			"potential cycle: field '%s' set by rule '%s' and again by rule '%s'",
 			key, prevRule, ruleID), "")
 	}
+	if e.fieldsSet == nil {
+		e.fieldsSet = make(map[string]string)
+	}
 	e.fieldsSet[key] = ruleID
+	if e.provenance {
+		e.recordProvenance(key, ruleID)
+	}
 
+	e.chargeValue(key, value)
 	def, ok := e.schema.Definitions[key]
-	if !ok {
-		// Create new definition if it doesn't exist
-		t := true
-		e.schema.Definitions[key] = &Definition{
-			Type:    inferType(value),
-			Value:   value,
-			Visible: &t,
-		}
+	if !ok && strings.Contains(key, ".") {
+		e.setNestedValue(key, value, ruleID)
+		return
+	}
+	if !ok || def == nil {
+		e.charge(key, len(key)+64)
+		// Create new definition if it doesn't exist (or is null)
+		def = &Definition{Type: inferType(value), Value: value}
+		def.SetVisible(true)
+		e.schema.Definitions[key] = def
+		e.notifyFieldChanged(key, nil, value, ruleID)
 		return
 	}
 
+	value = e.currencyValue(def, value)
+	old := def.Value
 	def.Value = value
+	e.notifyFieldChanged(key, old, value, ruleID)
+}
+
+// setNestedValue writes into a nested structure using dot notation: "applicant.address.city".
+// Missing intermediate objects (and the root definition) are created.
+// Refuses to overwrite a non-object value on the way down — that would silently discard data.
+func (e *Engine) setNestedValue(path string, value any, ruleID string) {
+	parts := strings.Split(path, ".")
+
+	def, ok := e.schema.Definitions[parts[0]]
+	if !ok || def == nil {
+		def = &Definition{Type: "object"}
+		def.SetVisible(true)
+		e.schema.Definitions[parts[0]] = def
+	}
+
+	if def.Value == nil {
+		def.Value = make(map[string]any)
+	}
+	current, ok := def.Value.(map[string]any)
+	if !ok {
+		e.addError(parts[0], ruleID, ErrRuntimeWarning, fmt.Sprintf(
+			"cannot set '%s': field '%s' is not an object", path, parts[0]), "")
+		return
+	}
+
+	for i, part := range parts[1 : len(parts)-1] {
+		next, exists := current[part]
+		if !exists || next == nil {
+			child := make(map[string]any)
+			current[part] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			e.addError(parts[0], ruleID, ErrRuntimeWarning, fmt.Sprintf(
+				"cannot set '%s': '%s' is not an object", path, strings.Join(parts[:i+2], ".")), "")
+			return
+		}
+		current = child
+	}
+
+	leaf := parts[len(parts)-1]
+	old := current[leaf]
+	current[leaf] = value
+	e.notifyFieldChanged(path, old, value, ruleID)
+}
+
+// applyHideCascade clears the values of hidden fields that opted in with on_hide: "clear".
+// This keeps stale answers to questions the user can no longer see out of validation
+// and derived values, matching what Verify observes when it replays the journey.
+func (e *Engine) applyHideCascade() {
+	for id, def := range e.schema.Definitions {
+		if def == nil || def.OnHide != OnHideClear || def.IsReadonly() {
+			continue
+		}
+		if !def.IsVisible() {
+			old := def.Value
+			def.Value = nil
+			e.notifyFieldChanged(id, old, nil, "")
+		}
+	}
 }
 
 // applyUIModify applies UI metadata changes to a definition.
@@ -431,7 +964,7 @@ func (e *Engine) applyUIModify(key string, mods any) {
 
 	// Apply visibility and metadata modifications
 	if visible, ok := modMap["visible"].(bool); ok {
-		def.Visible = &visible
+		def.SetVisible(visible)
 	}
 	if uiClass, ok := modMap["ui_class"].(string); ok {
 		def.UIClass = uiClass
@@ -439,8 +972,11 @@ func (e *Engine) applyUIModify(key string, mods any) {
 	if uiMessage, ok := modMap["ui_message"].(string); ok {
 		def.UIMessage = uiMessage
 	}
+	if severity, ok := modMap["ui_severity"].(string); ok && isValidSeverity(severity) {
+		def.UISeverity = severity
+	}
 	if required, ok := modMap["required"].(bool); ok {
-		def.Required = required
+		def.SetRequired(required)
 	}
 
 	// Apply numeric constraints (min, max, step)
@@ -468,46 +1004,165 @@ func (e *Engine) applyUIModify(key string, mods any) {
 	}
 }
 
-// computeDerived evaluates all derived fields in the state model.
+// evaluateDisplayExprs resolves label_expr and ui_message_expr into display text.
+func (e *Engine) evaluateDisplayExprs() {
+	for _, id := range sortedIDs(e.schema.Definitions) {
+		def := e.schema.Definitions[id]
+		if def == nil || (def.LabelExpr == nil && def.UIMessageExpr == nil) {
+			continue
+		}
+		if def.LabelExpr != nil {
+			if text, ok := displayText(e.resolve(def.LabelExpr)); ok {
+				def.Label = text
+			}
+		}
+		if def.UIMessageExpr != nil {
+			if text, ok := displayText(e.resolve(def.UIMessageExpr)); ok {
+				def.UIMessage = text
+			}
+		}
+	}
+}
+
+// displayText converts a resolved value to display text. Returns false for nil.
+// Whole numbers render without a fractional part (4200, not 4200.00).
+func displayText(value any) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return fmt.Sprintf("%v", v), true
+	}
+}
+
+// collectAnnotations gathers ui_message/ui_severity pairs into structured, field-sorted annotations.
+// Hidden fields are skipped — guidance on an invisible field can't be acted on.
+func (e *Engine) collectAnnotations() []Annotation {
+	var annotations []Annotation
+	for id, def := range e.schema.Definitions {
+		if def == nil || def.UIMessage == "" || !def.IsVisible() {
+			continue
+		}
+		severity := def.UISeverity
+		if severity == "" {
+			severity = SeverityInfo
+		}
+		annotations = append(annotations, Annotation{FieldID: id, Severity: severity, Message: def.UIMessage})
+	}
+	sort.Slice(annotations, func(i, j int) bool {
+		return annotations[i].FieldID < annotations[j].FieldID
+	})
+	return annotations
+}
+
+// isValidSeverity reports whether s is a known UI severity.
+func isValidSeverity(s string) bool {
+	return s == SeverityInfo || s == SeverityWarning || s == SeverityError
+}
+
+// computeDerived evaluates all derived fields in the state model, each exactly once and
+// after the derived fields it reads. Fields on a dependency cycle are reported once, on the
+// first call, and set to null.
 func (e *Engine) computeDerived() {
 	if e.schema.StateModel == nil || e.schema.StateModel.Derived == nil {
 		return
 	}
+	if e.derivedPlan == nil {
+		e.derivedPlan = e.planDerived()
+		e.reportCycles(e.derivedPlan)
+	}
 
-	for name, derivedDef := range e.schema.StateModel.Derived {
+	e.derivedPass++
+	e.derivedValues = make(map[string]any, len(e.derivedPlan.order))
+	defer func() { e.derivedValues = nil }()
+	for _, name := range e.derivedPlan.order {
+		derivedDef := e.schema.StateModel.Derived[name]
 		if derivedDef == nil || derivedDef.Eval == nil {
 			continue
 		}
 
-		// Evaluate the expression
-		value := e.resolve(derivedDef.Eval)
-
-		if existing, ok := e.schema.Definitions[name]; ok && existing != nil {
-			existing.Value = value
-			existing.Readonly = true
-			if existing.Visible == nil {
-				t := true
-				existing.Visible = &t
-			}
+		// Evaluate the expression; derived fields that read this one use the unrounded value
+		var value any
+		if e.session != nil {
+			value = e.session.derivedValue(e, name, e.derivedPass, derivedDef.Eval)
 		} else {
-			t := true
-			e.schema.Definitions[name] = &Definition{
-				Type:     inferType(value),
-				Value:    value,
-				Readonly: true,
-				Visible:  &t,
+			value = e.resolve(derivedDef.Eval)
+		}
+		e.derivedValues[name] = value
+		e.chargeValue(name, value)
+		e.storeDerived(name, value)
+	}
+	for _, cycle := range e.derivedPlan.cycles {
+		for _, name := range cycle {
+			if def := e.schema.StateModel.Derived[name]; def != nil && def.Eval != nil {
+				e.storeDerived(name, nil)
 			}
 		}
 	}
 }
 
+// storeDerived writes a computed value into the derived field's definition, creating it if needed.
+func (e *Engine) storeDerived(name string, value any) {
+	if existing, ok := e.schema.Definitions[name]; ok && existing != nil {
+		value = e.currencyValue(existing, value)
+		old := existing.Value
+		existing.Value = value
+		e.notifyFieldChanged(name, old, value, "")
+		existing.SetReadonly(true)
+		if existing.Visible == nil {
+			existing.SetVisible(true)
+		}
+		return
+	}
+	def := &Definition{Type: inferType(value), Value: value}
+	def.SetReadonly(true)
+	def.SetVisible(true)
+	e.schema.Definitions[name] = def
+	e.notifyFieldChanged(name, nil, value, "")
+}
+
+// omitDefaults clears UI metadata that only restates its default, so it is left out
+// of the output. Run calls it just before marshaling; nothing reads the schema after.
+func omitDefaults(schema *Schema) {
+	for _, def := range schema.Definitions {
+		if def != nil && def.Visible != nil && *def.Visible {
+			def.Visible = nil
+		}
+	}
+}
+
 // marshal converts the schema back to JSON.
-func (e *Engine) marshal() (string, error) {
-	result, err := json.MarshalIndent(e.schema, "", "  ")
-	if err != nil {
+// Output is indented unless WithCompactOutput is set; WithBuffer reuses the caller's buffer.
+func (e *Engine) marshal(cfg runConfig) (string, error) {
+	if cfg.buffer == nil {
+		var result []byte
+		var err error
+		if cfg.compact {
+			result, err = json.Marshal(e.schema)
+		} else {
+			result, err = json.MarshalIndent(e.schema, "", "  ")
+		}
+		if err != nil {
+			return "", fmt.Errorf("marshal: %w", err)
+		}
+		return string(result), nil
+	}
+
+	buf := cfg.buffer
+	buf.Reset()
+	enc := json.NewEncoder(buf)
+	if !cfg.compact {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(e.schema); err != nil {
 		return "", fmt.Errorf("marshal: %w", err)
 	}
-	return string(result), nil
+	// Encoder terminates with a newline; Marshal doesn't
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
 }
 
 // inferType determines the type string for a value.
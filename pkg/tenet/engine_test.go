@@ -1,7 +1,12 @@
 package tenet
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -121,6 +126,344 @@ func TestOperatorArithmetic(t *testing.T) {
 	}
 }
 
+func TestOperatorModuloPowAbs(t *testing.T) {
+	schema := &Schema{
+		Definitions: map[string]*Definition{
+			"amount":  {Type: "number", Value: float64(1250)},
+			"balance": {Type: "number", Value: float64(-40)},
+			"missing": {Type: "number", Value: nil},
+		},
+	}
+	engine := NewEngine(schema)
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`{"%": [{"var": "amount"}, 100]}`, float64(50)},
+		{`{"%": [-7, 3]}`, float64(-1)},
+		{`{"%": [{"var": "amount"}, 0]}`, nil},
+		{`{"pow": [1.05, 2]}`, 1.05 * 1.05},
+		{`{"pow": [-8, 0.5]}`, nil},
+		{`{"abs": {"var": "balance"}}`, float64(40)},
+		{`{"abs": [{"var": "missing"}]}`, nil},
+		{`{"pow": [{"var": "missing"}, 2]}`, nil},
+	}
+	for _, tt := range tests {
+		var expr any
+		if err := json.Unmarshal([]byte(tt.expr), &expr); err != nil {
+			t.Fatalf("bad test expression %s: %v", tt.expr, err)
+		}
+		if got := engine.resolve(expr); got != tt.want {
+			t.Errorf("resolve(%s) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestOperatorStrings(t *testing.T) {
+	schema := &Schema{
+		Definitions: map[string]*Definition{
+			"country": {Type: "string", Value: "  se "},
+			"name":    {Type: "string", Value: "Zoë Berg"},
+			"amount":  {Type: "number", Value: float64(4200)},
+			"items":   {Type: "string", Value: []any{"a", "b"}},
+			"missing": {Type: "string", Value: nil},
+		},
+	}
+	engine := NewEngine(schema)
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`{"cat": ["Loan of ", {"var": "amount"}, " SEK"]}`, "Loan of 4200 SEK"},
+		{`{"concat": [{"var": "name"}, {"var": "missing"}, "!"]}`, "Zoë Berg!"},
+		{`{"upper": {"trim": {"var": "country"}}}`, "SE"},
+		{`{"lower": ["ABC"]}`, "abc"},
+		{`{"substr": [{"var": "name"}, 0, 3]}`, "Zoë"},
+		{`{"substr": [{"var": "name"}, -4]}`, "Berg"},
+		{`{"substr": [{"var": "name"}, 1, -2]}`, "oë Be"},
+		{`{"substr": [{"var": "name"}, 20]}`, ""},
+		{`{"length": {"var": "name"}}`, float64(8)},
+		{`{"length": {"var": "items"}}`, float64(2)},
+		{`{"length": {"var": "amount"}}`, nil},
+		{`{"upper": {"var": "missing"}}`, nil},
+		{`{"substr": [{"var": "missing"}, 0, 1]}`, nil},
+	}
+	for _, tt := range tests {
+		var expr any
+		if err := json.Unmarshal([]byte(tt.expr), &expr); err != nil {
+			t.Fatalf("bad test expression %s: %v", tt.expr, err)
+		}
+		if got := engine.resolve(expr); got != tt.want {
+			t.Errorf("resolve(%s) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestOperatorDateArithmetic(t *testing.T) {
+	schema := &Schema{
+		Definitions: map[string]*Definition{
+			"breach_at": {Type: "string", Value: "2025-06-13T09:30:00Z"},
+			"start":     {Type: "date", Value: "2025-01-31"},
+			"missing":   {Type: "date", Value: nil},
+		},
+	}
+	engine := NewEngine(schema)
+	engine.date = time.Date(2025, 6, 15, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`{"today": []}`, "2025-06-15"},
+		{`{"now": []}`, "2025-06-15T08:00:00Z"},
+		{`{"date_add": [{"var": "breach_at"}, 72, "hours"]}`, "2025-06-16T09:30:00Z"},
+		{`{"date_add": [{"var": "start"}, 30]}`, "2025-03-02"},
+		{`{"date_add": [{"var": "start"}, 1, "months"]}`, "2025-03-03"},
+		{`{"date_add": [{"var": "start"}, -2, "weeks"]}`, "2025-01-17"},
+		{`{"date_add": [{"var": "start"}, 12, "hours"]}`, "2025-01-31T12:00:00Z"},
+		{`{"date_diff": [{"var": "start"}, {"today": []}, "days"]}`, float64(135)},
+		{`{"date_diff": [{"var": "start"}, "2025-02-28", "months"]}`, float64(0)},
+		{`{"date_diff": [{"var": "start"}, "2025-03-31", "months"]}`, float64(2)},
+		{`{"date_diff": ["2000-06-16", {"today": []}, "years"]}`, float64(24)},
+		{`{"date_diff": [{"today": []}, {"var": "start"}, "weeks"]}`, float64(-19)},
+		{`{"date_diff": [{"var": "breach_at"}, {"now": []}, "hours"]}`, float64(46)},
+		{`{"date_add": [{"var": "missing"}, 1]}`, nil},
+		{`{"date_diff": [{"var": "start"}, {"var": "missing"}]}`, nil},
+		{`{"date_add": [{"var": "start"}, 1, "fortnights"]}`, nil},
+	}
+	for _, tt := range tests {
+		var expr any
+		if err := json.Unmarshal([]byte(tt.expr), &expr); err != nil {
+			t.Fatalf("bad test expression %s: %v", tt.expr, err)
+		}
+		if got := engine.resolve(expr); got != tt.want {
+			t.Errorf("resolve(%s) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+	if len(engine.errors) != 1 || !strings.Contains(engine.errors[0].Message, "fortnights") {
+		t.Errorf("expected one warning for the unknown unit, got %+v", engine.errors)
+	}
+}
+
+func TestDateArithmeticUsesEffectiveDate(t *testing.T) {
+	schema := `{
+		"definitions": {
+			"breach_detected_at": {"type": "string", "value": "2025-06-13T09:30:00Z"},
+			"authority_notified": {"type": "boolean", "value": false}
+		},
+		"state_model": {"derived": {
+			"notification_deadline": {"eval": {"date_add": [{"var": "breach_detected_at"}, 72, "hours"]}}
+		}},
+		"logic_tree": [{
+			"id": "gdpr_33",
+			"law_ref": "GDPR Art. 33(1)",
+			"when": "not authority_notified and after(now(), notification_deadline)",
+			"then": {"error_msg": "The supervisory authority must be notified within 72 hours"}
+		}]
+	}`
+
+	for _, tt := range []struct {
+		date    time.Time
+		overdue bool
+	}{
+		{time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC), false},
+		{time.Date(2025, 6, 16, 12, 0, 0, 0, time.UTC), true},
+	} {
+		result, err := Run(schema, tt.date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if got := strings.Contains(result, `"message": "The supervisory authority`); got != tt.overdue {
+			t.Errorf("on %s: overdue = %v, want %v\n%s", tt.date, got, tt.overdue, result)
+		}
+	}
+}
+
+func TestOperatorAggregation(t *testing.T) {
+	schema := &Schema{
+		Definitions: map[string]*Definition{
+			"line_items": {Type: "object", Value: []any{
+				map[string]any{"sku": "A", "amount": float64(1200), "qty": float64(2)},
+				map[string]any{"sku": "B", "amount": float64(300), "qty": float64(1)},
+				map[string]any{"sku": "C", "amount": nil},
+			}},
+			"scores": {Type: "number", Value: []any{float64(70), float64(90), "n/a"}},
+			"empty":  {Type: "number", Value: []any{}},
+			"a":      {Type: "number", Value: float64(3)},
+		},
+	}
+	engine := NewEngine(schema)
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`{"sum": [{"var": "line_items"}, {"var": ".amount"}]}`, float64(1500)},
+		{`{"sum": [{"var": "line_items"}, {"*": [{"var": ".amount"}, {"var": ".qty"}]}]}`, float64(2700)},
+		{`{"sum": [{"var": "scores"}]}`, float64(160)},
+		{`{"sum": [{"var": "empty"}]}`, float64(0)},
+		{`{"avg": [{"var": "scores"}]}`, float64(80)},
+		{`{"avg": [{"var": "empty"}]}`, nil},
+		{`{"count": [{"var": "line_items"}]}`, float64(3)},
+		{`{"count": [{"var": "line_items"}, {">": [{"var": ".amount"}, 1000]}]}`, float64(1)},
+		{`{"min": [{"var": "line_items"}, {"var": ".amount"}]}`, float64(300)},
+		{`{"max": [{"var": "scores"}]}`, float64(90)},
+		{`{"max": [{"var": "a"}, 7, "x"]}`, float64(7)},
+		{`{"min": [{"var": "a"}, 7]}`, float64(3)},
+		{`{"max": [{"var": "empty"}]}`, nil},
+		{`{"sum": [{"var": "missing"}, {"var": ".amount"}]}`, nil},
+	}
+	for _, tt := range tests {
+		var expr any
+		if err := json.Unmarshal([]byte(tt.expr), &expr); err != nil {
+			t.Fatalf("bad test expression %s: %v", tt.expr, err)
+		}
+		if got := engine.resolve(expr); got != tt.want {
+			t.Errorf("resolve(%s) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	if engine.currentElement != nil {
+		t.Errorf("element context leaked: %v", engine.currentElement)
+	}
+}
+
+func TestOperatorArrayTransforms(t *testing.T) {
+	schema := &Schema{
+		Definitions: map[string]*Definition{
+			"line_items": {Type: "object", Value: []any{
+				map[string]any{"sku": "A", "amount": float64(1200), "taxable": true},
+				map[string]any{"sku": "B", "amount": float64(300), "taxable": false},
+				map[string]any{"sku": "C", "amount": float64(50), "taxable": true},
+			}},
+			"flags": {Type: "object", Value: []any{true, false, nil, "x", float64(0)}},
+			"empty": {Type: "number", Value: []any{}},
+		},
+	}
+	engine := NewEngine(schema)
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`{"map": [{"var": "line_items"}, {"var": ".sku"}]}`, []any{"A", "B", "C"}},
+		{`{"map": [{"var": "line_items"}, {"*": [{"var": ".amount"}, 2]}]}`, []any{float64(2400), float64(600), float64(100)}},
+		{`{"map": [{"var": "empty"}, {"var": ".sku"}]}`, []any{}},
+		{`{"filter": [{"var": "line_items"}, {"var": ".taxable"}]}`, []any{
+			map[string]any{"sku": "A", "amount": float64(1200), "taxable": true},
+			map[string]any{"sku": "C", "amount": float64(50), "taxable": true},
+		}},
+		{`{"filter": [{"var": "flags"}]}`, []any{true, "x"}},
+		{`{"map": [{"filter": [{"var": "line_items"}, {"<": [{"var": ".amount"}, 1000]}]}, {"var": ".sku"}]}`, []any{"B", "C"}},
+		{`{"sum": [{"filter": [{"var": "line_items"}, {"var": ".taxable"}]}, {"var": ".amount"}]}`, float64(1250)},
+		{`{"reduce": [{"var": "line_items"}, {"+": [{"var": ".accumulator"}, {"var": ".current.amount"}]}, 0]}`, float64(1550)},
+		{`{"reduce": [{"map": [{"var": "line_items"}, {"var": ".sku"}]}, {"cat": [{"var": ".accumulator"}, {"var": ".current"}]}, ""]}`, "ABC"},
+		{`{"reduce": [{"var": "empty"}, {"+": [{"var": ".accumulator"}, 1]}, 10]}`, float64(10)},
+		{`{"map": [{"var": "missing"}, {"var": ".sku"}]}`, nil},
+		{`{"filter": "x"}`, nil},
+		{`{"reduce": [{"var": "line_items"}]}`, nil},
+	}
+	for _, tt := range tests {
+		var expr any
+		if err := json.Unmarshal([]byte(tt.expr), &expr); err != nil {
+			t.Fatalf("bad test expression %s: %v", tt.expr, err)
+		}
+		if got := engine.resolve(expr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolve(%s) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	if engine.currentElement != nil {
+		t.Errorf("element context leaked: %v", engine.currentElement)
+	}
+}
+
+func TestOperatorPresence(t *testing.T) {
+	schema := &Schema{
+		Definitions: map[string]*Definition{
+			"name":    {Type: "string", Value: "Ada"},
+			"email":   {Type: "string", Value: ""},
+			"phone":   {Type: "string"},
+			"address": {Type: "object", Value: map[string]any{"city": "Lund"}},
+			"tags":    {Type: "object", Value: []any{"a", "b"}},
+		},
+	}
+	engine := NewEngine(schema)
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`{"merge": [[1, 2], 3, [[4]]]}`, []any{float64(1), float64(2), float64(3), []any{float64(4)}}},
+		{`{"merge": [{"var": "tags"}, "c"]}`, []any{"a", "b", "c"}},
+		{`{"merge": []}`, []any{}},
+		{`{"missing": ["name", "email", "phone", "fax"]}`, []any{"email", "phone", "fax"}},
+		{`{"missing": ["address.city", "address.zip"]}`, []any{"address.zip"}},
+		{`{"missing": "name"}`, []any{}},
+		{`{"missing": {"merge": [["name", "phone"], "email"]}}`, []any{"phone", "email"}},
+		{`{"missing_some": [1, ["name", "email", "phone"]]}`, []any{}},
+		{`{"missing_some": [2, ["name", "email", "phone"]]}`, []any{"email", "phone"}},
+		{`{"missing_some": [0, []]}`, []any{}},
+	}
+	for _, tt := range tests {
+		var expr any
+		if err := json.Unmarshal([]byte(tt.expr), &expr); err != nil {
+			t.Fatalf("bad test expression %s: %v", tt.expr, err)
+		}
+		if got := engine.resolve(expr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resolve(%s) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	// An empty result is falsy, and unknown fields aren't runtime warnings
+	if engine.isTruthy(engine.resolve(map[string]any{"missing": []any{"name"}})) {
+		t.Error("expected missing of a filled field to be falsy")
+	}
+	if len(engine.errors) != 0 {
+		t.Errorf("unexpected errors: %+v", engine.errors)
+	}
+}
+
+func TestOperatorRounding(t *testing.T) {
+	engine := NewEngine(&Schema{Definitions: map[string]*Definition{
+		"amount": {Type: "currency", Value: 1234.5678},
+	}})
+
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{`{"round": 2.5}`, float64(3)},
+		{`{"round": -2.5}`, float64(-3)},
+		{`{"round": [1.005, 2]}`, 1.01},
+		{`{"round": [{"*": [0.1, 3]}, 2]}`, 0.3},
+		{`{"round": [1234.5678, -2]}`, float64(1200)},
+		{`{"floor": [-1.21, 1]}`, -1.3},
+		{`{"floor": 7.9}`, float64(7)},
+		{`{"ceil": [1.21, 1]}`, 1.3},
+		{`{"ceil": -7.9}`, float64(-7)},
+		{`{"round_currency": [{"var": "amount"}]}`, 1234.57},
+		{`{"round_currency": [{"var": "amount"}, "JPY"]}`, float64(1235)},
+		{`{"round_currency": [{"var": "amount"}, "kwd"]}`, 1234.568},
+		{`{"round_currency": [{"var": "amount"}, "SEK"]}`, 1234.57},
+		{`{"round_currency": [{"var": "amount"}, 1]}`, 1234.6},
+		{`{"round": [null, 2]}`, nil},
+		{`{"round": ["x"]}`, nil},
+		{`{"round": [1.5, "x"]}`, nil},
+	}
+	for _, tt := range tests {
+		var expr any
+		if err := json.Unmarshal([]byte(tt.expr), &expr); err != nil {
+			t.Fatalf("bad test expression %s: %v", tt.expr, err)
+		}
+		if got := engine.resolve(expr); got != tt.want {
+			t.Errorf("resolve(%s) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
 func TestOperatorNilSafe(t *testing.T) {
 	schema := &Schema{
 		Definitions: map[string]*Definition{
@@ -388,3 +731,528 @@ func TestVerify(t *testing.T) {
 		t.Errorf("Expected verification to pass, got issues: %+v", result.Issues)
 	}
 }
+
+func TestRunOutputOptions(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	input := createLoanSchema("employed", 720, 75000, 250000)
+
+	pretty, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	compact, err := Run(input, date, WithCompactOutput())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.Contains(compact, "\n") {
+		t.Error("compact output should not contain newlines")
+	}
+	if len(compact) >= len(pretty) {
+		t.Errorf("compact output (%d bytes) should be smaller than pretty (%d bytes)", len(compact), len(pretty))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("stale content")
+	for i := 0; i < 2; i++ {
+		buffered, err := Run(input, date, WithBuffer(&buf))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if buffered != pretty {
+			t.Fatal("buffered output differs from default output")
+		}
+	}
+	bufferedCompact, err := Run(input, date, WithBuffer(&buf), WithCompactOutput())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if bufferedCompact != compact {
+		t.Fatal("buffered compact output differs from compact output")
+	}
+}
+
+func TestRunWithPatchOutput(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	input := createLoanSchema("employed", 580, 75000, 250000)
+
+	full, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	result, err := Run(input, date, WithPatchOutput())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var ops []PatchOp
+	if err := json.Unmarshal([]byte(result), &ops); err != nil {
+		t.Fatalf("expected a JSON Patch, got %s: %v", result, err)
+	}
+
+	found := false
+	for _, op := range ops {
+		if op.Op == "replace" && op.Path == "/definitions/approval_status/value" {
+			found = true
+			assertEqual(t, string(op.Value), `"review_required"`)
+		}
+		if strings.HasPrefix(op.Path, "/definitions/applicant_income") {
+			t.Errorf("expected no operation on an unchanged input, got %+v", op)
+		}
+	}
+	if !found {
+		t.Errorf("expected approval_status to be replaced, got %s", result)
+	}
+
+	// Applying the patch to the input gives the full result
+	var doc, want any
+	json.Unmarshal([]byte(input), &doc)
+	json.Unmarshal([]byte(full), &want)
+	for _, op := range ops {
+		doc = applyPatchOp(t, doc, op)
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("patched input differs from the result:\n%v\nwant:\n%v", doc, want)
+	}
+
+	unchanged, err := Diff(full, full)
+	if err != nil || len(unchanged) != 0 {
+		t.Errorf("expected no operations between equal documents, got %v, %v", unchanged, err)
+	}
+
+	// Formatting, defaults and unknown keys of the input aren't changes
+	noop := `{
+		"definitions": {
+			"a": {"type": "number", "value": 1.50, "visible": true},
+			"b": {"type": "string", "value": "x", "extra": 1}
+		}
+	}`
+	result, err = Run(noop, date, WithPatchOutput(), WithCompactOutput())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	assertEqual(t, result, `[{"op":"add","path":"/status","value":"READY"}]`)
+}
+
+// applyPatchOp applies one add, remove or replace operation to a decoded document.
+func applyPatchOp(t *testing.T, doc any, op PatchOp) any {
+	t.Helper()
+	var value any
+	if op.Op != "remove" {
+		json.Unmarshal(op.Value, &value)
+	}
+	if op.Path == "" {
+		return value
+	}
+	tokens := strings.Split(op.Path[1:], "/")
+	parent := doc
+	for _, token := range tokens[:len(tokens)-1] {
+		switch p := parent.(type) {
+		case map[string]any:
+			parent = p[token]
+		case []any:
+			var i int
+			fmt.Sscan(token, &i)
+			parent = p[i]
+		}
+	}
+	last := strings.NewReplacer("~1", "/", "~0", "~").Replace(tokens[len(tokens)-1])
+	switch p := parent.(type) {
+	case map[string]any:
+		if op.Op == "remove" {
+			delete(p, last)
+		} else {
+			p[last] = value
+		}
+	case []any:
+		var i int
+		fmt.Sscan(last, &i)
+		p[i] = value // Diff only replaces array elements in place
+	default:
+		t.Fatalf("cannot apply %+v", op)
+	}
+	return doc
+}
+
+func TestRunWithOptions(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	input := createLoanSchema("employed", 720, 75000, 250000)
+
+	want, err := Run(input, date, WithCompactOutput(), WithCompletion())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	changes := 0
+	got, err := RunWithOptions(input, Options{
+		EffectiveDate:  date,
+		Compact:        true,
+		Completion:     true,
+		FieldListeners: []func(FieldChange){func(FieldChange) { changes++ }},
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("RunWithOptions output differs from Run with the same options:\n%s\n%s", got, want)
+	}
+	if changes == 0 {
+		t.Error("expected the field listener to be called")
+	}
+
+	// Extra options apply last
+	got, err = RunWithOptions(input, Options{EffectiveDate: date, Compact: true, Extra: []RunOption{func(c *runConfig) { c.compact = false }}})
+	if err != nil {
+		t.Fatalf("RunWithOptions failed: %v", err)
+	}
+	if !strings.Contains(got, "\n") {
+		t.Error("expected Extra to override Compact")
+	}
+}
+
+func TestRunSchema(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	input := createLoanSchema("employed", 580, 75000, 250000)
+
+	want, err := Run(input, date, WithVerboseOutput())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, input)
+	before, _ := json.Marshal(schema)
+
+	result, err := RunSchema(schema, Options{EffectiveDate: date})
+	if err != nil {
+		t.Fatalf("RunSchema failed: %v", err)
+	}
+	got, _ := json.Marshal(result)
+	wantCompact, _ := json.Marshal(parseResult(t, want))
+	if string(got) != string(wantCompact) {
+		t.Errorf("RunSchema differs from Run:\n%s\n%s", got, wantCompact)
+	}
+	if after, _ := json.Marshal(schema); string(after) != string(before) {
+		t.Error("RunSchema modified its input")
+	}
+
+	if _, err := RunSchema(nil, Options{}); err == nil {
+		t.Error("expected an error for a nil schema")
+	}
+}
+
+func TestRunRuleTimings(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	input := createLoanSchema("employed", 720, 75000, 250000)
+
+	timed := make(map[string]int)
+	_, err := Run(input, date, WithRuleTimings(func(ruleID string, d time.Duration) {
+		if d < 0 {
+			t.Errorf("negative duration for %s", ruleID)
+		}
+		timed[ruleID]++
+	}))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	schema := parseResult(t, input)
+	for _, rule := range schema.LogicTree {
+		if timed[rule.ID] != 1 {
+			t.Errorf("rule %s timed %d times, want 1", rule.ID, timed[rule.ID])
+		}
+	}
+}
+
+func TestRunTracer(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	input := createLoanSchema("employed", 720, 75000, 250000)
+
+	var events []string
+	var stack []string
+	tracer := func(phase string) func() {
+		events = append(events, phase)
+		stack = append(stack, phase)
+		return func() {
+			if top := stack[len(stack)-1]; top != phase {
+				t.Errorf("phase %s ended while %s was open", phase, top)
+			}
+			stack = stack[:len(stack)-1]
+			events = append(events, "/"+phase)
+		}
+	}
+
+	result, err := Run(input, date, WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := []string{"parse", "/parse", "temporal", "/temporal", "derived", "/derived", "logic", "/logic",
+		"derived", "/derived", "validate", "/validate", "marshal", "/marshal"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got phases %v, want %v", events, want)
+	}
+
+	events = nil
+	compiled, err := Compile(input, WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if vr := VerifyWithCompiled(result, compiled); !vr.Valid {
+		t.Fatalf("expected valid, got %+v", vr.Issues)
+	}
+	if len(events) < 4 || events[0] != "parse" || events[2] != "replay" || events[len(events)-1] != "/final_state" {
+		t.Errorf("unexpected verify phases %v", events)
+	}
+	if len(stack) != 0 {
+		t.Errorf("phases left open: %v", stack)
+	}
+}
+
+func TestRunWithDecoder(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	input := createLoanSchema("employed", 720, 75000, 250000)
+
+	calls := 0
+	decode := func(data []byte, v any) error {
+		calls++
+		return json.Unmarshal(data, v)
+	}
+
+	withDecoder, err := Run(input, date, WithDecoder(decode))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	plain, _ := Run(input, date)
+	assertEqual(t, calls, 1)
+	assertEqual(t, withDecoder, plain)
+
+	failing := func([]byte, any) error { return errors.New("boom") }
+	if _, err := Run(input, date, WithDecoder(failing)); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected decoder error, got %v", err)
+	}
+}
+
+func TestRunWithPartial(t *testing.T) {
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"income": {"type": "number", "value": 50000},
+			"broken": {"type": "number", "options": "not-a-list"},
+			"tax": {"type": "number", "readonly": true}
+		},
+		"logic_tree": [
+			{"id": "half_done", "when": {"==": [1, 1]}, "then": "set tax"},
+			{"id": "flat_tax", "when": {">": [{"var": "income"}, 0]}, "then": {"set": {"tax": {"*": [{"var": "income"}, 0.2]}}}}
+		],
+		"state_model": {"derived": {
+			"net": {"eval": {"-": [{"var": "income"}, {"var": "tax"}]}},
+			"bad": {"eval": 1, "by_jurisdiction": "US"}
+		}},
+		"valid_from": 2025
+	}`
+
+	if _, err := Run(input, date); err == nil {
+		t.Fatal("expected the malformed document to fail without WithPartial")
+	}
+	result, err := Run(input, date, WithPartial())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+	assertEqual(t, schema.Definitions["tax"].Value, 10000.0)
+	if schema.Definitions["broken"] != nil {
+		t.Error("expected the malformed definition to be dropped")
+	}
+
+	var skipped []string
+	for _, e := range schema.Errors {
+		if e.Kind == ErrRuntimeWarning && strings.HasPrefix(e.Message, "Skipped malformed ") {
+			skipped = append(skipped, strings.SplitN(strings.TrimPrefix(e.Message, "Skipped malformed "), ":", 2)[0])
+		}
+	}
+	want := []string{
+		"definitions.broken",
+		"logic_tree[0] (rule 'half_done')",
+		"state_model.derived.bad",
+		"valid_from",
+	}
+	if strings.Join(skipped, "|") != strings.Join(want, "|") {
+		t.Errorf("skipped %q, want %q", skipped, want)
+	}
+
+	if _, err := Run(`{"definitions": {`, date, WithPartial()); err == nil {
+		t.Error("expected invalid JSON to fail even with WithPartial")
+	}
+}
+
+func TestRunWithStrictFields(t *testing.T) {
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"$schema": "https://example.com/tenet.schema.json",
+		"definitions": {"age": {"type": "number", "requird": true, "Label": "Age"}},
+		"logci_tree": [{"id": "adult", "when": {">=": [{"var": "age"}, 18]}, "then": {"sett": {"adult": true}}}],
+		"state_model": {"derived": {"twice": {"eval": {"*": [{"var": "age"}, 2]}, "evl": 1}}}
+	}`
+
+	if _, err := Run(input, date); err != nil {
+		t.Fatalf("unknown fields should be ignored by default: %v", err)
+	}
+	_, err := Run(input, date, WithStrictFields())
+	var unknown *UnknownFieldsError
+	if !errors.As(err, &unknown) || !errors.Is(err, ErrUnknownFields) {
+		t.Fatalf("expected *UnknownFieldsError, got %v", err)
+	}
+	want := []string{"definitions.age.requird", "logci_tree", "state_model.derived.twice.evl"}
+	assertEqual(t, strings.Join(unknown.Paths, "|"), strings.Join(want, "|"))
+
+	// Paths inside known arrays are indexed
+	paths, err := UnknownFields(`{"definitions": {}, "logic_tree": [{"id": "a"}, {"id": "b", "then": {"sett": {}}}]}`)
+	if err != nil {
+		t.Fatalf("UnknownFields failed: %v", err)
+	}
+	assertEqual(t, strings.Join(paths, "|"), "logic_tree[1].then.sett")
+
+	if _, err := Run(`{"definitions": {"age": {"type": "number", "value": 30}}}`, date, WithStrictFields()); err != nil {
+		t.Errorf("expected a clean document to pass: %v", err)
+	}
+}
+
+func TestRunWithRejectDuplicateKeys(t *testing.T) {
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"income": {"type": "number", "value": 1},
+			"income": {"type": "number", "value": 2},
+			"tax": {"type": "number"}
+		},
+		"logic_tree": [
+			{"id": "a", "when": {"and": [true], "and": [false]}, "then": {"set": {"tax": 1}}, "then": {"set": {"tax": 2}}, "then": {}}
+		]
+	}`
+
+	if _, err := Run(input, date); err != nil {
+		t.Fatalf("duplicate keys should be accepted by default: %v", err)
+	}
+	_, err := Run(input, date, WithRejectDuplicateKeys())
+	var dup *DuplicateKeysError
+	if !errors.As(err, &dup) || !errors.Is(err, ErrDuplicateKeys) {
+		t.Fatalf("expected *DuplicateKeysError, got %v", err)
+	}
+	want := []string{"definitions.income", "logic_tree[0].when.and", "logic_tree[0].then"}
+	assertEqual(t, strings.Join(dup.Paths, "|"), strings.Join(want, "|"))
+
+	// The same key in different objects is not a duplicate
+	paths, err := DuplicateKeys(`{"definitions": {"a": {"type": "number"}, "b": {"type": "number"}}}`)
+	if err != nil || paths != nil {
+		t.Errorf("expected no duplicates, got %v (%v)", paths, err)
+	}
+	if _, err := DuplicateKeys(`{"definitions": {`); err == nil {
+		t.Error("expected an error for truncated JSON")
+	}
+}
+
+func TestRunWithMeta(t *testing.T) {
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	base := `{
+		"protocol": "loan/1",
+		"definitions": {"amount": {"type": "number", "value": 100}},
+		"temporal_map": [{"valid_range": ["2025-01-01", null], "logic_version": "v2025", "status": "ACTIVE"}]
+	}`
+	compiled, err := Compile(base)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	result, err := Run(base, date, WithMeta(compiled))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	meta := parseResult(t, result).Meta
+	if meta == nil {
+		t.Fatal("expected a meta block")
+	}
+	assertEqual(t, meta.SchemaHash, compiled.Hash())
+	assertEqual(t, meta.InputHash, compiled.Hash()) // Same text
+	assertEqual(t, meta.Protocol, "loan/1")
+	assertEqual(t, meta.LogicVersion, "v2025")
+	assertEqual(t, meta.EffectiveDate, "2025-06-01")
+	assertEqual(t, meta.EngineVersion, EngineVersion)
+	if _, err := time.Parse(time.RFC3339, meta.EvaluatedAt); err != nil {
+		t.Errorf("evaluated_at should be RFC 3339: %v", err)
+	}
+
+	// Meta from an earlier run is not carried forward
+	rerun, err := Run(result, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if parseResult(t, rerun).Meta != nil {
+		t.Error("meta should only be emitted with WithMeta")
+	}
+}
+
+func TestRequiresEngine(t *testing.T) {
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	schema := func(requires string) string {
+		return `{"requires_engine": "` + requires + `", "definitions": {"a": {"type": "number", "value": 1}}}`
+	}
+
+	for _, requires := range []string{">=0.5", "0.5", ">=0.4, <1", "=0.5.0", "<=v0.5"} {
+		if _, err := Run(schema(requires), date); err != nil {
+			t.Errorf("%s: expected engine %s to satisfy it, got %v", requires, EngineVersion, err)
+		}
+	}
+
+	for _, requires := range []string{">=1.3", "<0.5", ">0.5.0, <0.6"} {
+		_, err := Run(schema(requires), date)
+		var ve *EngineVersionError
+		if !errors.As(err, &ve) || !errors.Is(err, ErrEngineIncompatible) {
+			t.Fatalf("%s: expected *EngineVersionError, got %v", requires, err)
+		}
+		assertEqual(t, ve.Requires, requires)
+		assertEqual(t, ve.Version, EngineVersion)
+	}
+
+	if _, err := Compile(schema(">=one")); !errors.Is(err, ErrEngineIncompatible) {
+		t.Errorf("malformed constraint should fail Compile, got %v", err)
+	}
+	if vr := Verify(schema(""), schema(">=9")); vr.Valid || vr.Error == "" {
+		t.Errorf("Verify should fail against a schema for a newer engine, got %+v", vr)
+	}
+}
+
+func TestRunDeterministicOutput(t *testing.T) {
+	// Several blocked writes, a derived cycle and display expressions with unknown
+	// variables all report errors; their order must not depend on map iteration.
+	schema := `{
+		"definitions": {
+			"a": {"type": "number", "value": 1, "readonly": true},
+			"b": {"type": "number", "value": 2, "readonly": true},
+			"c": {"type": "number", "value": 3, "readonly": true},
+			"d": {"type": "number", "value": 4, "readonly": true},
+			"p": {"type": "string", "label_expr": {"var": "missing_p"}},
+			"q": {"type": "string", "label_expr": {"var": "missing_q"}},
+			"r": {"type": "string", "ui_message_expr": {"var": "missing_r"}}
+		},
+		"state_model": {"derived": {
+			"x": {"eval": {"+": [{"var": "y"}, 1]}},
+			"y": {"eval": {"+": [{"var": "x"}, 1]}},
+			"z": {"eval": {"var": "missing_z"}}
+		}},
+		"logic_tree": [
+			{"id": "overwrite", "when": {"==": [1, 1]}, "then": {"set": {"d": 40, "c": 30, "b": 20, "a": 10}}}
+		]
+	}`
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	first, err := Run(schema, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(parseResult(t, first).Errors) < 4 {
+		t.Fatalf("expected errors from the blocked writes, got %s", first)
+	}
+	for i := 0; i < 30; i++ {
+		next, err := Run(schema, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if next != first {
+			t.Fatalf("output differs between runs:\n%s\n%s", first, next)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package tenet
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// checkEvidenceWindows validates when each signed attestation was signed: inside the
+// valid_range of the effective temporal branch, and no earlier than the last change
+// (changed_at) of the fields it certifies — its covers, or every user-editable field in
+// the submitted document when it has none. A signature that predates the content it
+// certifies doesn't certify that content.
+func checkEvidenceWindows(newSchema, resultSchema *Schema) []VerifyIssue {
+	var issues []VerifyIssue
+
+	ids := make([]string, 0, len(newSchema.Attestations))
+	for id := range newSchema.Attestations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	branch := branchForVersion(resultSchema)
+
+	for _, id := range ids {
+		att := newSchema.Attestations[id]
+		if att == nil || !att.Signed || att.Evidence == nil || att.Evidence.Timestamp == "" {
+			continue // Missing evidence is reported by validateFinalState
+		}
+		if _, ok := resultSchema.Attestations[id]; !ok {
+			continue
+		}
+
+		signedAt, ok := parseDate(att.Evidence.Timestamp)
+		if !ok {
+			issues = append(issues, VerifyIssue{
+				Code:        VerifyAttestationNoTimestamp,
+				Severity:    VerifySeverityError,
+				FieldID:     id,
+				Message:     fmt.Sprintf("attestation '%s' has an unparseable timestamp '%s'", id, att.Evidence.Timestamp),
+				Claimed:     att.Evidence.Timestamp,
+				Remediation: resignAttestation(id),
+			})
+			continue
+		}
+
+		if branch != nil && !inBranch(signedAt, branch) {
+			issues = append(issues, VerifyIssue{
+				Code:     VerifyAttestationOutsideVersion,
+				Severity: VerifySeverityError,
+				FieldID:  id,
+				Message: fmt.Sprintf("attestation '%s' was signed at %s, outside logic version '%s'",
+					id, att.Evidence.Timestamp, branch.LogicVersion),
+				Expected:    branch.ValidRange,
+				Claimed:     att.Evidence.Timestamp,
+				Remediation: resignAttestation(id),
+			})
+		}
+
+		if issue := checkSignedStatement(id, att, resultSchema.Attestations[id]); issue != nil {
+			issues = append(issues, *issue)
+		}
+
+		if field, changedAt := lastChange(resultSchema.Attestations[id], newSchema, resultSchema); field != "" && changedAt.After(signedAt) {
+			issues = append(issues, VerifyIssue{
+				Code:     VerifyAttestationStale,
+				Severity: VerifySeverityError,
+				FieldID:  id,
+				Message: fmt.Sprintf("attestation '%s' was signed at %s, before field '%s' changed at %s",
+					id, att.Evidence.Timestamp, field, newSchema.Definitions[field].ChangedAt),
+				Expected:    newSchema.Definitions[field].ChangedAt,
+				Claimed:     att.Evidence.Timestamp,
+				Remediation: resignAttestation(id),
+			})
+		}
+	}
+
+	return issues
+}
+
+// branchForVersion returns the temporal branch the run selected, or nil.
+func branchForVersion(schema *Schema) *TemporalBranch {
+	if schema.ActiveVersion == "" {
+		return nil
+	}
+	for _, branch := range schema.TemporalMap {
+		if branch != nil && branch.LogicVersion == schema.ActiveVersion {
+			return branch
+		}
+	}
+	return nil
+}
+
+// inBranch reports whether t falls within the branch's valid_range.
+// A date-only end covers that whole day.
+func inBranch(t time.Time, branch *TemporalBranch) bool {
+	if branch.ValidRange[0] != nil {
+		if start, ok := parseDate(*branch.ValidRange[0]); ok && t.Before(start) {
+			return false
+		}
+	}
+	if branch.ValidRange[1] != nil {
+		if end, ok := parseDate(*branch.ValidRange[1]); ok {
+			if len(*branch.ValidRange[1]) == len("2006-01-02") {
+				end = end.AddDate(0, 0, 1)
+			}
+			if !t.Before(end) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lastChange returns the field certified by att with the latest parseable changed_at in
+// the submitted document. Without covers, every user-editable field is certified;
+// computed fields change as a consequence and don't count.
+func lastChange(att *Attestation, newSchema, resultSchema *Schema) (string, time.Time) {
+	var field string
+	var latest time.Time
+	for id, def := range newSchema.Definitions {
+		if def == nil || def.ChangedAt == "" {
+			continue
+		}
+		if att != nil && len(att.Covers) > 0 {
+			if !slices.Contains(att.Covers, id) {
+				continue
+			}
+		} else if resultDef := resultSchema.Definitions[id]; resultDef == nil || resultDef.IsReadonly() {
+			continue
+		}
+		changedAt, ok := parseDate(def.ChangedAt)
+		if !ok {
+			continue
+		}
+		if changedAt.After(latest) || (changedAt.Equal(latest) && id < field) {
+			field, latest = id, changedAt
+		}
+	}
+	return field, latest
+}
+
+// invalidateStaleSignature un-signs an attestation whose covered fields changed after
+// it was signed, so it must be re-signed before the document can be READY.
+// Reports whether the signature was invalidated.
+func (e *Engine) invalidateStaleSignature(id string, att *Attestation) bool {
+	if !att.Signed || len(att.Covers) == 0 || att.Evidence == nil {
+		return false
+	}
+	signedAt, ok := parseDate(att.Evidence.Timestamp)
+	if !ok {
+		return false
+	}
+
+	field, changedAt := lastChange(att, e.schema, e.schema)
+	if field == "" || !changedAt.After(signedAt) {
+		return false
+	}
+
+	att.Signed = false
+	e.addError(id, "", ErrAttestationIncomplete, fmt.Sprintf(
+		"Attestation '%s' must be re-signed: covered field '%s' changed after signing", id, field), att.LawRef)
+	return true
+}
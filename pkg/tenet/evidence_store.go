@@ -0,0 +1,127 @@
+package tenet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrEvidenceNotFound is returned by EvidenceStore.Fetch for an unknown provider_audit_id.
+var ErrEvidenceNotFound = errors.New("evidence not found")
+
+// EvidenceStore keeps the authoritative evidence records, keyed by provider_audit_id. The
+// application saves each record as the signing provider reports it (e.g., from a webhook);
+// VerifyWithEvidence and a Service with an EvidenceStore then check submitted documents
+// against it instead of trusting the copy embedded in the document.
+// Implementations must be safe for concurrent use.
+type EvidenceStore interface {
+	Save(ev *Evidence) error
+	Fetch(providerAuditID string) (*Evidence, error) // ErrEvidenceNotFound if there is none
+}
+
+// MemoryEvidenceStore is an in-memory EvidenceStore, for tests and single-process servers.
+type MemoryEvidenceStore struct {
+	mu      sync.RWMutex
+	records map[string]Evidence
+}
+
+// NewMemoryEvidenceStore creates an empty MemoryEvidenceStore.
+func NewMemoryEvidenceStore() *MemoryEvidenceStore {
+	return &MemoryEvidenceStore{records: make(map[string]Evidence)}
+}
+
+// Save stores a copy of ev, replacing any record with the same provider_audit_id.
+func (s *MemoryEvidenceStore) Save(ev *Evidence) error {
+	if ev == nil || ev.ProviderAuditID == "" {
+		return errors.New("evidence needs a provider_audit_id")
+	}
+	s.mu.Lock()
+	s.records[ev.ProviderAuditID] = *ev
+	s.mu.Unlock()
+	return nil
+}
+
+// Fetch returns a copy of the record saved under providerAuditID.
+func (s *MemoryEvidenceStore) Fetch(providerAuditID string) (*Evidence, error) {
+	s.mu.RLock()
+	ev, ok := s.records[providerAuditID]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrEvidenceNotFound, providerAuditID)
+	}
+	return &ev, nil
+}
+
+// VerifyWithEvidence is VerifyWithCompiled with each signed attestation's evidence looked up
+// in store by its provider_audit_id. A record the store doesn't have is reported as
+// evidence_not_found, and an embedded timestamp or signer that differs from the record as
+// evidence_mismatch. The stored record then replaces the embedded copy for the rest of the
+// verification, so signing windows and staleness are judged on the authoritative timestamp.
+// Fields the store leaves empty (logic_version and statement, which Run stamps) are kept
+// from the document.
+func VerifyWithEvidence(newJson string, compiled *CompiledSchema, store EvidenceStore, maxIter ...int) VerifyResult {
+	return verifyCompiled(newJson, compiled, store, maxIter...)
+}
+
+// resolveEvidence replaces the evidence embedded in a submitted document with the stored
+// records and reports what is missing or differs.
+func resolveEvidence(doc *Schema, store EvidenceStore) []VerifyIssue {
+	ids := make([]string, 0, len(doc.Attestations))
+	for id := range doc.Attestations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var issues []VerifyIssue
+	for _, id := range ids {
+		att := doc.Attestations[id]
+		if att == nil || !att.Signed || att.Evidence == nil || att.Evidence.ProviderAuditID == "" {
+			continue // Missing evidence is reported by validateFinalState
+		}
+		embedded := att.Evidence
+		stored, err := store.Fetch(embedded.ProviderAuditID)
+		if errors.Is(err, ErrEvidenceNotFound) {
+			issues = append(issues, VerifyIssue{
+				Code:        VerifyEvidenceNotFound,
+				Severity:    VerifySeverityError,
+				FieldID:     id,
+				Message:     fmt.Sprintf("attestation '%s' cites evidence '%s', which the evidence store doesn't have", id, embedded.ProviderAuditID),
+				Claimed:     embedded.ProviderAuditID,
+				Remediation: resignAttestation(id),
+			})
+			continue
+		}
+		if err != nil {
+			issues = append(issues, VerifyIssue{
+				Code:     VerifyInternalError,
+				Severity: VerifySeverityError,
+				FieldID:  id,
+				Message:  fmt.Sprintf("fetching evidence for attestation '%s': %v", id, err),
+			})
+			continue
+		}
+
+		if stored.Timestamp != embedded.Timestamp || stored.SignerID != embedded.SignerID {
+			issues = append(issues, VerifyIssue{
+				Code:        VerifyEvidenceMismatch,
+				Severity:    VerifySeverityError,
+				FieldID:     id,
+				Message:     fmt.Sprintf("attestation '%s' embeds evidence that differs from the stored record '%s'", id, embedded.ProviderAuditID),
+				Expected:    map[string]any{"timestamp": stored.Timestamp, "signer_id": stored.SignerID},
+				Claimed:     map[string]any{"timestamp": embedded.Timestamp, "signer_id": embedded.SignerID},
+				Remediation: resignAttestation(id),
+			})
+		}
+
+		merged := *stored
+		if merged.LogicVersion == "" {
+			merged.LogicVersion = embedded.LogicVersion
+		}
+		if merged.Statement == "" {
+			merged.Statement = embedded.Statement
+		}
+		att.Evidence = &merged
+	}
+	return issues
+}
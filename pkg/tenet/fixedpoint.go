@@ -0,0 +1,64 @@
+package tenet
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DefaultFixedPointIterations bounds WithFixedPoint when it is given no limit.
+const DefaultFixedPointIterations = 100
+
+// WithFixedPoint repeats the logic tree and derived state until no definition's value changes,
+// instead of running derived → logic → derived once. Chains such as "rule A sets x, derived y
+// reads x, rule B fires on y" then work whatever order the rules are in. At most maxIterations
+// passes run (0 = DefaultFixedPointIterations); a document still changing after that is
+// reported with a runtime warning. Errors and annotations come from the final pass, while
+// field-change listeners see every pass.
+//
+// Verify replays without run options; compile the base schema with Compile(base,
+// WithFixedPoint(n)) and use VerifyWithCompiled to verify such documents.
+func WithFixedPoint(maxIterations int) RunOption {
+	return func(c *runConfig) {
+		if maxIterations <= 0 {
+			maxIterations = DefaultFixedPointIterations
+		}
+		c.fixedPoint = maxIterations
+	}
+}
+
+// valueSnapshot is the value of every definition, for detecting a fixed point.
+type valueSnapshot map[string]any
+
+// snapshotValues copies the current definition values. Objects are copied deeply, since
+// rules setting nested paths write into them in place.
+func (e *Engine) snapshotValues() valueSnapshot {
+	snap := make(valueSnapshot, len(e.schema.Definitions))
+	for id, def := range e.schema.Definitions {
+		if def != nil {
+			snap[id] = cloneValue(def.Value)
+		}
+	}
+	return snap
+}
+
+// converged reports whether no definition was added, removed or changed since snap.
+func (e *Engine) converged(snap valueSnapshot) bool {
+	n := 0
+	for id, def := range e.schema.Definitions {
+		if def == nil {
+			continue
+		}
+		n++
+		old, ok := snap[id]
+		if !ok || !reflect.DeepEqual(old, def.Value) {
+			return false
+		}
+	}
+	return n == len(snap)
+}
+
+// nonConvergenceWarning reports a document that was still changing after the last pass.
+func (e *Engine) nonConvergenceWarning(passes int) {
+	e.addError("", "", ErrRuntimeWarning, fmt.Sprintf(
+		"Logic did not reach a fixed point after %d iterations; values are from the last pass", passes), "")
+}
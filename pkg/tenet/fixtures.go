@@ -0,0 +1,212 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SchemaTest is a named regression case shipped inside the schema's `tests` array.
+// It patches definition values, runs the schema, and compares the outcome with expectations.
+type SchemaTest struct {
+	Name   string         `json:"name"`
+	Date   string         `json:"date,omitempty"`   // Effective date (defaults to valid_from, then today)
+	Set    map[string]any `json:"set,omitempty"`    // Definition values to override before running
+	Sign   []string       `json:"sign,omitempty"`   // Attestations to mark signed (with placeholder evidence)
+	Expect *TestExpect    `json:"expect,omitempty"` // Expected outcome
+}
+
+// TestExpect describes the expected outcome of a SchemaTest. Omitted parts are not checked.
+type TestExpect struct {
+	Status DocStatus       `json:"status,omitempty"`
+	Values map[string]any  `json:"values,omitempty"` // Expected definition values after Run
+	Errors []ExpectedError `json:"errors,omitempty"` // Each must match at least one emitted error
+	// NoErrors asserts that Run emitted no errors at all.
+	NoErrors bool `json:"no_errors,omitempty"`
+}
+
+// ExpectedError matches an emitted ValidationError. Empty fields match anything.
+type ExpectedError struct {
+	Kind    ErrorKind `json:"kind,omitempty"`
+	FieldID string    `json:"field_id,omitempty"`
+	RuleID  string    `json:"rule_id,omitempty"`
+}
+
+// TestResult is the outcome of a single SchemaTest.
+type TestResult struct {
+	Name     string   `json:"name"`
+	Passed   bool     `json:"passed"`
+	Failures []string `json:"failures,omitempty"`
+}
+
+// TestReport summarizes a RunSchemaTests call.
+type TestReport struct {
+	Passed  int          `json:"passed"`
+	Failed  int          `json:"failed"`
+	Results []TestResult `json:"results"`
+}
+
+// RunSchemaTests executes every case in the schema's `tests` array.
+// Returns an error only if the schema itself can't be parsed; failing cases are reported in TestReport.
+func RunSchemaTests(jsonText string, opts ...RunOption) (*TestReport, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(jsonText), &schema); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	tests := schema.Tests
+	schema.Tests = nil
+	report := &TestReport{Results: make([]TestResult, 0, len(tests))}
+
+	for i, test := range tests {
+		if test == nil {
+			continue
+		}
+		name := test.Name
+		if name == "" {
+			name = fmt.Sprintf("test_%d", i)
+		}
+
+		result := TestResult{Name: name}
+		result.Failures = runSchemaTest(&schema, test, opts)
+		result.Passed = len(result.Failures) == 0
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// runSchemaTest runs one case against a copy of the schema and returns its failures.
+func runSchemaTest(base *Schema, test *SchemaTest, opts []RunOption) []string {
+	// Work on a deep copy so cases can't leak into each other
+	raw, err := json.Marshal(base)
+	if err != nil {
+		return []string{fmt.Sprintf("marshal schema: %v", err)}
+	}
+	var schema Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return []string{fmt.Sprintf("copy schema: %v", err)}
+	}
+
+	var failures []string
+
+	for _, id := range sortedKeys(test.Set) {
+		def, ok := schema.Definitions[id]
+		if !ok || def == nil {
+			failures = append(failures, fmt.Sprintf("set: field '%s' does not exist", id))
+			continue
+		}
+		def.Value = test.Set[id]
+	}
+	for _, id := range test.Sign {
+		att, ok := schema.Attestations[id]
+		if !ok || att == nil {
+			failures = append(failures, fmt.Sprintf("sign: attestation '%s' does not exist", id))
+			continue
+		}
+		att.Signed = true
+		att.Evidence = &Evidence{ProviderAuditID: "test-fixture", Timestamp: "1970-01-01T00:00:00Z", SignerID: "test-fixture"}
+	}
+	if len(failures) > 0 {
+		return failures
+	}
+
+	date := time.Now()
+	dateStr := test.Date
+	if dateStr == "" {
+		dateStr = schema.ValidFrom
+	}
+	if dateStr != "" {
+		parsed, ok := parseDate(dateStr)
+		if !ok {
+			return []string{fmt.Sprintf("invalid date '%s'", dateStr)}
+		}
+		date = parsed
+	}
+
+	input, err := json.Marshal(&schema)
+	if err != nil {
+		return []string{fmt.Sprintf("marshal case: %v", err)}
+	}
+	output, err := Run(string(input), date, opts...)
+	if err != nil {
+		return []string{fmt.Sprintf("run failed: %v", err)}
+	}
+	var result Schema
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return []string{fmt.Sprintf("parse result: %v", err)}
+	}
+
+	return checkExpectations(&result, test.Expect)
+}
+
+// checkExpectations compares a Run result with a TestExpect.
+func checkExpectations(result *Schema, expect *TestExpect) []string {
+	if expect == nil {
+		return nil
+	}
+
+	var failures []string
+	engine := &Engine{}
+
+	if expect.Status != "" && result.Status != expect.Status {
+		failures = append(failures, fmt.Sprintf("status: got %s, want %s", result.Status, expect.Status))
+	}
+
+	for _, id := range sortedKeys(expect.Values) {
+		want := expect.Values[id]
+		def, ok := result.Definitions[id]
+		if !ok || def == nil {
+			failures = append(failures, fmt.Sprintf("value '%s': field missing from result", id))
+			continue
+		}
+		if !engine.compareEqual(def.Value, want) {
+			failures = append(failures, fmt.Sprintf("value '%s': got %v, want %v", id, def.Value, want))
+		}
+	}
+
+	for _, want := range expect.Errors {
+		if !hasMatchingError(result.Errors, want) {
+			failures = append(failures, fmt.Sprintf("expected error not emitted: %+v", want))
+		}
+	}
+
+	if expect.NoErrors && len(result.Errors) > 0 {
+		failures = append(failures, fmt.Sprintf("expected no errors, got %d (first: %s)", len(result.Errors), result.Errors[0].Message))
+	}
+
+	return failures
+}
+
+// hasMatchingError reports whether any error matches the expectation's non-empty fields.
+func hasMatchingError(errors []ValidationError, want ExpectedError) bool {
+	for _, err := range errors {
+		if want.Kind != "" && err.Kind != want.Kind {
+			continue
+		}
+		if want.FieldID != "" && err.FieldID != want.FieldID {
+			continue
+		}
+		if want.RuleID != "" && err.RuleID != want.RuleID {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
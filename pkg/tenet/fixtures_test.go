@@ -0,0 +1,77 @@
+package tenet
+
+import (
+	"strings"
+	"testing"
+)
+
+const fixtureSchema = `{
+	"valid_from": "2025-01-01",
+	"definitions": {
+		"credit_score": {"type": "number", "value": null, "required": true},
+		"decision": {"type": "string", "value": "pending"}
+	},
+	"attestations": {
+		"confirm": {"statement": "I confirm", "required": true}
+	},
+	"logic_tree": [
+		{"id": "approve", "when": {">=": [{"var": "credit_score"}, 700]}, "then": {"set": {"decision": "approved"}}},
+		{"id": "deny", "when": {"<": [{"var": "credit_score"}, 600]}, "then": {"set": {"decision": "denied"}, "error_msg": "Score too low"}}
+	],
+	"tests": [
+		{
+			"name": "good credit is approved",
+			"set": {"credit_score": 720},
+			"sign": ["confirm"],
+			"expect": {"status": "READY", "values": {"decision": "approved"}, "no_errors": true}
+		},
+		{
+			"name": "bad credit is denied",
+			"set": {"credit_score": 550},
+			"sign": ["confirm"],
+			"expect": {"status": "INVALID", "values": {"decision": "denied"}, "errors": [{"kind": "constraint_violation", "rule_id": "deny"}]}
+		},
+		{
+			"name": "unsigned is incomplete",
+			"set": {"credit_score": 650},
+			"expect": {"status": "INCOMPLETE", "errors": [{"kind": "attestation_incomplete", "field_id": "confirm"}]}
+		}
+	]
+}`
+
+func TestRunSchemaTests(t *testing.T) {
+	t.Run("passing fixtures", func(t *testing.T) {
+		report, err := RunSchemaTests(fixtureSchema)
+		if err != nil {
+			t.Fatalf("RunSchemaTests failed: %v", err)
+		}
+		if report.Failed != 0 {
+			t.Fatalf("expected all to pass, got %+v", report.Results)
+		}
+		assertEqual(t, report.Passed, 3)
+	})
+
+	t.Run("failing fixture reports reasons", func(t *testing.T) {
+		broken := strings.Replace(fixtureSchema, `{"decision": "approved"}`, `{"decision": "denied"}`, 1)
+		broken = strings.Replace(broken, `"set": {"credit_score": 650}`, `"set": {"credit_score": 650, "nope": 1}`, 1)
+
+		report, err := RunSchemaTests(broken)
+		if err != nil {
+			t.Fatalf("RunSchemaTests failed: %v", err)
+		}
+		assertEqual(t, report.Failed, 2)
+		assertEqual(t, report.Results[0].Passed, false)
+		if !strings.Contains(report.Results[0].Failures[0], "value 'decision'") {
+			t.Errorf("unexpected failure: %v", report.Results[0].Failures)
+		}
+		if !strings.Contains(report.Results[2].Failures[0], "'nope' does not exist") {
+			t.Errorf("unexpected failure: %v", report.Results[2].Failures)
+		}
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		if _, err := RunSchemaTests("{"); err == nil {
+			t.Fatal("expected parse error")
+		}
+	})
+}
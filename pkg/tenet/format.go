@@ -0,0 +1,177 @@
+package tenet
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Precedence levels for FormatExpr (higher binds tighter).
+const (
+	precIf = iota + 1
+	precOr
+	precAnd
+	precNot
+	precCompare
+	precAdd
+	precMul
+	precAtom
+)
+
+// formatInfix maps binary operators to their rendering and precedence.
+var formatInfix = map[string]struct {
+	symbol string
+	prec   int
+}{
+	"==":     {"=", precCompare},
+	"!=":     {"≠", precCompare},
+	">":      {">", precCompare},
+	"<":      {"<", precCompare},
+	">=":     {"≥", precCompare},
+	"<=":     {"≤", precCompare},
+	"before": {"before", precCompare},
+	"after":  {"after", precCompare},
+	"in":     {"in", precCompare},
+	"+":      {"+", precAdd},
+	"-":      {"-", precAdd},
+	"*":      {"*", precMul},
+	"/":      {"/", precMul},
+	"%":      {"%", precMul},
+}
+
+// FormatExpr renders a JSON-logic expression as readable infix text, e.g.
+// `credit_score ≥ 700 AND employment_status in ["employed", "self_employed"]`.
+// String literals are quoted so they can't be mistaken for field names.
+// Unknown operators render as function calls.
+func FormatExpr(expr any) string {
+	text, _ := formatNode(expr)
+	return text
+}
+
+// formatNode returns the rendering of node and its precedence.
+func formatNode(node any) (string, int) {
+	switch v := node.(type) {
+	case nil:
+		return "null", precAtom
+	case bool:
+		return strconv.FormatBool(v), precAtom
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), precAtom
+	case int:
+		return strconv.Itoa(v), precAtom
+	case string:
+		return strconv.Quote(v), precAtom
+	case []any:
+		parts := make([]string, len(v))
+		for i, elem := range v {
+			parts[i] = FormatExpr(elem)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", precAtom
+	case map[string]any:
+		if len(v) != 1 {
+			return formatObject(v), precAtom
+		}
+		for op, args := range v {
+			return formatOperator(op, args)
+		}
+	}
+	return fmt.Sprint(node), precAtom
+}
+
+// formatOperator renders a single-operator node.
+func formatOperator(op string, args any) (string, int) {
+	list, isList := args.([]any)
+	if !isList {
+		list = []any{args}
+	}
+
+	switch op {
+	case "var":
+		name, _ := list[0].(string)
+		if name == "" {
+			return "item", precAtom
+		}
+		if strings.HasPrefix(name, ".") {
+			return "item" + name, precAtom
+		}
+		return name, precAtom
+
+	case "and", "or":
+		prec, word := precAnd, " AND "
+		if op == "or" {
+			prec, word = precOr, " OR "
+		}
+		parts := make([]string, len(list))
+		for i, arg := range list {
+			parts[i] = formatOperand(arg, prec+1)
+		}
+		return strings.Join(parts, word), prec
+
+	case "not", "!":
+		return "NOT " + formatOperand(list[0], precNot), precNot
+
+	case "if":
+		var b strings.Builder
+		for i := 0; i+1 < len(list); i += 2 {
+			if i > 0 {
+				b.WriteString(" ELSE ")
+			}
+			b.WriteString("IF " + formatOperand(list[i], precOr) + " THEN " + formatOperand(list[i+1], precOr))
+		}
+		if len(list)%2 == 1 {
+			b.WriteString(" ELSE " + formatOperand(list[len(list)-1], precOr))
+		}
+		return b.String(), precIf
+
+	case "some", "all", "none":
+		if len(list) == 2 {
+			return fmt.Sprintf("%s of %s match (%s)", op, formatOperand(list[0], precAtom), FormatExpr(list[1])), precCompare
+		}
+	}
+
+	if infix, ok := formatInfix[op]; ok && len(list) >= 2 {
+		// Left-associative: the right operand of a same-precedence operator needs parentheses
+		parts := make([]string, len(list))
+		for i, arg := range list {
+			min := infix.prec
+			if i > 0 {
+				min = infix.prec + 1
+			}
+			parts[i] = formatOperand(arg, min)
+		}
+		return strings.Join(parts, " "+infix.symbol+" "), infix.prec
+	}
+	if op == "-" && len(list) == 1 {
+		return "-" + formatOperand(list[0], precAtom), precAtom
+	}
+
+	parts := make([]string, len(list))
+	for i, arg := range list {
+		parts[i] = FormatExpr(arg)
+	}
+	return op + "(" + strings.Join(parts, ", ") + ")", precAtom
+}
+
+// formatOperand renders node, parenthesized if it binds looser than min.
+func formatOperand(node any, min int) string {
+	text, prec := formatNode(node)
+	if prec < min {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// formatObject renders a literal object with sorted keys.
+func formatObject(m map[string]any) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = strconv.Quote(k) + ": " + FormatExpr(m[k])
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
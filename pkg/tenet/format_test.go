@@ -0,0 +1,33 @@
+package tenet
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFormatExpr(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`{"and": [{">=": [{"var": "credit_score"}, 700]}, {"in": [{"var": "employment_status"}, ["employed", "self_employed"]]}]}`,
+			`credit_score ≥ 700 AND employment_status in ["employed", "self_employed"]`},
+		{`{"or": [{"and": [{"var": "a"}, {"var": "b"}]}, {"var": "c"}]}`, `a AND b OR c`},
+		{`{"and": [{"or": [{"var": "a"}, {"var": "b"}]}, {"!": {"var": "c"}}]}`, `(a OR b) AND NOT c`},
+		{`{"*": [{"+": [{"var": "salary"}, {"var": "bonus"}]}, 0.3]}`, `(salary + bonus) * 0.3`},
+		{`{"-": [10, {"-": [5, 2]}]}`, `10 - (5 - 2)`},
+		{`{"if": [{"<": [{"var": "age"}, 18]}, "minor", {"<": [{"var": "age"}, 65]}, "adult", "senior"]}`,
+			`IF age < 18 THEN "minor" ELSE IF age < 65 THEN "adult" ELSE "senior"`},
+		{`{"some": [{"var": "items"}, {">": [{"var": ""}, 5]}]}`, `some of items match (item > 5)`},
+		{`{"!=": [{"var": "status"}, null]}`, `status ≠ null`},
+		{`{"custom_op": [1, "x"]}`, `custom_op(1, "x")`},
+	}
+
+	for _, tt := range tests {
+		var expr any
+		if err := json.Unmarshal([]byte(tt.expr), &expr); err != nil {
+			t.Fatalf("bad test expression %s: %v", tt.expr, err)
+		}
+		assertEqual(t, FormatExpr(expr), tt.want)
+	}
+}
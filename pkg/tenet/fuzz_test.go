@@ -0,0 +1,135 @@
+package tenet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Fuzz targets for the expression evaluator. Run's recover() turns a panic into an
+// "internal error", so these call the evaluator directly and let panics fail the test:
+//
+//	go test ./pkg/tenet -run '^$' -fuzz FuzzResolve -fuzztime 1m
+//	go test ./pkg/tenet -run '^$' -fuzz FuzzRun -fuzztime 1m
+
+// fuzzExprs seed the corpus with every operator, including malformed arities.
+var fuzzExprs = []string{
+	`{"var": "a"}`,
+	`{"var": "obj.nested.x"}`,
+	`{"var": ""}`,
+	`{"var": 5}`,
+	`{"==": [{"var": "a"}, 1]}`,
+	`{"!=": [null, "x"]}`,
+	`{">": [{"var": "a"}, "2"]}`,
+	`{"<=": [1]}`,
+	`{"and": [true, {"var": "s"}]}`,
+	`{"or": []}`,
+	`{"not": [{"var": "list"}]}`,
+	`{"!": null}`,
+	`{"if": [{"var": "b"}, 1, {"var": "a"}, 2, 3]}`,
+	`{"if": []}`,
+	`{"+": [{"var": "a"}, 1e308]}`,
+	`{"-": [1]}`,
+	`{"*": "x"}`,
+	`{"/": [1, 0]}`,
+	`{"%": [-7, 0]}`,
+	`{"pow": [-8, 0.5]}`,
+	`{"abs": {"var": "a"}}`,
+	`{"cat": ["x", {"var": "a"}, null, [1, 2]]}`,
+	`{"substr": [{"var": "s"}, -1e300, 1e300]}`,
+	`{"substr": ["abc", 1, -10]}`,
+	`{"lower": {"var": "s"}}`,
+	`{"upper": [1]}`,
+	`{"trim": "  x "}`,
+	`{"length": {"var": "list"}}`,
+	`{"before": ["2025-01-01", {"var": "d"}]}`,
+	`{"after": [{"var": "d"}, "2025-13-45"]}`,
+	`{"today": []}`,
+	`{"date_add": [{"var": "d"}, 1e300, "hours"]}`,
+	`{"date_add": [{"now": null}, -1, "years"]}`,
+	`{"date_diff": ["0001-01-01", "9999-12-31T23:59:59Z", "hours"]}`,
+	`{"date_diff": [{"var": "d"}, {"var": "d"}, 7]}`,
+	`{"in": [{"var": "s"}, {"var": "list"}]}`,
+	`{"in": [1, "abc"]}`,
+	`{"some": [{"var": "list"}, {">": [{"var": ""}, 1]}]}`,
+	`{"all": [{"var": "list"}, {"all": [{"var": ""}, true]}]}`,
+	`{"none": ["x", {"var": ""}]}`,
+	`{"sum": [{"var": "list"}, {"var": ".k"}]}`,
+	`{"avg": {"var": "list"}}`,
+	`{"count": [{"var": "list"}, {"var": ""}]}`,
+	`{"max": [{"var": "a"}, {"var": "list"}]}`,
+	`{"min": []}`,
+	`{"map": [{"var": "list"}, {"var": ".k"}]}`,
+	`{"filter": [{"var": "list"}]}`,
+	`{"reduce": [{"var": "list"}, {"+": [{"var": ".accumulator"}, {"var": ".current"}]}, 0]}`,
+	`{"merge": [{"var": "list"}, 1, [2]]}`,
+	`{"missing": ["a", "s.x", "", {"var": "s"}]}`,
+	`{"missing_some": [{"var": "a"}, {"var": "list"}]}`,
+	`{"round": [{"var": "a"}, 1e300]}`,
+	`{"floor": [-1e308, -400]}`,
+	`{"ceil": ["2.5"]}`,
+	`{"round_currency": [1.005, "JPY"]}`,
+	`{"unknown_op": [1, 2]}`,
+	`{"a": 1, "b": 2}`,
+	`[{"var": "a"}, {"var": "s"}]`,
+}
+
+// fuzzDoc is the document the expressions are evaluated against.
+const fuzzDoc = `{
+	"a": 3,
+	"b": false,
+	"s": "Zoë",
+	"d": "2025-06-15",
+	"list": [1, "two", null, [3], {"k": 4}],
+	"obj": {"nested": {"x": 1}}
+}`
+
+func FuzzResolve(f *testing.F) {
+	for _, expr := range fuzzExprs {
+		f.Add(expr, fuzzDoc)
+	}
+
+	f.Fuzz(func(t *testing.T, exprJSON, docJSON string) {
+		var expr any
+		if json.Unmarshal([]byte(exprJSON), &expr) != nil {
+			return
+		}
+		var doc map[string]any
+		if json.Unmarshal([]byte(docJSON), &doc) != nil {
+			return
+		}
+
+		schema := &Schema{Definitions: make(map[string]*Definition, len(doc))}
+		for id, value := range doc {
+			schema.Definitions[id] = &Definition{Type: "string", Value: value}
+		}
+		schema.StateModel = &StateModel{Derived: map[string]*DerivedDef{
+			"derived": {Eval: expr},
+		}}
+
+		engine := NewEngine(schema)
+		engine.resolve(expr)
+		engine.getVar("derived")
+		engine.isTruthy(engine.resolve(map[string]any{"some": []any{map[string]any{"var": "list"}, expr}}))
+	})
+}
+
+func FuzzRun(f *testing.F) {
+	for _, expr := range fuzzExprs {
+		f.Add(`{"definitions": {"a": {"type": "number", "value": 3}, "s": {"type": "string", "value": "x"}, "out": {"type": "string"}},
+			"state_model": {"derived": {"d": {"eval": ` + expr + `}}},
+			"logic_tree": [{"id": "r", "when": ` + expr + `, "then": {"set": {"out": ` + expr + `}, "ui_modify": {"a": {"visible": ` + expr + `}}}}]}`)
+	}
+	// A null definition written by a rule used to dereference nil in setDefinitionValue
+	f.Add(`{"definitions": {"a": null}, "logic_tree": [null, {"id": "r", "when": true, "then": {"set": {"a": 1}, "ui_modify": {"a": null}}}]}`)
+	f.Add(`{"definitions": {"n": {"type": "number", "value": 1, "min": 5, "max": 1}}, "logic_tree": [{"id": "r", "when": "n % 2 == 1 and upper(s) in ['X']", "then": {"set": {"n": "n + 1"}}}]}`)
+
+	date := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, schemaJSON string) {
+		_, err := Run(schemaJSON, date)
+		if err != nil && strings.HasPrefix(err.Error(), "internal error") {
+			t.Fatalf("Run panicked: %v\nschema: %s", err, schemaJSON)
+		}
+	})
+}
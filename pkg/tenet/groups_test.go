@@ -0,0 +1,68 @@
+package tenet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFieldGroups(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	schemaFor := func(account, routing, email, phone string) string {
+		return `{
+			"definitions": {
+				"bank_account": {"type": "string", "value": ` + account + `},
+				"routing_number": {"type": "string", "value": ` + routing + `},
+				"email": {"type": "string", "value": ` + email + `},
+				"phone": {"type": "string", "value": ` + phone + `}
+			},
+			"require_together": [
+				{"id": "bank_details", "fields": ["bank_account", "routing_number"], "law_ref": "Payments Act §2"}
+			],
+			"require_one_of": [
+				{"id": "contact", "fields": ["email", "phone"]}
+			]
+		}`
+	}
+
+	t.Run("all satisfied", func(t *testing.T) {
+		result, err := Run(schemaFor(`"123"`, `"456"`, `"a@b.se"`, `null`), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		assertEqual(t, schema.Status, StatusReady)
+		assertEqual(t, len(schema.Errors), 0)
+	})
+
+	t.Run("empty together group is fine", func(t *testing.T) {
+		result, err := Run(schemaFor(`null`, `""`, `null`, `"070"`), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		assertEqual(t, parseResult(t, result).Status, StatusReady)
+	})
+
+	t.Run("partial together group is incomplete", func(t *testing.T) {
+		result, err := Run(schemaFor(`"123"`, `null`, `"a@b.se"`, `null`), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		assertEqual(t, schema.Status, StatusIncomplete)
+		assertEqual(t, len(schema.Errors), 1)
+		assertEqual(t, schema.Errors[0].FieldID, "routing_number")
+		assertEqual(t, schema.Errors[0].Kind, ErrMissingRequired)
+		assertHasErrorWithLawRef(t, schema, "Payments Act §2")
+	})
+
+	t.Run("one_of group unsatisfied", func(t *testing.T) {
+		result, err := Run(schemaFor(`null`, `null`, `""`, `null`), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		assertEqual(t, schema.Status, StatusIncomplete)
+		assertEqual(t, len(schema.Errors), 1)
+		assertEqual(t, schema.Errors[0].RuleID, "contact")
+	})
+}
@@ -0,0 +1,72 @@
+package tenet
+
+import (
+	"slices"
+	"strings"
+)
+
+// WithJurisdiction evaluates the document for a jurisdiction code (e.g., "US-CA" or "SE"),
+// overriding the document's own jurisdiction. See Rule.Jurisdictions and DerivedDef.ByJurisdiction.
+func WithJurisdiction(code string) RunOption {
+	return func(c *runConfig) {
+		c.jurisdiction = code
+	}
+}
+
+// jurisdictionCovers reports whether a scope applies to the active jurisdiction: the same
+// code or a parent of it ("US" covers "US-CA"). Codes compare case-insensitively.
+func jurisdictionCovers(scope, active string) bool {
+	if active == "" {
+		return false
+	}
+	return strings.EqualFold(scope, active) ||
+		(len(active) > len(scope) && active[len(scope)] == '-' && strings.EqualFold(scope, active[:len(scope)]))
+}
+
+// applyJurisdiction selects the logic for the active jurisdiction: rules scoped to other
+// jurisdictions are disabled, and derived fields with a by_jurisdiction formula for it use
+// that formula instead of eval. The most specific matching code wins ("US-CA" over "US").
+// Without an active jurisdiction, scoped rules are disabled and eval applies.
+func (e *Engine) applyJurisdiction(active string) {
+	for _, rule := range e.schema.LogicTree {
+		if rule == nil || len(rule.Jurisdictions) == 0 {
+			continue
+		}
+		if !slices.ContainsFunc(rule.Jurisdictions, func(scope string) bool { return jurisdictionCovers(scope, active) }) {
+			rule.Disabled = true
+		}
+	}
+
+	if e.schema.StateModel == nil || active == "" {
+		return
+	}
+	// Derived definitions may be shared with a CompiledSchema, so replace them instead of writing into them
+	var derived map[string]*DerivedDef
+	for _, name := range sortedIDs(e.schema.StateModel.Derived) {
+		def := e.schema.StateModel.Derived[name]
+		if def == nil || len(def.ByJurisdiction) == 0 {
+			continue
+		}
+		best := ""
+		for _, scope := range sortedIDs(def.ByJurisdiction) {
+			if jurisdictionCovers(scope, active) && len(scope) > len(best) {
+				best = scope
+			}
+		}
+		if best == "" {
+			continue
+		}
+		if derived == nil {
+			derived = make(map[string]*DerivedDef, len(e.schema.StateModel.Derived))
+			for k, v := range e.schema.StateModel.Derived {
+				derived[k] = v
+			}
+		}
+		derived[name] = &DerivedDef{Eval: def.ByJurisdiction[best]}
+	}
+	if derived != nil {
+		model := *e.schema.StateModel
+		model.Derived = derived
+		e.schema.StateModel = &model
+	}
+}
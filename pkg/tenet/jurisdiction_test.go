@@ -0,0 +1,91 @@
+package tenet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJurisdictionScoping(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"income": {"type": "number", "value": 100000},
+			"needs_w2": {"type": "boolean", "value": false},
+			"needs_ca_form": {"type": "boolean", "value": false}
+		},
+		"logic_tree": [
+			{"id": "us", "jurisdictions": ["US"], "when": {"==": [1, 1]}, "then": {"set": {"needs_w2": true}}},
+			{"id": "ca", "jurisdictions": ["US-CA"], "when": {"==": [1, 1]}, "then": {"set": {"needs_ca_form": true}}}
+		],
+		"state_model": {
+			"inputs": ["income"],
+			"derived": {
+				"tax": {
+					"eval": {"*": [{"var": "income"}, 0.2]},
+					"by_jurisdiction": {
+						"US": {"*": [{"var": "income"}, 0.25]},
+						"US-CA": "= income * 0.3"
+					}
+				}
+			}
+		}
+	}`
+
+	run := func(opts ...RunOption) *Schema {
+		t.Helper()
+		result, err := Run(input, date, opts...)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return parseResult(t, result)
+	}
+
+	t.Run("no jurisdiction", func(t *testing.T) {
+		schema := run()
+		assertEqual(t, schema.Definitions["needs_w2"].Value, false)
+		assertEqual(t, schema.Definitions["tax"].Value, 20000.0)
+		assertEqual(t, schema.Jurisdiction, "")
+	})
+
+	t.Run("parent jurisdiction", func(t *testing.T) {
+		schema := run(WithJurisdiction("US-NY"))
+		assertEqual(t, schema.Definitions["needs_w2"].Value, true)
+		assertEqual(t, schema.Definitions["needs_ca_form"].Value, false)
+		assertEqual(t, schema.Definitions["tax"].Value, 25000.0)
+		assertEqual(t, schema.Jurisdiction, "US-NY")
+	})
+
+	t.Run("most specific formula wins", func(t *testing.T) {
+		schema := run(WithJurisdiction("us-ca"))
+		assertEqual(t, schema.Definitions["needs_w2"].Value, true)
+		assertEqual(t, schema.Definitions["needs_ca_form"].Value, true)
+		assertEqual(t, schema.Definitions["tax"].Value, 30000.0)
+	})
+
+	t.Run("code prefix is not a parent", func(t *testing.T) {
+		schema := run(WithJurisdiction("USA"))
+		assertEqual(t, schema.Definitions["needs_w2"].Value, false)
+	})
+
+	t.Run("verify replays the recorded jurisdiction", func(t *testing.T) {
+		compiled, err := Compile(input)
+		if err != nil {
+			t.Fatalf("Compile failed: %v", err)
+		}
+		completed, err := Run(input, date, WithJurisdiction("US-CA"))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if vr := VerifyWithCompiled(completed, compiled); !vr.Valid {
+			t.Fatalf("expected valid, got %+v", vr.Issues)
+		}
+		// The compiled base must not keep the selected formula
+		plain, err := Run(input, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if vr := VerifyWithCompiled(plain, compiled); !vr.Valid {
+			t.Fatalf("expected valid, got %+v", vr.Issues)
+		}
+	})
+}
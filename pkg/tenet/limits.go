@@ -0,0 +1,222 @@
+package tenet
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded is wrapped by every *LimitError, for errors.Is checks.
+var ErrLimitExceeded = errors.New("schema complexity limit exceeded")
+
+// Limits are hard caps on schema size and shape, enforced after parsing so a service
+// rejects an accidentally deployed megaschema before evaluating it. Zero means unlimited.
+type Limits struct {
+	MaxDefinitions int // Entries in definitions
+	MaxRules       int // Rules in logic_tree, counting each decision table row as a rule
+	MaxDepth       int // Nesting depth of any JSON-logic expression (conditions, set values, derived, display)
+	MaxOptions     int // Options of any single definition
+
+	// Approximate bytes one evaluation may allocate for values set by rules, definitions they
+	// create, derived values, concatenated text and errors. Unlike the caps above it is
+	// enforced while running: the run is aborted as soon as the budget is spent.
+	MaxAllocation int
+}
+
+// LimitError reports which limit a schema exceeded.
+type LimitError struct {
+	Limit  string // "max_definitions", "max_rules", "max_depth", "max_options" or "max_allocation"
+	Max    int    // Configured limit
+	Actual int    // What the schema has
+	Where  string // Definition or rule ID, when the limit applies to one
+}
+
+func (e *LimitError) Error() string {
+	if e.Where != "" {
+		return fmt.Sprintf("%s: %s is %d in '%s' (limit %d)", ErrLimitExceeded, e.Limit, e.Actual, e.Where, e.Max)
+	}
+	return fmt.Sprintf("%s: %s is %d (limit %d)", ErrLimitExceeded, e.Limit, e.Actual, e.Max)
+}
+
+// Unwrap makes errors.Is(err, ErrLimitExceeded) hold.
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Check returns a *LimitError for the first limit the schema exceeds, or nil.
+// Infix expression strings count as one level; they are bounded by document size.
+func (l Limits) Check(s *Schema) error {
+	if l.MaxDefinitions > 0 && len(s.Definitions) > l.MaxDefinitions {
+		return &LimitError{Limit: "max_definitions", Max: l.MaxDefinitions, Actual: len(s.Definitions)}
+	}
+	rules := len(s.LogicTree)
+	for _, table := range s.DecisionTables {
+		if table != nil {
+			rules += len(table.Rows)
+		}
+	}
+	if l.MaxRules > 0 && rules > l.MaxRules {
+		return &LimitError{Limit: "max_rules", Max: l.MaxRules, Actual: rules}
+	}
+
+	for _, id := range sortedIDs(s.Definitions) {
+		def := s.Definitions[id]
+		if def == nil {
+			continue
+		}
+		if l.MaxOptions > 0 && len(def.Options) > l.MaxOptions {
+			return &LimitError{Limit: "max_options", Max: l.MaxOptions, Actual: len(def.Options), Where: id}
+		}
+		if err := l.checkDepth(id, def.LabelExpr, def.UIMessageExpr); err != nil {
+			return err
+		}
+	}
+
+	if err := l.checkDepth("summary", summaryExprs(s.Summary)...); err != nil {
+		return err
+	}
+
+	if l.MaxDepth <= 0 {
+		return nil
+	}
+	for _, rule := range s.LogicTree {
+		if rule == nil {
+			continue
+		}
+		exprs := []any{rule.When, rule.WhenAny, rule.Unless}
+		if rule.Then != nil {
+			for _, val := range rule.Then.Set {
+				exprs = append(exprs, val)
+			}
+		}
+		if err := l.checkDepth(rule.ID, exprs...); err != nil {
+			return err
+		}
+	}
+	if s.StateModel != nil {
+		for _, name := range sortedIDs(s.StateModel.Derived) {
+			if derived := s.StateModel.Derived[name]; derived != nil {
+				exprs := []any{derived.Eval}
+				for _, scope := range sortedIDs(derived.ByJurisdiction) {
+					exprs = append(exprs, derived.ByJurisdiction[scope])
+				}
+				if err := l.checkDepth(name, exprs...); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkDepth applies MaxDepth to expressions belonging to where.
+func (l Limits) checkDepth(where string, exprs ...any) error {
+	if l.MaxDepth <= 0 {
+		return nil
+	}
+	for _, expr := range exprs {
+		if depth := exprDepth(expr, l.MaxDepth+1); depth > l.MaxDepth {
+			return &LimitError{Limit: "max_depth", Max: l.MaxDepth, Actual: depth, Where: where}
+		}
+	}
+	return nil
+}
+
+// exprDepth returns the nesting depth of a JSON value, stopping once it reaches stop.
+// Scalars have depth 0; each object or array level adds one.
+func exprDepth(node any, stop int) int {
+	if stop <= 0 {
+		return 0
+	}
+	deepest := 0
+	switch v := node.(type) {
+	case map[string]any:
+		for _, val := range v {
+			deepest = max(deepest, exprDepth(val, stop-1))
+		}
+	case []any:
+		for _, elem := range v {
+			deepest = max(deepest, exprDepth(elem, stop-1))
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
+
+// charge counts bytes against the run's MaxAllocation budget, aborting the run with a
+// *LimitError once it is spent. where names the field, rule or operator responsible.
+func (e *Engine) charge(where string, bytes int) {
+	if e.allocMax <= 0 {
+		return
+	}
+	e.allocUsed += bytes
+	if e.allocUsed > e.allocMax {
+		panic(&LimitError{Limit: "max_allocation", Max: e.allocMax, Actual: e.allocUsed, Where: where})
+	}
+}
+
+// chargeValue charges the approximate size of a value. Values built by rules can share
+// sub-structures (an array holding the same field twice), so sizes are counted as they
+// would be marshaled, and the walk stops as soon as the budget is spent.
+func (e *Engine) chargeValue(where string, value any) {
+	if e.allocMax <= 0 {
+		return
+	}
+	e.charge(where, approxSize(value, e.allocMax-e.allocUsed+1))
+}
+
+// approxSize estimates the bytes a value occupies, stopping once it exceeds stop.
+func approxSize(value any, stop int) int {
+	switch v := value.(type) {
+	case string:
+		return len(v) + 16
+	case []any:
+		size := 24
+		for _, elem := range v {
+			if size += approxSize(elem, stop-size); size > stop {
+				return size
+			}
+		}
+		return size
+	case map[string]any:
+		size := 48
+		for key, val := range v {
+			if size += len(key) + 16 + approxSize(val, stop-size); size > stop {
+				return size
+			}
+		}
+		return size
+	default:
+		return 8
+	}
+}
+
+// recovered turns a panic recovered from an evaluation into its error: the *LimitError
+// of a spent allocation budget, or an internal error.
+func recovered(r any) error {
+	if le, ok := r.(*LimitError); ok {
+		return le
+	}
+	return fmt.Errorf("internal error: %v", r)
+}
+
+// WithLimits rejects documents that exceed the given limits before evaluating them, and
+// aborts evaluations that exceed MaxAllocation. Run returns the *LimitError.
+func WithLimits(l Limits) RunOption {
+	return func(c *runConfig) {
+		c.limits = &l
+	}
+}
+
+// CompileWithLimits is Compile that also enforces limits on the base schema. Options are
+// passed to Compile.
+func CompileWithLimits(jsonText string, l Limits, opts ...RunOption) (*CompiledSchema, error) {
+	compiled, err := Compile(jsonText, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.Check(compiled.base); err != nil {
+		return nil, err
+	}
+	return compiled, nil
+}
@@ -0,0 +1,35 @@
+package tenet
+
+// FieldChange describes a definition value that changed during evaluation.
+type FieldChange struct {
+	FieldID string `json:"field_id"`          // Definition ID (or dot path for nested sets)
+	Old     any    `json:"old"`               // Value before the change
+	New     any    `json:"new"`               // Value after the change
+	RuleID  string `json:"rule_id,omitempty"` // Rule that set it (empty for derived and on_hide changes)
+}
+
+// OnFieldChanged registers a listener called synchronously whenever a definition value changes.
+// Listeners let streaming transports push incremental updates while a large schema is still evaluating.
+func (e *Engine) OnFieldChanged(fn func(FieldChange)) {
+	if fn != nil {
+		e.fieldListeners = append(e.fieldListeners, fn)
+	}
+}
+
+// OnErrorAdded registers a listener called synchronously whenever an error is recorded.
+func (e *Engine) OnErrorAdded(fn func(ValidationError)) {
+	if fn != nil {
+		e.errorListeners = append(e.errorListeners, fn)
+	}
+}
+
+// notifyFieldChanged fires field listeners if the value actually changed.
+func (e *Engine) notifyFieldChanged(fieldID string, old, new any, ruleID string) {
+	if len(e.fieldListeners) == 0 || e.compareEqual(old, new) {
+		return
+	}
+	change := FieldChange{FieldID: fieldID, Old: old, New: new, RuleID: ruleID}
+	for _, fn := range e.fieldListeners {
+		fn(change)
+	}
+}
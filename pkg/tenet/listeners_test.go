@@ -0,0 +1,83 @@
+package tenet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFieldAndErrorListeners(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"income": {"type": "number", "value": 50000},
+			"tier": {"type": "string", "value": "basic"},
+			"notes": {"type": "string", "value": "draft", "on_hide": "clear"},
+			"age": {"type": "number", "value": 15, "min": 18}
+		},
+		"logic_tree": [
+			{"id": "premium", "when": {">": [{"var": "income"}, 40000]}, "then": {"set": {"tier": "premium"}}},
+			{"id": "same", "when": {">": [{"var": "income"}, 0]}, "then": {"set": {"income": 50000}}},
+			{"id": "hide_notes", "when": {">": [{"var": "income"}, 0]}, "then": {"ui_modify": {"notes": {"visible": false}}}}
+		],
+		"state_model": {
+			"derived": {"tax": {"eval": {"*": [{"var": "income"}, 0.25]}}}
+		}
+	}`
+
+	var changes []FieldChange
+	var errs []ValidationError
+	_, err := Run(input, date,
+		WithFieldListener(func(c FieldChange) { changes = append(changes, c) }),
+		WithErrorListener(func(e ValidationError) { errs = append(errs, e) }),
+	)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	byField := make(map[string]FieldChange)
+	for _, c := range changes {
+		if _, seen := byField[c.FieldID]; !seen {
+			byField[c.FieldID] = c
+		}
+	}
+
+	assertEqual(t, byField["tier"].RuleID, "premium")
+	assertEqual(t, byField["tier"].Old, any("basic"))
+	assertEqual(t, byField["tier"].New, any("premium"))
+	assertEqual(t, byField["tax"].New, any(12500.0))
+	assertEqual(t, byField["notes"].Old, any("draft"))
+	if byField["notes"].New != nil {
+		t.Errorf("expected notes cleared, got %v", byField["notes"].New)
+	}
+	// Setting a field to its current value is not a change
+	if _, ok := byField["income"]; ok {
+		t.Errorf("unexpected change for unchanged income: %+v", byField["income"])
+	}
+
+	if len(errs) != 1 || errs[0].FieldID != "age" {
+		t.Fatalf("expected one error for age, got %+v", errs)
+	}
+}
+
+func TestEngineListenersRegisterDirectly(t *testing.T) {
+	schema := &Schema{Definitions: map[string]*Definition{
+		"a": {Type: "number", Value: 1.0},
+	}}
+	engine := NewEngine(schema)
+
+	var got []FieldChange
+	engine.OnFieldChanged(func(c FieldChange) { got = append(got, c) })
+	engine.OnFieldChanged(nil) // ignored
+
+	engine.setDefinitionValue("a", 2.0, "r1")
+	engine.setDefinitionValue("b", "new", "r2")
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", got)
+	}
+	assertEqual(t, got[0], FieldChange{FieldID: "a", Old: 1.0, New: 2.0, RuleID: "r1"})
+	assertEqual(t, got[1].FieldID, "b")
+	if got[1].Old != nil {
+		t.Errorf("expected nil old value for new field, got %v", got[1].Old)
+	}
+}
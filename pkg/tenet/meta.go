@@ -0,0 +1,45 @@
+package tenet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// RunMeta makes a stored result self-describing: which schema and engine produced it,
+// for which effective date, and when. Run only adds it with WithMeta.
+type RunMeta struct {
+	SchemaHash    string `json:"schema_hash,omitempty"`   // CompiledSchema.Hash() of the base schema passed to WithMeta
+	InputHash     string `json:"input_hash"`              // SHA-256 of the document passed to Run
+	Protocol      string `json:"protocol,omitempty"`      // The document's protocol identifier
+	LogicVersion  string `json:"logic_version,omitempty"` // logic_version selected by temporal_map
+	EffectiveDate string `json:"effective_date"`          // Date the logic was evaluated for (YYYY-MM-DD)
+	EvaluatedAt   string `json:"evaluated_at"`            // RFC 3339 time of evaluation (UTC)
+	EngineVersion string `json:"engine_version"`          // Version of the engine that evaluated the document
+}
+
+// WithMeta adds a meta block describing the evaluation to the output. base is the schema
+// the document was created from, recorded by hash; it may be nil when there is none.
+func WithMeta(base *CompiledSchema) RunOption {
+	return func(c *runConfig) {
+		c.meta = true
+		c.metaBase = base
+	}
+}
+
+// newRunMeta describes an evaluation of jsonText (already run into schema) for date.
+func newRunMeta(jsonText string, schema *Schema, date time.Time, base *CompiledSchema) *RunMeta {
+	sum := sha256.Sum256([]byte(jsonText))
+	meta := &RunMeta{
+		InputHash:     hex.EncodeToString(sum[:]),
+		Protocol:      schema.Protocol,
+		LogicVersion:  schema.ActiveVersion,
+		EffectiveDate: date.Format("2006-01-02"),
+		EvaluatedAt:   time.Now().UTC().Format(time.RFC3339),
+		EngineVersion: EngineVersion,
+	}
+	if base != nil {
+		meta.SchemaHash = base.Hash()
+	}
+	return meta
+}
@@ -0,0 +1,103 @@
+package tenet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Normalizer cleans up a definition value before logic and validation run.
+// It receives the raw value (possibly nil) and returns the replacement.
+type Normalizer func(value any) any
+
+// builtinNormalizers are available to every schema by name.
+var builtinNormalizers = map[string]Normalizer{
+	"trim":  normalizeString(strings.TrimSpace),
+	"lower": normalizeString(strings.ToLower),
+	"upper": normalizeString(strings.ToUpper),
+	"collapse_spaces": normalizeString(func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	}),
+	"date": normalizeDate,
+}
+
+// normalizeString lifts a string transform into a Normalizer that leaves non-strings alone.
+func normalizeString(fn func(string) string) Normalizer {
+	return func(value any) any {
+		if s, ok := value.(string); ok {
+			return fn(s)
+		}
+		return value
+	}
+}
+
+// normalizeDate canonicalizes common date spellings to YYYY-MM-DD (or RFC 3339 when a time is present).
+// Unparseable values are returned unchanged so type validation can report them.
+func normalizeDate(value any) any {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	s = strings.TrimSpace(s)
+	t, ok := parseDate(s)
+	if !ok {
+		for _, format := range []string{"2006/01/02", "2006.01.02", "20060102"} {
+			if parsed, err := time.Parse(format, s); err == nil {
+				t, ok = parsed, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return value
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// normalizeValues applies each definition's normalizers: type-wide ones configured via
+// WithTypeNormalizers first, then the definition's own `normalize` list.
+// Unknown normalizer names are reported as runtime warnings.
+func (e *Engine) normalizeValues(custom map[string]Normalizer, byType map[string][]string) {
+	if len(byType) == 0 && !e.hasNormalizeLists() {
+		return
+	}
+
+	ids := make([]string, 0, len(e.schema.Definitions))
+	for id := range e.schema.Definitions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		def := e.schema.Definitions[id]
+		if def == nil || def.Value == nil || def.IsReadonly() {
+			continue
+		}
+		names := append(append([]string(nil), byType[def.Type]...), def.Normalize...)
+		for _, name := range names {
+			fn, ok := custom[name]
+			if !ok {
+				fn, ok = builtinNormalizers[name]
+			}
+			if !ok {
+				e.addError(id, "", ErrRuntimeWarning, fmt.Sprintf("Unknown normalizer '%s' on field '%s'", name, id), "")
+				continue
+			}
+			def.Value = fn(def.Value)
+		}
+	}
+}
+
+// hasNormalizeLists reports whether any definition declares `normalize`.
+func (e *Engine) hasNormalizeLists() bool {
+	for _, def := range e.schema.Definitions {
+		if def != nil && len(def.Normalize) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package tenet
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizers(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"country": {"type": "select", "value": " se ", "options": ["SE", "NO"], "normalize": ["trim", "upper"]},
+			"name": {"type": "string", "value": "  Ada   Lovelace ", "normalize": ["collapse_spaces"]},
+			"born": {"type": "date", "value": "1815/12/10", "normalize": ["date"]},
+			"note": {"type": "string", "value": "  untouched  "},
+			"odd": {"type": "string", "value": "x", "normalize": ["no_such_thing"]}
+		},
+		"logic_tree": [
+			{"id": "nordic", "when": {"==": [{"var": "country"}, "SE"]}, "then": {"set": {"note": "nordic"}}}
+		]
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	// Normalized before logic and validation: the select check and the rule both see "SE"
+	assertDefinitionValue(t, schema, "country", "SE")
+	assertDefinitionValue(t, schema, "note", "nordic")
+	assertDefinitionValue(t, schema, "name", "Ada Lovelace")
+	assertDefinitionValue(t, schema, "born", "1815-12-10")
+
+	if len(schema.Errors) != 1 || schema.Errors[0].Kind != ErrRuntimeWarning || schema.Errors[0].FieldID != "odd" {
+		t.Fatalf("expected one runtime warning for 'odd', got %+v", schema.Errors)
+	}
+}
+
+func TestNormalizerOptions(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"email": {"type": "string", "value": "  Ada@Example.COM ", "normalize": ["email"]},
+			"city": {"type": "string", "value": " Lund "},
+			"amount": {"type": "number", "value": 5}
+		}
+	}`
+
+	result, err := Run(input, date,
+		WithTypeNormalizers("string", "trim"),
+		WithNormalizer("email", func(v any) any {
+			if s, ok := v.(string); ok {
+				return strings.ToLower(s)
+			}
+			return v
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	// Type-wide normalizers run before the field's own list
+	assertDefinitionValue(t, schema, "email", "ada@example.com")
+	assertDefinitionValue(t, schema, "city", "Lund")
+	assertDefinitionValue(t, schema, "amount", 5.0)
+}
@@ -2,6 +2,9 @@ package tenet
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -9,6 +12,11 @@ import (
 // executeOperator handles all JSON-logic operators.
 // Returns nil for operations on nil values (nil-safe behavior).
 func (e *Engine) executeOperator(op string, args any) any {
+	if e.decimal && decimalOps[op] && e.currencyOperands(args) {
+		a := e.resolveArgs(args, 2)
+		return decimalArith(op, a[0], a[1])
+	}
+
 	switch op {
 	// === Variable Access ===
 	case "var":
@@ -74,6 +82,50 @@ func (e *Engine) executeOperator(op string, args any) any {
 		a := e.resolveArgs(args, 2)
 		return e.opDivide(a[0], a[1])
 
+	case "%":
+		a := e.resolveArgs(args, 2)
+		return e.opModulo(a[0], a[1])
+
+	case "pow":
+		a := e.resolveArgs(args, 2)
+		return e.opPow(a[0], a[1])
+
+	case "abs":
+		a := e.resolveArgs(args, 1)
+		return e.opAbs(a[0])
+
+	case "round", "floor", "ceil":
+		a := e.resolveArgs(args, 2)
+		return opRound(op, a[0], a[1])
+
+	case "round_currency":
+		a := e.resolveArgs(args, 2)
+		return opRoundCurrency(a[0], a[1])
+
+	// === String Operators ===
+	case "cat", "concat":
+		return e.opConcat(args)
+
+	case "substr":
+		a := e.resolveArgs(args, 3)
+		return e.opSubstr(a[0], a[1], a[2])
+
+	case "lower":
+		a := e.resolveArgs(args, 1)
+		return mapString(a[0], strings.ToLower)
+
+	case "upper":
+		a := e.resolveArgs(args, 1)
+		return mapString(a[0], strings.ToUpper)
+
+	case "trim":
+		a := e.resolveArgs(args, 1)
+		return mapString(a[0], strings.TrimSpace)
+
+	case "length":
+		a := e.resolveArgs(args, 1)
+		return opLength(a[0])
+
 	// === Date Operators ===
 	case "before":
 		a := e.resolveArgs(args, 2)
@@ -83,6 +135,20 @@ func (e *Engine) executeOperator(op string, args any) any {
 		a := e.resolveArgs(args, 2)
 		return e.compareDates(a[0], a[1], func(x, y time.Time) bool { return x.After(y) })
 
+	case "today":
+		return e.date.Format("2006-01-02")
+
+	case "now":
+		return e.date.Format(time.RFC3339)
+
+	case "date_add":
+		a := e.resolveArgs(args, 3)
+		return e.opDateAdd(a[0], a[1], a[2])
+
+	case "date_diff":
+		a := e.resolveArgs(args, 3)
+		return e.opDateDiff(a[0], a[1], a[2])
+
 	// === Collection Operators ===
 	case "in":
 		a := e.resolveArgs(args, 2)
@@ -97,6 +163,33 @@ func (e *Engine) executeOperator(op string, args any) any {
 	case "none":
 		return e.opNone(args)
 
+	// === Aggregation Operators ===
+	case "sum", "avg", "count":
+		return e.opAggregate(op, args)
+
+	case "min", "max":
+		return e.opMinMax(op, args)
+
+	// === Array Transformation Operators ===
+	case "map":
+		return e.opMap(args)
+
+	case "filter":
+		return e.opFilter(args)
+
+	case "reduce":
+		return e.opReduce(args)
+
+	case "merge":
+		return e.opMerge(args)
+
+	// === Presence Operators ===
+	case "missing":
+		return e.opMissing(args)
+
+	case "missing_some":
+		return e.opMissingSome(args)
+
 	default:
 		// Unknown operator - add error and return nil
 		e.addError("", "", ErrRuntimeWarning, fmt.Sprintf("Unknown operator '%s' in logic expression", op), "")
@@ -155,6 +248,103 @@ func (e *Engine) compareDates(a, b any, cmp func(time.Time, time.Time) bool) boo
 	return cmp(aTime, bTime)
 }
 
+// dateUnit returns the unit argument of date_add/date_diff ("days" when omitted).
+// Unknown units are reported as runtime warnings.
+func (e *Engine) dateUnit(op string, unit any) (string, bool) {
+	if unit == nil {
+		return "days", true
+	}
+	name, _ := unit.(string)
+	switch name {
+	case "hours", "days", "weeks", "months", "years":
+		return name, true
+	}
+	e.addError("", "", ErrRuntimeWarning, fmt.Sprintf("Unknown unit '%v' in '%s' (use hours, days, weeks, months or years)", unit, op), "")
+	return "", false
+}
+
+// opDateAdd adds amount units to a date; a negative amount subtracts. Months and years
+// follow the calendar (Jan 31 + 1 month normalizes to Mar 3, as in Go's AddDate); fractional
+// amounts are truncated except for hours. A date without a time stays a date (YYYY-MM-DD)
+// unless hours are added; anything else is returned as RFC 3339.
+// Returns nil if the date or amount is nil or invalid.
+func (e *Engine) opDateAdd(date, amount, unit any) any {
+	t, ok := parseDate(date)
+	if !ok {
+		return nil
+	}
+	n, ok := toFloat(amount)
+	if !ok || math.IsNaN(n) || math.Abs(n) > maxDateAmount {
+		return nil
+	}
+	u, ok := e.dateUnit("date_add", unit)
+	if !ok {
+		return nil
+	}
+
+	switch u {
+	case "hours":
+		t = t.Add(time.Duration(n * float64(time.Hour)))
+	case "days":
+		t = t.AddDate(0, 0, int(n))
+	case "weeks":
+		t = t.AddDate(0, 0, 7*int(n))
+	case "months":
+		t = t.AddDate(0, int(n), 0)
+	case "years":
+		t = t.AddDate(int(n), 0, 0)
+	}
+
+	if s, _ := date.(string); len(s) == len("2006-01-02") && u != "hours" {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// maxDateAmount bounds date_add amounts (a million days is ~2700 years) so durations
+// can't overflow.
+const maxDateAmount = 1e6
+
+// opDateDiff returns the number of whole units from one date to another: positive when
+// to is later, truncated toward zero. Months and years count calendar months, so
+// 2025-01-31 to 2025-02-28 is 0 months. Returns nil if either date is nil or invalid.
+func (e *Engine) opDateDiff(from, to, unit any) any {
+	a, aOk := parseDate(from)
+	b, bOk := parseDate(to)
+	if !aOk || !bOk {
+		return nil
+	}
+	u, ok := e.dateUnit("date_diff", unit)
+	if !ok {
+		return nil
+	}
+
+	switch u {
+	case "hours":
+		return math.Trunc(b.Sub(a).Hours())
+	case "days":
+		return math.Trunc(b.Sub(a).Hours() / 24)
+	case "weeks":
+		return math.Trunc(b.Sub(a).Hours() / (24 * 7))
+	case "months":
+		return float64(monthsBetween(a, b))
+	default:
+		return float64(monthsBetween(a, b) / 12)
+	}
+}
+
+// monthsBetween counts whole calendar months from a to b (negative if b is earlier).
+func monthsBetween(a, b time.Time) int {
+	if b.Before(a) {
+		return -monthsBetween(b, a)
+	}
+	n := (b.Year()-a.Year())*12 + int(b.Month()-a.Month())
+	if n > 0 && a.AddDate(0, n, 0).After(b) {
+		n--
+	}
+	return n
+}
+
 // === Logical Operators ===
 
 // opAnd returns true if all arguments are truthy.
@@ -267,6 +457,235 @@ func (e *Engine) opDivide(a, b any) any {
 	return aNum / bNum
 }
 
+// opModulo returns the remainder of a / b, with the sign of a (as in JavaScript).
+// Returns nil if either is nil or b is zero.
+func (e *Engine) opModulo(a, b any) any {
+	if a == nil || b == nil {
+		return nil
+	}
+	aNum, aOk := toFloat(a)
+	bNum, bOk := toFloat(b)
+	if !aOk || !bOk || bNum == 0 {
+		return nil
+	}
+	return math.Mod(aNum, bNum)
+}
+
+// opPow raises a to the power b. Returns nil if either is nil or the result
+// isn't a finite number (e.g., a negative base with a fractional exponent).
+func (e *Engine) opPow(a, b any) any {
+	if a == nil || b == nil {
+		return nil
+	}
+	aNum, aOk := toFloat(a)
+	bNum, bOk := toFloat(b)
+	if !aOk || !bOk {
+		return nil
+	}
+	result := math.Pow(aNum, bNum)
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return nil
+	}
+	return result
+}
+
+// opAbs returns the absolute value of a number. Returns nil if it is nil.
+func (e *Engine) opAbs(a any) any {
+	if a == nil {
+		return nil
+	}
+	num, ok := toFloat(a)
+	if !ok {
+		return nil
+	}
+	return math.Abs(num)
+}
+
+// opRound rounds a number to the given decimal places (0 if omitted; negative places round
+// to tens, hundreds and so on). round goes half away from zero, floor down and ceil up.
+// Rounding is decimal: the number is taken as its shortest representation, so 1.005
+// rounds to 1.01 although the float is slightly below it. Returns nil if it isn't a number.
+func opRound(op string, value, places any) any {
+	num, ok := toFloat(value)
+	if !ok {
+		return nil
+	}
+	p := 0.0
+	if places != nil {
+		if p, ok = toFloat(places); !ok {
+			return nil
+		}
+	}
+	return roundDecimal(op, num, int(math.Max(-maxRoundPlaces, math.Min(maxRoundPlaces, p))))
+}
+
+// maxRoundPlaces bounds the places accepted by the rounding operators; a float64 carries
+// at most 17 significant digits.
+const maxRoundPlaces = 17
+
+// currencyPlaces lists the ISO 4217 currencies whose minor unit isn't two decimals.
+var currencyPlaces = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// opRoundCurrency rounds an amount half away from zero to a currency's minor unit. The second
+// argument is an ISO 4217 code ("JPY" has no decimals, "KWD" three, most currencies two) or a
+// number of places; it defaults to two.
+func opRoundCurrency(value, currency any) any {
+	places := any(2.0)
+	switch c := currency.(type) {
+	case nil:
+	case string:
+		if n, ok := currencyPlaces[strings.ToUpper(strings.TrimSpace(c))]; ok {
+			places = float64(n)
+		}
+	default:
+		places = c
+	}
+	return opRound("round", value, places)
+}
+
+// roundDecimal rounds num to places decimals in exact decimal arithmetic.
+func roundDecimal(op string, num float64, places int) any {
+	r, ok := exactRat(num)
+	if !ok {
+		return nil
+	}
+	exp := int64(places)
+	if exp < 0 {
+		exp = -exp
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+	if places >= 0 {
+		r.Mul(r, scale)
+	} else {
+		r.Quo(r, scale)
+	}
+
+	// Integer part toward zero, then adjust by the remainder
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		switch op {
+		case "floor":
+			if r.Sign() < 0 {
+				quo.Sub(quo, big.NewInt(1))
+			}
+		case "ceil":
+			if r.Sign() > 0 {
+				quo.Add(quo, big.NewInt(1))
+			}
+		default:
+			// |rem| / denom >= 1/2 rounds away from zero
+			twice := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2))
+			if twice.Cmp(r.Denom()) >= 0 {
+				quo.Add(quo, big.NewInt(int64(r.Sign())))
+			}
+		}
+	}
+
+	result := new(big.Rat).SetInt(quo)
+	if places >= 0 {
+		result.Quo(result, scale)
+	} else {
+		result.Mul(result, scale)
+	}
+	f, _ := result.Float64()
+	if math.IsInf(f, 0) {
+		return nil
+	}
+	return f
+}
+
+// exactRat returns the decimal a float stands for, taken as its shortest representation
+// (0.1 is exactly 1/10). ok is false for NaN and infinities.
+func exactRat(num float64) (*big.Rat, bool) {
+	if math.IsNaN(num) || math.IsInf(num, 0) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(strconv.FormatFloat(num, 'g', -1, 64))
+}
+
+// === String Operators ===
+
+// opConcat joins its arguments as text. Numbers render like computed labels (4200, not
+// 4200.00); null contributes nothing, so optional parts can be concatenated freely.
+func (e *Engine) opConcat(args any) any {
+	arr, ok := args.([]any)
+	if !ok {
+		arr = []any{args}
+	}
+	var sb strings.Builder
+	for _, arg := range arr {
+		text, _ := displayText(e.resolve(arg))
+		e.charge("cat", len(text))
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
+
+// opSubstr returns part of a string, counting characters (not bytes). A negative start
+// counts from the end; a negative length leaves that many characters off the end; no
+// length means the rest of the string. Out-of-range bounds are clamped.
+// Returns nil if s is not a string or start is not a number.
+func (e *Engine) opSubstr(s, start, length any) any {
+	str, ok := s.(string)
+	if !ok {
+		return nil
+	}
+	from, ok := toFloat(start)
+	if !ok {
+		return nil
+	}
+	runes := []rune(str)
+	n := float64(len(runes))
+	// Clamp in float space: converting a huge or NaN float to int is undefined
+	if from < 0 {
+		from += n
+	}
+	begin := math.Min(math.Max(math.Trunc(from), 0), n)
+	end := n
+	if length != nil {
+		count, ok := toFloat(length)
+		if !ok {
+			return nil
+		}
+		if count < 0 {
+			end = n + count
+		} else {
+			end = begin + count
+		}
+		end = math.Min(math.Max(math.Trunc(end), begin), n)
+	}
+	if math.IsNaN(begin) || math.IsNaN(end) {
+		return nil
+	}
+	return string(runes[int(begin):int(end)])
+}
+
+// mapString applies fn to a string. Returns nil for anything else.
+func mapString(v any, fn func(string) string) any {
+	str, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	return fn(str)
+}
+
+// opLength returns the number of characters in a string or elements in an array.
+// Returns nil for anything else.
+func opLength(v any) any {
+	switch x := v.(type) {
+	case string:
+		return float64(len([]rune(x)))
+	case []any:
+		return float64(len(x))
+	}
+	return nil
+}
+
 // === Collection Operators ===
 
 // opSome returns true if ANY element in the array satisfies the condition.
@@ -363,14 +782,282 @@ func (e *Engine) opNone(args any) bool {
 // evalWithContext evaluates a condition with a temporary context value.
 // Used by some/all/none to set the current element as {"var": ""}.
 func (e *Engine) evalWithContext(condition any, contextValue any) bool {
+	return e.isTruthy(e.resolveWithContext(condition, contextValue))
+}
+
+// resolveWithContext evaluates an expression with contextValue as the current element.
+func (e *Engine) resolveWithContext(expr any, contextValue any) any {
 	// Save and restore the context value for {"var": ""}
 	oldContext := e.currentElement
 	e.currentElement = contextValue
-	result := e.isTruthy(e.resolve(condition))
+	result := e.resolve(expr)
 	e.currentElement = oldContext
 	return result
 }
 
+// === Aggregation Operators ===
+
+// aggregateArgs resolves the collection of an aggregation and returns it with the
+// optional per-element expression. ok is false if the collection isn't an array.
+func (e *Engine) aggregateArgs(args any) (items []any, each any, ok bool) {
+	arr, isList := args.([]any)
+	if !isList {
+		arr = []any{args}
+	}
+	if len(arr) == 0 {
+		return nil, nil, false
+	}
+	items, ok = e.resolve(arr[0]).([]any)
+	if len(arr) > 1 {
+		each = arr[1]
+	}
+	return items, each, ok
+}
+
+// elementValues returns each element of items, or each's value for it when given.
+func (e *Engine) elementValues(items []any, each any) []any {
+	if each == nil {
+		return items
+	}
+	values := make([]any, len(items))
+	for i, item := range items {
+		values[i] = e.resolveWithContext(each, item)
+	}
+	return values
+}
+
+// opAggregate computes sum, avg or count over an array, optionally of an expression
+// evaluated per element ({"var": ".amount"} reads the element's amount):
+//
+//	{"sum": [{"var": "line_items"}, {"var": ".amount"}]}
+//	{"count": [{"var": "line_items"}, {">": [{"var": ".amount"}, 1000]}]}
+//
+// sum and avg skip values that aren't numbers; sum of none is 0, avg of none is nil.
+// count without a condition is the array's length. Returns nil if the collection isn't an array.
+func (e *Engine) opAggregate(op string, args any) any {
+	items, each, ok := e.aggregateArgs(args)
+	if !ok {
+		return nil
+	}
+
+	if op == "count" {
+		if each == nil {
+			return float64(len(items))
+		}
+		n := 0
+		for _, item := range items {
+			if e.evalWithContext(each, item) {
+				n++
+			}
+		}
+		return float64(n)
+	}
+
+	total, n := 0.0, 0
+	for _, v := range e.elementValues(items, each) {
+		if num, ok := toFloat(v); ok {
+			total += num
+			n++
+		}
+	}
+	if op == "avg" {
+		if n == 0 {
+			return nil
+		}
+		return total / float64(n)
+	}
+	return total
+}
+
+// opMinMax returns the smallest or largest number. With an array as the first argument it
+// aggregates like sum ({"max": [{"var": "line_items"}, {"var": ".amount"}]}); otherwise
+// it compares its arguments ({"max": [{"var": "a"}, {"var": "b"}, 0]}). Values that aren't
+// numbers are skipped; returns nil if none are left.
+func (e *Engine) opMinMax(op string, args any) any {
+	var values []any
+	if items, each, ok := e.aggregateArgs(args); ok {
+		values = e.elementValues(items, each)
+	} else if arr, isList := args.([]any); isList {
+		values = make([]any, len(arr))
+		for i, arg := range arr {
+			values[i] = e.resolve(arg)
+		}
+	} else {
+		values = []any{e.resolve(args)}
+	}
+
+	var best any
+	for _, v := range values {
+		num, ok := toFloat(v)
+		if !ok {
+			continue
+		}
+		if bestNum, _ := toFloat(best); best == nil || (op == "min" && num < bestNum) || (op == "max" && num > bestNum) {
+			best = num
+		}
+	}
+	return best
+}
+
+// === Array Transformation Operators ===
+
+// opMap returns the value of an expression for each element of an array, so a list of
+// line items becomes a list of their amounts:
+//
+//	{"map": [{"var": "line_items"}, {"*": [{"var": ".price"}, {"var": ".quantity"}]}]}
+//
+// Without an expression the array is returned as is. Returns nil if the collection isn't an array.
+func (e *Engine) opMap(args any) any {
+	items, each, ok := e.aggregateArgs(args)
+	if !ok {
+		return nil
+	}
+	e.charge("map", 16*len(items))
+	values := e.elementValues(items, each)
+	if each != nil {
+		for _, v := range values {
+			e.chargeValue("map", v)
+		}
+	}
+	return values
+}
+
+// opFilter returns the elements of an array that satisfy a condition, in order:
+//
+//	{"filter": [{"var": "line_items"}, {"var": ".taxable"}]}
+//
+// Without a condition the truthy elements are kept. Returns nil if the collection isn't an array.
+func (e *Engine) opFilter(args any) any {
+	items, cond, ok := e.aggregateArgs(args)
+	if !ok {
+		return nil
+	}
+	if cond == nil {
+		cond = map[string]any{"var": ""}
+	}
+	kept := make([]any, 0, len(items))
+	for _, item := range items {
+		if e.evalWithContext(cond, item) {
+			kept = append(kept, item)
+		}
+	}
+	e.charge("filter", 16*len(kept))
+	return kept
+}
+
+// opReduce folds an array into a single value. The expression is evaluated once per
+// element with {"var": ".current"} as the element and {"var": ".accumulator"} as the
+// result so far, starting from the third argument (nil if omitted):
+//
+//	{"reduce": [{"var": "line_items"}, {"+": [{"var": ".accumulator"}, {"var": ".current.amount"}]}, 0]}
+//
+// Returns the initial value for an empty array and nil if the collection isn't an array.
+func (e *Engine) opReduce(args any) any {
+	arr, ok := args.([]any)
+	if !ok || len(arr) < 2 {
+		return nil
+	}
+	items, ok := e.resolve(arr[0]).([]any)
+	if !ok {
+		return nil
+	}
+	var acc any
+	if len(arr) > 2 {
+		acc = e.resolve(arr[2])
+	}
+	for _, item := range items {
+		acc = e.resolveWithContext(arr[1], map[string]any{"current": item, "accumulator": acc})
+		e.chargeValue("reduce", acc)
+	}
+	return acc
+}
+
+// opMerge flattens its arguments one level into a single array:
+// {"merge": [[1, 2], 3, [[4]]]} is [1, 2, 3, [4]].
+func (e *Engine) opMerge(args any) []any {
+	arr, ok := args.([]any)
+	if !ok {
+		arr = []any{args}
+	}
+	merged := make([]any, 0, len(arr))
+	for _, arg := range arr {
+		value := e.resolve(arg)
+		if items, ok := value.([]any); ok {
+			merged = append(merged, items...)
+		} else {
+			merged = append(merged, value)
+		}
+	}
+	e.charge("merge", 16*len(merged))
+	return merged
+}
+
+// === Presence Helpers ===
+
+// opMissing returns the field IDs (or dotted paths) among its arguments that have no value,
+// in order. A value is missing when it is null or "", as for required fields. An empty
+// result is falsy, so a rule can require fields together:
+//
+//	{"if": [{"var": "has_spouse"}, {"missing": ["spouse_name", "spouse_ssn"]}, []]}
+//
+// The keys may also come from one array argument, such as a merge.
+func (e *Engine) opMissing(args any) []any {
+	keys, ok := args.([]any)
+	if !ok {
+		keys = []any{args}
+	}
+	resolved := make([]any, len(keys))
+	for i, key := range keys {
+		resolved[i] = e.resolve(key)
+	}
+	if len(resolved) > 0 {
+		if list, ok := resolved[0].([]any); ok {
+			resolved = list
+		}
+	}
+
+	missing := make([]any, 0)
+	for _, key := range resolved {
+		if path, ok := key.(string); ok && !isFilled(e.lookup(path)) {
+			missing = append(missing, path)
+		}
+	}
+	return missing
+}
+
+// opMissingSome returns the missing fields of a list if fewer than need of them have a
+// value, and an empty array once enough are filled:
+//
+//	{"missing_some": [1, ["phone", "email", "address"]]}
+func (e *Engine) opMissingSome(args any) []any {
+	a := e.resolveArgs(args, 2)
+	need, _ := toFloat(a[0])
+	keys, _ := a[1].([]any)
+
+	missing := e.opMissing([]any{keys})
+	if float64(len(keys)-len(missing)) >= need {
+		return []any{}
+	}
+	return missing
+}
+
+// lookup reads a field like getVar, but a field the schema doesn't define is just absent
+// rather than a runtime warning.
+func (e *Engine) lookup(path string) any {
+	root, _, _ := strings.Cut(path, ".")
+	if root != "" {
+		if _, ok := e.schema.Definitions[root]; !ok {
+			if e.schema.StateModel == nil || e.schema.StateModel.Derived[root] == nil {
+				if target, ok := e.aliasPath(path); ok {
+					return e.lookup(target)
+				}
+				return nil
+			}
+		}
+	}
+	return e.getVar(path)
+}
+
 // opIn checks if needle is in haystack (array or string).
 func (e *Engine) opIn(needle, haystack any) bool {
 	if needle == nil || haystack == nil {
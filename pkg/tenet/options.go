@@ -0,0 +1,317 @@
+package tenet
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// RunOption configures optional behavior of Run.
+// Options are applied in order; later options override earlier ones.
+type RunOption func(*runConfig)
+
+// runConfig holds the resolved options for a single Run.
+type runConfig struct {
+	encrypter   Encrypter // Encrypts/decrypts sensitive values (nil = plaintext)
+	includeTags []string  // Only rules carrying one of these tags are evaluated (empty = all)
+	excludeTags []string  // Rules carrying any of these tags are skipped
+	features    []string  // Enabled feature flags (rules with another `feature` are skipped)
+
+	jurisdiction string // Overrides the document's jurisdiction (empty = use the document's)
+
+	compact bool          // Marshal without indentation
+	verbose bool          // Marshal UI defaults (visible: true) instead of omitting them
+	patch   bool          // Return a JSON Patch from the input to the result
+	buffer  *bytes.Buffer // Reused for marshaling (nil = allocate per run)
+	decoder Decoder       // Unmarshals input documents (nil = encoding/json)
+	limits  *Limits       // Complexity limits checked after unmarshal (nil = none)
+	partial bool          // Skip and report malformed parts instead of failing to unmarshal
+	strict  bool          // Reject documents with keys no schema field accepts
+
+	rejectDuplicates bool // Reject documents that repeat a key within an object
+
+	skipped []ValidationError // Parts dropped by partial decoding, reported by runSchema
+
+	meta     bool            // Add a meta block to the output
+	metaBase *CompiledSchema // Base schema recorded in the meta block (nil = none)
+
+	completion bool // Add a completion block to the output
+
+	attestationFields bool // Mirror rich attestations as readonly definitions
+	provenance        bool // Record the rule behind each set value as set_by
+	fixedPoint        int  // Maximum logic/derived passes until values settle (0 = a single pass)
+
+	decimal        bool // Evaluate currency arithmetic in exact decimal
+	currencyPlaces int  // Decimals currency values are rounded to in decimal mode
+
+	fieldListeners []func(FieldChange)         // Registered on the engine via OnFieldChanged
+	errorListeners []func(ValidationError)     // Registered on the engine via OnErrorAdded
+	ruleTimer      func(string, time.Duration) // Receives the guard+action time of each evaluated rule
+	tracer         PhaseTracer                 // Receives the start and end of each evaluation phase
+
+	normalizers     map[string]Normalizer // Custom normalizers by name (override built-ins)
+	typeNormalizers map[string][]string   // Normalizer names applied to every field of a type
+
+	index   *schemaIndex  // Lookups precomputed by Compile (nil = compute per run)
+	session *sessionCache // Rule and derived results reused by a Session (nil = evaluate everything)
+}
+
+// newRunConfig applies the given options over the defaults.
+func newRunConfig(opts []RunOption) runConfig {
+	var cfg runConfig
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	return cfg
+}
+
+// WithEncrypter decrypts sensitive values after unmarshal and encrypts them again before marshal.
+// See Encrypter for the envelope format.
+func WithEncrypter(enc Encrypter) RunOption {
+	return func(c *runConfig) {
+		c.encrypter = enc
+	}
+}
+
+// WithTags evaluates only rules that carry at least one of the given tags.
+// Untagged rules are skipped while an include list is set.
+func WithTags(tags ...string) RunOption {
+	return func(c *runConfig) {
+		c.includeTags = append(c.includeTags, tags...)
+	}
+}
+
+// WithoutTags skips rules that carry any of the given tags.
+// Exclusion wins over inclusion when a rule matches both.
+func WithoutTags(tags ...string) RunOption {
+	return func(c *runConfig) {
+		c.excludeTags = append(c.excludeTags, tags...)
+	}
+}
+
+// Decoder unmarshals a JSON document into v, with the semantics of json.Unmarshal.
+// Drop-in replacements such as sonic.Unmarshal or goccy/go-json's Unmarshal match this
+// signature directly.
+type Decoder func(data []byte, v any) error
+
+// WithDecoder decodes input documents with d instead of encoding/json. Decoding dominates
+// Run time for very large documents (hundreds of fields), so a faster library can pay off
+// there; output is still produced by encoding/json.
+func WithDecoder(d Decoder) RunOption {
+	return func(c *runConfig) {
+		c.decoder = d
+	}
+}
+
+// unmarshal decodes data with the configured decoder.
+func (c runConfig) unmarshal(data []byte, v any) error {
+	if c.decoder != nil {
+		return c.decoder(data, v)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WithCompactOutput returns the result without indentation, which is smaller and cheaper to produce.
+func WithCompactOutput() RunOption {
+	return func(c *runConfig) {
+		c.compact = true
+	}
+}
+
+// WithVerboseOutput emits UI defaults such as "visible": true on every field. By default
+// Run omits them, since a field without "visible" is visible, which shrinks the payload
+// for reactive clients that re-render on every change.
+func WithVerboseOutput() RunOption {
+	return func(c *runConfig) {
+		c.verbose = true
+	}
+}
+
+// WithBuffer marshals the result into buf, reusing its capacity across runs.
+// The buffer is reset first; it must not be shared between concurrent runs.
+func WithBuffer(buf *bytes.Buffer) RunOption {
+	return func(c *runConfig) {
+		c.buffer = buf
+	}
+}
+
+// WithFieldListener registers fn to be called whenever a definition value changes during Run.
+func WithFieldListener(fn func(FieldChange)) RunOption {
+	return func(c *runConfig) {
+		c.fieldListeners = append(c.fieldListeners, fn)
+	}
+}
+
+// WithErrorListener registers fn to be called whenever an error is recorded during Run.
+func WithErrorListener(fn func(ValidationError)) RunOption {
+	return func(c *runConfig) {
+		c.errorListeners = append(c.errorListeners, fn)
+	}
+}
+
+// WithRuleTimings calls fn with the time spent evaluating each rule (guards and actions).
+// Intended for profiling; it adds a clock read per rule.
+func WithRuleTimings(fn func(ruleID string, d time.Duration)) RunOption {
+	return func(c *runConfig) {
+		c.ruleTimer = fn
+	}
+}
+
+// PhaseTracer is called when a phase of an evaluation starts and returns the function that
+// ends it. Run's phases are "parse", "temporal" (branch selection, pruning and input
+// normalization), "derived", "logic", "validate" and "marshal"; "derived" runs twice, before
+// and after the logic tree. VerifyWithCompiled traces "parse", a "replay" per iteration with
+// the run phases nested inside, and "final_state". Phases end in reverse order of starting,
+// so an adapter can map them onto spans of a tracing library.
+type PhaseTracer func(phase string) (end func())
+
+// WithTracer reports the phases of each evaluation to t, to attribute latency in hosted
+// deployments. Compile accepts it too, for VerifyWithCompiled.
+func WithTracer(t PhaseTracer) RunOption {
+	return func(c *runConfig) {
+		c.tracer = t
+	}
+}
+
+// start begins a phase and returns the function that ends it; a nil tracer does nothing.
+func (t PhaseTracer) start(phase string) func() {
+	if t == nil {
+		return func() {}
+	}
+	return t(phase)
+}
+
+// WithNormalizer registers a custom normalizer usable by name in `normalize` lists
+// and WithTypeNormalizers. It overrides a built-in of the same name.
+func WithNormalizer(name string, fn Normalizer) RunOption {
+	return func(c *runConfig) {
+		if c.normalizers == nil {
+			c.normalizers = make(map[string]Normalizer)
+		}
+		c.normalizers[name] = fn
+	}
+}
+
+// WithTypeNormalizers applies the named normalizers to every field of the given type
+// (e.g., WithTypeNormalizers("string", "trim")), before any per-field `normalize` list.
+func WithTypeNormalizers(typ string, names ...string) RunOption {
+	return func(c *runConfig) {
+		if c.typeNormalizers == nil {
+			c.typeNormalizers = make(map[string][]string)
+		}
+		c.typeNormalizers[typ] = append(c.typeNormalizers[typ], names...)
+	}
+}
+
+// WithFeatures enables feature flags. A rule that declares `feature` only runs when its flag
+// is enabled, which allows staged rollouts of new logic against live traffic.
+func WithFeatures(flags ...string) RunOption {
+	return func(c *runConfig) {
+		c.features = append(c.features, flags...)
+	}
+}
+
+// Options is the struct form of the run options, for callers that build their configuration
+// as data (from a config file or request) rather than as a list of RunOption values. Each
+// field corresponds to the With* option named in its comment; zero values leave the
+// default behavior.
+type Options struct {
+	EffectiveDate time.Time // Date the document is evaluated for (zero = time.Now())
+
+	Jurisdiction string   // WithJurisdiction
+	Tags         []string // WithTags
+	ExcludeTags  []string // WithoutTags
+	Features     []string // WithFeatures
+
+	Limits    *Limits   // WithLimits
+	Encrypter Encrypter // WithEncrypter
+	Decoder   Decoder   // WithDecoder
+	Partial   bool      // WithPartial
+	Strict    bool      // WithStrictFields
+
+	RejectDuplicateKeys bool // WithRejectDuplicateKeys
+
+	Compact bool          // WithCompactOutput
+	Verbose bool          // WithVerboseOutput
+	Patch   bool          // WithPatchOutput
+	Buffer  *bytes.Buffer // WithBuffer
+
+	Meta              bool            // WithMeta
+	MetaBase          *CompiledSchema // Base schema recorded by WithMeta
+	Completion        bool            // WithCompletion
+	AttestationFields bool            // WithAttestationFields
+	Provenance        bool            // WithProvenance
+	FixedPoint        int             // WithFixedPoint with this limit (0 = single pass)
+
+	DecimalCurrency bool // WithDecimalCurrency
+	CurrencyPlaces  int  // Places passed to WithDecimalCurrency
+
+	FieldListeners []func(FieldChange)                  // WithFieldListener
+	ErrorListeners []func(ValidationError)              // WithErrorListener
+	RuleTimings    func(ruleID string, d time.Duration) // WithRuleTimings
+	Tracer         PhaseTracer                          // WithTracer
+
+	Normalizers     map[string]Normalizer // WithNormalizer
+	TypeNormalizers map[string][]string   // WithTypeNormalizers
+
+	Extra []RunOption // Applied after the fields above
+}
+
+// RunOptions returns the RunOption list equivalent to o, for APIs that take variadic options
+// (Check, CompareRuns, ServiceConfig.RunOptions). EffectiveDate is not an option and is left out.
+func (o Options) RunOptions() []RunOption {
+	var opts []RunOption
+	add := func(on bool, opt RunOption) {
+		if on {
+			opts = append(opts, opt)
+		}
+	}
+	add(o.Jurisdiction != "", WithJurisdiction(o.Jurisdiction))
+	add(len(o.Tags) > 0, WithTags(o.Tags...))
+	add(len(o.ExcludeTags) > 0, WithoutTags(o.ExcludeTags...))
+	add(len(o.Features) > 0, WithFeatures(o.Features...))
+	if o.Limits != nil {
+		opts = append(opts, WithLimits(*o.Limits))
+	}
+	add(o.Encrypter != nil, WithEncrypter(o.Encrypter))
+	add(o.Decoder != nil, WithDecoder(o.Decoder))
+	add(o.Partial, WithPartial())
+	add(o.Strict, WithStrictFields())
+	add(o.RejectDuplicateKeys, WithRejectDuplicateKeys())
+	add(o.Compact, WithCompactOutput())
+	add(o.Verbose, WithVerboseOutput())
+	add(o.Patch, WithPatchOutput())
+	add(o.Buffer != nil, WithBuffer(o.Buffer))
+	add(o.Meta, WithMeta(o.MetaBase))
+	add(o.Completion, WithCompletion())
+	add(o.AttestationFields, WithAttestationFields())
+	add(o.Provenance, WithProvenance())
+	add(o.FixedPoint > 0, WithFixedPoint(o.FixedPoint))
+	add(o.DecimalCurrency, WithDecimalCurrency(o.CurrencyPlaces))
+	for _, fn := range o.FieldListeners {
+		opts = append(opts, WithFieldListener(fn))
+	}
+	for _, fn := range o.ErrorListeners {
+		opts = append(opts, WithErrorListener(fn))
+	}
+	add(o.RuleTimings != nil, WithRuleTimings(o.RuleTimings))
+	add(o.Tracer != nil, WithTracer(o.Tracer))
+	for _, name := range sortedIDs(o.Normalizers) {
+		opts = append(opts, WithNormalizer(name, o.Normalizers[name]))
+	}
+	for _, typ := range sortedIDs(o.TypeNormalizers) {
+		opts = append(opts, WithTypeNormalizers(typ, o.TypeNormalizers[typ]...))
+	}
+	return append(opts, o.Extra...)
+}
+
+// RunWithOptions is Run configured by an Options struct.
+func RunWithOptions(jsonText string, o Options) (string, error) {
+	date := o.EffectiveDate
+	if date.IsZero() {
+		date = time.Now()
+	}
+	return Run(jsonText, date, o.RunOptions()...)
+}
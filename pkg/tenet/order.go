@@ -0,0 +1,98 @@
+package tenet
+
+import "sort"
+
+// OrderedFieldIDs returns the schema's definition IDs in author-intended display order:
+// definitions with an order first (ascending), then the rest. Ties and unordered
+// definitions sort by ID, so the result is stable across JSON round-trips.
+func OrderedFieldIDs(s *Schema) []string {
+	ids := sortedIDs(s.Definitions)
+	sort.SliceStable(ids, func(i, j int) bool {
+		a, b := s.Definitions[ids[i]], s.Definitions[ids[j]]
+		switch {
+		case a == nil || a.Order == nil:
+			return false
+		case b == nil || b.Order == nil:
+			return true
+		default:
+			return *a.Order < *b.Order
+		}
+	})
+	return ids
+}
+
+// focusOrder is the focus_order output: the fields with blocking errors, in the order a
+// user should fix them. Fields are visited in display order, with all fields of a page
+// kept together at the position of the page's first field. Like field_order it is nil
+// unless some definition declares an order or a page.
+func focusOrder(s *Schema) []string {
+	declared := false
+	for _, def := range s.Definitions {
+		if def != nil && (def.Order != nil || def.Page != "") {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		return nil
+	}
+
+	blocking := make(map[string]bool)
+	for _, err := range s.Errors {
+		if isBlocking(err.Kind) && s.Definitions[err.FieldID] != nil {
+			blocking[err.FieldID] = true
+		}
+	}
+	if len(blocking) == 0 {
+		return nil
+	}
+
+	ids := OrderedFieldIDs(s)
+	pageRank := make(map[string]int)
+	rank := make(map[string]int, len(ids))
+	for i, id := range ids {
+		rank[id] = i
+		if def := s.Definitions[id]; def != nil && def.Page != "" {
+			if _, seen := pageRank[def.Page]; !seen {
+				pageRank[def.Page] = i
+			}
+		}
+	}
+	group := func(id string) int {
+		if page := s.Definitions[id].Page; page != "" {
+			return pageRank[page]
+		}
+		return rank[id]
+	}
+
+	var focus []string
+	for _, id := range ids {
+		if blocking[id] {
+			focus = append(focus, id)
+		}
+	}
+	sort.SliceStable(focus, func(i, j int) bool {
+		return group(focus[i]) < group(focus[j])
+	})
+	return focus
+}
+
+// isBlocking reports whether an error kind keeps a document from being READY.
+func isBlocking(kind ErrorKind) bool {
+	switch kind {
+	case ErrTypeMismatch, ErrMissingRequired, ErrConstraintViolation, ErrAttestationIncomplete:
+		return true
+	}
+	return false
+}
+
+// fieldOrder is the field_order output: OrderedFieldIDs when any definition declares
+// an order, nil otherwise (alphabetical order needs no extra payload).
+func fieldOrder(s *Schema) []string {
+	for _, def := range s.Definitions {
+		if def != nil && def.Order != nil {
+			return OrderedFieldIDs(s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// SchemaOverlay is a patch to a schema's decision logic, e.g. one arm of an A/B experiment.
+// Definitions, derived fields and rules are patched by ID with JSON merge patch semantics
+// (RFC 7386): listed keys replace the base's, null removes them, and an ID the base
+// doesn't have is added. Removing a whole definition or derived field takes a null patch;
+// rules are removed with remove_rules.
+type SchemaOverlay struct {
+	ID          string         `json:"id"`                    // REQUIRED: Identity recorded in the output
+	Description string         `json:"description,omitempty"` // What the variant changes
+	Definitions map[string]any `json:"definitions,omitempty"` // Patches by definition ID
+	Derived     map[string]any `json:"derived,omitempty"`     // Patches by state_model.derived ID
+	LogicTree   []any          `json:"logic_tree,omitempty"`  // Rule patches matched by id; new ids are appended
+	RemoveRules []string       `json:"remove_rules,omitempty"`
+}
+
+// AppliedOverlay records an overlay in the schema it was applied to, so every result
+// evaluated from it names the experiment arm and what it touched.
+type AppliedOverlay struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description,omitempty"`
+	Added       []string `json:"added,omitempty"`   // Definitions, derived fields and rules the overlay introduced
+	Changed     []string `json:"changed,omitempty"` // Existing ones it patched
+	Removed     []string `json:"removed,omitempty"` // Ones it removed
+}
+
+// ApplyOverlay is Overlay for an already decoded overlay.
+func ApplyOverlay(baseSchema string, overlay *SchemaOverlay) (string, error) {
+	if overlay == nil || overlay.ID == "" {
+		return "", fmt.Errorf("overlay: id is required")
+	}
+	var schema Schema
+	if err := json.Unmarshal([]byte(baseSchema), &schema); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+	for _, applied := range schema.Overlays {
+		if applied != nil && applied.ID == overlay.ID {
+			return "", fmt.Errorf("overlay '%s' is already applied", overlay.ID)
+		}
+	}
+
+	record := &AppliedOverlay{ID: overlay.ID, Description: overlay.Description}
+
+	if schema.Definitions == nil {
+		schema.Definitions = make(map[string]*Definition)
+	}
+	for _, id := range sortedIDs(overlay.Definitions) {
+		def, err := patchItem(schema.Definitions[id], overlay.Definitions[id])
+		if err != nil {
+			return "", fmt.Errorf("overlay '%s': definition '%s': %w", overlay.ID, id, err)
+		}
+		record.note(id, schema.Definitions[id] != nil, def != nil)
+		if def == nil {
+			delete(schema.Definitions, id)
+		} else {
+			schema.Definitions[id] = def
+		}
+	}
+
+	if len(overlay.Derived) > 0 {
+		if schema.StateModel == nil {
+			schema.StateModel = &StateModel{}
+		}
+		if schema.StateModel.Derived == nil {
+			schema.StateModel.Derived = make(map[string]*DerivedDef)
+		}
+	}
+	for _, id := range sortedIDs(overlay.Derived) {
+		derived, err := patchItem(schema.StateModel.Derived[id], overlay.Derived[id])
+		if err != nil {
+			return "", fmt.Errorf("overlay '%s': derived field '%s': %w", overlay.ID, id, err)
+		}
+		record.note(id, schema.StateModel.Derived[id] != nil, derived != nil)
+		if derived == nil {
+			delete(schema.StateModel.Derived, id)
+		} else {
+			schema.StateModel.Derived[id] = derived
+		}
+	}
+
+	for i, patch := range overlay.LogicTree {
+		fields, ok := patch.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("overlay '%s': logic_tree[%d] must be an object", overlay.ID, i)
+		}
+		id, _ := fields["id"].(string)
+		if id == "" {
+			return "", fmt.Errorf("overlay '%s': logic_tree[%d] has no id", overlay.ID, i)
+		}
+		at := slices.IndexFunc(schema.LogicTree, func(r *Rule) bool { return r != nil && r.ID == id })
+		var base *Rule
+		if at >= 0 {
+			base = schema.LogicTree[at]
+		}
+		rule, err := patchItem(base, patch)
+		if err != nil {
+			return "", fmt.Errorf("overlay '%s': rule '%s': %w", overlay.ID, id, err)
+		}
+		record.note(id, base != nil, true)
+		if at >= 0 {
+			schema.LogicTree[at] = rule
+		} else {
+			schema.LogicTree = append(schema.LogicTree, rule)
+		}
+	}
+
+	for _, id := range overlay.RemoveRules {
+		at := slices.IndexFunc(schema.LogicTree, func(r *Rule) bool { return r != nil && r.ID == id })
+		if at < 0 {
+			return "", fmt.Errorf("overlay '%s': cannot remove unknown rule '%s'", overlay.ID, id)
+		}
+		schema.LogicTree = slices.Delete(schema.LogicTree, at, at+1)
+		record.Removed = append(record.Removed, id)
+	}
+
+	schema.Overlays = append(schema.Overlays, record)
+
+	result, err := json.MarshalIndent(&schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return string(result), nil
+}
+
+// Overlay applies an overlay (JSON, see SchemaOverlay) to a schema and returns the patched schema.
+// The overlay's identity and the IDs it added, changed and removed are appended to the
+// schema's `overlays`, which Run carries into every result. Overlays stack: applying a
+// second one patches the first's result, and applying the same ID twice is an error.
+func Overlay(baseSchema, overlay string) (string, error) {
+	var o SchemaOverlay
+	if err := json.Unmarshal([]byte(overlay), &o); err != nil {
+		return "", fmt.Errorf("unmarshal overlay: %w", err)
+	}
+	return ApplyOverlay(baseSchema, &o)
+}
+
+// note records one patched item. Rules are never removed by patches, only by remove_rules.
+func (a *AppliedOverlay) note(id string, existed, exists bool) {
+	switch {
+	case !existed && exists:
+		a.Added = append(a.Added, id)
+	case existed && exists:
+		a.Changed = append(a.Changed, id)
+	case existed:
+		a.Removed = append(a.Removed, id)
+	}
+}
+
+// patchItem applies a JSON merge patch to a schema item. A nil result means the
+// patch removed it (or removed something that wasn't there).
+func patchItem[T any](base *T, patch any) (*T, error) {
+	if patch == nil {
+		return nil, nil
+	}
+	var doc any
+	if base != nil {
+		raw, err := json.Marshal(base)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+	}
+	raw, err := json.Marshal(mergePatch(doc, patch))
+	if err != nil {
+		return nil, err
+	}
+	var out T
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// mergePatch implements RFC 7386: objects merge recursively, null deletes a key,
+// and anything else replaces the target.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for key, val := range p {
+		if val == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergePatch(t[key], val)
+	}
+	return t
+}
@@ -0,0 +1,86 @@
+package tenet
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverlay(t *testing.T) {
+	base := `{
+		"definitions": {
+			"credit_score": {"type": "number", "value": 660},
+			"decision": {"type": "string", "value": "review"},
+			"legacy_flag": {"type": "boolean", "value": false}
+		},
+		"logic_tree": [
+			{"id": "approve", "law_ref": "Policy 4.1", "when": {">=": [{"var": "credit_score"}, 700]}, "then": {"set": {"decision": "approve"}}},
+			{"id": "legacy", "when": {"==": [{"var": "legacy_flag"}, true]}, "then": {"set": {"decision": "review"}}}
+		]
+	}`
+	variant := `{
+		"id": "exp-17-b",
+		"description": "Lower the approval threshold",
+		"definitions": {"legacy_flag": null, "score_band": {"type": "string"}},
+		"logic_tree": [
+			{"id": "approve", "when": {">=": [{"var": "credit_score"}, 650]}},
+			{"id": "band", "when": {">=": [{"var": "credit_score"}, 650]}, "then": {"set": {"score_band": "B"}}}
+		],
+		"remove_rules": ["legacy"]
+	}`
+
+	patched, err := Overlay(base, variant)
+	if err != nil {
+		t.Fatalf("Overlay failed: %v", err)
+	}
+
+	t.Run("patch", func(t *testing.T) {
+		schema := parseResult(t, patched)
+		if len(schema.LogicTree) != 2 || schema.LogicTree[0].ID != "approve" || schema.LogicTree[1].ID != "band" {
+			t.Fatalf("unexpected rules after overlay: %d", len(schema.LogicTree))
+		}
+		// Merge patch keeps what the overlay doesn't mention
+		assertEqual(t, schema.LogicTree[0].LawRef, "Policy 4.1")
+		if _, ok := schema.Definitions["legacy_flag"]; ok {
+			t.Error("legacy_flag should be removed")
+		}
+
+		if len(schema.Overlays) != 1 {
+			t.Fatalf("expected one applied overlay, got %d", len(schema.Overlays))
+		}
+		applied := schema.Overlays[0]
+		assertEqual(t, applied.ID, "exp-17-b")
+		if !slices.Equal(applied.Added, []string{"score_band", "band"}) ||
+			!slices.Equal(applied.Changed, []string{"approve"}) ||
+			!slices.Equal(applied.Removed, []string{"legacy_flag", "legacy"}) {
+			t.Errorf("unexpected provenance: %+v", applied)
+		}
+	})
+
+	t.Run("run", func(t *testing.T) {
+		result, err := Run(patched, time.Now())
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		assertDefinitionValue(t, schema, "decision", "approve")
+		assertDefinitionValue(t, schema, "score_band", "B")
+		// The experiment arm is recorded in every result
+		if len(schema.Overlays) != 1 || schema.Overlays[0].ID != "exp-17-b" {
+			t.Errorf("expected overlay identity in output, got %+v", schema.Overlays)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := Overlay(patched, variant); err == nil || !strings.Contains(err.Error(), "already applied") {
+			t.Errorf("expected already applied error, got %v", err)
+		}
+		if _, err := Overlay(base, `{"logic_tree": []}`); err == nil || !strings.Contains(err.Error(), "id is required") {
+			t.Errorf("expected missing id error, got %v", err)
+		}
+		if _, err := Overlay(base, `{"id": "x", "remove_rules": ["nope"]}`); err == nil || !strings.Contains(err.Error(), "unknown rule 'nope'") {
+			t.Errorf("expected unknown rule error, got %v", err)
+		}
+	})
+}
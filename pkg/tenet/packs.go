@@ -0,0 +1,287 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PackLoader returns the JSON text of a named rule pack.
+type PackLoader func(name string) (string, error)
+
+// DirPackLoader loads packs from <dir>/<name>.json.
+func DirPackLoader(dir string) PackLoader {
+	return func(name string) (string, error) {
+		if strings.ContainsAny(name, `/\`) || name == ".." {
+			return "", fmt.Errorf("invalid pack name '%s'", name)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// packSections are the top-level keys a pack may contain. Any other section (temporal_map,
+// tests, parameters, ...) has no merge rule and is rejected rather than dropped.
+var packSections = map[string]bool{
+	"protocol": true, "schema_id": true, "version": true, // Describe the pack itself; not merged
+	"definitions": true, "attestations": true, "logic_tree": true, "state_model": true,
+	"decision_tables": true, "law_refs": true, "aliases": true,
+	"require_together": true, "require_one_of": true,
+	"templates": true, "use_templates": true, "use_packs": true,
+}
+
+// ResolvePacks merges every pack listed in `use_packs` into the schema.
+// A pack is an ordinary schema fragment (definitions, attestations, logic_tree, state_model,
+// decision_tables, law_refs, aliases, field groups and templates) and may itself use other
+// packs. Pack rules run before the schema's own rules, and pack decision tables before the
+// schema's own tables. A pack with any other section is an error.
+//
+// The same ID appearing in two sources is allowed only if both copies are identical;
+// anything else is a conflict and returns an error rather than silently picking one.
+// The merged packs move from use_packs to packs, so resolving an already resolved schema
+// is a no-op.
+func ResolvePacks(jsonText string, load PackLoader) (string, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(jsonText), &schema); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+	if len(schema.UsePacks) == 0 {
+		return jsonText, nil
+	}
+	if load == nil {
+		return "", fmt.Errorf("schema uses packs %v but no pack loader is configured", schema.UsePacks)
+	}
+
+	m := &packMerger{load: load, loaded: make(map[string]bool), loading: make(map[string]bool)}
+	own, ownTables := schema.LogicTree, schema.DecisionTables
+	schema.LogicTree, schema.DecisionTables = nil, nil
+	for _, name := range schema.UsePacks {
+		if err := m.merge(&schema, name); err != nil {
+			return "", err
+		}
+	}
+	if err := mergeRules(&schema, own, "schema"); err != nil {
+		return "", err
+	}
+	if err := mergeTables(&schema, ownTables, "schema"); err != nil {
+		return "", err
+	}
+	schema.Packs = append(schema.Packs, m.order...)
+	schema.UsePacks = nil
+
+	result, err := json.MarshalIndent(&schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return string(result), nil
+}
+
+// packMerger tracks loaded packs so shared dependencies merge once and cycles are reported.
+type packMerger struct {
+	load    PackLoader
+	loaded  map[string]bool
+	loading map[string]bool
+	order   []string // Names in the order they were merged
+}
+
+func (m *packMerger) merge(dst *Schema, name string) error {
+	if m.loaded[name] {
+		return nil
+	}
+	if m.loading[name] {
+		return fmt.Errorf("pack '%s': circular use_packs", name)
+	}
+	m.loading[name] = true
+	defer delete(m.loading, name)
+
+	text, err := m.load(name)
+	if err != nil {
+		return fmt.Errorf("pack '%s': %w", name, err)
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(text), &sections); err != nil {
+		return fmt.Errorf("pack '%s': unmarshal: %w", name, err)
+	}
+	for _, key := range sortedIDs(sections) {
+		if !packSections[key] {
+			return fmt.Errorf("pack '%s': section '%s' can't be merged from a pack", name, key)
+		}
+	}
+	var pack Schema
+	if err := json.Unmarshal([]byte(text), &pack); err != nil {
+		return fmt.Errorf("pack '%s': unmarshal: %w", name, err)
+	}
+
+	for _, dep := range pack.UsePacks {
+		if err := m.merge(dst, dep); err != nil {
+			return err
+		}
+	}
+
+	source := "pack '" + name + "'"
+	if dst.Definitions == nil {
+		dst.Definitions = make(map[string]*Definition)
+	}
+	for id, def := range pack.Definitions {
+		if err := mergeEntry(dst.Definitions, id, def, "definition", source); err != nil {
+			return err
+		}
+	}
+	if len(pack.Attestations) > 0 && dst.Attestations == nil {
+		dst.Attestations = make(map[string]*Attestation)
+	}
+	for id, att := range pack.Attestations {
+		if err := mergeEntry(dst.Attestations, id, att, "attestation", source); err != nil {
+			return err
+		}
+	}
+	if len(pack.LawRefs) > 0 && dst.LawRefs == nil {
+		dst.LawRefs = make(map[string]*LawRef)
+	}
+	for id, ref := range pack.LawRefs {
+		if err := mergeEntry(dst.LawRefs, id, ref, "law_ref", source); err != nil {
+			return err
+		}
+	}
+	if pack.StateModel != nil {
+		if dst.StateModel == nil {
+			dst.StateModel = &StateModel{}
+		}
+		if dst.StateModel.Derived == nil {
+			dst.StateModel.Derived = make(map[string]*DerivedDef)
+		}
+		for id, derived := range pack.StateModel.Derived {
+			if err := mergeEntry(dst.StateModel.Derived, id, derived, "derived field", source); err != nil {
+				return err
+			}
+		}
+		for _, input := range pack.StateModel.Inputs {
+			if !slices.Contains(dst.StateModel.Inputs, input) {
+				dst.StateModel.Inputs = append(dst.StateModel.Inputs, input)
+			}
+		}
+	}
+	if len(pack.Aliases) > 0 && dst.Aliases == nil {
+		dst.Aliases = make(map[string]string)
+	}
+	for old, id := range pack.Aliases {
+		if err := mergeEntry(dst.Aliases, old, id, "alias", source); err != nil {
+			return err
+		}
+	}
+	if len(pack.Templates) > 0 && dst.Templates == nil {
+		dst.Templates = make(map[string]*Template)
+	}
+	for id, tmpl := range pack.Templates {
+		if err := mergeEntry(dst.Templates, id, tmpl, "template", source); err != nil {
+			return err
+		}
+	}
+	dst.UseTemplates = appendUnique(dst.UseTemplates, pack.UseTemplates)
+	dst.RequireTogether = appendUnique(dst.RequireTogether, pack.RequireTogether)
+	dst.RequireOneOf = appendUnique(dst.RequireOneOf, pack.RequireOneOf)
+	if err := mergeTables(dst, pack.DecisionTables, source); err != nil {
+		return err
+	}
+	if err := mergeRules(dst, pack.LogicTree, source); err != nil {
+		return err
+	}
+
+	m.loaded[name] = true
+	m.order = append(m.order, name)
+	return nil
+}
+
+// mergeEntry adds value under id, failing if a different value is already there.
+func mergeEntry[T any](dst map[string]T, id string, value T, kind, source string) error {
+	if existing, ok := dst[id]; ok {
+		if !sameJSON(existing, value) {
+			return fmt.Errorf("%s: %s '%s' conflicts with an existing definition", source, kind, id)
+		}
+		return nil
+	}
+	dst[id] = value
+	return nil
+}
+
+// mergeRules appends rules, skipping identical duplicates and rejecting conflicting IDs.
+func mergeRules(dst *Schema, rules []*Rule, source string) error {
+	for _, rule := range rules {
+		if rule == nil {
+			continue
+		}
+		duplicate := false
+		for _, existing := range dst.LogicTree {
+			if existing == nil || existing.ID != rule.ID || rule.ID == "" {
+				continue
+			}
+			if !sameJSON(existing, rule) {
+				return fmt.Errorf("%s: rule '%s' conflicts with an existing rule", source, rule.ID)
+			}
+			duplicate = true
+			break
+		}
+		if !duplicate {
+			dst.LogicTree = append(dst.LogicTree, rule)
+		}
+	}
+	return nil
+}
+
+// mergeTables appends decision tables, skipping identical duplicates and rejecting
+// conflicting IDs.
+func mergeTables(dst *Schema, tables []*DecisionTable, source string) error {
+	for _, table := range tables {
+		if table == nil {
+			continue
+		}
+		duplicate := false
+		for _, existing := range dst.DecisionTables {
+			if existing == nil || existing.ID != table.ID || table.ID == "" {
+				continue
+			}
+			if !sameJSON(existing, table) {
+				return fmt.Errorf("%s: decision table '%s' conflicts with an existing table", source, table.ID)
+			}
+			duplicate = true
+			break
+		}
+		if !duplicate {
+			dst.DecisionTables = append(dst.DecisionTables, table)
+		}
+	}
+	return nil
+}
+
+// appendUnique appends the values of add that dst doesn't already hold an identical copy of.
+func appendUnique[T any](dst, add []T) []T {
+	for _, value := range add {
+		if !slices.ContainsFunc(dst, func(existing T) bool { return sameJSON(existing, value) }) {
+			dst = append(dst, value)
+		}
+	}
+	return dst
+}
+
+// warnUnresolvedPacks reports use_packs that were never resolved: their rules and fields
+// are missing from the evaluation.
+func (e *Engine) warnUnresolvedPacks() {
+	if len(e.schema.UsePacks) == 0 {
+		return
+	}
+	e.addError("", "", ErrRuntimeWarning, fmt.Sprintf(
+		"Schema uses packs %v that were not resolved; their rules are not evaluated (see ResolvePacks)", e.schema.UsePacks), "")
+}
+
+// sameJSON compares two values by their JSON encoding.
+func sameJSON(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
@@ -0,0 +1,177 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolvePacks(t *testing.T) {
+	packs := map[string]string{
+		"identity": `{
+			"definitions": {"full_name": {"type": "string", "required": true}}
+		}`,
+		"kyc_eu_v2": `{
+			"use_packs": ["identity"],
+			"definitions": {
+				"full_name": {"type": "string", "required": true},
+				"pep": {"type": "boolean", "value": false},
+				"enhanced_due_diligence": {"type": "boolean", "value": false}
+			},
+			"logic_tree": [
+				{"id": "kyc_pep", "law_ref": "AMLD Art. 20", "when": {"==": [{"var": "pep"}, true]}, "then": {"set": {"enhanced_due_diligence": true}}}
+			]
+		}`,
+	}
+	load := func(name string) (string, error) {
+		text, ok := packs[name]
+		if !ok {
+			return "", fmt.Errorf("not found")
+		}
+		return text, nil
+	}
+
+	input := `{
+		"use_packs": ["kyc_eu_v2"],
+		"definitions": {"pep": {"type": "boolean", "value": true}},
+		"logic_tree": [
+			{"id": "own", "when": {"==": [{"var": "enhanced_due_diligence"}, true]}, "then": {"error_msg": "Manual review"}}
+		]
+	}`
+
+	t.Run("merge", func(t *testing.T) {
+		// The schema redeclares pep with a different value than the pack
+		_, err := ResolvePacks(input, load)
+		if err == nil || !strings.Contains(err.Error(), "definition 'pep' conflicts") {
+			t.Fatalf("expected pep conflict, got %v", err)
+		}
+
+		compatible := strings.Replace(input, `"definitions": {"pep": {"type": "boolean", "value": true}}`, `"definitions": {}`, 1)
+		resolved, err := ResolvePacks(compatible, load)
+		if err != nil {
+			t.Fatalf("ResolvePacks failed: %v", err)
+		}
+		schema := parseResult(t, resolved)
+		assertDefinitionExists(t, schema, "full_name")
+		if len(schema.LogicTree) != 2 || schema.LogicTree[0].ID != "kyc_pep" || schema.LogicTree[1].ID != "own" {
+			t.Fatalf("expected pack rules before own rules, got %+v", schema.LogicTree)
+		}
+
+		assertEqual(t, strings.Join(schema.Packs, ","), "identity,kyc_eu_v2")
+		if len(schema.UsePacks) != 0 {
+			t.Errorf("resolved schema should not list use_packs, got %v", schema.UsePacks)
+		}
+		again, err := ResolvePacks(resolved, load)
+		if err != nil {
+			t.Fatalf("re-resolving should be a no-op, got %v", err)
+		}
+		assertEqual(t, again, resolved)
+
+		schema.Definitions["pep"].Value = true
+		raw, _ := json.Marshal(schema)
+		result, err := Run(string(raw), time.Now())
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		assertDefinitionValue(t, parseResult(t, result), "enhanced_due_diligence", true)
+	})
+
+	t.Run("conflicting rule", func(t *testing.T) {
+		conflicting := `{"use_packs": ["kyc_eu_v2"], "definitions": {}, "logic_tree": [{"id": "kyc_pep", "when": true, "then": {"error_msg": "x"}}]}`
+		if _, err := ResolvePacks(conflicting, load); err == nil || !strings.Contains(err.Error(), "rule 'kyc_pep' conflicts") {
+			t.Fatalf("expected rule conflict, got %v", err)
+		}
+	})
+
+	t.Run("tables, groups and templates", func(t *testing.T) {
+		packs["affordability"] = `{
+			"aliases": {"monthly_income": "income"},
+			"decision_tables": [{
+				"id": "band",
+				"inputs": ["income"],
+				"outputs": ["band"],
+				"rows": [{"when": [">= 5000"], "then": ["high"]}, {"when": ["-"], "then": ["low"]}]
+			}],
+			"require_together": [{"id": "contact", "fields": ["phone", "email"]}],
+			"templates": {"address": {"definitions": {"city": {"type": "string"}}}},
+			"use_templates": [{"template": "address", "prefix": "home"}]
+		}`
+		input := `{
+			"use_packs": ["affordability"],
+			"definitions": {
+				"income": {"type": "number", "value": 6000},
+				"band": {"type": "string"},
+				"phone": {"type": "string", "value": "555"},
+				"email": {"type": "string"}
+			}
+		}`
+		resolved, err := ResolvePacks(input, load)
+		if err != nil {
+			t.Fatalf("ResolvePacks failed: %v", err)
+		}
+		expanded, err := ExpandTemplates(resolved)
+		if err != nil {
+			t.Fatalf("ExpandTemplates failed: %v", err)
+		}
+		result, err := Run(expanded, time.Now())
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		parsed := parseResult(t, result)
+		assertDefinitionValue(t, parsed, "band", "high")
+		assertDefinitionExists(t, parsed, "home_city")
+		assertEqual(t, parsed.Aliases["monthly_income"], "income")
+		if !strings.Contains(fmt.Sprint(parsed.Errors), "email") {
+			t.Errorf("expected the pack's require_together to apply, got %+v", parsed.Errors)
+		}
+	})
+
+	t.Run("unmergeable section", func(t *testing.T) {
+		packs["dated"] = `{"definitions": {}, "temporal_map": [{"valid_range": ["2024-01-01", null], "logic_version": "v1"}]}`
+		_, err := ResolvePacks(`{"use_packs": ["dated"], "definitions": {}}`, load)
+		if err == nil || !strings.Contains(err.Error(), "section 'temporal_map'") {
+			t.Fatalf("expected temporal_map to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("unresolved warns", func(t *testing.T) {
+		result, err := Run(input, time.Now())
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		parsed := parseResult(t, result)
+		// The pack's missing fields add their own warnings after this one
+		if len(parsed.Errors) == 0 || parsed.Errors[0].Kind != ErrRuntimeWarning || !strings.Contains(parsed.Errors[0].Message, "kyc_eu_v2") {
+			t.Fatalf("expected a runtime warning naming the pack, got %+v", parsed.Errors)
+		}
+	})
+
+	t.Run("cycle and missing", func(t *testing.T) {
+		packs["a"] = `{"use_packs": ["b"], "definitions": {}}`
+		packs["b"] = `{"use_packs": ["a"], "definitions": {}}`
+		if _, err := ResolvePacks(`{"use_packs": ["a"], "definitions": {}}`, load); err == nil || !strings.Contains(err.Error(), "circular") {
+			t.Fatalf("expected cycle error, got %v", err)
+		}
+		if _, err := ResolvePacks(`{"use_packs": ["nope"], "definitions": {}}`, load); err == nil {
+			t.Fatal("expected error for missing pack")
+		}
+	})
+}
+
+func TestDirPackLoader(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "basic.json"), []byte(`{"definitions": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	load := DirPackLoader(dir)
+	if _, err := load("basic"); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if _, err := load("../basic"); err == nil {
+		t.Fatal("expected error for path traversal")
+	}
+}
@@ -0,0 +1,169 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parameter declares a template parameter substituted by Instantiate.
+type Parameter struct {
+	Type        string `json:"type,omitempty"`        // "string", "number", "boolean" or "date" (empty = any)
+	Default     any    `json:"default,omitempty"`     // Used when no value is supplied (nil = required)
+	Description string `json:"description,omitempty"` // Human-readable purpose
+}
+
+// Instantiate turns a schema template into a concrete schema.
+// Every {"$param": "name"} node is replaced by the parameter's value and every "${name}"
+// inside a string is interpolated, for declared names only. The `parameters` block is
+// replaced by `parameter_values`, recording exactly what the concrete schema was built from.
+//
+// Supplying an undeclared parameter, omitting one without a default, or passing a value
+// of the wrong type is an error. A schema without `parameters` is returned unchanged.
+func Instantiate(jsonText string, params map[string]any) (string, error) {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(jsonText), &doc); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+
+	rawDecls, hasDecls := doc["parameters"]
+	if !hasDecls {
+		for name := range params {
+			return "", fmt.Errorf("unknown parameter '%s': schema declares no parameters", name)
+		}
+		return jsonText, nil
+	}
+
+	var decls map[string]*Parameter
+	declJSON, _ := json.Marshal(rawDecls)
+	if err := json.Unmarshal(declJSON, &decls); err != nil {
+		return "", fmt.Errorf("parameters: %w", err)
+	}
+
+	values, err := resolveParameters(decls, params)
+	if err != nil {
+		return "", err
+	}
+
+	delete(doc, "parameters")
+	substituted, err := substituteParams(doc, values)
+	if err != nil {
+		return "", err
+	}
+	out := substituted.(map[string]any)
+	out["parameter_values"] = values
+
+	result, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return string(result), nil
+}
+
+// resolveParameters combines supplied values with defaults and checks declared types.
+func resolveParameters(decls map[string]*Parameter, params map[string]any) (map[string]any, error) {
+	for _, name := range sortedKeys(params) {
+		if _, ok := decls[name]; !ok {
+			return nil, fmt.Errorf("unknown parameter '%s'", name)
+		}
+	}
+
+	values := make(map[string]any, len(decls))
+	for name, decl := range decls {
+		if decl == nil {
+			decl = &Parameter{}
+		}
+		value, supplied := params[name]
+		if !supplied {
+			if decl.Default == nil {
+				return nil, fmt.Errorf("parameter '%s' is required", name)
+			}
+			value = decl.Default
+		}
+		if !parameterTypeMatches(decl.Type, value) {
+			return nil, fmt.Errorf("parameter '%s': expected %s, got %v", name, decl.Type, value)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// parameterTypeMatches reports whether value fits a declared parameter type.
+func parameterTypeMatches(typ string, value any) bool {
+	switch typ {
+	case "":
+		return true
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "date":
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		_, ok = parseDate(s)
+		return ok
+	default:
+		return false
+	}
+}
+
+// substituteParams walks a JSON value replacing {"$param": name} nodes and "${name}" in strings.
+func substituteParams(node any, values map[string]any) (any, error) {
+	switch v := node.(type) {
+	case map[string]any:
+		if ref, ok := v["$param"]; ok && len(v) == 1 {
+			name, _ := ref.(string)
+			value, ok := values[name]
+			if !ok {
+				return nil, fmt.Errorf("undeclared parameter '%v'", ref)
+			}
+			return value, nil
+		}
+		for key, elem := range v {
+			replaced, err := substituteParams(elem, values)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = replaced
+		}
+		return v, nil
+
+	case []any:
+		for i, elem := range v {
+			replaced, err := substituteParams(elem, values)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = replaced
+		}
+		return v, nil
+
+	case string:
+		if !strings.Contains(v, "${") {
+			return v, nil
+		}
+		for name, value := range values {
+			v = strings.ReplaceAll(v, "${"+name+"}", formatParam(value))
+		}
+		return v, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// formatParam renders a parameter value for string interpolation.
+func formatParam(value any) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,78 @@
+package tenet
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const parameterTemplate = `{
+	"protocol": "Tax_${jurisdiction}_${year}",
+	"parameters": {
+		"jurisdiction": {"type": "string"},
+		"year": {"type": "number", "default": 2025},
+		"threshold": {"type": "number", "default": 50000, "description": "High-income threshold"}
+	},
+	"definitions": {
+		"income": {"type": "number", "value": 60000, "label": "Income for ${year}"},
+		"high_income": {"type": "boolean", "value": false}
+	},
+	"logic_tree": [
+		{
+			"id": "high",
+			"when": {">": [{"var": "income"}, {"$param": "threshold"}]},
+			"then": {"set": {"high_income": true}}
+		}
+	]
+}`
+
+func TestInstantiate(t *testing.T) {
+	concrete, err := Instantiate(parameterTemplate, map[string]any{"jurisdiction": "SE", "threshold": 75000.0})
+	if err != nil {
+		t.Fatalf("Instantiate failed: %v", err)
+	}
+
+	schema := parseResult(t, concrete)
+	assertEqual(t, schema.Protocol, "Tax_SE_2025")
+	assertEqual(t, schema.Definitions["income"].Label, "Income for 2025")
+	if schema.Parameters != nil {
+		t.Error("concrete schema should not declare parameters")
+	}
+	assertEqual(t, schema.ParameterValues["jurisdiction"], any("SE"))
+	assertEqual(t, schema.ParameterValues["year"], any(2025.0))
+	assertEqual(t, schema.ParameterValues["threshold"], any(75000.0))
+
+	result, err := Run(concrete, time.Now())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	assertDefinitionValue(t, parseResult(t, result), "high_income", false)
+}
+
+func TestInstantiateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   string
+	}{
+		{"missing required", nil, "'jurisdiction' is required"},
+		{"unknown", map[string]any{"jurisdiction": "SE", "rate": 0.3}, "unknown parameter 'rate'"},
+		{"wrong type", map[string]any{"jurisdiction": "SE", "year": "2026"}, "'year': expected number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Instantiate(parameterTemplate, tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	plain := `{"definitions": {}}`
+	if out, err := Instantiate(plain, nil); err != nil || out != plain {
+		t.Errorf("schema without parameters should pass through, got %q, %v", out, err)
+	}
+	if _, err := Instantiate(plain, map[string]any{"year": 2026.0}); err == nil {
+		t.Error("expected error for parameter on a schema without parameters")
+	}
+}
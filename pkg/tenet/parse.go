@@ -0,0 +1,519 @@
+package tenet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseExpr compiles an infix expression such as `credit_score >= 700 and dti <= 0.43`
+// into JSON-logic. It accepts everything FormatExpr produces, plus ASCII spellings:
+//
+//	or ||   and &&   not !   = == != ≠ > < >= ≥ <= ≤ before after in   + - * / %
+//	if c then a else b   some(items, cond)   some of items match (cond)   op(args...)
+//
+// Keywords are case-insensitive. Strings use single or double quotes; bare words are
+// field references (dot paths allowed), and `item` refers to the current element inside
+// some/all/none and sum/avg/count/min/max (`item.amount` to one of its fields).
+func ParseExpr(text string) (any, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected '%s' at offset %d", tok.text, tok.pos)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	num  float64
+}
+
+// tokenize splits infix text into tokens.
+func tokenize(text string) ([]token, error) {
+	var tokens []token
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				((runes[i] == '+' || runes[i] == '-') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			n, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number '%s' at offset %d", string(runes[start:i]), start)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i]), pos: start, num: n})
+
+		case r == '"' || r == '\'':
+			start := i
+			var b strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at offset %d", start)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokString, text: b.String(), pos: start})
+
+		case unicode.IsLetter(r) || r == '_' || r == '$':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.' || runes[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), pos: start})
+
+		default:
+			start := i
+			op := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "==", "!=", ">=", "<=", "&&", "||":
+					op = two
+				}
+			}
+			if !strings.Contains("=!≠><≥≤&|+-*/%()[],", string(r)) || op == "&" || op == "|" {
+				return nil, fmt.Errorf("unexpected character '%c' at offset %d", r, start)
+			}
+			i += len([]rune(op))
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: start})
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "end of input", pos: len(runes)}), nil
+}
+
+// exprParser is a recursive-descent parser over tokens.
+type exprParser struct {
+	tokens     []token
+	pos        int
+	quantified int // depth inside some/all/none and aggregations, where `item` means the current element
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isKeyword reports whether tok is the given case-insensitive keyword.
+func isKeyword(tok token, word string) bool {
+	return tok.kind == tokIdent && strings.EqualFold(tok.text, word)
+}
+
+func (p *exprParser) expect(text string) error {
+	tok := p.next()
+	if (tok.kind == tokOp && tok.text == text) || isKeyword(tok, text) {
+		return nil
+	}
+	return fmt.Errorf("expected '%s' at offset %d, got '%s'", text, tok.pos, tok.text)
+}
+
+func (p *exprParser) parseExpr() (any, error) {
+	if isKeyword(p.peek(), "if") {
+		return p.parseIf()
+	}
+	return p.parseOr()
+}
+
+// parseIf handles `if c then a [else if c2 then b] else d`.
+func (p *exprParser) parseIf() (any, error) {
+	var args []any
+	for {
+		p.next() // "if"
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("then"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, cond, value)
+
+		if !isKeyword(p.peek(), "else") {
+			return map[string]any{"if": args}, nil
+		}
+		p.next()
+		if !isKeyword(p.peek(), "if") {
+			alt, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"if": append(args, alt)}, nil
+		}
+	}
+}
+
+// parseChain parses left-to-right runs of one logical operator into a single n-ary node.
+func (p *exprParser) parseChain(op string, words []string, operand func() (any, error)) (any, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	args := []any{first}
+	for p.matchAny(words) {
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, next)
+	}
+	if len(args) == 1 {
+		return first, nil
+	}
+	return map[string]any{op: args}, nil
+}
+
+// matchAny consumes the next token if it is one of words (operators or keywords).
+func (p *exprParser) matchAny(words []string) bool {
+	tok := p.peek()
+	for _, w := range words {
+		if (tok.kind == tokOp && tok.text == w) || isKeyword(tok, w) {
+			p.next()
+			return true
+		}
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (any, error) {
+	return p.parseChain("or", []string{"or", "||"}, p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (any, error) {
+	return p.parseChain("and", []string{"and", "&&"}, p.parseNot)
+}
+
+func (p *exprParser) parseNot() (any, error) {
+	if tok := p.peek(); isKeyword(tok, "not") || (tok.kind == tokOp && tok.text == "!") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"!": operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// comparisonOps maps infix spellings to JSON-logic operators.
+var comparisonOps = map[string]string{
+	"=": "==", "==": "==", "!=": "!=", "≠": "!=",
+	">": ">", "<": "<", ">=": ">=", "≥": ">=", "<=": "<=", "≤": "<=",
+	"before": "before", "after": "after", "in": "in",
+}
+
+func (p *exprParser) parseComparison() (any, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	text := tok.text
+	if tok.kind == tokIdent {
+		text = strings.ToLower(text)
+	}
+	op, ok := comparisonOps[text]
+	if !ok || tok.kind == tokString || tok.kind == tokNumber {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{op: []any{left, right}}, nil
+}
+
+// parseBinary parses left-associative binary operators (+ - or * /).
+func (p *exprParser) parseBinary(ops string, operand func() (any, error)) (any, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokOp || len(tok.text) != 1 || !strings.Contains(ops, tok.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = map[string]any{tok.text: []any{left, right}}
+	}
+}
+
+func (p *exprParser) parseAdditive() (any, error) {
+	return p.parseBinary("+-", p.parseMultiplicative)
+}
+
+func (p *exprParser) parseMultiplicative() (any, error) {
+	return p.parseBinary("*/%", p.parseUnary)
+}
+
+func (p *exprParser) parseUnary() (any, error) {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == "-" {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := operand.(float64); ok {
+			return -n, nil
+		}
+		// The - operator is binary, so negation subtracts from zero
+		return map[string]any{"-": []any{0.0, operand}}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (any, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return tok.num, nil
+	case tokString:
+		return tok.text, nil
+	case tokOp:
+		switch tok.text {
+		case "(":
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expect(")")
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return items, nil
+		}
+	case tokIdent:
+		return p.parseIdent(tok)
+	}
+	return nil, fmt.Errorf("unexpected '%s' at offset %d", tok.text, tok.pos)
+}
+
+// parseIdent handles literals, quantifiers, function calls and field references.
+func (p *exprParser) parseIdent(tok token) (any, error) {
+	word := strings.ToLower(tok.text)
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	quantifier := word == "some" || word == "all" || word == "none"
+	aggregation := word == "sum" || word == "avg" || word == "count" || word == "min" || word == "max" ||
+		word == "map" || word == "filter" || word == "reduce"
+
+	// FormatExpr's long form: some of items match (cond)
+	if quantifier && isKeyword(p.peek(), "of") {
+		p.next()
+		list, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("match"); err != nil {
+			return nil, err
+		}
+		p.quantified++
+		cond, err := p.parsePrimary()
+		p.quantified--
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{word: []any{list, cond}}, nil
+	}
+
+	if next := p.peek(); next.kind == tokOp && next.text == "(" {
+		p.next()
+		if quantifier || aggregation {
+			p.quantified++
+		}
+		args, err := p.parseList(")")
+		if quantifier || aggregation {
+			p.quantified--
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := tok.text
+		if quantifier {
+			name = word
+		}
+		return map[string]any{name: args}, nil
+	}
+
+	if p.quantified > 0 && word == "item" {
+		return map[string]any{"var": ""}, nil
+	}
+	if field, ok := strings.CutPrefix(tok.text, "item."); ok && p.quantified > 0 {
+		return map[string]any{"var": "." + field}, nil
+	}
+	return map[string]any{"var": tok.text}, nil
+}
+
+// parseList parses comma-separated expressions up to the closing token.
+func (p *exprParser) parseList(closing string) ([]any, error) {
+	items := make([]any, 0)
+	if tok := p.peek(); tok.kind == tokOp && tok.text == closing {
+		p.next()
+		return items, nil
+	}
+	for {
+		item, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		tok := p.next()
+		if tok.kind == tokOp && tok.text == closing {
+			return items, nil
+		}
+		if tok.kind != tokOp || tok.text != "," {
+			return nil, fmt.Errorf("expected ',' or '%s' at offset %d, got '%s'", closing, tok.pos, tok.text)
+		}
+	}
+}
+
+// compileInfix replaces infix strings in when, when_any, unless and derived eval with
+// their JSON-logic form. A string that fails to parse is reported as a runtime warning
+// and compiled to false, so the rule never fires on a condition nobody can read. Derived
+// evals are infix only when marked with a leading "=" (see ParseEval); other strings are
+// constants.
+// Expressions may be shared with a CompiledSchema, so arrays and derived definitions
+// are replaced rather than written to.
+func (e *Engine) compileInfix() {
+	for _, rule := range e.schema.LogicTree {
+		if rule == nil {
+			continue
+		}
+		rule.When = e.compileCondition(rule.When, rule.ID, "when")
+		rule.Unless = e.compileCondition(rule.Unless, rule.ID, "unless")
+		if rule.WhenAny != nil {
+			rule.WhenAny = e.compileCondition(rule.WhenAny, rule.ID, "when_any").([]any)
+		}
+	}
+
+	if e.schema.StateModel == nil {
+		return
+	}
+	var derived map[string]*DerivedDef
+	for _, name := range sortedIDs(e.schema.StateModel.Derived) {
+		def := e.schema.StateModel.Derived[name]
+		if def == nil {
+			continue
+		}
+		text, ok := def.Eval.(string)
+		if !ok {
+			continue
+		}
+		expr, err := ParseEval(text)
+		if err != nil {
+			e.addError(name, "", ErrRuntimeWarning, fmt.Sprintf(
+				"Derived field '%s' has an unparseable eval '%s': %v", name, text, err), "")
+		} else if expr == text {
+			continue
+		}
+		if derived == nil {
+			derived = make(map[string]*DerivedDef, len(e.schema.StateModel.Derived))
+			for k, v := range e.schema.StateModel.Derived {
+				derived[k] = v
+			}
+		}
+		compiled := *def
+		compiled.Eval = expr
+		derived[name] = &compiled
+	}
+	if derived != nil {
+		model := *e.schema.StateModel
+		model.Derived = derived
+		e.schema.StateModel = &model
+	}
+}
+
+// ParseEval compiles a derived eval string the way Run does. A formula marked with a
+// leading "=" ("= loan_amount / income") is parsed as infix; any other string ("pending",
+// "Not applicable") is a constant and returned unchanged.
+func ParseEval(text string) (any, error) {
+	formula, ok := strings.CutPrefix(strings.TrimSpace(text), "=")
+	if !ok {
+		return text, nil
+	}
+	return ParseExpr(formula)
+}
+
+// compileCondition parses a condition that is an infix string, or an array containing some.
+// Arrays are copied before any element is replaced.
+func (e *Engine) compileCondition(cond any, ruleID, field string) any {
+	switch c := cond.(type) {
+	case string:
+		expr, err := ParseExpr(c)
+		if err != nil {
+			e.addError("", ruleID, ErrRuntimeWarning, fmt.Sprintf(
+				"Rule '%s' has an unparseable %s '%s': %v", ruleID, field, c, err), "")
+			return false
+		}
+		return expr
+	case []any:
+		out, copied := c, false
+		for i, elem := range c {
+			if _, ok := elem.(string); ok {
+				if !copied {
+					out, copied = append([]any(nil), c...), true
+				}
+				out[i] = e.compileCondition(elem, ruleID, field)
+			}
+		}
+		return out
+	}
+	return cond
+}
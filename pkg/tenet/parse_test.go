@@ -0,0 +1,143 @@
+package tenet
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExpr(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`credit_score >= 700 and dti <= 0.43`,
+			`{"and": [{">=": [{"var": "credit_score"}, 700]}, {"<=": [{"var": "dti"}, 0.43]}]}`},
+		{`a && b || !c`, `{"or": [{"and": [{"var": "a"}, {"var": "b"}]}, {"!": {"var": "c"}}]}`},
+		{`status = 'active' AND NOT flagged`, `{"and": [{"==": [{"var": "status"}, "active"]}, {"!": {"var": "flagged"}}]}`},
+		{`applicant.age < 18`, `{"<": [{"var": "applicant.age"}, 18]}`},
+		{`(salary + bonus) * 0.3 - -1`, `{"-": [{"*": [{"+": [{"var": "salary"}, {"var": "bonus"}]}, 0.3]}, -1]}`},
+		{`country in ["SE", "NO"]`, `{"in": [{"var": "country"}, ["SE", "NO"]]}`},
+		{`some(items, item > 5)`, `{"some": [{"var": "items"}, {">": [{"var": ""}, 5]}]}`},
+		{`if age < 18 then "minor" else "adult"`, `{"if": [{"<": [{"var": "age"}, 18]}, "minor", "adult"]}`},
+		{`max(a, 2, true)`, `{"max": [{"var": "a"}, 2, true]}`},
+		{`amount % 100 + pow(rate, 2)`, `{"+": [{"%": [{"var": "amount"}, 100]}, {"pow": [{"var": "rate"}, 2]}]}`},
+		{`sum(line_items, item.amount * item.qty) > 1000`, `{">": [{"sum": [{"var": "line_items"}, {"*": [{"var": ".amount"}, {"var": ".qty"}]}]}, 1000]}`},
+		{`sum(filter(line_items, item.taxable), item.amount)`, `{"sum": [{"filter": [{"var": "line_items"}, {"var": ".taxable"}]}, {"var": ".amount"}]}`},
+		{`reduce(items, item.accumulator + item.current, 0)`, `{"reduce": [{"var": "items"}, {"+": [{"var": ".accumulator"}, {"var": ".current"}]}, 0]}`},
+		{`round_currency(net * 0.25, 'SEK')`, `{"round_currency": [{"*": [{"var": "net"}, 0.25]}, "SEK"]}`},
+		{`upper(trim(country)) == 'SE'`, `{"==": [{"upper": [{"trim": [{"var": "country"}]}]}, "SE"]}`},
+		{`-x`, `{"-": [0, {"var": "x"}]}`},
+		{`-(a + b) * 2`, `{"*": [{"-": [0, {"+": [{"var": "a"}, {"var": "b"}]}]}, 2]}`},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseExpr(tt.text)
+		if err != nil {
+			t.Fatalf("ParseExpr(%q) failed: %v", tt.text, err)
+		}
+		var want any
+		if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+			t.Fatalf("bad test expression %s: %v", tt.want, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			gotJSON, _ := json.Marshal(got)
+			t.Errorf("ParseExpr(%q) = %s, want %s", tt.text, gotJSON, tt.want)
+		}
+	}
+
+	for _, bad := range []string{`a >=`, `(a > 1`, `"open`, `a # b`, `a b`} {
+		if _, err := ParseExpr(bad); err == nil {
+			t.Errorf("ParseExpr(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseExprRoundTrip(t *testing.T) {
+	// Everything FormatExpr renders parses back to the same JSON-logic
+	for _, src := range []string{
+		`{"and": [{">=": [{"var": "credit_score"}, 700]}, {"in": [{"var": "employment_status"}, ["employed", "self_employed"]]}]}`,
+		`{"and": [{"or": [{"var": "a"}, {"var": "b"}]}, {"!": {"var": "c"}}]}`,
+		`{"-": [10, {"-": [5, 2]}]}`,
+		`{"if": [{"<": [{"var": "age"}, 18]}, "minor", {"<": [{"var": "age"}, 65]}, "adult", "senior"]}`,
+		`{"some": [{"var": "items"}, {">": [{"var": ""}, 5]}]}`,
+		`{"!=": [{"var": "status"}, null]}`,
+	} {
+		var expr any
+		if err := json.Unmarshal([]byte(src), &expr); err != nil {
+			t.Fatalf("bad test expression %s: %v", src, err)
+		}
+		parsed, err := ParseExpr(FormatExpr(expr))
+		if err != nil {
+			t.Fatalf("ParseExpr(%q) failed: %v", FormatExpr(expr), err)
+		}
+		if !reflect.DeepEqual(parsed, expr) {
+			t.Errorf("round trip of %s changed it to %v", src, parsed)
+		}
+	}
+}
+
+func TestRunInfixConditions(t *testing.T) {
+	schema := `{
+		"definitions": {
+			"credit_score": {"type": "number", "value": 720},
+			"dti": {"type": "number", "value": 0.35},
+			"approved": {"type": "boolean", "value": false},
+			"tier": {"type": "string"}
+		},
+		"state_model": {
+			"derived": {"score_band": {"eval": "= if credit_score >= 700 then 'prime' else 'subprime'"}}
+		},
+		"logic_tree": [
+			{"id": "approve", "when": "credit_score >= 700 and dti <= 0.43", "then": {"set": {"approved": true}}},
+			{"id": "broken", "when": "credit_score >=", "then": {"set": {"tier": "gold"}}}
+		]
+	}`
+
+	result, err := Run(schema, time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	parsed := parseResult(t, result)
+
+	assertDefinitionValue(t, parsed, "approved", true)
+	assertDefinitionValue(t, parsed, "score_band", "prime")
+	if parsed.Definitions["tier"].Value != nil {
+		t.Error("rule with unparseable condition should not fire")
+	}
+	if len(parsed.Errors) != 1 || parsed.Errors[0].Kind != ErrRuntimeWarning ||
+		!strings.Contains(parsed.Errors[0].Message, "unparseable when") {
+		t.Fatalf("expected one runtime warning for the broken rule, got %+v", parsed.Errors)
+	}
+}
+
+func TestRunInfixDerived(t *testing.T) {
+	schema := `{
+		"definitions": {"x": {"type": "number", "value": 5}},
+		"state_model": {"derived": {
+			"negated": {"eval": "= -x"},
+			"offset": {"eval": "= -x + 8"},
+			"state": {"eval": "pending"},
+			"note": {"eval": "Not applicable"},
+			"copy": {"eval": {"var": "x"}}
+		}}
+	}`
+
+	result, err := Run(schema, time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	parsed := parseResult(t, result)
+
+	assertDefinitionValue(t, parsed, "negated", -5.0)
+	assertDefinitionValue(t, parsed, "offset", 3.0)
+	// Strings that aren't operator expressions stay constants, as before infix eval
+	assertDefinitionValue(t, parsed, "state", "pending")
+	assertDefinitionValue(t, parsed, "note", "Not applicable")
+	assertDefinitionValue(t, parsed, "copy", 5.0)
+	if len(parsed.Errors) != 0 {
+		t.Fatalf("expected no errors, got %+v", parsed.Errors)
+	}
+}
@@ -0,0 +1,159 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WithPartial evaluates a document that doesn't decode as a whole by skipping the parts that
+// don't: a malformed definition, rule, derived field, attestation, decision table or temporal
+// branch is dropped, and so is any other top-level key with the wrong shape. Each skipped part
+// is reported as a runtime warning naming its path, and the rest is evaluated as usual. Meant
+// for editors previewing half-written schemas; the status reflects only the parts evaluated, so
+// don't use it to accept submissions. Documents that aren't valid JSON objects still fail.
+func WithPartial() RunOption {
+	return func(c *runConfig) {
+		c.partial = true
+	}
+}
+
+// decodePartial decodes data into schema after a whole-document decode failed, dropping the
+// parts that don't decode. It returns a warning per skipped part, or the decode error when the
+// document isn't a JSON object or still fails without those parts.
+func decodePartial(data []byte, cfg runConfig, schema *Schema) ([]ValidationError, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var skipped []ValidationError
+	skip := func(fieldID, ruleID, path string, err error) {
+		skipped = append(skipped, ValidationError{
+			FieldID: fieldID,
+			RuleID:  ruleID,
+			Kind:    ErrRuntimeWarning,
+			Message: fmt.Sprintf("Skipped malformed %s: %v", path, err),
+		})
+	}
+
+	for _, key := range sortedIDs(doc) {
+		raw := doc[key]
+		var err error
+		switch key {
+		case "definitions":
+			raw, err = dropBadEntries[Definition](raw, func(id string, err error) {
+				skip(id, "", "definitions."+id, err)
+			})
+		case "attestations":
+			raw, err = dropBadEntries[Attestation](raw, func(id string, err error) {
+				skip(id, "", "attestations."+id, err)
+			})
+		case "law_refs":
+			raw, err = dropBadEntries[LawRef](raw, func(id string, err error) {
+				skip("", "", "law_refs."+id, err)
+			})
+		case "logic_tree":
+			raw, err = dropBadElements[Rule](raw, func(i int, elem json.RawMessage, err error) {
+				path, id := fmt.Sprintf("logic_tree[%d]", i), elementID(elem)
+				if id != "" {
+					path += fmt.Sprintf(" (rule '%s')", id)
+				}
+				skip("", id, path, err)
+			})
+		case "decision_tables":
+			raw, err = dropBadElements[DecisionTable](raw, func(i int, _ json.RawMessage, err error) {
+				skip("", "", fmt.Sprintf("decision_tables[%d]", i), err)
+			})
+		case "temporal_map":
+			raw, err = dropBadElements[TemporalBranch](raw, func(i int, _ json.RawMessage, err error) {
+				skip("", "", fmt.Sprintf("temporal_map[%d]", i), err)
+			})
+		case "state_model":
+			raw, err = dropBadDerived(raw, func(name string, err error) {
+				skip(name, "", "state_model.derived."+name, err)
+			})
+		}
+		if err == nil {
+			// Whatever remains must decode in place (this also catches keys without entries)
+			var probe Schema
+			err = json.Unmarshal(append(append([]byte(`{"`+key+`":`), raw...), '}'), &probe)
+		}
+		if err != nil {
+			skip("", "", key, err)
+			delete(doc, key)
+			continue
+		}
+		doc[key] = raw
+	}
+
+	cleaned, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.unmarshal(cleaned, schema); err != nil {
+		return nil, err
+	}
+	return skipped, nil
+}
+
+// dropBadEntries removes the entries of a JSON object that don't decode as T.
+func dropBadEntries[T any](raw json.RawMessage, report func(id string, err error)) (json.RawMessage, error) {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil || entries == nil {
+		return raw, err
+	}
+	for _, id := range sortedIDs(entries) {
+		var v T
+		if err := json.Unmarshal(entries[id], &v); err != nil {
+			report(id, err)
+			delete(entries, id)
+		}
+	}
+	return json.Marshal(entries)
+}
+
+// dropBadElements removes the elements of a JSON array that don't decode as T.
+func dropBadElements[T any](raw json.RawMessage, report func(i int, elem json.RawMessage, err error)) (json.RawMessage, error) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil || elems == nil {
+		return raw, err
+	}
+	kept := elems[:0]
+	for i, elem := range elems {
+		var v T
+		if err := json.Unmarshal(elem, &v); err != nil {
+			report(i, elem, err)
+			continue
+		}
+		kept = append(kept, elem)
+	}
+	return json.Marshal(kept)
+}
+
+// dropBadDerived removes the derived fields of a state_model that don't decode.
+func dropBadDerived(raw json.RawMessage, report func(name string, err error)) (json.RawMessage, error) {
+	var model map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &model); err != nil || model == nil {
+		return raw, err
+	}
+	derived, ok := model["derived"]
+	if !ok {
+		return raw, nil
+	}
+	derived, err := dropBadEntries[DerivedDef](derived, report)
+	if err != nil {
+		return nil, err
+	}
+	model["derived"] = derived
+	return json.Marshal(model)
+}
+
+// elementID returns the string id of a malformed rule, or "" when it has none.
+func elementID(elem json.RawMessage) string {
+	var v struct {
+		ID any `json:"id"`
+	}
+	json.Unmarshal(elem, &v)
+	id, _ := v.ID.(string)
+	return id
+}
@@ -0,0 +1,152 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PatchOp is one JSON Patch (RFC 6902) operation: "add", "remove" or "replace".
+type PatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`            // JSON Pointer (RFC 6901), e.g. "/definitions/tier/value"
+	Value json.RawMessage `json:"value,omitempty"` // New value, for add and replace
+}
+
+// WithPatchOutput makes Run return a JSON Patch (RFC 6902) from the input document to the
+// result instead of the result itself, so a front-end can apply the changes to the document
+// it holds rather than replace it. The patch starts from the input as parsed and marshaled
+// the way the result is, so only what the run changed is included: values rules set,
+// visibility and required flags, errors and status. Keys the schema doesn't know and number
+// formatting (1.50 for 1.5) are not patched. WithCompactOutput applies to the patch.
+func WithPatchOutput() RunOption {
+	return func(c *runConfig) {
+		c.patch = true
+	}
+}
+
+// Diff returns the JSON Patch that turns the document from into to. Objects are compared
+// key by key and arrays of the same length element by element; anything else that differs,
+// including arrays whose length changed, is replaced whole. Keys are visited in sorted
+// order, so the patch is the same for the same documents.
+func Diff(from, to string) ([]PatchOp, error) {
+	fromDoc, err := decodeNumbers(from)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal from: %w", err)
+	}
+	toDoc, err := decodeNumbers(to)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal to: %w", err)
+	}
+	var ops []PatchOp
+	if err := diffValues("", fromDoc, toDoc, &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// decodeNumbers decodes a JSON document, keeping numbers as written.
+func decodeNumbers(jsonText string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(jsonText))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// diffValues appends the operations that turn from into to at path.
+func diffValues(path string, from, to any, ops *[]PatchOp) error {
+	switch f := from.(type) {
+	case map[string]any:
+		t, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+		for _, key := range sortedIDs(f) {
+			if _, kept := t[key]; !kept {
+				*ops = append(*ops, PatchOp{Op: "remove", Path: path + "/" + pointerEscaper.Replace(key)})
+			}
+		}
+		for _, key := range sortedIDs(t) {
+			keyPath := path + "/" + pointerEscaper.Replace(key)
+			old, exists := f[key]
+			if !exists {
+				if err := appendPatchOp(ops, "add", keyPath, t[key]); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := diffValues(keyPath, old, t[key], ops); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		t, ok := to.([]any)
+		if !ok || len(t) != len(f) {
+			break
+		}
+		for i := range f {
+			if err := diffValues(path+"/"+strconv.Itoa(i), f[i], t[i], ops); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+	return appendPatchOp(ops, "replace", path, to)
+}
+
+func appendPatchOp(ops *[]PatchOp, op, path string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	*ops = append(*ops, PatchOp{Op: op, Path: path, Value: raw})
+	return nil
+}
+
+// pointerEscaper escapes a key for use as a JSON Pointer reference token.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// marshalInput marshals a parsed input document as Run would marshal its result, without
+// evaluating it.
+func marshalInput(schema *Schema, cfg runConfig) (string, error) {
+	input := cloneSchema(schema)
+	if !cfg.verbose {
+		omitDefaults(input)
+	}
+	out, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("marshal input: %w", err)
+	}
+	return string(out), nil
+}
+
+// patchResult is the WithPatchOutput result: the patch from input to result, marshaled like
+// the result would have been.
+func patchResult(input, result string, cfg runConfig) (string, error) {
+	ops, err := Diff(input, result)
+	if err != nil {
+		return "", err
+	}
+	if ops == nil {
+		ops = []PatchOp{}
+	}
+	var out []byte
+	if cfg.compact {
+		out, err = json.Marshal(ops)
+	} else {
+		out, err = json.MarshalIndent(ops, "", "  ")
+	}
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,50 @@
+package tenet
+
+import "fmt"
+
+// fixedPrefills returns the author-provided values of editable fields whose prefill
+// policy is fixed, keyed by field ID. Readonly fields are already checked as computed.
+func fixedPrefills(base *Schema) map[string]any {
+	fixed := make(map[string]any)
+	for id, def := range base.Definitions {
+		if def == nil || def.Value == nil || def.IsReadonly() {
+			continue
+		}
+		policy := def.Prefill
+		if policy == "" {
+			policy = base.PrefillPolicy
+		}
+		if policy == PrefillFixed {
+			fixed[id] = def.Value
+		}
+	}
+	return fixed
+}
+
+// checkPrefills reports submitted values that differ from fixed author-provided values.
+// A field left out of the submission keeps the author's value and is not reported.
+func checkPrefills(newSchema *Schema, fixed map[string]any) []VerifyIssue {
+	engine := &Engine{}
+	var issues []VerifyIssue
+	for _, id := range sortedIDs(fixed) {
+		newDef, ok := newSchema.Definitions[id]
+		if !ok || newDef == nil || engine.compareEqual(newDef.Value, fixed[id]) {
+			continue
+		}
+		issues = append(issues, VerifyIssue{
+			Code:     VerifyPrefillChanged,
+			Severity: VerifySeverityError,
+			FieldID:  id,
+			Message:  fmt.Sprintf("field '%s' has a fixed value that the submission changed", id),
+			Expected: fixed[id],
+			Claimed:  newDef.Value,
+			Remediation: &Remediation{
+				Action:      RemediationSetValue,
+				Target:      id,
+				Value:       fixed[id],
+				Description: fmt.Sprintf("restore field '%s' to the value the schema provides", id),
+			},
+		})
+	}
+	return issues
+}
@@ -0,0 +1,41 @@
+package tenet
+
+import "strings"
+
+// Provenance names the rule that last set a definition's value (see WithProvenance).
+type Provenance struct {
+	RuleID string `json:"rule_id"`
+	LawRef string `json:"law_ref,omitempty"` // The rule's legal citation
+}
+
+// WithProvenance records on each definition a rule set the rule and law_ref that produced its
+// value, as `set_by`, so the output shows why a computed value is what it is. A rule that sets
+// a nested path ("applicant.age") is recorded on the root definition. Inputs and derived
+// values carry no set_by; set_by in the input is ignored and recomputed.
+func WithProvenance() RunOption {
+	return func(c *runConfig) {
+		c.provenance = true
+	}
+}
+
+// recordProvenance notes that the current rule set key.
+func (e *Engine) recordProvenance(key, ruleID string) {
+	root, _, _ := strings.Cut(key, ".")
+	p := &Provenance{RuleID: ruleID}
+	if rule := e.currentRule; rule != nil && rule.ID == ruleID {
+		p.LawRef = rule.LawRef
+	}
+	if e.setBy == nil {
+		e.setBy = make(map[string]*Provenance)
+	}
+	e.setBy[root] = p
+}
+
+// applyProvenance stamps the recorded provenance onto the definitions.
+func (e *Engine) applyProvenance() {
+	for id, p := range e.setBy {
+		if def := e.schema.Definitions[id]; def != nil {
+			def.SetBy = p
+		}
+	}
+}
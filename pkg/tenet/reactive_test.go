@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -203,6 +204,147 @@ func TestDerivedStateComputation(t *testing.T) {
 	})
 }
 
+// TestFixedPoint tests that WithFixedPoint repeats logic and derived state until values settle.
+func TestFixedPoint(t *testing.T) {
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	// flag_high reads a derived value that only changes after set_base runs
+	chain := `{
+		"definitions": {
+			"base": {"type": "number", "value": 1},
+			"flagged": {"type": "boolean", "value": false},
+			"notes": {"type": "string"}
+		},
+		"state_model": {"derived": {"doubled": {"eval": {"*": [{"var": "base"}, 2]}}}},
+		"logic_tree": [
+			{"id": "flag_high", "when": {">": [{"var": "doubled"}, 10]}, "then": {"set": {"flagged": true}}},
+			{"id": "needs_notes", "when": {"==": [{"var": "flagged"}, true]}, "then": {"error_msg": "Notes are required", "error_kind": "notice"}},
+			{"id": "set_base", "when": {"==": [1, 1]}, "then": {"set": {"base": 20}}}
+		]
+	}`
+
+	single, err := Run(chain, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	assertDefinitionValue(t, parseResult(t, single), "flagged", false)
+
+	result, err := Run(chain, date, WithFixedPoint(0))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+	assertDefinitionValue(t, schema, "doubled", 40.0)
+	assertDefinitionValue(t, schema, "flagged", true)
+	if len(schema.Errors) != 1 || schema.Errors[0].RuleID != "needs_notes" {
+		t.Errorf("expected only the last pass's errors, got %+v", schema.Errors)
+	}
+
+	// Verify replays with the compiled option
+	compiled, err := Compile(chain, WithFixedPoint(0))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if vr := VerifyWithCompiled(result, compiled); !vr.Valid {
+		t.Errorf("expected the fixed-point result to verify, got %+v", vr.Issues)
+	}
+
+	t.Run("does not converge", func(t *testing.T) {
+		counter := `{
+			"definitions": {"count": {"type": "number", "value": 0}},
+			"logic_tree": [{"id": "bump", "when": {"==": [1, 1]}, "then": {"set": {"count": {"+": [{"var": "count"}, 1]}}}}]
+		}`
+		result, err := Run(counter, date, WithFixedPoint(5))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		assertDefinitionValue(t, schema, "count", 5.0)
+		if len(schema.Errors) != 1 || schema.Errors[0].Kind != ErrRuntimeWarning {
+			t.Errorf("expected a non-convergence warning, got %+v", schema.Errors)
+		}
+	})
+}
+
+// TestSession checks that a Session's edits re-evaluate only what depends on them and
+// give the same result as running the edited document.
+func TestSession(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	session, err := NewSession(createLoanSchema("employed", 720, 75000, 250000), date)
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	assertDefinitionValue(t, session.Schema(), "approval_status", "approved")
+
+	delta, err := session.SetValue("credit_score", 580.0)
+	if err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	// rule_low_credit_review and rule_good_credit_approval read credit_score
+	if delta.RulesEvaluated != 2 || delta.DerivedEvaluated != 0 {
+		t.Errorf("expected only the two credit rules to be evaluated, got %d rules and %d derived fields",
+			delta.RulesEvaluated, delta.DerivedEvaluated)
+	}
+	for _, id := range []string{"credit_score", "approval_status", "risk_level", "additional_docs_required", "income_verification"} {
+		if delta.Changed[id] == nil {
+			t.Errorf("expected %s in the delta, got %v", id, delta.Changed)
+		}
+	}
+	if delta.Changed["loan_amount"] != nil || delta.Changed["debt_to_income_ratio"] != nil {
+		t.Errorf("expected unchanged fields to be left out of the delta, got %v", delta.Changed)
+	}
+	assertEqual(t, delta.Changed["approval_status"].Value, "review_required")
+
+	// A field no rule or derived value reads evaluates nothing, but is still validated
+	delta, err = session.SetValue("income_verification", true)
+	if err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	if delta.RulesEvaluated != 0 || delta.DerivedEvaluated != 0 {
+		t.Errorf("expected no evaluation, got %d rules and %d derived fields", delta.RulesEvaluated, delta.DerivedEvaluated)
+	}
+
+	// Derived values and the rules that read them are re-evaluated with their inputs
+	delta, err = session.SetValue("loan_amount", 400000.0)
+	if err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	if delta.DerivedEvaluated == 0 || delta.Changed["debt_to_income_ratio"] == nil {
+		t.Errorf("expected debt_to_income_ratio to be re-evaluated, got %+v", delta)
+	}
+
+	got, err := session.Result()
+	if err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+	want, err := Run(createLoanSchemaFull("employed", 580, 75000, 400000, true), date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("session result differs from Run:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := session.SetValue("debt_to_income_ratio", 0.1); err == nil {
+		t.Error("expected setting a derived field to fail")
+	}
+	if _, err := session.SetValue("nonexistent", 1); err == nil {
+		t.Error("expected setting an undefined field to fail")
+	}
+
+	// A derived field with a constant eval reads nothing, but is still not an input
+	constant, err := NewSession(`{
+		"definitions": {"tier": {"type": "string"}},
+		"state_model": {"derived": {"tier": {"eval": "standard"}}}
+	}`, date)
+	if err != nil {
+		t.Fatalf("NewSession failed: %v", err)
+	}
+	if _, err := constant.SetValue("tier", "premium"); err == nil || !strings.Contains(err.Error(), "readonly") {
+		t.Errorf("expected setting a constant derived field to fail, got %v", err)
+	}
+	assertDefinitionValue(t, constant.Schema(), "tier", "standard")
+}
+
 // TestUIModification tests that rules can modify UI metadata on definitions.
 func TestUIModification(t *testing.T) {
 	effectiveDate := time.Now()
@@ -223,10 +365,10 @@ func TestUIModification(t *testing.T) {
 			t.Fatal("income_verification not found")
 		}
 
-		if attestation.Visible == nil || !*attestation.Visible {
+		if !attestation.IsVisible() {
 			t.Error("income_verification should be visible")
 		}
-		if !attestation.Required {
+		if !attestation.IsRequired() {
 			t.Error("income_verification should be required")
 		}
 	})
@@ -428,8 +570,8 @@ func assertDefinitionRequired(t *testing.T, schema *Schema, id string, expected
 		t.Errorf("Definition '%s' not found", id)
 		return
 	}
-	if def.Required != expected {
-		t.Errorf("Definition '%s'.Required = %v, want %v", id, def.Required, expected)
+	if def.IsRequired() != expected {
+		t.Errorf("Definition '%s'.Required = %v, want %v", id, def.IsRequired(), expected)
 	}
 }
 
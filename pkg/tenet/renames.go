@@ -0,0 +1,69 @@
+package tenet
+
+import "fmt"
+
+// renamedFields maps each legacy ID the base schema declares in renamed_from to the field
+// that now has it. IDs that are still fields are skipped, and a legacy ID claimed by several
+// fields goes to the first by ID.
+func renamedFields(base *Schema) map[string]string {
+	renames := make(map[string]string)
+	for _, id := range sortedIDs(base.Definitions) {
+		def := base.Definitions[id]
+		if def == nil {
+			continue
+		}
+		for _, old := range def.RenamedFrom {
+			if _, isField := base.Definitions[old]; isField || old == "" {
+				continue
+			}
+			if _, claimed := renames[old]; !claimed {
+				renames[old] = id
+			}
+		}
+	}
+	return renames
+}
+
+// migrateRenamed returns the submission with definitions under legacy IDs moved to their
+// current IDs, and a warning for each one moved. The submission itself isn't modified. A
+// legacy definition whose current ID is also submitted stays where it is and is reported
+// as unknown.
+func migrateRenamed(newSchema *Schema, renames map[string]string) (*Schema, []VerifyIssue) {
+	var moved []string
+	for _, old := range sortedIDs(renames) {
+		if _, ok := newSchema.Definitions[old]; !ok {
+			continue
+		}
+		if _, ok := newSchema.Definitions[renames[old]]; !ok {
+			moved = append(moved, old)
+		}
+	}
+	if len(moved) == 0 {
+		return newSchema, nil
+	}
+
+	migrated := *newSchema
+	migrated.Definitions = make(map[string]*Definition, len(newSchema.Definitions))
+	for id, def := range newSchema.Definitions {
+		migrated.Definitions[id] = def
+	}
+	var issues []VerifyIssue
+	for _, old := range moved {
+		id := renames[old]
+		migrated.Definitions[id] = migrated.Definitions[old]
+		delete(migrated.Definitions, old)
+		issues = append(issues, VerifyIssue{
+			Code:     VerifyRenamedField,
+			Severity: VerifySeverityWarning,
+			FieldID:  old,
+			Message:  fmt.Sprintf("field '%s' has been renamed to '%s'", old, id),
+			Remediation: &Remediation{
+				Action:      RemediationRenameField,
+				Target:      old,
+				Value:       id,
+				Description: fmt.Sprintf("move field '%s' to '%s'", old, id),
+			},
+		})
+	}
+	return &migrated, issues
+}
@@ -3,6 +3,7 @@ package tenet
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Engine holds state during execution of a schema.
@@ -11,17 +12,31 @@ type Engine struct {
 	errors            []ValidationError
 	fieldsSet         map[string]string // tracks which fields were set by which rule (cycle detection)
 	currentElement    any               // current element context for some/all/none operators
-	derivedInProgress map[string]bool   // cycle detection for derived fields
+	derivedInProgress map[string]bool   // cycle detection for derived fields read outside computeDerived
+	derivedPlan       *derivedPlan      // derived evaluation order (built by the first computeDerived)
+	derivedValues     map[string]any    // derived values computed so far by the running computeDerived
+	derivedPass       int               // computeDerived calls so far, to key the session cache
+
+	fieldListeners []func(FieldChange)         // registered via OnFieldChanged
+	errorListeners []func(ValidationError)     // registered via OnErrorAdded
+	ruleTimer      func(string, time.Duration) // per-rule timing hook (nil = untimed)
+	currentRule    *Rule                       // rule whose action is being applied (nil outside the logic tree)
+	index          *schemaIndex                // lookups precomputed by Compile (nil outside compiled runs)
+	date           time.Time                   // effective date of the run, for today and now
+	decimal        bool                        // currency arithmetic in exact decimal (WithDecimalCurrency)
+	currencyPlaces int                         // decimals computed currency values are rounded to
+	allocMax       int                         // Limits.MaxAllocation (0 = unlimited)
+	allocUsed      int                         // bytes charged so far
+	readOnly       bool                        // Check: rules don't set values
+	provenance     bool                        // Record the rule behind each set value (WithProvenance)
+	setBy          map[string]*Provenance      // Root definition ID → rule that last set it
+	session        *sessionCache               // Results reused across a Session's evaluations (nil = none)
 }
 
 // NewEngine creates an engine for the given schema.
+// Bookkeeping maps and the error slice are allocated lazily — most runs set few fields and emit few errors.
 func NewEngine(schema *Schema) *Engine {
-	return &Engine{
-		schema:            schema,
-		errors:            make([]ValidationError, 0),
-		fieldsSet:         make(map[string]string),
-		derivedInProgress: make(map[string]bool),
-	}
+	return &Engine{schema: schema}
 }
 
 // resolve evaluates any JSON-logic node and returns its value.
@@ -62,22 +77,43 @@ func (e *Engine) resolve(node any) any {
 
 // getVar retrieves a value using dot notation: "user.address.city"
 // Returns nil if the path doesn't exist (distinguishes "unknown" from "zero").
-// Special case: empty path "" returns the current element context (used by some/all/none).
+// Special case: empty path "" returns the current element context (used by some/all/none
+// and aggregations), and a leading dot reads from it: ".amount" is the element's amount.
 func (e *Engine) getVar(path string) any {
 	if path == "" {
 		// Return current element context for {"var": ""} in some/all/none
 		return e.currentElement
 	}
+	if rest, ok := strings.CutPrefix(path, "."); ok {
+		return e.accessPath(e.currentElement, strings.Split(rest, "."))
+	}
 
-	parts := strings.Split(path, ".")
+	// Fast path: most references are plain field IDs; compiled schemas index dotted paths
+	var parts []string
+	if strings.IndexByte(path, '.') < 0 {
+		parts = []string{path}
+	} else if parts = e.index.path(path); parts == nil {
+		parts = strings.Split(path, ".")
+	}
 
 	// First, check derived state (derived values take precedence)
 	if e.schema.StateModel != nil && e.schema.StateModel.Derived != nil {
 		if derived, ok := e.schema.StateModel.Derived[parts[0]]; ok {
+			// Within computeDerived dependencies are already computed; fields on a cycle
+			// were reported once and read as unset
+			if value, ok := e.derivedValues[parts[0]]; ok {
+				return e.accessPath(value, parts[1:])
+			}
+			if e.derivedPlan != nil && e.derivedPlan.cyclic[parts[0]] {
+				return nil
+			}
 			if e.derivedInProgress[parts[0]] {
 				e.addError("", "", ErrCycleDetected, fmt.Sprintf("Circular dependency detected in derived field '%s'", parts[0]), "")
 				return nil
 			}
+			if e.derivedInProgress == nil {
+				e.derivedInProgress = make(map[string]bool)
+			}
 			e.derivedInProgress[parts[0]] = true
 			result := e.resolve(derived.Eval)
 			delete(e.derivedInProgress, parts[0])
@@ -97,6 +133,11 @@ func (e *Engine) getVar(path string) any {
 		return e.accessPath(def.Value, parts[1:])
 	}
 
+	// A former ID listed in aliases reads the field it was renamed to
+	if target, ok := e.aliasPath(path); ok {
+		return e.getVar(target)
+	}
+
 	// Variable not found - add error (unless we're in a some/all/none context)
 	if e.currentElement == nil {
 		e.addError("", "", ErrRuntimeWarning, fmt.Sprintf("Undefined variable '%s' in logic expression", parts[0]), "")
@@ -173,7 +214,7 @@ func (e *Engine) resolveArgs(args any, expected int) []any {
 
 // addError appends a validation error to the engine's error list.
 func (e *Engine) addError(fieldID, ruleID string, kind ErrorKind, message, lawRef string) {
-	e.errors = append(e.errors, ValidationError{
+	e.emitError(ValidationError{
 		FieldID: fieldID,
 		RuleID:  ruleID,
 		Kind:    kind,
@@ -181,3 +222,20 @@ func (e *Engine) addError(fieldID, ruleID string, kind ErrorKind, message, lawRe
 		LawRef:  lawRef,
 	})
 }
+
+// emitError appends a prepared validation error, filling in its citation and rule documentation.
+func (e *Engine) emitError(err ValidationError) {
+	fieldID, ruleID, message, lawRef := err.FieldID, err.RuleID, err.Message, err.LawRef
+	e.charge(fieldID, len(message)+64)
+	if lawRef != "" && e.schema != nil {
+		err.Citation = e.schema.LawRefs[lawRef]
+	}
+	if rule := e.currentRule; rule != nil && rule.ID == ruleID {
+		err.RuleTitle = rule.Title
+		err.References = rule.References
+	}
+	e.errors = append(e.errors, err)
+	for _, fn := range e.errorListeners {
+		fn(err)
+	}
+}
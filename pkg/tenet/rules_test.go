@@ -0,0 +1,622 @@
+package tenet
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRuleTags(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"amount": {"type": "number", "value": 100}
+		},
+		"logic_tree": [
+			{"id": "always", "when": {">": [{"var": "amount"}, 0]}, "then": {"set": {"base": true}}},
+			{"id": "final", "tags": ["submission"], "when": {">": [{"var": "amount"}, 0]}, "then": {"set": {"final_check": true}}},
+			{"id": "hint", "tags": ["draft_hints"], "when": {">": [{"var": "amount"}, 0]}, "then": {"set": {"hint_shown": true}}},
+			{"id": "both", "tags": ["submission", "draft_hints"], "when": {">": [{"var": "amount"}, 0]}, "then": {"set": {"both": true}}}
+		]
+	}`
+
+	fired := func(t *testing.T, opts ...RunOption) map[string]bool {
+		t.Helper()
+		result, err := Run(input, date, opts...)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		out := make(map[string]bool)
+		for _, id := range []string{"base", "final_check", "hint_shown", "both"} {
+			_, out[id] = schema.Definitions[id]
+		}
+		return out
+	}
+
+	t.Run("no options evaluates all rules", func(t *testing.T) {
+		got := fired(t)
+		for id, ok := range got {
+			if !ok {
+				t.Errorf("expected %s to be set", id)
+			}
+		}
+	})
+
+	t.Run("include only submission", func(t *testing.T) {
+		got := fired(t, WithTags("submission"))
+		assertEqual(t, got["base"], false)
+		assertEqual(t, got["final_check"], true)
+		assertEqual(t, got["hint_shown"], false)
+		assertEqual(t, got["both"], true)
+	})
+
+	t.Run("exclude draft hints", func(t *testing.T) {
+		got := fired(t, WithoutTags("draft_hints"))
+		assertEqual(t, got["base"], true)
+		assertEqual(t, got["final_check"], true)
+		assertEqual(t, got["hint_shown"], false)
+		assertEqual(t, got["both"], false)
+	})
+
+	t.Run("exclude wins over include", func(t *testing.T) {
+		got := fired(t, WithTags("submission"), WithoutTags("draft_hints"))
+		assertEqual(t, got["final_check"], true)
+		assertEqual(t, got["both"], false)
+	})
+}
+
+func TestWhenArrayAndWhenAny(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	schemaFor := func(score float64, employment string) string {
+		return fmt.Sprintf(`{
+			"definitions": {
+				"credit_score": {"type": "number", "value": %v},
+				"employment": {"type": "string", "value": %q}
+			},
+			"logic_tree": [
+				{
+					"id": "approve",
+					"when": [
+						{">=": [{"var": "credit_score"}, 700]},
+						{"!=": [{"var": "employment"}, "unemployed"]}
+					],
+					"then": {"set": {"approved": true}}
+				},
+				{
+					"id": "review",
+					"when_any": [
+						{"<": [{"var": "credit_score"}, 600]},
+						{"==": [{"var": "employment"}, "self_employed"]}
+					],
+					"then": {"set": {"manual_review": true}}
+				},
+				{
+					"id": "combined",
+					"when": {">=": [{"var": "credit_score"}, 500]},
+					"when_any": [
+						{"==": [{"var": "employment"}, "employed"]},
+						{"==": [{"var": "employment"}, "retired"]}
+					],
+					"then": {"set": {"combined": true}}
+				}
+			]
+		}`, score, employment)
+	}
+
+	tests := []struct {
+		name       string
+		score      float64
+		employment string
+		want       map[string]bool
+	}{
+		{"all conditions hold", 720, "employed", map[string]bool{"approved": true, "manual_review": false, "combined": true}},
+		{"one AND condition fails", 720, "unemployed", map[string]bool{"approved": false, "manual_review": false, "combined": false}},
+		{"any condition holds", 550, "employed", map[string]bool{"approved": false, "manual_review": true, "combined": true}},
+		{"second any condition holds", 750, "self_employed", map[string]bool{"approved": true, "manual_review": true, "combined": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run(schemaFor(tt.score, tt.employment), date)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			schema := parseResult(t, result)
+			for id, want := range tt.want {
+				_, got := schema.Definitions[id]
+				if got != want {
+					t.Errorf("%s set = %v, want %v", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUnlessGuard(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	schemaFor := func(income float64, exempt, student bool) string {
+		return fmt.Sprintf(`{
+			"definitions": {
+				"income": {"type": "number", "value": %v},
+				"exempt_s7": {"type": "boolean", "value": %v},
+				"student": {"type": "boolean", "value": %v}
+			},
+			"logic_tree": [
+				{
+					"id": "fee_applies",
+					"law_ref": "Fee Act §3",
+					"when": {">": [{"var": "income"}, 10000]},
+					"unless": {"var": "exempt_s7"},
+					"then": {"set": {"fee_due": true}}
+				},
+				{
+					"id": "surcharge",
+					"when": {">": [{"var": "income"}, 10000]},
+					"unless": [{"var": "exempt_s7"}, {"var": "student"}],
+					"then": {"set": {"surcharge_due": true}}
+				},
+				{
+					"id": "only_unless",
+					"unless": {"var": "exempt_s7"},
+					"then": {"set": {"not_exempt": true}}
+				}
+			]
+		}`, income, exempt, student)
+	}
+
+	tests := []struct {
+		name    string
+		income  float64
+		exempt  bool
+		student bool
+		want    map[string]bool
+	}{
+		{"no exception", 20000, false, false, map[string]bool{"fee_due": true, "surcharge_due": true, "not_exempt": true}},
+		{"exempt under s7", 20000, true, false, map[string]bool{"fee_due": false, "surcharge_due": false, "not_exempt": false}},
+		{"one of several exceptions", 20000, false, true, map[string]bool{"fee_due": true, "surcharge_due": false, "not_exempt": true}},
+		{"when fails regardless of unless", 5000, false, false, map[string]bool{"fee_due": false, "surcharge_due": false, "not_exempt": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run(schemaFor(tt.income, tt.exempt, tt.student), date)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			schema := parseResult(t, result)
+			for id, want := range tt.want {
+				_, got := schema.Definitions[id]
+				if got != want {
+					t.Errorf("%s set = %v, want %v", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSetNestedPaths(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"applicant": {"type": "object", "value": {"name": "Anna", "address": {"zip": "11122"}}},
+			"country": {"type": "string", "value": "SE"}
+		},
+		"logic_tree": [
+			{
+				"id": "fill_city",
+				"when": {"==": [{"var": "applicant.address.zip"}, "11122"]},
+				"then": {"set": {"applicant.address.city": "Stockholm", "meta.source.kind": "zip_lookup"}}
+			},
+			{
+				"id": "bad_path",
+				"when": {"==": [{"var": "country"}, "SE"]},
+				"then": {"set": {"country.code": "SE"}}
+			},
+			{
+				"id": "read_back",
+				"when": {"==": [{"var": "applicant.address.city"}, "Stockholm"]},
+				"then": {"set": {"city_known": true}}
+			}
+		]
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	applicant, _ := schema.Definitions["applicant"].Value.(map[string]any)
+	address, _ := applicant["address"].(map[string]any)
+	assertEqual(t, address["city"], any("Stockholm"))
+	assertEqual(t, address["zip"], any("11122"))
+	assertEqual(t, applicant["name"], any("Anna"))
+
+	meta, ok := schema.Definitions["meta"]
+	if !ok {
+		t.Fatal("expected 'meta' definition to be created")
+	}
+	assertEqual(t, meta.Type, "object")
+	source, _ := meta.Value.(map[string]any)["source"].(map[string]any)
+	assertEqual(t, source["kind"], any("zip_lookup"))
+
+	// Scalar values are never overwritten by a nested set
+	assertDefinitionValue(t, schema, "country", "SE")
+	found := false
+	for _, e := range schema.Errors {
+		if e.Kind == ErrRuntimeWarning && e.RuleID == "bad_path" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected runtime warning for bad_path, got %v", schema.Errors)
+	}
+
+	assertDefinitionValue(t, schema, "city_known", true)
+}
+
+func TestReadonlyProtection(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"readonly_protection": true,
+		"definitions": {
+			"income": {"type": "number", "value": 50000},
+			"decision": {"type": "string", "value": "pending", "readonly": true, "writable_by": ["core"]},
+			"audit_ref": {"type": "string", "value": "A-1", "readonly": true},
+			"note": {"type": "string"}
+		},
+		"logic_tree": [
+			{"id": "decide", "tags": ["core"], "when": {">": [{"var": "income"}, 0]}, "then": {"set": {"decision": "approved"}}},
+			{"id": "plugin_override", "tags": ["plugin"], "law_ref": "Internal 1.2", "when": {">": [{"var": "income"}, 0]}, "then": {"set": {"decision": "denied", "note": "plugin ran"}}},
+			{"id": "rewrite_ref", "when": {">": [{"var": "income"}, 0]}, "then": {"set": {"audit_ref": "B-2"}}}
+		],
+		"state_model": {"derived": {"tax": {"eval": {"*": [{"var": "income"}, 0.3]}}}}
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	assertDefinitionValue(t, schema, "decision", "approved")
+	assertDefinitionValue(t, schema, "audit_ref", "A-1")
+	// Unprotected fields in the same action are still set
+	assertDefinitionValue(t, schema, "note", "plugin ran")
+	assertDefinitionValue(t, schema, "tax", 15000.0)
+	assertEqual(t, schema.Status, StatusInvalid)
+
+	blocked := map[string]string{}
+	for _, e := range schema.Errors {
+		if e.Kind == ErrConstraintViolation {
+			blocked[e.RuleID] = e.FieldID
+		}
+	}
+	assertEqual(t, blocked["plugin_override"], "decision")
+	assertEqual(t, blocked["rewrite_ref"], "audit_ref")
+	assertHasErrorWithLawRef(t, schema, "Internal 1.2")
+
+	// Without the mode, the last writer wins as before
+	unprotected, err := Run(strings.Replace(input, `"readonly_protection": true`, `"readonly_protection": false`, 1), date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	assertDefinitionValue(t, parseResult(t, unprotected), "audit_ref", "B-2")
+}
+
+func TestRuleEffectiveDates(t *testing.T) {
+	input := `{
+		"definitions": {
+			"income": {"type": "number", "value": 50000},
+			"rate": {"type": "number", "value": 0}
+		},
+		"logic_tree": [
+			{"id": "old_rate", "valid_until": "2025-06-30", "when": {">": [{"var": "income"}, 0]}, "then": {"set": {"rate": 0.25}}},
+			{"id": "new_rate", "valid_from": "2025-07-01", "when": {">": [{"var": "income"}, 0]}, "then": {"set": {"rate": 0.22}}}
+		]
+	}`
+
+	tests := []struct {
+		date string
+		want float64
+	}{
+		{"2025-01-01", 0.25},
+		{"2025-06-30", 0.25},
+		{"2025-07-01", 0.22},
+		{"2026-01-01", 0.22},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			date, _ := time.Parse("2006-01-02", tt.date)
+			result, err := Run(input, date)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			schema := parseResult(t, result)
+			assertDefinitionValue(t, schema, "rate", tt.want)
+			if len(schema.Errors) != 0 {
+				t.Errorf("unexpected errors: %+v", schema.Errors)
+			}
+		})
+	}
+
+	bad := strings.Replace(input, `"valid_from": "2025-07-01"`, `"valid_from": "July 2025"`, 1)
+	result, err := Run(bad, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	// The unparseable bound is ignored, so new_rate also runs
+	schema := parseResult(t, result)
+	if len(schema.Errors) == 0 || schema.Errors[0].Kind != ErrRuntimeWarning || schema.Errors[0].RuleID != "new_rate" {
+		t.Fatalf("expected runtime warning for new_rate, got %+v", schema.Errors)
+	}
+	assertDefinitionValue(t, schema, "rate", 0.22)
+}
+
+func TestFeatureFlags(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"dti": {"type": "number", "value": 0.45},
+			"review": {"type": "boolean", "value": false},
+			"legacy": {"type": "boolean", "value": false}
+		},
+		"logic_tree": [
+			{"id": "new_dti", "feature": "new_dti_rule", "when": {">": [{"var": "dti"}, 0.4]}, "then": {"set": {"review": true}}},
+			{"id": "always", "when": {">": [{"var": "dti"}, 0]}, "then": {"set": {"legacy": true}}}
+		]
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+	assertDefinitionValue(t, schema, "review", false)
+	assertDefinitionValue(t, schema, "legacy", true)
+
+	result, err = Run(input, date, WithFeatures("other", "new_dti_rule"))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema = parseResult(t, result)
+	assertDefinitionValue(t, schema, "review", true)
+	assertDefinitionValue(t, schema, "legacy", true)
+}
+
+func TestFeatureFlagsVerify(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	doc := `{
+		"valid_from": "2025-01-01",
+		"definitions": {
+			"spend": {"type": "number", "value": 5000},
+			"tier": {"type": "string", "readonly": true}
+		},
+		"logic_tree": [
+			{"id": "gold", "feature": "new_rule", "when": {">": [{"var": "spend"}, 1000]}, "then": {"set": {"tier": "gold"}}}
+		]
+	}`
+
+	result, err := Run(doc, date, WithFeatures("new_rule"))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+	assertDefinitionValue(t, schema, "tier", "gold")
+	assertEqual(t, strings.Join(schema.Features, ","), "new_rule")
+
+	if vr := Verify(result, doc); !vr.Valid {
+		t.Errorf("expected the flagged result to verify, got %+v", vr.Issues)
+	}
+
+	compiled, err := Compile(doc, WithFeatures("new_rule"))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if vr := VerifyWithCompiled(result, compiled); !vr.Valid {
+		t.Errorf("expected the flagged result to verify against the compiled base, got %+v", vr.Issues)
+	}
+
+	// Compiled flags replace the ones the submission records
+	compiled, err = Compile(doc, WithFeatures("other"))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if vr := VerifyWithCompiled(result, compiled); vr.Valid {
+		t.Error("expected a flag the base wasn't compiled with to be rejected")
+	}
+}
+
+// TestFeatureFlagsShared checks that merging flags doesn't write into the caller's or a
+// CompiledSchema's Features backing array.
+func TestFeatureFlagsShared(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := &Schema{
+		Definitions: map[string]*Definition{"spend": {Type: "number", Value: 5000.0}},
+		Features:    make([]string, 1, 4),
+	}
+	input.Features[0] = "zeta"
+	if _, err := RunSchema(input, Options{EffectiveDate: date, Features: []string{"alpha"}}); err != nil {
+		t.Fatalf("RunSchema failed: %v", err)
+	}
+	if spare := input.Features[:cap(input.Features)]; spare[1] != "" {
+		t.Errorf("Run wrote into the caller's Features backing array: %q", spare)
+	}
+
+	doc := `{"valid_from": "2025-01-01", "features": ["zeta"], "definitions": {"spend": {"type": "number", "value": 5000}}}`
+	compiled, err := Compile(doc, WithFeatures("alpha"))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	result, err := Run(doc, date, WithFeatures("alpha"))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if vr := VerifyWithCompiled(result, compiled); !vr.Valid {
+				t.Errorf("expected concurrent verify to pass, got %+v", vr.Issues)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRuleDocumentationInErrors(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {"dti": {"type": "number", "value": 0.5}},
+		"logic_tree": [
+			{
+				"id": "dti_cap",
+				"title": "Debt-to-income cap",
+				"description": "Lenders must not approve loans where monthly debt exceeds 43% of income.",
+				"law_ref": "12 CFR 1026.43(e)",
+				"references": ["CFPB ATR/QM guidance 2014-01"],
+				"when": {">": [{"var": "dti"}, 0.43]},
+				"then": {"error_msg": "Debt-to-income ratio exceeds 43%"}
+			}
+		]
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+	if len(schema.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %+v", schema.Errors)
+	}
+	e := schema.Errors[0]
+	assertEqual(t, e.RuleTitle, "Debt-to-income cap")
+	assertEqual(t, e.LawRef, "12 CFR 1026.43(e)")
+	if len(e.References) != 1 || e.References[0] != "CFPB ATR/QM guidance 2014-01" {
+		t.Errorf("expected references on error, got %v", e.References)
+	}
+	assertEqual(t, schema.LogicTree[0].Description, "Lenders must not approve loans where monthly debt exceeds 43% of income.")
+}
+
+func TestLawRefRegistry(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"law_refs": {
+			"gdpr_33": {"title": "GDPR Art. 33(1) — Notification of a personal data breach", "url": "https://gdpr-info.eu/art-33-gdpr/", "jurisdiction": "EU"}
+		},
+		"definitions": {
+			"hours_since_breach": {"type": "number", "value": 80}
+		},
+		"logic_tree": [
+			{"id": "late", "law_ref": "gdpr_33", "when": {">": [{"var": "hours_since_breach"}, 72]}, "then": {"error_msg": "Notify within 72 hours"}},
+			{"id": "free_text", "law_ref": "Internal policy 4.2", "when": {">": [{"var": "hours_since_breach"}, 48]}, "then": {"error_msg": "Escalate after 48 hours"}}
+		]
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+	if len(schema.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %+v", schema.Errors)
+	}
+
+	cited := schema.Errors[0]
+	assertEqual(t, cited.LawRef, "gdpr_33")
+	if cited.Citation == nil {
+		t.Fatal("expected the registered citation on the error")
+	}
+	assertEqual(t, cited.Citation.Jurisdiction, "EU")
+	assertEqual(t, cited.Citation.URL, "https://gdpr-info.eu/art-33-gdpr/")
+
+	if schema.Errors[1].Citation != nil {
+		t.Error("free-text law_ref should not resolve to a citation")
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"income": {"type": "number", "value": 20000},
+			"tier": {"type": "string", "value": null, "readonly": true},
+			"applicant": {"type": "object", "value": {"name": "Ann"}},
+			"note": {"type": "string", "value": "typed", "set_by": {"rule_id": "forged"}}
+		},
+		"state_model": {"derived": {"double": {"eval": {"*": [{"var": "income"}, 2]}}}},
+		"logic_tree": [
+			{"id": "base_tier", "when": true, "then": {"set": {"tier": "standard"}}},
+			{"id": "low_income", "law_ref": "Act §4", "when": {"<": [{"var": "income"}, 30000]}, "then": {"set": {"tier": "assisted"}}},
+			{"id": "flag", "when": true, "then": {"set": {"applicant.flagged": true}}}
+		]
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.Contains(result, "set_by") {
+		t.Error("set_by should only be emitted with WithProvenance")
+	}
+
+	result, err = Run(input, date, WithProvenance())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+	if got := schema.Definitions["tier"].SetBy; got == nil || *got != (Provenance{RuleID: "low_income", LawRef: "Act §4"}) {
+		t.Errorf("expected tier set by low_income, got %+v", got)
+	}
+	if got := schema.Definitions["applicant"].SetBy; got == nil || got.RuleID != "flag" {
+		t.Errorf("expected a nested set to be recorded on the root, got %+v", got)
+	}
+	for _, id := range []string{"income", "double", "note"} {
+		if def := schema.Definitions[id]; def != nil && def.SetBy != nil {
+			t.Errorf("expected no provenance on %s, got %+v", id, def.SetBy)
+		}
+	}
+}
+
+func TestAliases(t *testing.T) {
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	schema := `{
+		"definitions": {
+			"applicant_income": {"type": "number", "value": 60000},
+			"applicant": {"type": "object", "value": {"age": 40}},
+			"band": {"type": "string"},
+			"note": {"type": "string", "visible": false},
+			"income_old": {"type": "number", "value": 1}
+		},
+		"aliases": {"income": "applicant_income", "person": "applicant", "tier": "band", "remark": "note", "income_old": "applicant_income"},
+		"state_model": {"derived": {"monthly": {"eval": {"/": [{"var": "income"}, 12]}}}},
+		"logic_tree": [
+			{"id": "high", "when": {"and": [{">": [{"var": "income"}, 50000]}, {">=": [{"var": "person.age"}, 18]}]},
+			 "then": {"set": {"tier": "high"}, "ui_modify": {"remark": {"visible": true}}}},
+			{"id": "shadowed", "when": {"==": [{"var": "income_old"}, 1]}, "then": {"set": {"band": "old"}}}
+		]
+	}`
+	result, err := Run(schema, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	parsed := parseResult(t, result)
+	assertDefinitionValue(t, parsed, "monthly", 5000.0)
+	if parsed.Definitions["tier"] != nil {
+		t.Error("set through an alias should write the aliased field")
+	}
+	if !parsed.Definitions["note"].IsVisible() {
+		t.Error("ui_modify through an alias should modify the aliased field")
+	}
+	// A field with the alias's ID wins, so "shadowed" reads income_old itself and sets band last
+	assertDefinitionValue(t, parsed, "band", "old")
+	for _, e := range parsed.Errors {
+		if strings.Contains(e.Message, "Undefined variable") {
+			t.Errorf("unexpected warning: %s", e.Message)
+		}
+	}
+}
@@ -15,9 +15,81 @@ type Schema struct {
 	TemporalMap  []*TemporalBranch       `json:"temporal_map,omitempty"` // Optional: Version routing
 	StateModel   *StateModel             `json:"state_model,omitempty"`  // Optional: Derived values
 
+	// Optional: Rules authored as tables, compiled into logic_tree rules by Run
+	DecisionTables []*DecisionTable `json:"decision_tables,omitempty"`
+
+	// Optional: Jurisdiction the document is evaluated for (e.g., "US-CA"); WithJurisdiction overrides it
+	// and Run records the one it used. Selects jurisdiction-scoped rules and derived formulas.
+	Jurisdiction string `json:"jurisdiction,omitempty"`
+
+	// Optional: Feature flags enabled for the document, in addition to WithFeatures. Run records
+	// every flag it evaluated with, so Verify replays the same rules.
+	Features []string `json:"features,omitempty"`
+
+	// Optional: Former field IDs mapped to the current ones, so rules and expressions written
+	// against an old ID keep working after a rename. Variables and set/ui_modify keys resolve
+	// through them; a field that still has the old ID takes precedence.
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// Optional: Citation registry. A law_ref that names an entry is a reference to it,
+	// and errors it produces carry the full citation; other law_refs stay free text.
+	LawRefs map[string]*LawRef `json:"law_refs,omitempty"`
+
+	// Optional: Title, subtitle and key figures computed by Run for list views
+	Summary *Summary `json:"summary,omitempty"`
+
+	// Optional: Engine versions the schema was written for, e.g. ">=0.5" or ">=0.5, <1".
+	// Run, Compile and Verify fail with an *EngineVersionError on any other engine.
+	RequiresEngine string `json:"requires_engine,omitempty"`
+
+	// Optional: What happens when the effective date selects an ARCHIVED temporal branch:
+	// "warn" (default) reports archived_version, "reject" also makes the document INVALID, "allow" is silent.
+	ArchivedPolicy string `json:"archived_policy,omitempty"`
+
+	// Optional: "validate" (default) checks hidden fields like visible ones; "skip" ignores them
+	// for required, type and constraint checks, so hiding a field needn't be paired with required=false.
+	HiddenValidation string `json:"hidden_validation,omitempty"`
+
+	// Optional: When true, readonly fields that aren't derived can only be set by rules
+	// named in their writable_by; other writes are blocked and reported.
+	ReadonlyProtection bool `json:"readonly_protection,omitempty"`
+
+	// Optional: Whether author-provided values of editable fields may be changed by the
+	// submission Verify checks: "editable" (default) or "fixed". Definitions can override it with prefill.
+	PrefillPolicy string `json:"prefill_policy,omitempty"`
+
+	// Optional: Group constraints across several fields
+	RequireTogether []*FieldGroup `json:"require_together,omitempty"` // If any field is filled, all must be
+	RequireOneOf    []*FieldGroup `json:"require_one_of,omitempty"`   // At least one field must be filled
+
+	// Optional: Regression fixtures executed by RunSchemaTests (ignored by Run)
+	Tests []*SchemaTest `json:"tests,omitempty"`
+
+	// Optional: Rule packs merged in by ResolvePacks (Run itself does not load packs, and warns
+	// if any are listed)
+	UsePacks []string `json:"use_packs,omitempty"`
+	Packs    []string `json:"packs,omitempty"` // Packs ResolvePacks merged in, dependencies first
+
+	// Optional: Reusable field blocks instantiated under a prefix by ExpandTemplates (Run itself does not expand them)
+	Templates    map[string]*Template `json:"templates,omitempty"`
+	UseTemplates []*TemplateUse       `json:"use_templates,omitempty"`
+
+	// Optional: Overlays (experiment variants) applied by Overlay, in order; carried into results
+	Overlays []*AppliedOverlay `json:"overlays,omitempty"`
+
+	// Optional: Template parameters substituted by Instantiate
+	Parameters      map[string]*Parameter `json:"parameters,omitempty"`
+	ParameterValues map[string]any        `json:"parameter_values,omitempty"` // Values a concrete schema was instantiated with
+
 	// Output fields (populated by Run)
-	Errors []ValidationError `json:"errors,omitempty"`
-	Status DocStatus         `json:"status,omitempty"`
+	Errors        []ValidationError `json:"errors,omitempty"`
+	Status        DocStatus         `json:"status,omitempty"`
+	ActiveVersion string            `json:"active_version,omitempty"` // logic_version selected by temporal_map for the effective date
+	Annotations   []Annotation      `json:"annotations,omitempty"`    // Per-field UI guidance (non-blocking)
+	FieldOrder    []string          `json:"field_order,omitempty"`    // Definition IDs in display order (only when some definition has an order)
+	Meta          *RunMeta          `json:"meta,omitempty"`           // Evaluation metadata (only with WithMeta)
+	FocusOrder    []string          `json:"focus_order,omitempty"`    // Fields with blocking errors, in the order to fix them (only when some definition has an order or page)
+	Completion    *Completion       `json:"completion,omitempty"`     // Required-field progress (only with WithCompletion)
 }
 
 // DocStatus represents the validation state of a document.
@@ -36,9 +108,33 @@ type Definition struct {
 	Value    any      `json:"value"`              // Current value (nil = not set)
 	Options  []string `json:"options,omitempty"`  // For "select" type
 	Label    string   `json:"label,omitempty"`    // Human-readable label
-	Required bool     `json:"required,omitempty"` // Is this field required?
-	Readonly bool     `json:"readonly,omitempty"` // True = computed, False = user-editable
-	Visible  *bool    `json:"visible,omitempty"`   // UI visibility (default true)
+	Required *bool    `json:"required,omitempty"` // Is this field required? (default false)
+	Readonly *bool    `json:"readonly,omitempty"` // True = computed, False = user-editable (default false)
+	Visible  *bool    `json:"visible,omitempty"`  // UI visibility (default true)
+	Order    *int     `json:"order,omitempty"`    // Display position; unordered fields follow ordered ones, by ID
+	Page     string   `json:"page,omitempty"`     // UI page or step the field is shown on (groups completion)
+
+	// Sensitive values are encrypted at rest when an Encrypter is configured
+	Sensitive bool `json:"sensitive,omitempty"`
+
+	// Rule IDs, tags or logic versions allowed to set this readonly field under readonly_protection
+	WritableBy []string `json:"writable_by,omitempty"`
+
+	// Overrides the schema's prefill_policy for this field's author-provided value
+	Prefill string `json:"prefill,omitempty"`
+
+	// Earlier IDs of this field; Verify reads submissions made before the rename under them
+	RenamedFrom []string `json:"renamed_from,omitempty"`
+
+	// Normalizers applied to the value before logic and validation (e.g., ["trim", "upper"])
+	Normalize []string `json:"normalize,omitempty"`
+
+	// ISO 8601 time the value was last edited (filled by the app, checked against signatures by Verify)
+	ChangedAt string `json:"changed_at,omitempty"`
+
+	// What happens to the value when the field is hidden: "keep" (default) or "clear".
+	// Cleared values are excluded from validation and from derived inputs.
+	OnHide string `json:"on_hide,omitempty"`
 
 	// Numeric constraints (for "number" and "currency" types)
 	Min  *float64 `json:"min,omitempty"`  // Minimum allowed value (nil = no minimum)
@@ -52,18 +148,87 @@ type Definition struct {
 
 	// UI metadata that can be modified by rules
 	UIClass   string `json:"ui_class,omitempty"`   // CSS class hint
-	UIMessage string `json:"ui_message,omitempty"` // Inline message/hint
+	UIMessage  string `json:"ui_message,omitempty"`  // Inline message/hint
+	UISeverity string `json:"ui_severity,omitempty"` // "info", "warning", "error" (default "info" when ui_message is set)
+
+	// Computed display text: JSON-logic expressions evaluated during Run into label/ui_message.
+	// A nil result keeps the static text.
+	LabelExpr     any `json:"label_expr,omitempty"`
+	UIMessageExpr any `json:"ui_message_expr,omitempty"`
+
+	// Rich attestation this definition mirrors (output only, see WithAttestationFields)
+	Attestation string `json:"attestation,omitempty"`
+
+	// Rule that last set the value (output only, see WithProvenance)
+	SetBy *Provenance `json:"set_by,omitempty"`
 }
 
+// UI flags are tri-state: nil means "not specified" and takes the default, so merges
+// (ui_modify, packs) can tell an omitted flag from an explicit false. Read them with the
+// Is* methods and write them with the Set* methods, which never alias another pointer.
+
+// IsVisible reports whether the field is shown (default true).
+func (d *Definition) IsVisible() bool { return d.Visible == nil || *d.Visible }
+
+// IsRequired reports whether the field must be filled (default false).
+func (d *Definition) IsRequired() bool { return d.Required != nil && *d.Required }
+
+// IsReadonly reports whether the field is computed rather than user-editable (default false).
+func (d *Definition) IsReadonly() bool { return d.Readonly != nil && *d.Readonly }
+
+// SetVisible sets visible explicitly.
+func (d *Definition) SetVisible(v bool) { d.Visible = &v }
+
+// SetRequired sets required explicitly.
+func (d *Definition) SetRequired(v bool) { d.Required = &v }
+
+// SetReadonly sets readonly explicitly.
+func (d *Definition) SetReadonly(v bool) { d.Readonly = &v }
+
+// on_hide behaviors for Definition.OnHide.
+const (
+	OnHideKeep  = "keep"  // Hidden fields keep their value (default)
+	OnHideClear = "clear" // Hidden fields have their value cleared during Run
+)
+
+// archived_policy values for Schema.ArchivedPolicy.
+const (
+	ArchivedWarn   = "warn"   // Report archived_version without affecting status (default)
+	ArchivedReject = "reject" // Report archived_version and mark the document INVALID
+	ArchivedAllow  = "allow"  // Evaluate archived branches silently
+)
+
+// prefill_policy values for Schema.PrefillPolicy and Definition.Prefill.
+const (
+	PrefillEditable = "editable" // Submissions may replace author-provided values (default)
+	PrefillFixed    = "fixed"    // Submissions must keep author-provided values
+)
+
+// hidden_validation modes for Schema.HiddenValidation.
+const (
+	HiddenValidate = "validate" // Hidden fields are validated (default)
+	HiddenSkip     = "skip"     // Hidden fields are not validated
+)
+
 // Rule represents a logic tree node with a when-then structure.
 // Each rule is anchored to a legal citation for audit purposes.
 type Rule struct {
-	ID           string         `json:"id"`
-	LawRef       string         `json:"law_ref,omitempty"`       // Legal citation (e.g., "GDPR Art. 33(1)")
-	LogicVersion string         `json:"logic_version,omitempty"` // Which temporal branch this belongs to
-	When         map[string]any `json:"when"`                    // JSON-logic condition
-	Then         *Action        `json:"then"`
-	Disabled     bool           `json:"disabled,omitempty"` // Set by prune() for inactive rules
+	ID            string   `json:"id"`
+	LawRef        string   `json:"law_ref,omitempty"`       // Legal citation (e.g., "GDPR Art. 33(1)")
+	Title         string   `json:"title,omitempty"`         // Short human-readable name
+	Description   string   `json:"description,omitempty"`   // What the rule does and why
+	References    []string `json:"references,omitempty"`    // Further citations, guidance or URLs beyond law_ref
+	LogicVersion  string   `json:"logic_version,omitempty"` // Which temporal branch this belongs to
+	When          any      `json:"when"`                    // JSON-logic condition, infix string, or array of conditions (implicit AND); not a map since when arrays
+	WhenAny       []any    `json:"when_any,omitempty"`      // Conditions of which at least one must hold (implicit OR)
+	Unless        any      `json:"unless,omitempty"`        // Exception guard: the rule is skipped if this holds (array = any exception)
+	Then          *Action  `json:"then"`
+	Tags          []string `json:"tags,omitempty"`          // Labels for selective evaluation (e.g., "submission")
+	Feature       string   `json:"feature,omitempty"`       // Feature flag that must be enabled (WithFeatures) for this rule to run
+	Jurisdictions []string `json:"jurisdictions,omitempty"` // Jurisdictions the rule applies to (parents cover children; empty = all)
+	ValidFrom     string   `json:"valid_from,omitempty"`    // First effective date (inclusive) for this rule alone
+	ValidUntil    string   `json:"valid_until,omitempty"`   // Last effective date (inclusive) for this rule alone
+	Disabled      bool     `json:"disabled,omitempty"`      // Set by prune() for inactive rules
 }
 
 // Action represents what happens when a rule's condition is true.
@@ -74,6 +239,20 @@ type Action struct {
 	ErrorKind ErrorKind      `json:"error_kind,omitempty"` // Error category for error_msg (defaults to constraint_violation)
 }
 
+// LawRef is a citation in the schema's law_refs registry, keyed by its ID.
+type LawRef struct {
+	Title        string `json:"title"`                  // Full citation (e.g., "GDPR Art. 33(1) — Notification of a breach")
+	URL          string `json:"url,omitempty"`          // Link to the authoritative text
+	Jurisdiction string `json:"jurisdiction,omitempty"` // Where it applies (e.g., "EU", "US-CA")
+}
+
+// FieldGroup is a declarative constraint over a set of fields (e.g., account number + routing number).
+type FieldGroup struct {
+	ID     string   `json:"id,omitempty"`
+	Fields []string `json:"fields"`
+	LawRef string   `json:"law_ref,omitempty"` // Legal citation for errors emitted by this group
+}
+
 // TemporalBranch routes logic based on effective dates.
 // Supports bitemporal logic with valid ranges.
 type TemporalBranch struct {
@@ -91,7 +270,26 @@ type StateModel struct {
 
 // DerivedDef is a computed field whose value is determined by a JSON-logic expression.
 type DerivedDef struct {
-	Eval map[string]any `json:"eval"` // JSON-logic expression (uses same syntax as Rule.When)
+	Eval any `json:"eval"` // JSON-logic expression or infix string (uses same syntax as Rule.When)
+
+	// Formulas that replace eval in specific jurisdictions, keyed by code. The most specific
+	// code covering the active jurisdiction wins; eval applies everywhere else.
+	ByJurisdiction map[string]any `json:"by_jurisdiction,omitempty"`
+}
+
+// UI severities for Definition.UISeverity and Annotation.Severity.
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Annotation is soft, per-field guidance set via ui_message/ui_severity.
+// Unlike ValidationError, annotations never affect document status.
+type Annotation struct {
+	FieldID  string `json:"field_id"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
 }
 
 // ErrorKind categorizes validation errors for programmatic status determination.
@@ -105,6 +303,7 @@ const (
 	ErrRuntimeWarning        ErrorKind = "runtime_warning"
 	ErrCycleDetected         ErrorKind = "cycle_detected"
 	ErrNotice                ErrorKind = "notice"
+	ErrArchivedVersion       ErrorKind = "archived_version"
 )
 
 // ValidationError represents a validation failure tied to a field and law reference.
@@ -114,23 +313,40 @@ type ValidationError struct {
 	Kind    ErrorKind `json:"kind"`               // Error category
 	Message string    `json:"message"`            // Human-readable error
 	LawRef  string    `json:"law_ref,omitempty"`  // Legal citation for the rule
+
+	// Documentation of the emitting rule, when it has any
+	RuleTitle  string   `json:"rule_title,omitempty"`
+	References []string `json:"references,omitempty"`
+
+	// The law_refs entry LawRef names, when it names one
+	Citation *LawRef `json:"citation,omitempty"`
+
+	// For cycle_detected: the derived fields on the cycle, sorted
+	Cycle []string `json:"cycle,omitempty"`
 }
 
 // Attestation represents a legally-binding signature requirement.
 // The VM validates attestations but does not perform signing — that's the app's job.
 type Attestation struct {
 	LawRef       string `json:"law_ref,omitempty"`       // Legal citation (e.g., "OSHA Section 1910.12")
-	Statement    string `json:"statement"`               // What they're signing
+	Statement    string `json:"statement"`               // What they're signing; {{field}} interpolates current values
 	RequiredRole string `json:"required_role,omitempty"` // Who can sign (e.g., "Compliance_Officer")
 	Provider     string `json:"provider,omitempty"`      // "DocuSign", "OpenID", "Manual"
 	Required     bool   `json:"required,omitempty"`      // Is signature required for READY?
 
+	// Fields the signature certifies. A change to any of them after evidence.timestamp
+	// (per their changed_at) invalidates the signature until it is re-signed.
+	Covers []string `json:"covers,omitempty"`
+
 	// Filled by the orchestrating application, validated by VM
 	Signed   bool      `json:"signed"`             // Has the attestation been signed?
 	Evidence *Evidence `json:"evidence,omitempty"` // Proof of signing (filled by app)
 
 	// Actions to execute when signed: true (processed during Run)
 	OnSign *Action `json:"on_sign,omitempty"`
+
+	// Statement with {{field}} placeholders filled from the document (output, templated statements only)
+	RenderedStatement string `json:"rendered_statement,omitempty"`
 }
 
 // Evidence contains the audit trail from a signing provider.
@@ -140,6 +356,7 @@ type Evidence struct {
 	Timestamp       string `json:"timestamp,omitempty"`         // ISO 8601 when signed
 	SignerID        string `json:"signer_id,omitempty"`         // Who signed (email, user ID)
 	LogicVersion    string `json:"logic_version,omitempty"`     // Schema version at signing time
+	Statement       string `json:"statement,omitempty"`         // Rendered statement that was signed (templated statements only)
 }
 
 // VerifyIssueCode categorizes verification failures for programmatic handling.
@@ -148,22 +365,57 @@ type VerifyIssueCode string
 
 const (
 	VerifyUnknownField          VerifyIssueCode = "unknown_field"           // Submitted field doesn't exist in schema
+	VerifyRenamedField          VerifyIssueCode = "renamed_field"           // Submitted field uses an ID the schema has renamed (warning)
 	VerifyComputedMismatch      VerifyIssueCode = "computed_mismatch"       // Readonly field value was tampered
 	VerifyAttestationUnsigned   VerifyIssueCode = "attestation_unsigned"    // Required attestation not signed
 	VerifyAttestationNoEvidence VerifyIssueCode = "attestation_no_evidence" // Signed but missing evidence
 	VerifyAttestationNoTimestamp VerifyIssueCode = "attestation_no_timestamp" // Evidence missing timestamp
+	VerifyAttestationOutsideVersion VerifyIssueCode = "attestation_outside_version" // Signed outside the effective temporal branch
+	VerifyAttestationStale      VerifyIssueCode = "attestation_stale"       // Signed before a field it certifies last changed
+	VerifyPrefillChanged        VerifyIssueCode = "prefill_changed"         // Submission changed a fixed author-provided value
+	VerifyAttestationStatementChanged VerifyIssueCode = "attestation_statement_changed" // Signed statement differs from the one the values render
+	VerifyEvidenceNotFound      VerifyIssueCode = "evidence_not_found"      // Evidence store has no record for the provider_audit_id
+	VerifyEvidenceMismatch      VerifyIssueCode = "evidence_mismatch"       // Embedded evidence differs from the stored record
 	VerifyStatusMismatch        VerifyIssueCode = "status_mismatch"         // Claimed status doesn't match computed
 	VerifyConvergenceFailed     VerifyIssueCode = "convergence_failed"      // Document didn't converge in max iterations
 	VerifyInternalError         VerifyIssueCode = "internal_error"          // Unexpected error (parse failure, panic, etc.)
 )
 
+// Severities for VerifyIssue.Severity. Only error-severity issues make a document invalid.
+const (
+	VerifySeverityError   = "error"
+	VerifySeverityWarning = "warning"
+)
+
+// RemediationAction names the fix a client can apply for a verification issue.
+type RemediationAction string
+
+const (
+	RemediationRemoveField       RemediationAction = "remove_field"       // Drop the field from the submission
+	RemediationRenameField       RemediationAction = "rename_field"       // Move the field to the ID in Remediation.Value
+	RemediationSetValue          RemediationAction = "set_value"          // Replace the field's value with Remediation.Value
+	RemediationSignAttestation   RemediationAction = "sign_attestation"   // Collect the missing signature
+	RemediationResignAttestation RemediationAction = "resign_attestation" // Sign again so the provider captures complete evidence
+	RemediationSetStatus         RemediationAction = "set_status"         // Replace the document status with Remediation.Value
+)
+
+// Remediation is a machine-actionable fix for a VerifyIssue.
+type Remediation struct {
+	Action      RemediationAction `json:"action"`
+	Target      string            `json:"target,omitempty"` // Field or attestation ID the action applies to
+	Value       any               `json:"value,omitempty"`  // Value to apply, for set_value and set_status
+	Description string            `json:"description"`      // Human-readable instruction, e.g. "re-sign attestation 'officer_sign'"
+}
+
 // VerifyIssue is a single structured problem found during verification.
 type VerifyIssue struct {
-	Code     VerifyIssueCode `json:"code"`               // Machine-parseable issue code
-	FieldID  string          `json:"field_id,omitempty"`  // Which field/attestation is affected
-	Message  string          `json:"message"`             // Developer-readable explanation
-	Expected any             `json:"expected,omitempty"`  // What the VM computed
-	Claimed  any             `json:"claimed,omitempty"`   // What was submitted
+	Code        VerifyIssueCode `json:"code"`                  // Machine-parseable issue code
+	Severity    string          `json:"severity"`              // VerifySeverityError or VerifySeverityWarning
+	FieldID     string          `json:"field_id,omitempty"`    // Which field/attestation is affected
+	Message     string          `json:"message"`               // Developer-readable explanation
+	Expected    any             `json:"expected,omitempty"`    // What the VM computed
+	Claimed     any             `json:"claimed,omitempty"`     // What was submitted
+	Remediation *Remediation    `json:"remediation,omitempty"` // Suggested fix (nil when there is none the client can apply)
 }
 
 // VerifyResult is the structured output of Verify().
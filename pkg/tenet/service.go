@@ -0,0 +1,223 @@
+package tenet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Errors returned by Service.
+var (
+	ErrSchemaNotFound   = errors.New("schema not registered")
+	ErrDocumentTooLarge = errors.New("document exceeds size limit")
+)
+
+// ServiceConfig configures a Service. The zero value is usable.
+type ServiceConfig struct {
+	RunOptions      []RunOption        // Applied to every Evaluate before per-call options
+	MaxDocumentSize int                // Maximum input size in bytes (0 = unlimited)
+	MaxIterations   int                // Verify replay limit (0 = Verify's default)
+	Limits          Limits             // Complexity limits for registered schemas and evaluated documents
+	OnEvent         func(ServiceEvent) // Called after every Evaluate/Verify (must be goroutine-safe)
+	EvidenceStore   EvidenceStore      // If set, Verify checks attestation evidence against it (see VerifyWithEvidence)
+}
+
+// ServiceEvent describes a completed Evaluate or Verify call, for logging and metrics hooks.
+type ServiceEvent struct {
+	Op         string            // "evaluate" or "verify"
+	RequestID  string            // ID carried by the call's context (see ContextWithRequestID)
+	SchemaID   string            // Registered ID (verify) or the document's schema_id (evaluate)
+	SchemaHash string            // CompiledSchema.Hash of the base: the registered one (verify) or WithMeta's (evaluate)
+	InputHash  string            // SHA-256 of the evaluated document (evaluate only)
+	Status     DocStatus         // Resulting document status (empty on error)
+	Valid      bool              // Verify outcome (always false for evaluate)
+	Errors     int               // Number of validation errors (evaluate) or issues (verify)
+	IssueCodes []VerifyIssueCode // Code of each verify issue, in order (verify only)
+	Duration   time.Duration     // Wall time of the call
+	Err        error             // Non-nil if the call failed
+}
+
+// Attrs returns the event as slog attributes, for a structured access log:
+//
+//	OnEvent: func(e tenet.ServiceEvent) { logger.LogAttrs(ctx, slog.LevelInfo, "tenet", e.Attrs()...) }
+func (e ServiceEvent) Attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("op", e.Op),
+		slog.String("request_id", e.RequestID),
+		slog.String("schema_id", e.SchemaID),
+		slog.String("schema_hash", e.SchemaHash),
+		slog.String("input_hash", e.InputHash),
+		slog.String("status", string(e.Status)),
+		slog.Int("errors", e.Errors),
+		slog.Duration("duration", e.Duration),
+	}
+	if e.Op == "verify" {
+		attrs = append(attrs, slog.Bool("valid", e.Valid))
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("err", e.Err.Error()))
+	}
+	return attrs
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying a request ID, typically taken from the
+// caller's X-Request-ID header. EvaluateContext and VerifyContext put it on their events.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "" if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Service is the unit a server embeds: it owns compiled base schemas, limits and hooks,
+// and its methods are safe for concurrent use.
+type Service struct {
+	cfg     ServiceConfig
+	mu      sync.RWMutex
+	schemas map[string]*CompiledSchema
+}
+
+// NewService creates a Service with the given configuration.
+func NewService(cfg ServiceConfig) *Service {
+	return &Service{
+		cfg:     cfg,
+		schemas: make(map[string]*CompiledSchema),
+	}
+}
+
+// Register compiles a base schema and stores it under id, replacing any previous version.
+// It is compiled with the service's RunOptions, so Verify replays documents the way
+// Evaluate produced them.
+func (s *Service) Register(id, jsonText string) error {
+	if err := s.checkSize(jsonText); err != nil {
+		return err
+	}
+	compiled, err := CompileWithLimits(jsonText, s.cfg.Limits, s.cfg.RunOptions...)
+	if err != nil {
+		return fmt.Errorf("register '%s': %w", id, err)
+	}
+	s.mu.Lock()
+	s.schemas[id] = compiled
+	s.mu.Unlock()
+	return nil
+}
+
+// Unregister removes a base schema. Unknown IDs are ignored.
+func (s *Service) Unregister(id string) {
+	s.mu.Lock()
+	delete(s.schemas, id)
+	s.mu.Unlock()
+}
+
+// Schema returns the compiled base schema registered under id.
+func (s *Service) Schema(id string) (*CompiledSchema, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	compiled, ok := s.schemas[id]
+	return compiled, ok
+}
+
+// Evaluate runs a document with the service's default options followed by opts.
+func (s *Service) Evaluate(jsonText string, date time.Time, opts ...RunOption) (string, error) {
+	return s.EvaluateContext(context.Background(), jsonText, date, opts...)
+}
+
+// EvaluateContext is Evaluate for a request: its event carries ctx's request ID and the
+// hash of the document evaluated. SchemaHash is set only when the caller names the base
+// with WithMeta; the document's own schema_id is client-supplied and proves nothing.
+func (s *Service) EvaluateContext(ctx context.Context, jsonText string, date time.Time, opts ...RunOption) (string, error) {
+	start := time.Now()
+	sum := sha256.Sum256([]byte(jsonText))
+	event := ServiceEvent{Op: "evaluate", RequestID: RequestIDFromContext(ctx), InputHash: hex.EncodeToString(sum[:])}
+
+	if err := s.checkSize(jsonText); err != nil {
+		event.Err = err
+		s.emit(event, start)
+		return "", err
+	}
+
+	all := append([]RunOption{WithLimits(s.cfg.Limits)}, s.cfg.RunOptions...)
+	all = append(all, opts...)
+	cfg := newRunConfig(all)
+	result, schema, err := runJSON(jsonText, date, cfg)
+	if err != nil {
+		event.Err = err
+	} else {
+		event.SchemaID = schema.SchemaID
+		event.Status = schema.Status
+		event.Errors = len(schema.Errors)
+		if cfg.metaBase != nil {
+			event.SchemaHash = cfg.metaBase.Hash()
+		}
+	}
+	s.emit(event, start)
+	return result, err
+}
+
+// Verify checks a completed document against the base schema registered under schemaID.
+func (s *Service) Verify(schemaID, newJson string) VerifyResult {
+	return s.VerifyContext(context.Background(), schemaID, newJson)
+}
+
+// VerifyContext is Verify for a request: its event carries ctx's request ID.
+func (s *Service) VerifyContext(ctx context.Context, schemaID, newJson string) VerifyResult {
+	start := time.Now()
+	event := ServiceEvent{Op: "verify", RequestID: RequestIDFromContext(ctx), SchemaID: schemaID}
+
+	var vr VerifyResult
+	compiled, ok := s.Schema(schemaID)
+	if !ok {
+		event.Err = fmt.Errorf("%w: '%s'", ErrSchemaNotFound, schemaID)
+	} else {
+		event.SchemaHash = compiled.Hash()
+		event.Err = s.checkSize(newJson)
+	}
+
+	if event.Err != nil {
+		vr = VerifyResult{
+			Issues: []VerifyIssue{{Code: VerifyInternalError, Severity: VerifySeverityError, Message: event.Err.Error()}},
+			Error:  event.Err.Error(),
+		}
+	} else {
+		vr = VerifyWithEvidence(newJson, compiled, s.cfg.EvidenceStore, s.cfg.MaxIterations)
+		if vr.Error != "" {
+			event.Err = errors.New(vr.Error)
+		}
+	}
+
+	event.Status = vr.Status
+	event.Valid = vr.Valid
+	event.Errors = len(vr.Issues)
+	for _, issue := range vr.Issues {
+		event.IssueCodes = append(event.IssueCodes, issue.Code)
+	}
+	s.emit(event, start)
+	return vr
+}
+
+// checkSize enforces MaxDocumentSize.
+func (s *Service) checkSize(jsonText string) error {
+	if s.cfg.MaxDocumentSize > 0 && len(jsonText) > s.cfg.MaxDocumentSize {
+		return fmt.Errorf("%w: %d bytes (limit %d)", ErrDocumentTooLarge, len(jsonText), s.cfg.MaxDocumentSize)
+	}
+	return nil
+}
+
+// emit calls the OnEvent hook, if any.
+func (s *Service) emit(event ServiceEvent, start time.Time) {
+	if s.cfg.OnEvent == nil {
+		return
+	}
+	event.Duration = time.Since(start)
+	s.cfg.OnEvent(event)
+}
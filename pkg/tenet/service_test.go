@@ -0,0 +1,299 @@
+package tenet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestService(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	base := createLoanSchema("employed", 720, 75000, 250000)
+
+	var mu sync.Mutex
+	var events []ServiceEvent
+	svc := NewService(ServiceConfig{
+		RunOptions:      []RunOption{WithCompactOutput()},
+		MaxDocumentSize: 64 * 1024,
+		OnEvent: func(e ServiceEvent) {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		},
+	})
+
+	if err := svc.Register("loan", base); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	compiled, _ := svc.Schema("loan")
+	if len(compiled.Hash()) != 64 {
+		t.Fatalf("expected hex SHA-256 hash, got %q", compiled.Hash())
+	}
+
+	completed, err := svc.Evaluate(base, date)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if strings.Contains(completed, "\n") {
+		t.Error("service RunOptions should apply (expected compact output)")
+	}
+
+	t.Run("concurrent evaluate and verify", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := svc.Evaluate(base, date); err != nil {
+					t.Errorf("Evaluate failed: %v", err)
+				}
+				if vr := svc.Verify("loan", completed); !vr.Valid {
+					t.Errorf("Verify failed: %+v", vr.Issues)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("unknown schema", func(t *testing.T) {
+		vr := svc.Verify("missing", completed)
+		if vr.Valid || vr.Error == "" {
+			t.Fatal("expected error for unregistered schema")
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		huge := strings.Repeat(" ", 64*1024) + base
+		if _, err := svc.Evaluate(huge, date); !errors.Is(err, ErrDocumentTooLarge) {
+			t.Fatalf("expected ErrDocumentTooLarge, got %v", err)
+		}
+		if err := svc.Register("huge", huge); !errors.Is(err, ErrDocumentTooLarge) {
+			t.Fatalf("expected ErrDocumentTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		svc.Unregister("loan")
+		if _, ok := svc.Schema("loan"); ok {
+			t.Fatal("schema should be gone")
+		}
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 1+32+1+1 {
+		t.Fatalf("expected 35 events, got %d", len(events))
+	}
+	first := events[0]
+	assertEqual(t, first.Op, "evaluate")
+	assertEqual(t, first.SchemaID, "loan_application")
+	assertEqual(t, first.Status, StatusReady)
+	for _, e := range events {
+		if e.Op == "verify" && e.SchemaID == "loan" {
+			assertEqual(t, e.SchemaHash, compiled.Hash())
+			break
+		}
+	}
+}
+
+func TestServiceRequestID(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	base := `{"schema_id": "form", "definitions": {"total": {"type": "number", "value": 5, "readonly": true}}}`
+
+	var events []ServiceEvent
+	svc := NewService(ServiceConfig{OnEvent: func(e ServiceEvent) { events = append(events, e) }})
+	if err := svc.Register("form", base); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	compiled, _ := svc.Schema("form")
+
+	ctx := ContextWithRequestID(context.Background(), "req-42")
+	completed, err := svc.EvaluateContext(ctx, base, date, WithMeta(compiled))
+	if err != nil {
+		t.Fatalf("EvaluateContext failed: %v", err)
+	}
+	svc.VerifyContext(ctx, "form", completed)
+
+	// A different document claiming the registered schema_id is not attributed to it
+	modified := strings.Replace(base, `"value": 5`, `"value": 7`, 1)
+	svc.Evaluate(modified, date)
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	for _, e := range events[:2] {
+		assertEqual(t, e.RequestID, "req-42")
+		assertEqual(t, e.SchemaHash, compiled.Hash())
+	}
+	assertEqual(t, events[0].InputHash, compiled.Hash())
+	assertEqual(t, events[2].RequestID, "")
+	assertEqual(t, events[2].SchemaID, "form")
+	assertEqual(t, events[2].SchemaHash, "")
+	modifiedHash := sha256.Sum256([]byte(modified))
+	assertEqual(t, events[2].InputHash, hex.EncodeToString(modifiedHash[:]))
+
+	attrs := map[string]string{}
+	for _, a := range events[0].Attrs() {
+		attrs[a.Key] = a.Value.String()
+	}
+	assertEqual(t, attrs["request_id"], "req-42")
+	assertEqual(t, attrs["schema_hash"], compiled.Hash())
+	assertEqual(t, attrs["status"], "READY")
+}
+
+// TestServiceRoundTrip checks that Verify replays with the options Evaluate used.
+func TestServiceRoundTrip(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	base := `{
+		"valid_from": "2025-01-16",
+		"definitions": {
+			"price": {"type": "currency", "value": 0.1},
+			"fee": {"type": "currency", "value": 0.2},
+			"qty": {"type": "number", "value": 3}
+		},
+		"state_model": {"derived": {
+			"subtotal": {"eval": "= price + fee"},
+			"total": {"eval": "= subtotal * qty"}
+		}}
+	}`
+
+	svc := NewService(ServiceConfig{RunOptions: []RunOption{WithDecimalCurrency(2)}})
+	if err := svc.Register("invoice", base); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	completed, err := svc.Evaluate(base, date)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	assertDefinitionValue(t, parseResult(t, completed), "total", 0.9)
+	if vr := svc.Verify("invoice", completed); !vr.Valid {
+		t.Errorf("expected the service's own output to verify, got %+v", vr.Issues)
+	}
+
+	t.Run("fixed point", func(t *testing.T) {
+		// Each rule reads the field the next one sets, so one pass sets only step3; Verify's
+		// replay alone settles after two
+		chain := `{
+			"valid_from": "2025-01-16",
+			"definitions": {
+				"step2": {"type": "boolean", "value": false, "readonly": true},
+				"step3": {"type": "boolean", "value": false, "readonly": true},
+				"flagged": {"type": "boolean", "value": false, "readonly": true}
+			},
+			"logic_tree": [
+				{"id": "flag", "when": {"==": [{"var": "step2"}, true]}, "then": {"set": {"flagged": true}}},
+				{"id": "second", "when": {"==": [{"var": "step3"}, true]}, "then": {"set": {"step2": true}}},
+				{"id": "first", "when": {"==": [1, 1]}, "then": {"set": {"step3": true}}}
+			]
+		}`
+		svc := NewService(ServiceConfig{RunOptions: []RunOption{WithFixedPoint(0)}})
+		if err := svc.Register("chain", chain); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		completed, err := svc.Evaluate(chain, date)
+		if err != nil {
+			t.Fatalf("Evaluate failed: %v", err)
+		}
+		assertDefinitionValue(t, parseResult(t, completed), "flagged", true)
+		if vr := svc.Verify("chain", completed); !vr.Valid {
+			t.Errorf("expected the service's own output to verify, got %+v", vr.Issues)
+		}
+	})
+}
+
+func TestServiceLimits(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	base := createLoanSchema("employed", 720, 75000, 250000)
+
+	svc := NewService(ServiceConfig{Limits: Limits{MaxDefinitions: 2}})
+	err := svc.Register("loan", base)
+	var le *LimitError
+	if !errors.As(err, &le) || !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected *LimitError, got %v", err)
+	}
+	assertEqual(t, le.Limit, "max_definitions")
+	if _, err := svc.Evaluate(base, date); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("Evaluate should enforce limits, got %v", err)
+	}
+
+	deep := `{"definitions": {"a": {"type": "number", "value": 1}}, "logic_tree": [
+		{"id": "r1", "when": {"and": [{"or": [{"==": [{"var": "a"}, 1]}]}]}, "then": {"set": {"a": 2}}}
+	]}`
+	_, err = Run(deep, date, WithLimits(Limits{MaxDepth: 3}))
+	if !errors.As(err, &le) {
+		t.Fatalf("expected *LimitError, got %v", err)
+	}
+	assertEqual(t, le.Limit, "max_depth")
+	assertEqual(t, le.Where, "r1")
+
+	if _, err := Run(deep, date, WithLimits(Limits{MaxDepth: 8, MaxRules: 1})); err != nil {
+		t.Fatalf("schema within limits should run: %v", err)
+	}
+}
+
+func TestAllocationLimit(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	// Each rule doubles s: 30 rules would build a 1 GiB string
+	var rules []string
+	for i := 0; i < 30; i++ {
+		rules = append(rules, fmt.Sprintf(`{"id": "r%d", "when": true, "then": {"set": {"s": {"cat": [{"var": "s"}, {"var": "s"}]}}}}`, i))
+	}
+	doubling := `{"definitions": {"s": {"type": "string", "value": "x"}}, "logic_tree": [` + strings.Join(rules, ",") + `]}`
+
+	_, err := Run(doubling, date, WithLimits(Limits{MaxAllocation: 1 << 20}))
+	var le *LimitError
+	if !errors.As(err, &le) || !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected *LimitError, got %v", err)
+	}
+	assertEqual(t, le.Limit, "max_allocation")
+	if le.Actual <= le.Max {
+		t.Errorf("expected usage over the budget, got %d of %d", le.Actual, le.Max)
+	}
+
+	// Nested arrays share their halves, so they are cheap to build but not to output
+	nested := strings.ReplaceAll(doubling, `{"cat": [{"var": "s"}, {"var": "s"}]}`, `[{"var": "s"}, {"var": "s"}]`)
+	if _, err := Run(nested, date, WithLimits(Limits{MaxAllocation: 1 << 20})); !errors.As(err, &le) {
+		t.Fatalf("expected *LimitError for nested values, got %v", err)
+	}
+
+	loan := createLoanSchema("employed", 720, 75000, 250000)
+	if _, err := Run(loan, date, WithLimits(Limits{MaxAllocation: 1 << 20})); err != nil {
+		t.Fatalf("ordinary schema should run within the budget: %v", err)
+	}
+}
+
+func TestMemorySessionStore(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	store := NewMemorySessionStore()
+	if _, err := store.Get("s1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	// Resume: the stored document goes back through Run unchanged
+	doc, err := Run(createLoanSchema("employed", 720, 75000, 250000), date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if err := store.Put("s1", doc); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	resumed, err := store.Get("s1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if again, err := Run(resumed, date); err != nil || again != doc {
+		t.Errorf("expected the resumed document to re-run to the same output (%v)", err)
+	}
+
+	store.Delete("s1")
+	if _, err := store.Get("s1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound after Delete, got %v", err)
+	}
+}
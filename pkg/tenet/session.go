@@ -0,0 +1,340 @@
+package tenet
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Session keeps a document in memory for a reactive UI and re-evaluates it as the user edits
+// it. SetValue changes one input and re-evaluates only the rule conditions and derived fields
+// that transitively depend on it; everything else reuses the previous evaluation. Validation,
+// display expressions and status are recomputed in full, so the result is always the same as
+// Run on the edited document. A Session is not safe for concurrent use.
+//
+// Run options apply as in Run, except that WithMeta is ignored. With WithFixedPoint every
+// edit re-evaluates the whole document.
+type Session struct {
+	input  *Schema // Document as the user edited it, before evaluation
+	output *Schema // Latest evaluated document
+	date   time.Time
+	cfg    runConfig
+	cache  *sessionCache
+	graph  *sessionGraph
+}
+
+// SessionDelta is what changed in a Session's evaluated document after an edit.
+type SessionDelta struct {
+	Changed map[string]*Definition `json:"changed,omitempty"` // Definitions added or changed (value or UI state), as in Run's output
+	Removed []string               `json:"removed,omitempty"` // Definitions no longer present
+
+	Status DocStatus         `json:"status"`           // Status after the edit
+	Errors []ValidationError `json:"errors,omitempty"` // All errors after the edit, as in Run's output
+
+	RulesEvaluated   int `json:"rules_evaluated"`   // Rule conditions evaluated (the others were reused)
+	DerivedEvaluated int `json:"derived_evaluated"` // Derived fields evaluated, per pass
+}
+
+// NewSession parses and evaluates a document. The dependency graph of its rules and derived
+// fields is built from the evaluated schema, so later edits know what they affect.
+func NewSession(jsonText string, date time.Time, opts ...RunOption) (s *Session, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = nil
+			err = recovered(r)
+		}
+	}()
+
+	cfg := newRunConfig(opts)
+	cfg.meta = false
+	var input Schema
+	if err := cfg.unmarshal([]byte(jsonText), &input); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	if err := checkEngine(&input); err != nil {
+		return nil, err
+	}
+	if cfg.limits != nil {
+		if err := cfg.limits.Check(&input); err != nil {
+			return nil, err
+		}
+	}
+	if err := decryptValues(&input, cfg.encrypter); err != nil {
+		return nil, err
+	}
+
+	s = &Session{input: &input, date: date, cfg: cfg, cache: newSessionCache()}
+	s.evaluate(nil)
+	s.graph = buildSessionGraph(s.output)
+	return s, nil
+}
+
+// SetValue sets an editable definition's value and re-evaluates what depends on it. It fails
+// for fields the document doesn't define and for readonly fields, which rules and derived
+// state own.
+func (s *Session) SetValue(id string, value any) (delta *SessionDelta, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			delta = nil
+			err = recovered(r)
+		}
+	}()
+
+	def, ok := s.input.Definitions[id]
+	if !ok || def == nil {
+		return nil, fmt.Errorf("field '%s' is not defined", id)
+	}
+	if _, derived := s.graph.derived[id]; derived || def.IsReadonly() {
+		return nil, fmt.Errorf("field '%s' is readonly", id)
+	}
+	def.Value = value
+
+	var dirty *sessionDirty
+	if s.cfg.fixedPoint == 0 {
+		dirty = s.graph.affectedBy(id)
+	}
+	previous := s.output
+	s.evaluate(dirty)
+	return s.diff(previous), nil
+}
+
+// Schema returns a copy of the latest evaluated document.
+func (s *Session) Schema() *Schema {
+	return cloneSchema(s.output)
+}
+
+// Result returns the latest evaluated document as JSON, as Run would, honoring
+// WithEncrypter and the output options.
+func (s *Session) Result() (string, error) {
+	out := cloneSchema(s.output)
+	if err := encryptValues(out, s.cfg.encrypter); err != nil {
+		return "", err
+	}
+	return NewEngine(out).marshal(s.cfg)
+}
+
+// evaluate runs the input with the cache, re-evaluating the dirty rules and derived fields
+// (everything when dirty is nil).
+func (s *Session) evaluate(dirty *sessionDirty) {
+	s.cache.begin(dirty)
+	schema := cloneSchema(s.input)
+	cfg := s.cfg
+	cfg.session = s.cache
+	runSchema(schema, s.date, cfg)
+	schema.Meta = nil
+	if !cfg.verbose {
+		omitDefaults(schema)
+	}
+	s.output = schema
+}
+
+// diff compares the latest output to the previous one.
+func (s *Session) diff(previous *Schema) *SessionDelta {
+	delta := &SessionDelta{
+		Status:           s.output.Status,
+		Errors:           s.output.Errors,
+		RulesEvaluated:   s.cache.rulesEvaluated,
+		DerivedEvaluated: s.cache.derivedEvaluated,
+	}
+	for _, id := range sortedIDs(s.output.Definitions) {
+		def := s.output.Definitions[id]
+		if old, ok := previous.Definitions[id]; !ok || !reflect.DeepEqual(old, def) {
+			if delta.Changed == nil {
+				delta.Changed = make(map[string]*Definition)
+			}
+			delta.Changed[id] = def
+		}
+	}
+	for _, id := range sortedIDs(previous.Definitions) {
+		if _, ok := s.output.Definitions[id]; !ok {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+	return delta
+}
+
+// sessionGraph records, for the evaluated schema, which fields each rule and derived field
+// reads and which fields each rule writes (set and ui_modify).
+type sessionGraph struct {
+	ruleReads  [][]string
+	ruleWrites [][]string
+	derived    map[string][]string // Derived field → fields it reads
+
+	closures map[string]*sessionDirty // Memoized affectedBy results
+}
+
+// sessionDirty is what an edit invalidates: rules by index in the logic tree and derived fields.
+type sessionDirty struct {
+	rules   map[int]bool
+	derived map[string]bool
+}
+
+func buildSessionGraph(schema *Schema) *sessionGraph {
+	e := NewEngine(schema)
+	canonical := func(path string) string {
+		if target, ok := e.aliasPath(path); ok {
+			path = target
+		}
+		root, _, _ := strings.Cut(path, ".")
+		return root
+	}
+	roots := func(exprs ...any) []string {
+		var out []string
+		for _, expr := range exprs {
+			for _, ref := range exprRoots(expr) {
+				out = append(out, canonical(ref))
+			}
+		}
+		return out
+	}
+
+	g := &sessionGraph{
+		ruleReads:  make([][]string, len(schema.LogicTree)),
+		ruleWrites: make([][]string, len(schema.LogicTree)),
+		derived:    make(map[string][]string),
+		closures:   make(map[string]*sessionDirty),
+	}
+	for i, rule := range schema.LogicTree {
+		if rule == nil {
+			continue
+		}
+		g.ruleReads[i] = roots(rule.When, rule.WhenAny, rule.Unless)
+		if rule.Then == nil {
+			continue
+		}
+		for key, value := range rule.Then.Set {
+			g.ruleReads[i] = append(g.ruleReads[i], roots(value)...)
+			g.ruleWrites[i] = append(g.ruleWrites[i], canonical(key))
+		}
+		for key := range rule.Then.UIModify {
+			g.ruleWrites[i] = append(g.ruleWrites[i], canonical(key))
+		}
+	}
+	if schema.StateModel != nil {
+		for name, def := range schema.StateModel.Derived {
+			if def != nil {
+				g.derived[name] = roots(def.Eval)
+			}
+		}
+	}
+
+	// Dependencies of the declared inputs are worked out up front
+	if schema.StateModel != nil {
+		for _, input := range schema.StateModel.Inputs {
+			g.affectedBy(input)
+		}
+	}
+	return g
+}
+
+// affectedBy returns the rules and derived fields that transitively read field: rules whose
+// conditions or set values read it or a field they invalidate, and the fields those rules write.
+func (g *sessionGraph) affectedBy(field string) *sessionDirty {
+	if dirty, ok := g.closures[field]; ok {
+		return dirty
+	}
+	affected := map[string]bool{field: true}
+	dirty := &sessionDirty{rules: make(map[int]bool), derived: make(map[string]bool)}
+	readsAffected := func(reads []string) bool {
+		for _, r := range reads {
+			if affected[r] {
+				return true
+			}
+		}
+		return false
+	}
+	for changed := true; changed; {
+		changed = false
+		for name, reads := range g.derived {
+			if !dirty.derived[name] && readsAffected(reads) {
+				dirty.derived[name], affected[name], changed = true, true, true
+			}
+		}
+		for i, reads := range g.ruleReads {
+			if dirty.rules[i] || !readsAffected(reads) {
+				continue
+			}
+			dirty.rules[i], changed = true, true
+			for _, w := range g.ruleWrites[i] {
+				affected[w] = true
+			}
+		}
+	}
+	g.closures[field] = dirty
+	return dirty
+}
+
+// sessionCache holds the rule condition results and derived values of the previous
+// evaluation, with the errors evaluating them reported, so clean ones can be replayed.
+type sessionCache struct {
+	dirty *sessionDirty // nil = evaluate everything
+
+	guards  map[int]sessionGuard
+	derived map[sessionDerivedKey]sessionValue
+
+	rulesEvaluated   int
+	derivedEvaluated int
+}
+
+type sessionGuard struct {
+	matched bool
+	errors  []ValidationError
+}
+
+// sessionDerivedKey identifies a derived value by field and computeDerived pass, since
+// Run computes derived state before and after the logic tree.
+type sessionDerivedKey struct {
+	name string
+	pass int
+}
+
+type sessionValue struct {
+	value  any
+	errors []ValidationError
+}
+
+func newSessionCache() *sessionCache {
+	return &sessionCache{
+		guards:  make(map[int]sessionGuard),
+		derived: make(map[sessionDerivedKey]sessionValue),
+	}
+}
+
+// begin starts an evaluation that re-evaluates what dirty names.
+func (c *sessionCache) begin(dirty *sessionDirty) {
+	c.dirty = dirty
+	c.rulesEvaluated, c.derivedEvaluated = 0, 0
+}
+
+// ruleMatches evaluates rule i's guards, or replays the previous result for a clean rule.
+func (c *sessionCache) ruleMatches(e *Engine, i int, rule *Rule) bool {
+	if cached, ok := c.guards[i]; ok && c.dirty != nil && !c.dirty.rules[i] {
+		for _, err := range cached.errors {
+			e.emitError(err)
+		}
+		return cached.matched
+	}
+	mark := len(e.errors)
+	matched := e.ruleMatches(rule)
+	c.guards[i] = sessionGuard{matched: matched, errors: append([]ValidationError(nil), e.errors[mark:]...)}
+	c.rulesEvaluated++
+	return matched
+}
+
+// derivedValue evaluates a derived field for the given pass, or replays the previous value
+// of a clean one.
+func (c *sessionCache) derivedValue(e *Engine, name string, pass int, eval any) any {
+	key := sessionDerivedKey{name, pass}
+	if cached, ok := c.derived[key]; ok && c.dirty != nil && !c.dirty.derived[name] {
+		for _, err := range cached.errors {
+			e.emitError(err)
+		}
+		return cloneValue(cached.value)
+	}
+	mark := len(e.errors)
+	value := e.resolve(eval)
+	c.derived[key] = sessionValue{value: cloneValue(value), errors: append([]ValidationError(nil), e.errors[mark:]...)}
+	c.derivedEvaluated++
+	return value
+}
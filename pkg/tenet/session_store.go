@@ -0,0 +1,62 @@
+package tenet
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrSessionNotFound is returned by SessionStore.Get for an unknown session ID.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionStore persists the document a user is working on, keyed by session ID, so a host
+// can resume evaluation after a restart or on another device: Put the latest Run output
+// after each change, Get it back and continue with Run. The package provides
+// MemorySessionStore; pkg/sqlstore implements it on database/sql.
+// Implementations must be safe for concurrent use.
+type SessionStore interface {
+	Get(sessionID string) (string, error) // ErrSessionNotFound if there is none
+	Put(sessionID, document string) error // Replaces any stored document
+	Delete(sessionID string) error        // Deleting an unknown session is not an error
+}
+
+// MemorySessionStore is an in-memory SessionStore, for tests and single-process hosts.
+type MemorySessionStore struct {
+	mu        sync.RWMutex
+	documents map[string]string
+}
+
+// NewMemorySessionStore creates an empty MemorySessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{documents: make(map[string]string)}
+}
+
+// Get returns the document stored for sessionID.
+func (s *MemorySessionStore) Get(sessionID string) (string, error) {
+	s.mu.RLock()
+	doc, ok := s.documents[sessionID]
+	s.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: '%s'", ErrSessionNotFound, sessionID)
+	}
+	return doc, nil
+}
+
+// Put stores document under sessionID.
+func (s *MemorySessionStore) Put(sessionID, document string) error {
+	if sessionID == "" {
+		return errors.New("session ID is empty")
+	}
+	s.mu.Lock()
+	s.documents[sessionID] = document
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete removes the document stored for sessionID.
+func (s *MemorySessionStore) Delete(sessionID string) error {
+	s.mu.Lock()
+	delete(s.documents, sessionID)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,190 @@
+package tenet
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DocumentLink feeds a field of one document into another during RunSet.
+type DocumentLink struct {
+	From  string `json:"from"`         // Source document key
+	Field string `json:"field"`        // Definition or derived field in the source
+	To    string `json:"to"`           // Target document key
+	As    string `json:"as,omitempty"` // Target field (defaults to Field)
+}
+
+// SetResult is the outcome of evaluating a linked document set.
+type SetResult struct {
+	Documents map[string]string    `json:"documents"` // Evaluated JSON per document key
+	Statuses  map[string]DocStatus `json:"statuses"`  // Status per document key
+	Status    DocStatus            `json:"status"`    // Combined status: the worst of Statuses
+	Order     []string             `json:"order"`     // Evaluation order (sources before targets)
+}
+
+// RunSet evaluates related documents (e.g., an application and its appendices) as one case.
+// Documents are run in link order; after a source is evaluated, each linked field's final value
+// (including derived values) is copied into the target as a readonly definition before the
+// target runs. The combined status is INVALID if any document is invalid, otherwise
+// INCOMPLETE if any is incomplete, otherwise READY.
+//
+// Links that reference unknown documents, or that form a cycle, are an error. WithLimits
+// applies to each document, and the first one over a limit fails the set.
+func RunSet(docs map[string]string, links []DocumentLink, date time.Time, opts ...RunOption) (result *SetResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = nil
+			err = recovered(r)
+		}
+	}()
+
+	order, err := setOrder(docs, links)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := newRunConfig(opts)
+	result = &SetResult{
+		Documents: make(map[string]string, len(docs)),
+		Statuses:  make(map[string]DocStatus, len(docs)),
+		Status:    StatusReady,
+		Order:     order,
+	}
+	outputs := make(map[string]*Schema, len(docs))
+
+	for _, key := range order {
+		var schema Schema
+		if err := cfg.unmarshal([]byte(docs[key]), &schema); err != nil {
+			return nil, fmt.Errorf("document '%s': unmarshal: %w", key, err)
+		}
+		if err := checkEngine(&schema); err != nil {
+			return nil, fmt.Errorf("document '%s': %w", key, err)
+		}
+		if cfg.limits != nil {
+			if err := cfg.limits.Check(&schema); err != nil {
+				return nil, fmt.Errorf("document '%s': %w", key, err)
+			}
+		}
+		if err := decryptValues(&schema, cfg.encrypter); err != nil {
+			return nil, fmt.Errorf("document '%s': %w", key, err)
+		}
+
+		for _, link := range links {
+			if link.To == key {
+				applyLink(&schema, link, outputs[link.From])
+			}
+		}
+
+		engine := runSchema(&schema, date, cfg)
+		outputs[key] = cloneSchema(&schema)
+
+		if err := encryptValues(&schema, cfg.encrypter); err != nil {
+			return nil, fmt.Errorf("document '%s': %w", key, err)
+		}
+		if !cfg.verbose {
+			omitDefaults(&schema)
+		}
+		text, err := engine.marshal(runConfig{compact: cfg.compact})
+		if err != nil {
+			return nil, fmt.Errorf("document '%s': %w", key, err)
+		}
+
+		result.Documents[key] = text
+		result.Statuses[key] = schema.Status
+		result.Status = worseStatus(result.Status, schema.Status)
+	}
+
+	return result, nil
+}
+
+// applyLink copies a source field's value into the target schema as a readonly definition.
+func applyLink(target *Schema, link DocumentLink, source *Schema) {
+	var value any
+	if def, ok := source.Definitions[link.Field]; ok && def != nil {
+		value = cloneValue(def.Value)
+	}
+
+	as := link.As
+	if as == "" {
+		as = link.Field
+	}
+	if target.Definitions == nil {
+		target.Definitions = make(map[string]*Definition)
+	}
+	if def, ok := target.Definitions[as]; ok && def != nil {
+		def.Value = value
+		def.SetReadonly(true)
+		return
+	}
+	def := &Definition{Type: inferType(value), Value: value}
+	def.SetReadonly(true)
+	def.SetVisible(true)
+	target.Definitions[as] = def
+}
+
+// setOrder returns document keys with every link source before its target (ties sorted by key).
+func setOrder(docs map[string]string, links []DocumentLink) ([]string, error) {
+	indegree := make(map[string]int, len(docs))
+	next := make(map[string][]string)
+	for key := range docs {
+		indegree[key] = 0
+	}
+	for _, link := range links {
+		if _, ok := docs[link.From]; !ok {
+			return nil, fmt.Errorf("link %s.%s: unknown document '%s'", link.From, link.Field, link.From)
+		}
+		if _, ok := docs[link.To]; !ok {
+			return nil, fmt.Errorf("link %s.%s: unknown document '%s'", link.From, link.Field, link.To)
+		}
+		if link.From == link.To {
+			return nil, fmt.Errorf("link %s.%s: document links to itself", link.From, link.Field)
+		}
+		next[link.From] = append(next[link.From], link.To)
+		indegree[link.To]++
+	}
+
+	var ready []string
+	for key, n := range indegree {
+		if n == 0 {
+			ready = append(ready, key)
+		}
+	}
+	sort.Strings(ready)
+
+	order := make([]string, 0, len(docs))
+	for len(ready) > 0 {
+		key := ready[0]
+		ready = ready[1:]
+		order = append(order, key)
+		for _, to := range next[key] {
+			indegree[to]--
+			if indegree[to] == 0 {
+				ready = append(ready, to)
+				sort.Strings(ready)
+			}
+		}
+	}
+
+	if len(order) != len(docs) {
+		return nil, fmt.Errorf("document links form a cycle")
+	}
+	return order, nil
+}
+
+// worseStatus returns the more severe of two statuses (INVALID > INCOMPLETE > READY).
+func worseStatus(a, b DocStatus) DocStatus {
+	rank := func(s DocStatus) int {
+		switch s {
+		case StatusInvalid:
+			return 2
+		case StatusIncomplete:
+			return 1
+		default:
+			return 0
+		}
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,80 @@
+package tenet
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunSet(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	docs := map[string]string{
+		"application": `{
+			"definitions": {
+				"salary": {"type": "number", "value": 50000},
+				"bonus": {"type": "number", "value": 10000}
+			},
+			"state_model": {
+				"derived": {"total_income": {"eval": {"+": [{"var": "salary"}, {"var": "bonus"}]}}}
+			}
+		}`,
+		"appendix_a": `{
+			"definitions": {
+				"rent": {"type": "number", "value": 25000},
+				"affordable": {"type": "boolean", "value": false}
+			},
+			"logic_tree": [
+				{"id": "afford", "when": {"<": [{"var": "rent"}, {"*": [{"var": "applicant_income"}, 0.5]}]}, "then": {"set": {"affordable": true}}}
+			]
+		}`,
+		"appendix_b": `{
+			"definitions": {"signature": {"type": "string", "required": true}}
+		}`,
+	}
+	links := []DocumentLink{{From: "application", Field: "total_income", To: "appendix_a", As: "applicant_income"}}
+
+	result, err := RunSet(docs, links, date)
+	if err != nil {
+		t.Fatalf("RunSet failed: %v", err)
+	}
+
+	assertEqual(t, strings.Join(result.Order, ","), "appendix_b,application,appendix_a")
+	assertEqual(t, result.Statuses["application"], StatusReady)
+	assertEqual(t, result.Statuses["appendix_b"], StatusIncomplete)
+	assertEqual(t, result.Status, StatusIncomplete)
+
+	appendix := parseResult(t, result.Documents["appendix_a"])
+	assertDefinitionValue(t, appendix, "applicant_income", 60000.0)
+	assertDefinitionValue(t, appendix, "affordable", true)
+	if !appendix.Definitions["applicant_income"].IsReadonly() {
+		t.Error("linked field should be readonly")
+	}
+}
+
+func TestRunSetErrors(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	docs := map[string]string{
+		"a": `{"definitions": {"x": {"type": "number", "value": 1}}}`,
+		"b": `{"definitions": {"y": {"type": "number", "value": 2}}}`,
+	}
+
+	cycle := []DocumentLink{{From: "a", Field: "x", To: "b"}, {From: "b", Field: "y", To: "a"}}
+	if _, err := RunSet(docs, cycle, date); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+
+	unknown := []DocumentLink{{From: "a", Field: "x", To: "missing"}}
+	if _, err := RunSet(docs, unknown, date); err == nil || !strings.Contains(err.Error(), "unknown document 'missing'") {
+		t.Errorf("expected unknown document error, got %v", err)
+	}
+
+	// Each document is checked, not just the first
+	docs["b"] = `{"definitions": {"y": {"type": "number", "value": 2}, "z": {"type": "number", "value": 3}}}`
+	_, err := RunSet(docs, nil, date, WithLimits(Limits{MaxDefinitions: 1}))
+	var le *LimitError
+	if !errors.As(err, &le) || !strings.Contains(err.Error(), "document 'b'") {
+		t.Fatalf("expected *LimitError for document 'b', got %v", err)
+	}
+	assertEqual(t, le.Limit, "max_definitions")
+}
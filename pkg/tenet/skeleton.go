@@ -0,0 +1,100 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Skeleton is a structure-only view of a document with every value stripped.
+// It records which fields exist, their visibility and whether they were filled,
+// so hosts can run completion-funnel analytics without storing personal data.
+type Skeleton struct {
+	SchemaID     string                         `json:"schema_id,omitempty"`
+	Version      string                         `json:"version,omitempty"`
+	Status       DocStatus                      `json:"status,omitempty"`
+	Fields       map[string]SkeletonField       `json:"fields"`
+	Attestations map[string]SkeletonAttestation `json:"attestations,omitempty"`
+	Errors       []SkeletonError                `json:"errors,omitempty"`
+}
+
+// SkeletonField describes a definition without its value.
+type SkeletonField struct {
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Readonly bool   `json:"readonly"`
+	Visible  bool   `json:"visible"`
+	Filled   bool   `json:"filled"` // Value was present (non-nil, non-empty string)
+}
+
+// SkeletonAttestation describes an attestation without statement or evidence.
+type SkeletonAttestation struct {
+	Required bool `json:"required"`
+	Signed   bool `json:"signed"`
+}
+
+// SkeletonError is a validation error without its message.
+// Messages are dropped because they may quote submitted values.
+type SkeletonError struct {
+	FieldID string    `json:"field_id,omitempty"`
+	RuleID  string    `json:"rule_id,omitempty"`
+	Kind    ErrorKind `json:"kind"`
+	LawRef  string    `json:"law_ref,omitempty"`
+}
+
+// ExportSkeleton converts a document (typically a Run result) into its structure-only form.
+func ExportSkeleton(jsonText string) (string, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(jsonText), &schema); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+
+	result, err := json.MarshalIndent(NewSkeleton(&schema), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return string(result), nil
+}
+
+// NewSkeleton builds the structure-only view of a parsed document.
+func NewSkeleton(schema *Schema) *Skeleton {
+	sk := &Skeleton{
+		SchemaID: schema.SchemaID,
+		Version:  schema.Version,
+		Status:   schema.Status,
+		Fields:   make(map[string]SkeletonField, len(schema.Definitions)),
+	}
+
+	for id, def := range schema.Definitions {
+		if def == nil {
+			continue
+		}
+		sk.Fields[id] = SkeletonField{
+			Type:     def.Type,
+			Required: def.IsRequired(),
+			Readonly: def.IsReadonly(),
+			Visible:  def.IsVisible(),
+			Filled:   isFilled(def.Value),
+		}
+	}
+
+	if len(schema.Attestations) > 0 {
+		sk.Attestations = make(map[string]SkeletonAttestation, len(schema.Attestations))
+		for id, att := range schema.Attestations {
+			if att == nil {
+				continue
+			}
+			sk.Attestations[id] = SkeletonAttestation{Required: att.Required, Signed: att.Signed}
+		}
+	}
+
+	for _, err := range schema.Errors {
+		sk.Errors = append(sk.Errors, SkeletonError{
+			FieldID: err.FieldID,
+			RuleID:  err.RuleID,
+			Kind:    err.Kind,
+			LawRef:  err.LawRef,
+		})
+	}
+
+	return sk
+}
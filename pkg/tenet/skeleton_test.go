@@ -0,0 +1,62 @@
+package tenet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportSkeleton(t *testing.T) {
+	input := `{
+		"schema_id": "loan",
+		"definitions": {
+			"name": {"type": "string", "value": "Anna Svensson", "required": true},
+			"email": {"type": "string", "value": "", "required": true},
+			"country": {"type": "select", "value": "XX", "options": ["SE", "NO"]},
+			"secret_note": {"type": "string", "value": "hidden answer", "visible": false}
+		},
+		"attestations": {
+			"confirm": {"statement": "I confirm", "required": true, "signed": false}
+		}
+	}`
+
+	result, err := Run(input, time.Now())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	out, err := ExportSkeleton(result)
+	if err != nil {
+		t.Fatalf("ExportSkeleton failed: %v", err)
+	}
+
+	for _, leaked := range []string{"Anna Svensson", "XX", "hidden answer", "I confirm"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("skeleton leaked value %q", leaked)
+		}
+	}
+
+	var sk Skeleton
+	if err := json.Unmarshal([]byte(out), &sk); err != nil {
+		t.Fatalf("invalid skeleton JSON: %v", err)
+	}
+
+	assertEqual(t, sk.SchemaID, "loan")
+	assertEqual(t, sk.Status, StatusIncomplete)
+	assertEqual(t, sk.Fields["name"].Filled, true)
+	assertEqual(t, sk.Fields["email"].Filled, false)
+	assertEqual(t, sk.Fields["email"].Required, true)
+	assertEqual(t, sk.Fields["secret_note"].Visible, false)
+	assertEqual(t, sk.Fields["country"].Type, "select")
+	assertEqual(t, sk.Attestations["confirm"].Signed, false)
+
+	if len(sk.Errors) == 0 {
+		t.Fatal("expected errors to be carried over")
+	}
+	for _, e := range sk.Errors {
+		if e.Kind == "" {
+			t.Errorf("error missing kind: %+v", e)
+		}
+	}
+}
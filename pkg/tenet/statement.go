@@ -0,0 +1,77 @@
+package tenet
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// statementPlaceholder matches {{field}} (or {{field.path}}) in attestation statements.
+var statementPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// isTemplated reports whether a statement interpolates document values.
+func isTemplated(statement string) bool {
+	return statementPlaceholder.MatchString(statement)
+}
+
+// renderStatement substitutes {{field}} placeholders with the current values, formatted
+// like computed labels. Unset values render as empty text.
+func (e *Engine) renderStatement(statement string) string {
+	return statementPlaceholder.ReplaceAllStringFunc(statement, func(match string) string {
+		path := statementPlaceholder.FindStringSubmatch(match)[1]
+		text, _ := displayText(e.getVar(path))
+		return text
+	})
+}
+
+// invalidateChangedStatement fills rendered_statement when the attestation's statement is
+// templated. The signing app freezes the statement it showed into evidence.statement; a
+// signature whose frozen statement no longer matches is un-signed, since the signer agreed to
+// different figures, and so is one without a frozen statement, since Run can't tell what was
+// signed. Reports whether the signature was invalidated.
+func (e *Engine) invalidateChangedStatement(id string, att *Attestation) bool {
+	att.RenderedStatement = ""
+	if !isTemplated(att.Statement) {
+		return false
+	}
+	att.RenderedStatement = e.renderStatement(att.Statement)
+
+	if !att.Signed || att.Evidence == nil {
+		return false
+	}
+	reason := "the signed statement no longer matches the document"
+	switch att.Evidence.Statement {
+	case att.RenderedStatement:
+		return false
+	case "":
+		reason = "its evidence doesn't record the signed statement"
+	}
+	att.Signed = false
+	e.addError(id, "", ErrAttestationIncomplete, fmt.Sprintf(
+		"Attestation '%s' must be re-signed: %s", id, reason), att.LawRef)
+	return true
+}
+
+// checkSignedStatement compares the statement frozen into a submitted attestation's
+// evidence with the statement rendered from the verified values.
+func checkSignedStatement(id string, att, resultAtt *Attestation) *VerifyIssue {
+	if resultAtt == nil || resultAtt.RenderedStatement == "" {
+		return nil
+	}
+	signed := att.Evidence.Statement
+	if signed == resultAtt.RenderedStatement {
+		return nil
+	}
+	message := fmt.Sprintf("attestation '%s' was signed for a different statement than the document now renders", id)
+	if signed == "" {
+		message = fmt.Sprintf("attestation '%s' has no signed statement in its evidence", id)
+	}
+	return &VerifyIssue{
+		Code:        VerifyAttestationStatementChanged,
+		Severity:    VerifySeverityError,
+		FieldID:     id,
+		Message:     message,
+		Expected:    resultAtt.RenderedStatement,
+		Claimed:     att.Evidence.Statement,
+		Remediation: resignAttestation(id),
+	}
+}
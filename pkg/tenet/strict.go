@@ -0,0 +1,139 @@
+package tenet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownFields is wrapped by every *UnknownFieldsError, for errors.Is checks.
+var ErrUnknownFields = errors.New("unknown schema fields")
+
+// UnknownFieldsError lists the keys of a document that no schema field accepts.
+type UnknownFieldsError struct {
+	Paths []string // e.g. "logci_tree", "definitions.age.requird", "logic_tree[2].then.sett"
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrUnknownFields, strings.Join(e.Paths, ", "))
+}
+
+// Unwrap makes errors.Is(err, ErrUnknownFields) hold.
+func (e *UnknownFieldsError) Unwrap() error {
+	return ErrUnknownFields
+}
+
+// WithStrictFields rejects documents with keys no schema field accepts, which encoding/json
+// otherwise drops silently: a misspelled "logci_tree" means a schema without rules. Run fails
+// with an *UnknownFieldsError naming every such key by its path. Keys are matched
+// case-insensitively, as encoding/json does. Keys starting with "$" ("$schema", "$comment")
+// are allowed anywhere, and expressions (when, set values, eval) are not checked.
+func WithStrictFields() RunOption {
+	return func(c *runConfig) {
+		c.strict = true
+	}
+}
+
+// UnknownFields returns the paths of the keys in a document that no schema field accepts,
+// ordered by key at each level, or nil when every key is known. Use it to check a schema being
+// edited without running it.
+func UnknownFields(jsonText string) ([]string, error) {
+	var doc any
+	if err := json.Unmarshal([]byte(jsonText), &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	var paths []string
+	collectUnknown(doc, reflect.TypeOf(Schema{}), "", &paths)
+	return paths, nil
+}
+
+// checkUnknownFields returns an *UnknownFieldsError for a document with unknown keys.
+func checkUnknownFields(jsonText string) error {
+	paths, err := UnknownFields(jsonText)
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		return &UnknownFieldsError{Paths: paths}
+	}
+	return nil
+}
+
+// collectUnknown walks a decoded JSON value alongside the Go type it decodes into.
+func collectUnknown(v any, t reflect.Type, path string, paths *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for _, key := range sortedIDs(obj) {
+			if strings.HasPrefix(key, "$") {
+				continue // Annotations such as "$schema" and "$comment"
+			}
+			field, ok := fields[strings.ToLower(key)]
+			if !ok {
+				*paths = append(*paths, joinPath(path, key))
+				continue
+			}
+			collectUnknown(obj[key], field, joinPath(path, key), paths)
+		}
+	case reflect.Map:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		for _, key := range sortedIDs(obj) {
+			collectUnknown(obj[key], t.Elem(), joinPath(path, key), paths)
+		}
+	case reflect.Slice:
+		arr, ok := v.([]any)
+		if !ok {
+			return
+		}
+		for i, elem := range arr {
+			collectUnknown(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i), paths)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// structFields caches jsonFields by type.
+var structFields sync.Map // reflect.Type -> map[string]reflect.Type
+
+// jsonFields maps the lowercased JSON names of a struct's fields to their types.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	if cached, ok := structFields.Load(t); ok {
+		return cached.(map[string]reflect.Type)
+	}
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	structFields.Store(t, fields)
+	return fields
+}
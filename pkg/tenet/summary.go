@@ -0,0 +1,71 @@
+package tenet
+
+// Summary is a schema-level digest of the document for list views: a title, a subtitle
+// and key figures. Like label_expr, each *_expr is evaluated by Run against the final
+// state and written to the matching output field; a nil result keeps the static value.
+type Summary struct {
+	Title    string           `json:"title,omitempty"`
+	Subtitle string           `json:"subtitle,omitempty"`
+	Figures  []*SummaryFigure `json:"figures,omitempty"`
+
+	TitleExpr    any `json:"title_expr,omitempty"`
+	SubtitleExpr any `json:"subtitle_expr,omitempty"`
+}
+
+// SummaryFigure is one labelled key figure (e.g., "Loan amount": 250000).
+// Value keeps the expression's type, so numbers stay numbers for the host to format.
+type SummaryFigure struct {
+	Label     string `json:"label"`
+	Value     any    `json:"value"`
+	ValueExpr any    `json:"value_expr,omitempty"`
+}
+
+// computeSummary evaluates the summary expressions into a fresh Summary, leaving the
+// (possibly shared) input untouched.
+func (e *Engine) computeSummary() {
+	in := e.schema.Summary
+	if in == nil {
+		return
+	}
+	out := *in
+	if in.TitleExpr != nil {
+		if text, ok := displayText(e.resolve(in.TitleExpr)); ok {
+			out.Title = text
+		}
+	}
+	if in.SubtitleExpr != nil {
+		if text, ok := displayText(e.resolve(in.SubtitleExpr)); ok {
+			out.Subtitle = text
+		}
+	}
+	if in.Figures != nil {
+		out.Figures = make([]*SummaryFigure, len(in.Figures))
+		for i, fig := range in.Figures {
+			if fig == nil {
+				continue
+			}
+			f := *fig
+			if fig.ValueExpr != nil {
+				if value := e.resolve(fig.ValueExpr); value != nil {
+					f.Value = value
+				}
+			}
+			out.Figures[i] = &f
+		}
+	}
+	e.schema.Summary = &out
+}
+
+// summaryExprs lists the summary's expressions, for indexing, limits and lint.
+func summaryExprs(s *Summary) []any {
+	if s == nil {
+		return nil
+	}
+	exprs := []any{s.TitleExpr, s.SubtitleExpr}
+	for _, fig := range s.Figures {
+		if fig != nil {
+			exprs = append(exprs, fig.ValueExpr)
+		}
+	}
+	return exprs
+}
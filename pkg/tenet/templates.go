@@ -0,0 +1,315 @@
+package tenet
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Template is a reusable block of fields (e.g., an address) with the rules, derived values
+// and field groups that belong to it. ExpandTemplates stamps it into a schema once per use.
+type Template struct {
+	Definitions     map[string]*Definition `json:"definitions"`
+	LogicTree       []*Rule                `json:"logic_tree,omitempty"`
+	Derived         map[string]*DerivedDef `json:"derived,omitempty"`
+	RequireTogether []*FieldGroup          `json:"require_together,omitempty"`
+	RequireOneOf    []*FieldGroup          `json:"require_one_of,omitempty"`
+}
+
+// TemplateUse instantiates a template under a prefix.
+type TemplateUse struct {
+	Template string `json:"template"` // Key in templates
+	Prefix   string `json:"prefix"`   // Prepended to every field, rule and group ID (prefix_id)
+}
+
+// ExpandTemplates instantiates every entry of `use_templates`. Each use copies the template's
+// definitions, derived values, rules and field groups into the schema with IDs renamed to
+// <prefix>_<id>, and rewrites references to the template's own fields inside conditions,
+// set values, ui_modify targets, display expressions and groups. References to fields outside
+// the template are left alone, so template rules can read schema-level fields.
+//
+// IDs are joined with "_" rather than "." because dotted var paths address nested values.
+// Template rules run before the schema's own rules. An ID that already exists is an error.
+// The `templates` and `use_templates` blocks are removed; a schema without `use_templates`
+// is returned unchanged.
+func ExpandTemplates(jsonText string) (string, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(jsonText), &schema); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+	if len(schema.UseTemplates) == 0 {
+		return jsonText, nil
+	}
+
+	if schema.Definitions == nil {
+		schema.Definitions = make(map[string]*Definition)
+	}
+	own := schema.LogicTree
+	schema.LogicTree = nil
+	for i, use := range schema.UseTemplates {
+		if use == nil || use.Template == "" || use.Prefix == "" {
+			return "", fmt.Errorf("use_templates[%d]: template and prefix are required", i)
+		}
+		tmpl, ok := schema.Templates[use.Template]
+		if !ok || tmpl == nil {
+			return "", fmt.Errorf("use_templates[%d]: unknown template '%s'", i, use.Template)
+		}
+		if err := expandTemplate(&schema, tmpl, use.Prefix); err != nil {
+			return "", fmt.Errorf("template '%s' as '%s': %w", use.Template, use.Prefix, err)
+		}
+	}
+	for _, rule := range own {
+		if rule != nil && rule.ID != "" && hasRule(schema.LogicTree, rule.ID) {
+			return "", fmt.Errorf("rule '%s' conflicts with a template rule", rule.ID)
+		}
+	}
+	schema.LogicTree = append(schema.LogicTree, own...)
+	schema.Templates = nil
+	schema.UseTemplates = nil
+
+	result, err := json.MarshalIndent(&schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return string(result), nil
+}
+
+// expandTemplate copies one instance of tmpl into dst under prefix.
+func expandTemplate(dst *Schema, tmpl *Template, prefix string) error {
+	// Work on a private deep copy: the same template can be used many times
+	raw, err := json.Marshal(tmpl)
+	if err != nil {
+		return err
+	}
+	var t Template
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return err
+	}
+
+	local := make(map[string]bool, len(t.Definitions)+len(t.Derived))
+	for id := range t.Definitions {
+		local[id] = true
+	}
+	for id := range t.Derived {
+		local[id] = true
+	}
+	localRules := make(map[string]bool, len(t.LogicTree))
+	for _, rule := range t.LogicTree {
+		if rule != nil && rule.ID != "" {
+			localRules[rule.ID] = true
+		}
+	}
+	name := func(id string) string { return prefix + "_" + id }
+	rename := func(path string) string {
+		root, rest, nested := strings.Cut(path, ".")
+		if !local[root] {
+			return path
+		}
+		if nested {
+			return name(root) + "." + rest
+		}
+		return name(root)
+	}
+
+	for _, id := range sortedIDs(t.Definitions) {
+		def := t.Definitions[id]
+		if _, exists := dst.Definitions[name(id)]; exists {
+			return fmt.Errorf("definition '%s' already exists", name(id))
+		}
+		if def != nil {
+			def.LabelExpr = rewriteVars(def.LabelExpr, rename)
+			def.UIMessageExpr = rewriteVars(def.UIMessageExpr, rename)
+			for i, writer := range def.WritableBy {
+				if localRules[writer] {
+					def.WritableBy[i] = name(writer)
+				}
+			}
+			for i, old := range def.RenamedFrom {
+				def.RenamedFrom[i] = name(old)
+			}
+		}
+		dst.Definitions[name(id)] = def
+	}
+
+	if len(t.Derived) > 0 {
+		if dst.StateModel == nil {
+			dst.StateModel = &StateModel{}
+		}
+		if dst.StateModel.Derived == nil {
+			dst.StateModel.Derived = make(map[string]*DerivedDef)
+		}
+	}
+	for _, id := range sortedIDs(t.Derived) {
+		derived := t.Derived[id]
+		if _, exists := dst.StateModel.Derived[name(id)]; exists {
+			return fmt.Errorf("derived field '%s' already exists", name(id))
+		}
+		if derived != nil {
+			if derived.Eval, err = rewriteEval(derived.Eval, rename); err != nil {
+				return fmt.Errorf("derived field '%s': %w", id, err)
+			}
+			for scope, expr := range derived.ByJurisdiction {
+				if derived.ByJurisdiction[scope], err = rewriteEval(expr, rename); err != nil {
+					return fmt.Errorf("derived field '%s': %w", id, err)
+				}
+			}
+		}
+		dst.StateModel.Derived[name(id)] = derived
+	}
+
+	for _, rule := range t.LogicTree {
+		if rule == nil {
+			continue
+		}
+		if rule.ID != "" {
+			rule.ID = name(rule.ID)
+			if hasRule(dst.LogicTree, rule.ID) {
+				return fmt.Errorf("rule '%s' already exists", rule.ID)
+			}
+		}
+		if rule.When, err = rewriteCondition(rule.When, rename); err != nil {
+			return fmt.Errorf("rule '%s': %w", rule.ID, err)
+		}
+		if rule.Unless, err = rewriteCondition(rule.Unless, rename); err != nil {
+			return fmt.Errorf("rule '%s': %w", rule.ID, err)
+		}
+		for i, cond := range rule.WhenAny {
+			if rule.WhenAny[i], err = rewriteCondition(cond, rename); err != nil {
+				return fmt.Errorf("rule '%s': %w", rule.ID, err)
+			}
+		}
+		if rule.Then != nil {
+			rule.Then = rewriteAction(rule.Then, rename)
+		}
+		dst.LogicTree = append(dst.LogicTree, rule)
+	}
+
+	for _, group := range append(t.RequireTogether, t.RequireOneOf...) {
+		if group == nil {
+			continue
+		}
+		if group.ID != "" {
+			group.ID = name(group.ID)
+		}
+		for i, f := range group.Fields {
+			group.Fields[i] = rename(f)
+		}
+	}
+	dst.RequireTogether = append(dst.RequireTogether, t.RequireTogether...)
+	dst.RequireOneOf = append(dst.RequireOneOf, t.RequireOneOf...)
+	return nil
+}
+
+// rewriteAction renames set and ui_modify targets and rewrites set expressions.
+func rewriteAction(action *Action, rename func(string) string) *Action {
+	if action.Set != nil {
+		set := make(map[string]any, len(action.Set))
+		for field, val := range action.Set {
+			set[rename(field)] = rewriteVars(val, rename)
+		}
+		action.Set = set
+	}
+	if action.UIModify != nil {
+		mods := make(map[string]any, len(action.UIModify))
+		for field, mod := range action.UIModify {
+			mods[rename(field)] = mod
+		}
+		action.UIModify = mods
+	}
+	return action
+}
+
+// rewriteEval is rewriteVars for derived formulas. Infix formulas are compiled first;
+// string constants are left alone.
+func rewriteEval(eval any, rename func(string) string) (any, error) {
+	text, ok := eval.(string)
+	if !ok {
+		return rewriteVars(eval, rename), nil
+	}
+	expr, err := ParseEval(text)
+	if err != nil {
+		return nil, fmt.Errorf("unparseable expression '%s': %w", text, err)
+	}
+	if expr == text {
+		return text, nil
+	}
+	return rewriteVars(expr, rename), nil
+}
+
+// rewriteCondition is rewriteVars for conditions, which may be infix strings; those are
+// compiled to JSON-logic first.
+func rewriteCondition(cond any, rename func(string) string) (any, error) {
+	switch c := cond.(type) {
+	case string:
+		expr, err := ParseExpr(c)
+		if err != nil {
+			return nil, fmt.Errorf("unparseable expression '%s': %w", c, err)
+		}
+		return rewriteVars(expr, rename), nil
+	case []any:
+		out := make([]any, len(c))
+		for i, elem := range c {
+			var err error
+			if out[i], err = rewriteCondition(elem, rename); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	default:
+		return rewriteVars(cond, rename), nil
+	}
+}
+
+// rewriteVars returns a copy of a JSON-logic expression with every {"var": path} renamed.
+func rewriteVars(node any, rename func(string) string) any {
+	switch v := node.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, val := range v {
+			if path, ok := val.(string); ok && key == "var" && len(v) == 1 {
+				out[key] = rename(path)
+				continue
+			}
+			if (key == "missing" || key == "missing_some") && len(v) == 1 {
+				out[key] = renameKeys(val, rename)
+				continue
+			}
+			out[key] = rewriteVars(val, rename)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = rewriteVars(elem, rename)
+		}
+		return out
+	default:
+		return node
+	}
+}
+
+// renameKeys renames the field IDs that missing and missing_some take as string literals.
+func renameKeys(node any, rename func(string) string) any {
+	switch v := node.(type) {
+	case string:
+		return rename(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = renameKeys(elem, rename)
+		}
+		return out
+	default:
+		return rewriteVars(node, rename)
+	}
+}
+
+// hasRule reports whether rules contain one with the given ID.
+func hasRule(rules []*Rule, id string) bool {
+	for _, rule := range rules {
+		if rule != nil && rule.ID == id {
+			return true
+		}
+	}
+	return false
+}
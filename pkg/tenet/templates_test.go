@@ -0,0 +1,126 @@
+package tenet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplates(t *testing.T) {
+	input := `{
+		"templates": {
+			"address": {
+				"definitions": {
+					"country": {"type": "string"},
+					"postcode": {"type": "string"},
+					"needs_postcode": {"type": "boolean", "value": false}
+				},
+				"derived": {
+					"domestic": {"eval": "= country == base_country"}
+				},
+				"logic_tree": [
+					{"id": "us_postcode", "when": "country == 'US'", "then": {"set": {"needs_postcode": true}, "ui_modify": {"postcode": {"required": true}}}}
+				],
+				"require_together": [{"id": "pair", "fields": ["country", "postcode"]}]
+			}
+		},
+		"use_templates": [
+			{"template": "address", "prefix": "home"},
+			{"template": "address", "prefix": "work"}
+		],
+		"definitions": {
+			"base_country": {"type": "string", "value": "US"}
+		},
+		"logic_tree": [
+			{"id": "own", "when": {"==": [{"var": "work_domestic"}, false]}, "then": {"error_msg": "Work abroad needs a permit"}}
+		]
+	}`
+
+	t.Run("expand", func(t *testing.T) {
+		expanded, err := ExpandTemplates(input)
+		if err != nil {
+			t.Fatalf("ExpandTemplates failed: %v", err)
+		}
+		schema := parseResult(t, expanded)
+		for _, id := range []string{"base_country", "home_country", "home_postcode", "home_needs_postcode", "work_country", "work_postcode"} {
+			assertDefinitionExists(t, schema, id)
+		}
+		if schema.Templates != nil || schema.UseTemplates != nil {
+			t.Error("templates should be removed after expansion")
+		}
+		if len(schema.LogicTree) != 3 || schema.LogicTree[0].ID != "home_us_postcode" || schema.LogicTree[1].ID != "work_us_postcode" || schema.LogicTree[2].ID != "own" {
+			t.Fatalf("unexpected rule order: %d rules", len(schema.LogicTree))
+		}
+		if len(schema.RequireTogether) != 2 || schema.RequireTogether[1].ID != "work_pair" || schema.RequireTogether[1].Fields[0] != "work_country" {
+			t.Errorf("require_together not rewritten: %+v", schema.RequireTogether)
+		}
+		if _, ok := schema.StateModel.Derived["work_domestic"]; !ok {
+			t.Error("expected derived field work_domestic")
+		}
+	})
+
+	t.Run("run", func(t *testing.T) {
+		expanded, err := ExpandTemplates(input)
+		if err != nil {
+			t.Fatalf("ExpandTemplates failed: %v", err)
+		}
+		schema := parseResult(t, expanded)
+		schema.Definitions["work_country"].Value = "US"
+		data, _ := json.Marshal(schema)
+		result, err := Run(string(data), time.Now())
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema = parseResult(t, result)
+		assertDefinitionValue(t, schema, "work_needs_postcode", true)
+		assertDefinitionValue(t, schema, "home_needs_postcode", false)
+		assertDefinitionRequired(t, schema, "work_postcode", true)
+		assertDefinitionValue(t, schema, "work_domestic", true)
+		// Only the work address is half-filled
+		for _, e := range schema.Errors {
+			if e.FieldID != "work_postcode" {
+				t.Errorf("unexpected error: %+v", e)
+			}
+		}
+		if len(schema.Errors) == 0 {
+			t.Error("expected work_postcode to be reported missing")
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		clash := strings.Replace(input, `"base_country": {"type": "string", "value": "US"}`, `"home_postcode": {"type": "string"}`, 1)
+		if _, err := ExpandTemplates(clash); err == nil || !strings.Contains(err.Error(), "home_postcode") {
+			t.Errorf("expected conflict on home_postcode, got %v", err)
+		}
+		unknown := strings.Replace(input, `"template": "address", "prefix": "work"`, `"template": "adress", "prefix": "work"`, 1)
+		if _, err := ExpandTemplates(unknown); err == nil || !strings.Contains(err.Error(), "unknown template") {
+			t.Errorf("expected unknown template error, got %v", err)
+		}
+	})
+
+	t.Run("missing keys", func(t *testing.T) {
+		local := map[string]bool{"country": true, "postcode": true}
+		rename := func(path string) string {
+			if root, _, _ := strings.Cut(path, "."); local[root] {
+				return "home_" + path
+			}
+			return path
+		}
+		var expr any
+		json.Unmarshal([]byte(`{"or": [{"missing": ["country", "base_country"]}, {"missing_some": [1, ["postcode", "country.code"]]}]}`), &expr)
+		got, _ := json.Marshal(rewriteVars(expr, rename))
+		want := `{"or":[{"missing":["home_country","base_country"]},{"missing_some":[1,["home_postcode","home_country.code"]]}]}`
+		if string(got) != want {
+			t.Errorf("rewriteVars = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("noop", func(t *testing.T) {
+		plain := `{"definitions": {"a": {"type": "string"}}}`
+		out, err := ExpandTemplates(plain)
+		if err != nil || out != plain {
+			t.Errorf("expected unchanged schema, got %q, %v", out, err)
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package tenet
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -117,6 +119,90 @@ func (e *Engine) prune(activeBranch *TemporalBranch) {
 	}
 }
 
+// checkArchived reports an effective date that selects an ARCHIVED branch, per archived_policy.
+func (e *Engine) checkArchived(branch *TemporalBranch, date time.Time) {
+	if branch == nil || branch.Status != "ARCHIVED" || e.schema.ArchivedPolicy == ArchivedAllow {
+		return
+	}
+	e.addError("", "", ErrArchivedVersion, fmt.Sprintf(
+		"Effective date %s selects archived logic version '%s'",
+		date.Format("2006-01-02"), branch.LogicVersion), "")
+}
+
+// pruneDates marks rules as disabled if the effective date falls outside their own
+// valid_from / valid_until (both inclusive). This works with or without a temporal_map.
+// Unparseable dates are reported as runtime warnings and treated as absent.
+func (e *Engine) pruneDates(date time.Time) {
+	for _, rule := range e.schema.LogicTree {
+		if rule == nil || (rule.ValidFrom == "" && rule.ValidUntil == "") {
+			continue
+		}
+
+		if rule.ValidFrom != "" {
+			if from, ok := parseDate(rule.ValidFrom); !ok {
+				e.addError("", rule.ID, ErrRuntimeWarning, fmt.Sprintf(
+					"Rule '%s' has invalid valid_from '%s'", rule.ID, rule.ValidFrom), "")
+			} else if date.Before(from) {
+				rule.Disabled = true
+			}
+		}
+
+		if rule.ValidUntil != "" {
+			if until, ok := parseDate(rule.ValidUntil); !ok {
+				e.addError("", rule.ID, ErrRuntimeWarning, fmt.Sprintf(
+					"Rule '%s' has invalid valid_until '%s'", rule.ID, rule.ValidUntil), "")
+			} else if date.After(until) {
+				rule.Disabled = true
+			}
+		}
+	}
+}
+
+// pruneTags marks rules as disabled if they are filtered out by tag selection.
+// With no include or exclude tags, every rule is left as-is.
+func (e *Engine) pruneTags(include, exclude []string) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return
+	}
+
+	for _, rule := range e.schema.LogicTree {
+		if rule == nil {
+			continue
+		}
+
+		if len(include) > 0 && !hasAnyTag(rule.Tags, include) {
+			rule.Disabled = true
+		}
+		if hasAnyTag(rule.Tags, exclude) {
+			rule.Disabled = true
+		}
+	}
+}
+
+// pruneFeatures marks rules as disabled if they declare a feature flag that isn't enabled.
+func (e *Engine) pruneFeatures(enabled []string) {
+	for _, rule := range e.schema.LogicTree {
+		if rule == nil || rule.Feature == "" {
+			continue
+		}
+		if !slices.Contains(enabled, rule.Feature) {
+			rule.Disabled = true
+		}
+	}
+}
+
+// hasAnyTag reports whether tags contains any of the wanted tags.
+func hasAnyTag(tags, wanted []string) bool {
+	for _, tag := range tags {
+		for _, w := range wanted {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // getActiveVersion returns the logic version for a given date.
 // Returns empty string if no temporal mapping exists.
 func (e *Engine) getActiveVersion(targetDate time.Time) string {
@@ -126,3 +212,26 @@ func (e *Engine) getActiveVersion(targetDate time.Time) string {
 	}
 	return branch.LogicVersion
 }
+
+// ActiveVersion returns the logic_version the schema's temporal_map selects for date,
+// or "" if there is no temporal_map or no branch covers the date.
+func ActiveVersion(jsonText string, date time.Time) (string, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(jsonText), &schema); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+	return NewEngine(&schema).getActiveVersion(date), nil
+}
+
+// stampEvidenceVersion records the active logic version on the evidence of signed
+// attestations that don't carry one yet, so each signature names the version it was given under.
+func (e *Engine) stampEvidenceVersion() {
+	if e.schema.ActiveVersion == "" {
+		return
+	}
+	for _, att := range e.schema.Attestations {
+		if att != nil && att.Signed && att.Evidence != nil && att.Evidence.LogicVersion == "" {
+			att.Evidence.LogicVersion = e.schema.ActiveVersion
+		}
+	}
+}
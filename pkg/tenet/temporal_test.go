@@ -0,0 +1,122 @@
+package tenet
+
+import (
+	"testing"
+	"time"
+)
+
+const versionedSchema = `{
+	"definitions": {
+		"income": {"type": "number", "value": 50000},
+		"rate": {"type": "number", "value": 0}
+	},
+	"attestations": {
+		"confirm": {
+			"statement": "I confirm",
+			"signed": true,
+			"evidence": {"provider_audit_id": "a-1", "timestamp": "2025-03-01T10:00:00Z", "signer_id": "ada"}
+		}
+	},
+	"temporal_map": [
+		{"valid_range": ["2024-01-01", "2024-12-31"], "logic_version": "v2024", "status": "ARCHIVED"},
+		{"valid_range": ["2025-01-01", null], "logic_version": "v2025", "status": "ACTIVE"}
+	],
+	"logic_tree": [
+		{"id": "rate_2024", "logic_version": "v2024", "when": {">": [{"var": "income"}, 0]}, "then": {"set": {"rate": 0.25}}},
+		{"id": "rate_2025", "logic_version": "v2025", "when": {">": [{"var": "income"}, 0]}, "then": {"set": {"rate": 0.22}}}
+	]
+}`
+
+func TestActiveVersion(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), ""},
+		{time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "v2024"},
+		{time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), "v2025"},
+	}
+	for _, tt := range tests {
+		got, err := ActiveVersion(versionedSchema, tt.date)
+		if err != nil {
+			t.Fatalf("ActiveVersion failed: %v", err)
+		}
+		assertEqual(t, got, tt.want)
+	}
+
+	if _, err := ActiveVersion("not json", time.Now()); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestActiveVersionStamped(t *testing.T) {
+	result, err := Run(versionedSchema, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	assertEqual(t, schema.ActiveVersion, "v2025")
+	assertDefinitionValue(t, schema, "rate", 0.22)
+	assertEqual(t, schema.Attestations["confirm"].Evidence.LogicVersion, "v2025")
+
+	// Evidence that already names a version is left alone
+	preset := `{
+		"definitions": {},
+		"attestations": {"confirm": {"statement": "x", "signed": true, "evidence": {"logic_version": "v2024"}}},
+		"temporal_map": [{"valid_range": ["2025-01-01", null], "logic_version": "v2025"}]
+	}`
+	result, err = Run(preset, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	assertEqual(t, parseResult(t, result).Attestations["confirm"].Evidence.LogicVersion, "v2024")
+}
+
+func TestArchivedPolicy(t *testing.T) {
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		policy     string
+		wantErrors int
+		wantStatus DocStatus
+	}{
+		{"", 1, StatusReady},
+		{ArchivedWarn, 1, StatusReady},
+		{ArchivedReject, 1, StatusInvalid},
+		{ArchivedAllow, 0, StatusReady},
+	}
+	for _, tt := range tests {
+		t.Run("policy="+tt.policy, func(t *testing.T) {
+			input := versionedSchema
+			if tt.policy != "" {
+				input = `{"archived_policy": "` + tt.policy + `",` + versionedSchema[1:]
+			}
+			result, err := Run(input, date)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			schema := parseResult(t, result)
+
+			archived := 0
+			for _, e := range schema.Errors {
+				if e.Kind == ErrArchivedVersion {
+					archived++
+				}
+			}
+			assertEqual(t, archived, tt.wantErrors)
+			assertEqual(t, schema.Status, tt.wantStatus)
+			// The archived branch's logic still runs, so the outcome can be inspected
+			assertDefinitionValue(t, schema, "rate", 0.25)
+		})
+	}
+
+	// Active branches are never reported
+	result, err := Run(versionedSchema, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if errs := parseResult(t, result).Errors; len(errs) != 0 {
+		t.Errorf("unexpected errors: %+v", errs)
+	}
+}
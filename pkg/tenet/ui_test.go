@@ -0,0 +1,276 @@
+package tenet
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestUISeverityAnnotations(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"income": {"type": "number", "value": 95000},
+			"deductions": {"type": "number", "value": 40000, "ui_message": "Keep your receipts"},
+			"hidden": {"type": "string", "visible": false, "ui_message": "not shown"}
+		},
+		"logic_tree": [
+			{
+				"id": "high_deductions",
+				"when": {">": [{"var": "deductions"}, 30000]},
+				"then": {"ui_modify": {"deductions": {"ui_message": "Unusually high deductions", "ui_severity": "warning"}}}
+			},
+			{
+				"id": "bogus_severity",
+				"when": {">": [{"var": "income"}, 0]},
+				"then": {"ui_modify": {"income": {"ui_message": "Looks good", "ui_severity": "shouting"}}}
+			}
+		]
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	// Soft guidance never affects status
+	assertEqual(t, schema.Status, StatusReady)
+	assertEqual(t, schema.Definitions["deductions"].UISeverity, SeverityWarning)
+
+	if len(schema.Annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %+v", schema.Annotations)
+	}
+	assertEqual(t, schema.Annotations[0], Annotation{FieldID: "deductions", Severity: SeverityWarning, Message: "Unusually high deductions"})
+	// Unknown severities are ignored and fall back to info
+	assertEqual(t, schema.Annotations[1], Annotation{FieldID: "income", Severity: SeverityInfo, Message: "Looks good"})
+}
+
+func TestComputedLabelsAndMessages(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"income": {"type": "number", "value": 42000},
+			"tax": {
+				"type": "number",
+				"label": "Tax",
+				"label_expr": {"if": [{">": [{"var": "income"}, 40000]}, "Tax (high bracket)", "Tax (low bracket)"]},
+				"ui_message_expr": {"var": "estimated_tax"}
+			},
+			"note": {"type": "string", "label": "Note", "label_expr": {"var": "missing_label"}}
+		},
+		"state_model": {
+			"derived": {
+				"estimated_tax": {"eval": {"*": [{"var": "income"}, 0.1]}},
+				"missing_label": {"eval": {"if": [false, "never"]}}
+			}
+		}
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	assertEqual(t, schema.Definitions["tax"].Label, "Tax (high bracket)")
+	assertEqual(t, schema.Definitions["tax"].UIMessage, "4200")
+	// nil result keeps the static label
+	assertEqual(t, schema.Definitions["note"].Label, "Note")
+}
+
+func TestSummaryBlock(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"applicant": {"type": "string", "value": "Ada Lovelace"},
+			"amount": {"type": "number", "value": 250000},
+			"approved": {"type": "boolean", "value": true}
+		},
+		"state_model": {
+			"derived": {"monthly": {"eval": {"/": [{"var": "amount"}, 360]}}}
+		},
+		"summary": {
+			"title": "Loan application",
+			"title_expr": {"var": "applicant"},
+			"subtitle_expr": {"if": [{"var": "approved"}, "Approved", "Pending"]},
+			"figures": [
+				{"label": "Amount", "value_expr": {"var": "amount"}},
+				{"label": "Monthly", "value_expr": {"var": "monthly"}},
+				{"label": "Rate", "value": "n/a", "value_expr": {"var": "rate"}}
+			]
+		}
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	if schema.Summary == nil {
+		t.Fatal("expected a summary block")
+	}
+	assertEqual(t, schema.Summary.Title, "Ada Lovelace")
+	assertEqual(t, schema.Summary.Subtitle, "Approved")
+	assertEqual(t, len(schema.Summary.Figures), 3)
+	assertEqual(t, schema.Summary.Figures[0].Value, any(250000.0))
+	if monthly, _ := schema.Summary.Figures[1].Value.(float64); monthly < 694 || monthly > 695 {
+		t.Errorf("Monthly = %v, want ~694.4", schema.Summary.Figures[1].Value)
+	}
+	// nil result keeps the static value
+	assertEqual(t, schema.Summary.Figures[2].Value, any("n/a"))
+}
+
+func TestFieldOrder(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"zip": {"type": "string", "order": 3},
+			"name": {"type": "string", "order": 1},
+			"notes": {"type": "string"},
+			"email": {"type": "string", "order": 2},
+			"age": {"type": "number"}
+		}
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+	want := []string{"name", "email", "zip", "age", "notes"}
+	if !slices.Equal(schema.FieldOrder, want) {
+		t.Fatalf("expected field_order %v, got %v", want, schema.FieldOrder)
+	}
+
+	// Without any order the output stays lean
+	result, err = Run(`{"definitions": {"a": {"type": "string"}}}`, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if schema := parseResult(t, result); schema.FieldOrder != nil {
+		t.Errorf("expected no field_order, got %v", schema.FieldOrder)
+	}
+}
+
+func TestFocusOrder(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"name": {"type": "string", "required": true, "order": 1, "page": "personal"},
+			"income": {"type": "number", "value": "lots", "order": 2, "page": "finances"},
+			"email": {"type": "string", "required": true, "order": 3, "page": "personal"},
+			"notes": {"type": "string", "order": 4},
+			"age": {"type": "number", "value": 12, "min": 18}
+		}
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	// The personal page is fixed first, then finances, then unordered fields
+	want := []string{"name", "email", "income", "age"}
+	if !slices.Equal(schema.FocusOrder, want) {
+		t.Errorf("focus_order = %v, want %v", schema.FocusOrder, want)
+	}
+
+	// Without declared order or pages there is no focus_order
+	result, err = Run(`{"definitions": {"a": {"type": "string", "required": true}}}`, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if focus := parseResult(t, result).FocusOrder; focus != nil {
+		t.Errorf("expected no focus_order, got %v", focus)
+	}
+}
+
+func TestTriStateUIFlags(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"unset": {"type": "string", "value": "x"},
+			"explicit": {"type": "string", "value": "x", "required": false, "readonly": false},
+			"toggled": {"type": "string", "value": "x"}
+		},
+		"logic_tree": [
+			{"id": "r1", "when": {"==": [1, 1]}, "then": {"ui_modify": {"toggled": {"required": false, "visible": false}}}}
+		]
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+
+	unset := schema.Definitions["unset"]
+	if unset.Required != nil || unset.Readonly != nil {
+		t.Errorf("unspecified flags should stay unspecified, got required=%v readonly=%v", unset.Required, unset.Readonly)
+	}
+	if !unset.IsVisible() || unset.IsRequired() || unset.IsReadonly() {
+		t.Error("unspecified flags should read as their defaults")
+	}
+
+	explicit := schema.Definitions["explicit"]
+	if explicit.Required == nil || explicit.Readonly == nil {
+		t.Error("explicit false should survive the round-trip")
+	}
+
+	toggled := schema.Definitions["toggled"]
+	if toggled.Required == nil || toggled.IsRequired() || toggled.IsVisible() {
+		t.Errorf("ui_modify should set explicit flags, got %+v", toggled)
+	}
+
+	var def Definition
+	def.SetRequired(true)
+	other := def
+	other.SetRequired(false)
+	if !def.IsRequired() {
+		t.Error("setters must not write through a shared pointer")
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"hidden_validation": "skip",
+		"definitions": {
+			"name": {"type": "string", "required": true, "page": "personal", "value": "Ada"},
+			"email": {"type": "string", "required": true, "page": "personal", "value": ""},
+			"employed": {"type": "boolean", "value": true},
+			"employer": {"type": "string", "page": "work"},
+			"spouse": {"type": "string", "required": true, "visible": false}
+		},
+		"logic_tree": [
+			{"id": "need_employer", "when": {"==": [{"var": "employed"}, true]}, "then": {"ui_modify": {"employer": {"required": true}}}}
+		]
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if parseResult(t, result).Completion != nil {
+		t.Error("completion should only be emitted with WithCompletion")
+	}
+
+	result, err = Run(input, date, WithCompletion())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	c := parseResult(t, result).Completion
+	if c == nil {
+		t.Fatal("expected a completion block")
+	}
+	// name, email and the rule-required employer; the hidden spouse is skipped
+	assertEqual(t, c.Required, 3)
+	assertEqual(t, c.Filled, 1)
+	assertEqual(t, c.Percent, 33)
+	assertEqual(t, c.Pages["personal"].Percent, 50)
+	assertEqual(t, c.Pages["work"].Required, 1)
+	assertEqual(t, c.Pages["work"].Percent, 0)
+}
@@ -3,19 +3,21 @@ package tenet
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 )
 
 // validateDefinitions checks all definitions for type correctness and required fields.
 // Accumulates all errors (non-blocking).
 func (e *Engine) validateDefinitions() {
-	for id, def := range e.schema.Definitions {
-		if def == nil {
+	for _, id := range sortedIDs(e.schema.Definitions) {
+		def := e.schema.Definitions[id]
+		if def == nil || e.skipValidation(def) {
 			continue
 		}
 
 		// Check required fields
-		if def.Required {
+		if def.IsRequired() {
 			if def.Value == nil {
 				e.addError(id, "", ErrMissingRequired, fmt.Sprintf("Required field '%s' is missing", id), "")
 			} else if def.Type == "string" || def.Type == "select" {
@@ -75,7 +77,7 @@ func (e *Engine) validateType(id string, def *Definition) {
 			e.addError(id, "", ErrTypeMismatch, fmt.Sprintf("Field '%s' must be a string", id), "")
 			return
 		}
-		if !e.isValidOption(strVal, def.Options) {
+		if !e.isValidOption(id, strVal, def.Options) {
 			e.addError(id, "", ErrConstraintViolation, fmt.Sprintf("Field '%s' value '%s' is not a valid option", id, strVal), "")
 		}
 
@@ -112,7 +114,11 @@ func (e *Engine) validateStringConstraints(id string, value string, def *Definit
 		e.addError(id, "", ErrConstraintViolation, fmt.Sprintf("Field '%s' is too long (maximum %d characters)", id, *def.MaxLength), "")
 	}
 	if def.Pattern != "" {
-		re, err := regexp.Compile(def.Pattern)
+		re := e.index.pattern(def.Pattern)
+		var err error
+		if re == nil {
+			re, err = regexp.Compile(def.Pattern)
+		}
 		if err == nil && !re.MatchString(value) {
 			e.addError(id, "", ErrConstraintViolation,
 				fmt.Sprintf("Field '%s' does not match required pattern", id), "")
@@ -120,25 +126,105 @@ func (e *Engine) validateStringConstraints(id string, value string, def *Definit
 	}
 }
 
+// validateFieldGroups checks require_together and require_one_of group constraints.
+// Fields not present in definitions are ignored here (lint reports them).
+func (e *Engine) validateFieldGroups() {
+	for _, group := range e.schema.RequireTogether {
+		if group == nil {
+			continue
+		}
+		var filled, missing []string
+		for _, id := range group.Fields {
+			def, ok := e.schema.Definitions[id]
+			if !ok || def == nil || e.skipValidation(def) {
+				continue
+			}
+			if isFilled(def.Value) {
+				filled = append(filled, id)
+			} else {
+				missing = append(missing, id)
+			}
+		}
+		if len(filled) == 0 {
+			continue
+		}
+		for _, id := range missing {
+			e.addError(id, group.ID, ErrMissingRequired, fmt.Sprintf(
+				"Field '%s' is required together with '%s'", id, strings.Join(filled, "', '")), group.LawRef)
+		}
+	}
+
+	for _, group := range e.schema.RequireOneOf {
+		if group == nil || len(group.Fields) == 0 {
+			continue
+		}
+		considered, satisfied := 0, false
+		for _, id := range group.Fields {
+			def, ok := e.schema.Definitions[id]
+			if !ok || def == nil || e.skipValidation(def) {
+				continue
+			}
+			considered++
+			if isFilled(def.Value) {
+				satisfied = true
+				break
+			}
+		}
+		if considered > 0 && !satisfied {
+			e.addError(group.Fields[0], group.ID, ErrMissingRequired, fmt.Sprintf(
+				"At least one of '%s' is required", strings.Join(group.Fields, "', '")), group.LawRef)
+		}
+	}
+}
+
+// skipValidation reports whether a definition is exempt from validation.
+// Only hidden fields are exempt, and only when the schema opts in with hidden_validation: "skip".
+func (e *Engine) skipValidation(def *Definition) bool {
+	return e.schema.HiddenValidation == HiddenSkip && !def.IsVisible()
+}
+
+// isFilled reports whether a value counts as provided (same rule as required-field validation).
+func isFilled(value any) bool {
+	if value == nil {
+		return false
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return false
+	}
+	return true
+}
+
 // checkAttestations ensures all required attestations are confirmed.
 // Validates both legacy attestations in definitions and rich attestations.
 func (e *Engine) checkAttestations() {
 	// Check legacy attestations in definitions (simple type: attestation)
-	for id, def := range e.schema.Definitions {
-		if def == nil || def.Type != "attestation" {
+	for _, id := range sortedIDs(e.schema.Definitions) {
+		def := e.schema.Definitions[id]
+		if def == nil || def.Type != "attestation" || e.skipValidation(def) {
 			continue
 		}
-		if def.Required && def.Value != true {
+		if def.IsRequired() && def.Value != true {
 			e.addError(id, "", ErrAttestationIncomplete, fmt.Sprintf("Required attestation '%s' not confirmed", id), "")
 		}
 	}
 
 	// Check rich attestations
-	for id, att := range e.schema.Attestations {
+	for _, id := range sortedIDs(e.schema.Attestations) {
+		att := e.schema.Attestations[id]
 		if att == nil {
 			continue
 		}
 
+		// A change to the values a templated statement interpolates voids the signature
+		if e.invalidateChangedStatement(id, att) {
+			continue
+		}
+
+		// So does a change to a covered field after signing
+		if e.invalidateStaleSignature(id, att) {
+			continue
+		}
+
 		// Process on_sign if signed is true
 		if att.Signed && att.OnSign != nil {
 			e.applyAction(att.OnSign, "attestation_"+id, att.LawRef)
@@ -155,11 +241,27 @@ func (e *Engine) checkAttestations() {
 	}
 }
 
+// sortedIDs returns the keys of m in sorted order, so errors and side effects
+// come out the same on every run.
+func sortedIDs[V any](m map[string]V) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // isValidOption checks if a value is in the allowed options list.
-func (e *Engine) isValidOption(value string, options []string) bool {
+// Compiled schemas look it up in a precomputed set instead of scanning.
+func (e *Engine) isValidOption(id, value string, options []string) bool {
 	if options == nil {
 		return true // No restrictions
 	}
+	if set, ok := e.index.optionSet(id); ok {
+		_, found := set[value]
+		return found
+	}
 	for _, opt := range options {
 		if opt == value {
 			return true
@@ -175,6 +277,9 @@ func (e *Engine) determineStatus() DocStatus {
 		if err.Kind == ErrTypeMismatch {
 			return StatusInvalid
 		}
+		if err.Kind == ErrArchivedVersion && e.schema.ArchivedPolicy == ArchivedReject {
+			return StatusInvalid
+		}
 	}
 	for _, err := range e.errors {
 		if err.Kind == ErrMissingRequired || err.Kind == ErrAttestationIncomplete {
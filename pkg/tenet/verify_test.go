@@ -1,7 +1,10 @@
 package tenet
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestVerifyTurnBased(t *testing.T) {
@@ -102,6 +105,12 @@ func TestVerifyTurnBased(t *testing.T) {
 				if issue.Claimed != "low" {
 					t.Fatalf("Expected claimed 'low', got claimed=%v", issue.Claimed)
 				}
+				if issue.Severity != VerifySeverityError {
+					t.Fatalf("Expected error severity, got %q", issue.Severity)
+				}
+				if issue.Remediation == nil || issue.Remediation.Action != RemediationSetValue || issue.Remediation.Value != "high" {
+					t.Fatalf("Expected set_value remediation to 'high', got %+v", issue.Remediation)
+				}
 			}
 		}
 		if !found {
@@ -259,6 +268,10 @@ func TestVerifyWithAttestations(t *testing.T) {
 		for _, issue := range result.Issues {
 			if issue.Code == VerifyAttestationNoEvidence && issue.FieldID == "officer_sign" {
 				foundEvidence = true
+				if issue.Remediation == nil || issue.Remediation.Action != RemediationResignAttestation ||
+					issue.Remediation.Description != "re-sign attestation 'officer_sign'" {
+					t.Fatalf("Expected resign_attestation remediation, got %+v", issue.Remediation)
+				}
 			}
 		}
 		if !foundEvidence {
@@ -307,3 +320,587 @@ func TestVerifyConvergence(t *testing.T) {
 		}
 	})
 }
+
+func TestVerifyWithCompiled(t *testing.T) {
+	baseSchema := `{
+		"definitions": {
+			"revenue": {"type": "number", "value": null},
+			"details": {"type": "string", "visible": false}
+		},
+		"logic_tree": [
+			{
+				"id": "show_details",
+				"when": {">": [{"var": "revenue"}, 5000]},
+				"then": {"ui_modify": {"details": {"visible": true, "required": true}}, "set": {"meta.flagged": true}}
+			}
+		]
+	}`
+
+	compiled, err := Compile(baseSchema)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	large := `{
+		"definitions": {
+			"revenue": {"type": "number", "value": 9000},
+			"details": {"type": "string", "value": "big", "visible": true, "required": true},
+			"meta": {"type": "object", "value": {"flagged": true}}
+		},
+		"status": "READY"
+	}`
+	small := `{
+		"definitions": {
+			"revenue": {"type": "number", "value": 100},
+			"details": {"type": "string", "visible": false}
+		},
+		"status": "READY"
+	}`
+
+	// Reusing the compiled base must not leak state between calls
+	for i := 0; i < 3; i++ {
+		if vr := VerifyWithCompiled(large, compiled); !vr.Valid {
+			t.Fatalf("large: expected valid, got %+v", vr.Issues)
+		}
+		vr := VerifyWithCompiled(small, compiled)
+		if !vr.Valid {
+			t.Fatalf("small: expected valid, got %+v", vr.Issues)
+		}
+		if _, leaked := vr.Schema.Definitions["meta"]; leaked {
+			t.Fatal("definition created in a previous call leaked into the compiled base")
+		}
+	}
+
+	if _, err := Compile("{"); err == nil {
+		t.Error("expected Compile to fail on invalid JSON")
+	}
+	if vr := Verify(large, "{"); vr.Valid || vr.Error == "" {
+		t.Error("expected Verify to report an invalid base schema")
+	}
+}
+
+func TestVerifySchema(t *testing.T) {
+	date := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	base := parseResult(t, createLoanSchema("employed", 720, 75000, 250000))
+	completed, err := RunSchema(base, Options{EffectiveDate: date})
+	if err != nil {
+		t.Fatalf("RunSchema failed: %v", err)
+	}
+
+	if vr := VerifySchema(completed, base); !vr.Valid {
+		t.Fatalf("expected valid, got %+v", vr.Issues)
+	}
+
+	if completed.Status == StatusInvalid {
+		t.Fatal("expected the loan to evaluate to a valid status")
+	}
+	completed.Status = StatusInvalid
+	vr := VerifySchema(completed, base)
+	if vr.Valid {
+		t.Fatal("expected a tampered status to be reported")
+	}
+	jsonDoc, _ := json.Marshal(completed)
+	jsonBase, _ := json.Marshal(base)
+	if want := Verify(string(jsonDoc), string(jsonBase)); len(want.Issues) != len(vr.Issues) || want.Issues[0].Code != vr.Issues[0].Code {
+		t.Errorf("VerifySchema and Verify disagree: %+v vs %+v", vr.Issues, want.Issues)
+	}
+
+	if vr := VerifySchema(nil, base); vr.Valid || vr.Error == "" {
+		t.Error("expected an error for a nil schema")
+	}
+}
+
+func TestVerifyEvidenceWindow(t *testing.T) {
+	baseSchema := `{
+		"definitions": {
+			"income": {"type": "number", "value": null, "visible": true}
+		},
+		"temporal_map": [
+			{"valid_range": ["2025-01-01", "2025-12-31"], "logic_version": "v2025", "status": "ACTIVE"}
+		],
+		"attestations": {
+			"officer_sign": {"statement": "I certify the income", "required": true}
+		}
+	}`
+
+	completed := func(changedAt, signedAt string) string {
+		return `{
+			"valid_from": "2025-06-01",
+			"definitions": {
+				"income": {"type": "number", "value": 50000, "visible": true, "changed_at": "` + changedAt + `"}
+			},
+			"attestations": {
+				"officer_sign": {"statement": "I certify the income", "required": true, "signed": true,
+					"evidence": {"provider_audit_id": "a-1", "timestamp": "` + signedAt + `"}}
+			},
+			"status": "READY"
+		}`
+	}
+
+	hasIssue := func(vr VerifyResult, code VerifyIssueCode) bool {
+		for _, issue := range vr.Issues {
+			if issue.Code == code && issue.FieldID == "officer_sign" {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("signed after last change", func(t *testing.T) {
+		vr := Verify(completed("2025-06-01T09:00:00Z", "2025-06-01T10:00:00Z"), baseSchema)
+		if !vr.Valid {
+			t.Fatalf("expected valid, got %+v", vr.Issues)
+		}
+	})
+
+	t.Run("signed before content changed", func(t *testing.T) {
+		vr := Verify(completed("2025-06-02T09:00:00Z", "2025-06-01T10:00:00Z"), baseSchema)
+		if vr.Valid || !hasIssue(vr, VerifyAttestationStale) {
+			t.Fatalf("expected attestation_stale, got %+v", vr.Issues)
+		}
+	})
+
+	t.Run("signed outside the temporal branch", func(t *testing.T) {
+		vr := Verify(completed("2024-12-01T09:00:00Z", "2024-12-31T23:00:00Z"), baseSchema)
+		if vr.Valid || !hasIssue(vr, VerifyAttestationOutsideVersion) {
+			t.Fatalf("expected attestation_outside_version, got %+v", vr.Issues)
+		}
+	})
+
+	t.Run("last day of the branch is inside", func(t *testing.T) {
+		vr := Verify(completed("2025-12-31T09:00:00Z", "2025-12-31T18:00:00Z"), baseSchema)
+		if hasIssue(vr, VerifyAttestationOutsideVersion) {
+			t.Fatalf("signature on the branch's last day should be inside, got %+v", vr.Issues)
+		}
+	})
+}
+
+func TestVerifyWithEvidence(t *testing.T) {
+	baseSchema := `{
+		"definitions": {
+			"income": {"type": "number", "value": null, "visible": true}
+		},
+		"attestations": {
+			"officer_sign": {"statement": "I certify the income", "required": true}
+		}
+	}`
+	compiled, err := Compile(baseSchema)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	completed := func(auditID, signedAt string) string {
+		return `{
+			"valid_from": "2025-06-01",
+			"definitions": {
+				"income": {"type": "number", "value": 50000, "visible": true, "changed_at": "2025-06-01T09:00:00Z"}
+			},
+			"attestations": {
+				"officer_sign": {"statement": "I certify the income", "required": true, "signed": true,
+					"evidence": {"provider_audit_id": "` + auditID + `", "timestamp": "` + signedAt + `", "signer_id": "ann"}}
+			},
+			"status": "READY"
+		}`
+	}
+
+	store := NewMemoryEvidenceStore()
+	if err := store.Save(&Evidence{Timestamp: "2025-06-01T10:00:00Z"}); err == nil {
+		t.Error("expected Save to reject evidence without a provider_audit_id")
+	}
+	store.Save(&Evidence{ProviderAuditID: "a-1", Timestamp: "2025-06-01T10:00:00Z", SignerID: "ann"})
+	store.Save(&Evidence{ProviderAuditID: "a-2", Timestamp: "2025-06-01T08:00:00Z", SignerID: "ann"})
+
+	codes := func(vr VerifyResult) []VerifyIssueCode {
+		var out []VerifyIssueCode
+		for _, issue := range vr.Issues {
+			out = append(out, issue.Code)
+		}
+		return out
+	}
+
+	t.Run("matches the stored record", func(t *testing.T) {
+		vr := VerifyWithEvidence(completed("a-1", "2025-06-01T10:00:00Z"), compiled, store)
+		if !vr.Valid {
+			t.Fatalf("expected valid, got %+v", vr.Issues)
+		}
+	})
+
+	t.Run("unknown record", func(t *testing.T) {
+		vr := VerifyWithEvidence(completed("forged", "2025-06-01T10:00:00Z"), compiled, store)
+		if vr.Valid || len(vr.Issues) == 0 || vr.Issues[0].Code != VerifyEvidenceNotFound {
+			t.Fatalf("expected evidence_not_found, got %v", codes(vr))
+		}
+		if vr.Issues[0].Remediation == nil || vr.Issues[0].Remediation.Action != RemediationResignAttestation {
+			t.Errorf("expected a re-sign remediation, got %+v", vr.Issues[0].Remediation)
+		}
+	})
+
+	t.Run("stored timestamp wins", func(t *testing.T) {
+		// The document claims a signature after the last change; the provider recorded one before it
+		vr := VerifyWithEvidence(completed("a-2", "2025-06-01T10:00:00Z"), compiled, store)
+		got := codes(vr)
+		if vr.Valid || len(got) < 2 || got[0] != VerifyEvidenceMismatch {
+			t.Fatalf("expected evidence_mismatch first, got %v", got)
+		}
+		stale := false
+		for _, code := range got {
+			stale = stale || code == VerifyAttestationStale
+		}
+		if !stale {
+			t.Errorf("expected the stored timestamp to make the signature stale, got %v", got)
+		}
+	})
+
+	t.Run("without a store", func(t *testing.T) {
+		if vr := VerifyWithCompiled(completed("forged", "2025-06-01T10:00:00Z"), compiled); !vr.Valid {
+			t.Fatalf("expected the embedded evidence to be trusted, got %+v", vr.Issues)
+		}
+	})
+
+	t.Run("service", func(t *testing.T) {
+		svc := NewService(ServiceConfig{EvidenceStore: store})
+		if err := svc.Register("income", baseSchema); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		if vr := svc.Verify("income", completed("forged", "2025-06-01T10:00:00Z")); vr.Valid {
+			t.Error("expected the service to check evidence against its store")
+		}
+	})
+}
+
+func TestAttestationFields(t *testing.T) {
+	date := time.Date(2026, 1, 17, 0, 0, 0, 0, time.UTC)
+	base := `{
+		"definitions": {
+			"amount": {"type": "number", "value": null},
+			"witness_signed": {"type": "string", "value": "kept"}
+		},
+		"attestations": {
+			"officer": {"statement": "I certify this is correct", "required": true},
+			"witness": {"statement": "I witnessed the signature"}
+		}
+	}`
+
+	result, err := Run(base, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if _, ok := parseResult(t, result).Definitions["officer_signed"]; ok {
+		t.Error("attestation fields should only be added with WithAttestationFields")
+	}
+
+	signed := strings.Replace(base, `"required": true}`, `"required": true, "signed": true,
+		"evidence": {"provider_audit_id": "ds_1", "timestamp": "2026-01-17T12:00:00Z", "signer_id": "john@example.com"}}`, 1)
+	signed = strings.Replace(signed, `"value": null`, `"value": 5000`, 1)
+	result, err = Run(signed, date, WithAttestationFields())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	schema := parseResult(t, result)
+	assertDefinitionValue(t, schema, "officer_signed", true)
+	assertDefinitionValue(t, schema, "officer_signer", "john@example.com")
+	assertDefinitionValue(t, schema, "officer_signed_at", "2026-01-17T12:00:00Z")
+	assertDefinitionValue(t, schema, "witness_signer", nil)
+	// An author's definition isn't replaced
+	assertDefinitionValue(t, schema, "witness_signed", "kept")
+	if def := schema.Definitions["officer_signed"]; !def.IsReadonly() || def.Attestation != "officer" {
+		t.Errorf("expected a readonly mirror of officer, got %+v", def)
+	}
+
+	// Mirrors are output only: a plain re-run drops them and Verify doesn't treat them as input
+	rerun, err := Run(result, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if _, ok := parseResult(t, rerun).Definitions["officer_signer"]; ok {
+		t.Error("expected mirrored fields to be dropped without the option")
+	}
+	if vr := Verify(result, base); !vr.Valid {
+		t.Errorf("expected the mirrored document to verify, got %+v", vr.Issues)
+	}
+}
+
+func TestAttestationCovers(t *testing.T) {
+	doc := func(incomeChanged, notesChanged string) string {
+		return `{
+			"definitions": {
+				"income": {"type": "number", "value": 50000, "visible": true, "changed_at": "` + incomeChanged + `"},
+				"notes": {"type": "string", "value": "ok", "visible": true, "changed_at": "` + notesChanged + `"}
+			},
+			"attestations": {
+				"income_sign": {"statement": "I certify my income", "required": true, "covers": ["income"],
+					"signed": true, "evidence": {"provider_audit_id": "a-1", "timestamp": "2025-06-01T10:00:00Z"}}
+			}
+		}`
+	}
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("uncovered change keeps the signature", func(t *testing.T) {
+		result, err := Run(doc("2025-06-01T09:00:00Z", "2025-06-02T09:00:00Z"), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		parsed := parseResult(t, result)
+		assertEqual(t, parsed.Status, StatusReady)
+		assertEqual(t, parsed.Attestations["income_sign"].Signed, true)
+	})
+
+	t.Run("covered change requires re-sign", func(t *testing.T) {
+		result, err := Run(doc("2025-06-02T09:00:00Z", "2025-06-01T09:00:00Z"), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		parsed := parseResult(t, result)
+		assertEqual(t, parsed.Status, StatusIncomplete)
+		assertEqual(t, parsed.Attestations["income_sign"].Signed, false)
+		if len(parsed.Errors) != 1 || !strings.Contains(parsed.Errors[0].Message, "must be re-signed") {
+			t.Fatalf("expected a re-sign error, got %+v", parsed.Errors)
+		}
+	})
+
+	t.Run("verify flags the stale signature", func(t *testing.T) {
+		base := `{
+			"definitions": {
+				"income": {"type": "number", "value": null, "visible": true},
+				"notes": {"type": "string", "value": null, "visible": true}
+			},
+			"attestations": {"income_sign": {"statement": "I certify my income", "required": true, "covers": ["income"]}}
+		}`
+		completed := strings.Replace(doc("2025-06-02T09:00:00Z", "2025-06-01T09:00:00Z"),
+			`"attestations"`, `"valid_from": "2025-06-01", "status": "INCOMPLETE", "attestations"`, 1)
+
+		vr := Verify(completed, base)
+		if vr.Valid {
+			t.Fatal("expected invalid due to stale signature")
+		}
+		for _, issue := range vr.Issues {
+			if issue.Code == VerifyStatusMismatch {
+				t.Fatalf("replay should reach the same status, got %+v", issue)
+			}
+		}
+		found := false
+		for _, issue := range vr.Issues {
+			if issue.Code == VerifyAttestationStale && issue.FieldID == "income_sign" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("expected attestation_stale, got %+v", vr.Issues)
+		}
+	})
+}
+
+func TestAttestationStatementTemplate(t *testing.T) {
+	doc := func(income, evidence string) string {
+		return `{
+			"definitions": {
+				"income": {"type": "number", "value": ` + income + `, "visible": true}
+			},
+			"attestations": {
+				"income_sign": {"statement": "I certify that the reported income of {{income}} kr is accurate", "required": true,
+					"signed": true, "evidence": {"provider_audit_id": "a-1", "timestamp": "2025-06-01T10:00:00Z"` + evidence + `}}
+			}
+		}`
+	}
+	date := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	const rendered = "I certify that the reported income of 420000 kr is accurate"
+
+	t.Run("rendered and frozen at signing", func(t *testing.T) {
+		result, err := Run(doc("420000", `, "statement": "`+rendered+`"`), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		att := parseResult(t, result).Attestations["income_sign"]
+		assertEqual(t, att.RenderedStatement, rendered)
+		assertEqual(t, att.Signed, true)
+	})
+
+	t.Run("signature without a frozen statement requires re-sign", func(t *testing.T) {
+		// Run must not stamp the current figures as what was signed
+		result, err := Run(doc("510000", ""), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		parsed := parseResult(t, result)
+		att := parsed.Attestations["income_sign"]
+		assertEqual(t, att.Signed, false)
+		assertEqual(t, att.Evidence.Statement, "")
+		if len(parsed.Errors) != 1 || !strings.Contains(parsed.Errors[0].Message, "must be re-signed") {
+			t.Fatalf("expected a re-sign error, got %+v", parsed.Errors)
+		}
+	})
+
+	t.Run("changed value requires re-sign", func(t *testing.T) {
+		result, err := Run(doc("510000", `, "statement": "`+rendered+`"`), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		parsed := parseResult(t, result)
+		assertEqual(t, parsed.Status, StatusIncomplete)
+		assertEqual(t, parsed.Attestations["income_sign"].Signed, false)
+		if len(parsed.Errors) != 1 || !strings.Contains(parsed.Errors[0].Message, "must be re-signed") {
+			t.Fatalf("expected a re-sign error, got %+v", parsed.Errors)
+		}
+	})
+
+	t.Run("verify checks the signed statement", func(t *testing.T) {
+		base := `{
+			"definitions": {"income": {"type": "number", "value": null, "visible": true}},
+			"attestations": {"income_sign": {"statement": "I certify that the reported income of {{income}} kr is accurate", "required": true}}
+		}`
+		matching := strings.Replace(doc("420000", `, "statement": "`+rendered+`"`), `"attestations"`, `"status": "READY", "attestations"`, 1)
+		if vr := Verify(matching, base); !vr.Valid {
+			t.Fatalf("expected valid, got %+v", vr.Issues)
+		}
+
+		tampered := strings.Replace(doc("510000", `, "statement": "`+rendered+`"`), `"attestations"`, `"status": "INCOMPLETE", "attestations"`, 1)
+		vr := Verify(tampered, base)
+		found := false
+		for _, issue := range vr.Issues {
+			if issue.Code == VerifyAttestationStatementChanged && issue.FieldID == "income_sign" {
+				found = true
+			}
+		}
+		if vr.Valid || !found {
+			t.Fatalf("expected attestation_statement_changed, got %+v", vr.Issues)
+		}
+	})
+}
+
+func TestCompilePrecomputes(t *testing.T) {
+	baseSchema := `{
+		"definitions": {
+			"country": {"type": "select", "options": ["SE", "NO", "DK"], "value": null},
+			"zip": {"type": "string", "pattern": "^[0-9]{5}$", "value": null},
+			"address": {"type": "object", "value": null},
+			"nordic": {"type": "boolean", "readonly": true, "value": false}
+		},
+		"logic_tree": [
+			{"id": "nordic", "when": ["country in ['SE', 'NO', 'DK']", "address.city != null"], "then": {"set": {"nordic": true}}}
+		]
+	}`
+
+	compiled, err := Compile(baseSchema)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if _, ok := compiled.index.optionSet("country"); !ok {
+		t.Error("expected an option set for 'country'")
+	}
+	if compiled.index.pattern("^[0-9]{5}$") == nil {
+		t.Error("expected a precompiled pattern")
+	}
+	if parts := compiled.index.path("address.city"); len(parts) != 2 {
+		t.Errorf("expected indexed path segments, got %v", parts)
+	}
+	if _, isString := compiled.base.LogicTree[0].When.([]any)[0].(string); isString {
+		t.Error("expected infix conditions to be compiled at Compile time")
+	}
+
+	completed := `{
+		"definitions": {
+			"country": {"type": "select", "options": ["SE", "NO", "DK"], "value": "FI"},
+			"zip": {"type": "string", "pattern": "^[0-9]{5}$", "value": "1234"},
+			"address": {"type": "object", "value": {"city": "Oslo"}},
+			"nordic": {"type": "boolean", "readonly": true, "value": false}
+		},
+		"status": "INVALID"
+	}`
+	vr := VerifyWithCompiled(completed, compiled)
+	if !vr.Valid {
+		t.Fatalf("expected valid, got %+v", vr.Issues)
+	}
+	assertEqual(t, len(vr.Schema.Errors), 2) // invalid option and pattern mismatch
+}
+
+func TestVerifyPrefillPolicy(t *testing.T) {
+	base := func(policy, override string) string {
+		return `{
+			"prefill_policy": "` + policy + `",
+			"definitions": {
+				"country": {"type": "string", "value": "SE"` + override + `},
+				"name": {"type": "string", "value": null}
+			}
+		}`
+	}
+	completed := `{
+		"definitions": {
+			"country": {"type": "string", "value": "NO"},
+			"name": {"type": "string", "value": "Ada"}
+		},
+		"status": "READY"
+	}`
+
+	t.Run("editable by default", func(t *testing.T) {
+		vr := Verify(completed, base("", ""))
+		if !vr.Valid {
+			t.Fatalf("expected valid, got %+v", vr.Issues)
+		}
+		assertEqual(t, vr.Schema.Definitions["country"].Value, "NO")
+	})
+
+	t.Run("fixed prefill must match", func(t *testing.T) {
+		vr := Verify(completed, base(PrefillFixed, ""))
+		if vr.Valid || len(vr.Issues) != 1 {
+			t.Fatalf("expected one issue, got %+v", vr.Issues)
+		}
+		issue := vr.Issues[0]
+		assertEqual(t, issue.Code, VerifyPrefillChanged)
+		assertEqual(t, issue.FieldID, "country")
+		assertEqual(t, issue.Remediation.Value, "SE")
+		assertEqual(t, vr.Schema.Definitions["country"].Value, "SE")
+	})
+
+	t.Run("definition overrides schema policy", func(t *testing.T) {
+		vr := Verify(completed, base(PrefillFixed, `, "prefill": "editable"`))
+		if !vr.Valid {
+			t.Fatalf("expected valid, got %+v", vr.Issues)
+		}
+	})
+}
+
+func TestVerifyRenamedFields(t *testing.T) {
+	base := `{
+		"definitions": {
+			"annual_income": {"type": "number", "value": null, "renamed_from": ["income"]},
+			"name": {"type": "string", "value": null}
+		},
+		"state_model": {"derived": {"monthly_income": {"eval": {"/": [{"var": "annual_income"}, 12]}}}}
+	}`
+
+	t.Run("legacy ID is read under the current one", func(t *testing.T) {
+		legacy := `{
+			"definitions": {
+				"income": {"type": "number", "value": 60000},
+				"name": {"type": "string", "value": "Ada"},
+				"monthly_income": {"type": "number", "value": 5000, "readonly": true}
+			},
+			"status": "READY"
+		}`
+		vr := Verify(legacy, base)
+		if !vr.Valid || len(vr.Issues) != 1 {
+			t.Fatalf("expected valid with one warning, got %+v", vr.Issues)
+		}
+		issue := vr.Issues[0]
+		assertEqual(t, issue.Code, VerifyRenamedField)
+		assertEqual(t, issue.Severity, VerifySeverityWarning)
+		assertEqual(t, issue.FieldID, "income")
+		assertEqual(t, issue.Remediation.Value, "annual_income")
+		assertEqual(t, vr.Schema.Definitions["annual_income"].Value, 60000.0)
+	})
+
+	t.Run("legacy ID next to the current one is unknown", func(t *testing.T) {
+		both := `{
+			"definitions": {
+				"income": {"type": "number", "value": 1},
+				"annual_income": {"type": "number", "value": 60000},
+				"name": {"type": "string", "value": "Ada"},
+				"monthly_income": {"type": "number", "value": 5000, "readonly": true}
+			},
+			"status": "READY"
+		}`
+		vr := Verify(both, base)
+		if vr.Valid || len(vr.Issues) != 1 || vr.Issues[0].Code != VerifyUnknownField {
+			t.Fatalf("expected an unknown_field issue, got %+v", vr.Issues)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package tenet
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EngineVersion is the version of this engine's evaluation semantics. Schemas pin the
+// versions they were written for with requires_engine.
+const EngineVersion = "0.5.0"
+
+// ErrEngineIncompatible is wrapped by every *EngineVersionError, for errors.Is checks.
+var ErrEngineIncompatible = errors.New("incompatible engine version")
+
+// EngineVersionError reports a schema whose requires_engine the running engine doesn't satisfy.
+type EngineVersionError struct {
+	Requires string // The schema's requires_engine constraint
+	Version  string // EngineVersion of the running engine
+	Reason   string // Set when the constraint itself is malformed
+}
+
+func (e *EngineVersionError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: requires_engine '%s': %s", ErrEngineIncompatible, e.Requires, e.Reason)
+	}
+	return fmt.Sprintf("%s: schema requires engine %s, running %s", ErrEngineIncompatible, e.Requires, e.Version)
+}
+
+// Unwrap makes errors.Is(err, ErrEngineIncompatible) hold.
+func (e *EngineVersionError) Unwrap() error {
+	return ErrEngineIncompatible
+}
+
+// checkEngine returns an *EngineVersionError when the schema's requires_engine
+// excludes EngineVersion. Schemas without requires_engine run on any engine.
+func checkEngine(s *Schema) error {
+	if s.RequiresEngine == "" {
+		return nil
+	}
+	ok, err := versionSatisfies(EngineVersion, s.RequiresEngine)
+	if err != nil {
+		return &EngineVersionError{Requires: s.RequiresEngine, Version: EngineVersion, Reason: err.Error()}
+	}
+	if !ok {
+		return &EngineVersionError{Requires: s.RequiresEngine, Version: EngineVersion}
+	}
+	return nil
+}
+
+// versionSatisfies checks version against comma-separated constraints such as ">=0.5, <1".
+// Operators are >=, >, <=, <, = and ==; a bare version means >=.
+func versionSatisfies(version, constraints string) (bool, error) {
+	have, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	for _, part := range strings.Split(constraints, ",") {
+		part = strings.TrimSpace(part)
+		op := ">="
+		for _, candidate := range []string{">=", "<=", "==", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(part[len(candidate):])
+				break
+			}
+		}
+		want, err := parseVersion(part)
+		if err != nil {
+			return false, err
+		}
+		cmp := compareVersions(have, want)
+		var ok bool
+		switch op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parseVersion parses "major[.minor[.patch]]" (an optional leading "v" is allowed).
+// Missing components are zero.
+func parseVersion(text string) ([3]int, error) {
+	var v [3]int
+	parts := strings.Split(strings.TrimPrefix(text, "v"), ".")
+	if text == "" || len(parts) > 3 {
+		return v, fmt.Errorf("invalid version '%s'", text)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version '%s'", text)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, equal to or newer than b.
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,187 @@
+package tenet
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnHideClear(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := `{
+		"definitions": {
+			"married": {"type": "boolean", "value": null},
+			"spouse_income": {"type": "number", "value": null, "visible": false, "on_hide": "clear"},
+			"spouse_note": {"type": "number", "value": null, "visible": false}
+		},
+		"logic_tree": [
+			{
+				"id": "show_spouse",
+				"when": {"==": [{"var": "married"}, true]},
+				"then": {"ui_modify": {"spouse_income": {"visible": true}, "spouse_note": {"visible": true}}}
+			}
+		],
+		"state_model": {
+			"derived": {
+				"has_spouse_income": {"eval": {"!=": [{"var": "spouse_income"}, null]}}
+			}
+		}
+	}`
+
+	t.Run("hidden stale value is cleared", func(t *testing.T) {
+		// User first said married, typed an invalid value, then changed to not married
+		input := `{
+			"definitions": {
+				"married": {"type": "boolean", "value": false},
+				"spouse_income": {"type": "number", "value": "oops", "visible": false, "on_hide": "clear"},
+				"spouse_note": {"type": "number", "value": 12, "visible": false}
+			},
+			"logic_tree": [
+				{
+					"id": "show_spouse",
+					"when": {"==": [{"var": "married"}, true]},
+					"then": {"ui_modify": {"spouse_income": {"visible": true}, "spouse_note": {"visible": true}}}
+				}
+			],
+			"state_model": {
+				"derived": {
+					"has_spouse_income": {"eval": {"!=": [{"var": "spouse_income"}, null]}}
+				}
+			}
+		}`
+
+		result, err := Run(input, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+
+		assertDefinitionValue(t, schema, "spouse_income", nil)
+		assertDefinitionValue(t, schema, "has_spouse_income", false)
+		// Fields without on_hide keep their value
+		assertDefinitionValue(t, schema, "spouse_note", float64(12))
+		assertEqual(t, schema.Status, StatusReady)
+	})
+
+	t.Run("visible value is kept", func(t *testing.T) {
+		input := `{
+			"definitions": {
+				"married": {"type": "boolean", "value": true},
+				"spouse_income": {"type": "number", "value": 30000, "visible": false, "on_hide": "clear"}
+			},
+			"logic_tree": [
+				{
+					"id": "show_spouse",
+					"when": {"==": [{"var": "married"}, true]},
+					"then": {"ui_modify": {"spouse_income": {"visible": true}}}
+				}
+			]
+		}`
+
+		result, err := Run(input, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		assertDefinitionValue(t, parseResult(t, result), "spouse_income", float64(30000))
+	})
+
+	t.Run("Verify accepts the cleared document", func(t *testing.T) {
+		completed := `{
+			"definitions": {
+				"married": {"type": "boolean", "value": false},
+				"spouse_income": {"type": "number", "value": null, "visible": false, "on_hide": "clear"},
+				"spouse_note": {"type": "number", "value": null, "visible": false},
+				"has_spouse_income": {"type": "boolean", "value": false, "readonly": true}
+			},
+			"status": "READY"
+		}`
+
+		vr := Verify(completed, base)
+		if !vr.Valid {
+			t.Fatalf("expected valid, got %+v", vr.Issues)
+		}
+	})
+}
+
+func TestHiddenValidationMode(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	schemaFor := func(mode string) string {
+		return `{
+			"hidden_validation": "` + mode + `",
+			"definitions": {
+				"has_company": {"type": "boolean", "value": false},
+				"company_name": {"type": "string", "value": null, "required": true, "visible": false},
+				"company_size": {"type": "number", "value": -5, "min": 0, "visible": false},
+				"company_sign": {"type": "attestation", "value": null, "required": true, "visible": false}
+			},
+			"logic_tree": [
+				{
+					"id": "show_company",
+					"when": {"var": "has_company"},
+					"then": {"ui_modify": {"company_name": {"visible": true}, "company_size": {"visible": true}}}
+				}
+			]
+		}`
+	}
+
+	t.Run("default validates hidden fields", func(t *testing.T) {
+		result, err := Run(schemaFor(""), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		assertEqual(t, schema.Status, StatusIncomplete)
+		// company_name + company_sign missing, company_sign unconfirmed, company_size below min
+		if len(schema.Errors) != 4 {
+			t.Errorf("expected 4 errors, got %v", schema.Errors)
+		}
+	})
+
+	t.Run("skip ignores hidden fields", func(t *testing.T) {
+		result, err := Run(schemaFor(HiddenSkip), date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		assertEqual(t, schema.Status, StatusReady)
+		assertEqual(t, len(schema.Errors), 0)
+	})
+
+	t.Run("skip still validates fields once shown", func(t *testing.T) {
+		input := strings.Replace(schemaFor(HiddenSkip), `"value": false`, `"value": true`, 1)
+		result, err := Run(input, date)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		schema := parseResult(t, result)
+		assertEqual(t, schema.Status, StatusIncomplete)
+		// company_name missing + company_size below min; company_sign stays hidden
+		assertEqual(t, len(schema.Errors), 2)
+	})
+}
+
+func TestSparseVisibleOutput(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := `{
+		"definitions": {
+			"name": {"type": "string"},
+			"secret": {"type": "string", "visible": false}
+		}
+	}`
+
+	result, err := Run(input, date)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.Count(result, `"visible"`) != 1 || !strings.Contains(result, `"visible": false`) {
+		t.Errorf("expected only the hidden field to carry visible, got %s", result)
+	}
+
+	result, err = Run(input, date, WithVerboseOutput())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(result, `"visible": true`) {
+		t.Errorf("verbose output should include visible: true, got %s", result)
+	}
+}